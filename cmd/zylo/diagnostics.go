@@ -0,0 +1,284 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+	"github.com/zylo-lang/zylo/internal/builder"
+	"github.com/zylo-lang/zylo/internal/compiler"
+	"github.com/zylo-lang/zylo/internal/diag"
+	"github.com/zylo-lang/zylo/internal/evaluator"
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/modules"
+	"github.com/zylo-lang/zylo/internal/parser"
+	"github.com/zylo-lang/zylo/internal/sema"
+	"github.com/zylo-lang/zylo/internal/testrunner"
+)
+
+// outputOptions son las opciones de build, check y test que deciden cómo se
+// informan los diagnósticos y cuándo fallan.
+type outputOptions struct {
+	format string        // "text", "json" o "sarif"
+	failOn diag.Severity // Gravedad a partir de la cual el comando falla
+	never  bool          // --fail-on=none: los diagnósticos nunca hacen fallar
+}
+
+func defaultOutputOptions() outputOptions {
+	return outputOptions{format: "text", failOn: diag.Error}
+}
+
+// set aplica la opción name (--format o --fail-on) con value. Devuelve false
+// si name no es una de ellas.
+func (o *outputOptions) set(name, value string) (bool, error) {
+	switch name {
+	case "--format":
+		if value != "text" && value != "json" && value != "sarif" {
+			return true, fmt.Errorf("--format debe ser text, json o sarif")
+		}
+		o.format = value
+	case "--fail-on":
+		if value == "none" {
+			o.never = true
+			return true, nil
+		}
+		severity, err := diag.ParseSeverity(value)
+		if err != nil {
+			return true, fmt.Errorf("--fail-on debe ser error, warning, note o none")
+		}
+		o.failOn, o.never = severity, false
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// machine indica si la salida estándar es solo para el documento JSON o
+// SARIF; los mensajes para el usuario van entonces a stderr.
+func (o *outputOptions) machine() bool {
+	return o.format != "text"
+}
+
+// log devuelve dónde se escriben los mensajes para el usuario.
+func (o *outputOptions) log() io.Writer {
+	if o.machine() {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// emit escribe diagnostics con el formato elegido. En texto los muestra
+// con printer, si hay alguno; en JSON y SARIF siempre escribe el documento.
+func (o *outputOptions) emit(printer *diag.Printer, diagnostics []diag.Diagnostic) {
+	var err error
+	switch o.format {
+	case "json":
+		err = diag.WriteJSON(os.Stdout, diagnostics)
+	case "sarif":
+		err = diag.WriteSARIF(os.Stdout, diagnostics)
+	default:
+		if len(diagnostics) > 0 {
+			report(printer, diagnostics)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error escribiendo los diagnósticos: %v\n", err)
+	}
+}
+
+// exitCode devuelve 1 si algún diagnóstico es tan grave como --fail-on.
+func (o *outputOptions) exitCode(diagnostics []diag.Diagnostic) int {
+	if o.never {
+		return 0
+	}
+	for _, d := range diagnostics {
+		if d.Severity.AtLeast(o.failOn) {
+			return 1
+		}
+	}
+	return 0
+}
+
+// failed indica si hay algún error entre diagnostics.
+func failed(diagnostics []diag.Diagnostic) bool {
+	for _, d := range diagnostics {
+		if d.Severity == diag.Error {
+			return true
+		}
+	}
+	return false
+}
+
+// newPrinter crea el Printer de los errores del CLI, que van a la salida
+// estándar, con el código fuente de filename ya leído.
+func newPrinter(filename, source string) *diag.Printer {
+	printer := diag.NewPrinter(os.Stdout, diag.ColorEnabled(os.Stdout))
+	printer.SetSource(filename, source)
+	return printer
+}
+
+// report escribe diagnostics y cuántos son.
+func report(printer *diag.Printer, diagnostics []diag.Diagnostic) {
+	for _, d := range diagnostics {
+		printer.Print(d)
+	}
+	if len(diagnostics) == 1 {
+		fmt.Println("1 error")
+	} else {
+		fmt.Printf("%d errores\n", len(diagnostics))
+	}
+}
+
+// checkSource parsea source, el contenido de filename, y resuelve sus
+// nombres y comprueba sus tipos. Cada paso solo se hace si el anterior no
+// tuvo errores.
+func checkSource(filename, source string) (*ast.Program, []diag.Diagnostic) {
+	p := parser.New(lexer.New(source))
+	program := p.ParseProgram()
+	if len(p.Diagnostics()) > 0 {
+		return program, parseDiagnostics(filename, p.Diagnostics())
+	}
+
+	analyzer := sema.NewSemanticAnalyzer()
+	analyzer.Analyze(program)
+	if len(analyzer.NameErrors()) > 0 {
+		return program, nameDiagnostics(filename, analyzer.NameErrors())
+	}
+
+	checker := sema.NewChecker()
+	checker.Check(program)
+	return program, typeDiagnostics(filename, checker.Errors())
+}
+
+func parseDiagnostics(filename string, errs []parser.Diagnostic) []diag.Diagnostic {
+	diagnostics := make([]diag.Diagnostic, len(errs))
+	for i, err := range errs {
+		diagnostics[i] = diag.Diagnostic{File: filename, Span: err.Span, Code: err.Code, Message: err.Message}
+	}
+	return diagnostics
+}
+
+func nameDiagnostics(filename string, errs []*sema.NameError) []diag.Diagnostic {
+	diagnostics := make([]diag.Diagnostic, len(errs))
+	for i, err := range errs {
+		diagnostics[i] = diag.Diagnostic{File: filename, Span: err.Span, Code: err.Code, Message: err.Message, Suggestion: err.Suggestion}
+	}
+	return diagnostics
+}
+
+func typeDiagnostics(filename string, errs []*sema.TypeError) []diag.Diagnostic {
+	diagnostics := make([]diag.Diagnostic, len(errs))
+	for i, err := range errs {
+		diagnostics[i] = diag.Diagnostic{File: filename, Span: err.Span, Code: err.Code, Message: err.Message}
+	}
+	return diagnostics
+}
+
+func compileDiagnostic(filename string, err *compiler.Error) diag.Diagnostic {
+	return diag.Diagnostic{File: filename, Span: err.Span, Code: compiler.CodeCompile, Message: err.Message}
+}
+
+// maxCalls es el máximo de llamadas de la pila que se muestran en las notas
+// de un error de ejecución.
+const maxCalls = 10
+
+// runtimeDiagnostic convierte un error de ejecución; la pila de llamadas va
+// en las notas, de la llamada más reciente a la más antigua, con las
+// repeticiones de una llamada resumidas y como mucho maxCalls llamadas.
+func runtimeDiagnostic(err *evaluator.RuntimeError) diag.Diagnostic {
+	d := diag.Diagnostic{File: err.File, Span: err.Span, Code: err.Code(), Message: err.Message}
+	calls := err.Calls()
+	for i, call := range calls {
+		if i == maxCalls {
+			rest := 0
+			for _, call := range calls[i:] {
+				rest += call.Count
+			}
+			d.Notes = append(d.Notes, fmt.Sprintf("… %d llamadas más antiguas", rest))
+			break
+		}
+		file := call.Caller.File
+		if file == "" {
+			file = "<entrada>"
+		}
+		d.Notes = append(d.Notes, fmt.Sprintf("%s fue llamada desde %s:%d:%d, en %s",
+			call.Function, file, call.Caller.Line, call.Caller.Column, call.Caller.Function))
+		if call.Count > 1 {
+			d.Notes = append(d.Notes, fmt.Sprintf("… %d llamadas más a %s desde el mismo punto", call.Count-1, call.Function))
+		}
+	}
+	return d
+}
+
+// loadDiagnostics convierte un error al cargar los módulos que importa
+// filename.
+func loadDiagnostics(filename string, err error) []diag.Diagnostic {
+	var parseErr *modules.ParseError
+	if errors.As(err, &parseErr) {
+		return parseDiagnostics(parseErr.Path, parseErr.Diagnostics)
+	}
+	return []diag.Diagnostic{{File: filename, Code: modules.CodeLoad, Message: err.Error()}}
+}
+
+// buildDiagnostics convierte un error de builder.Build: uno por cada error
+// del generador o mensaje de go build, que ya vienen en coordenadas del
+// código Zylo.
+func buildDiagnostics(filename string, err error) []diag.Diagnostic {
+	var generateErr *builder.GenerateError
+	if errors.As(err, &generateErr) {
+		diagnostics := make([]diag.Diagnostic, len(generateErr.Errors))
+		for i, e := range generateErr.Errors {
+			diagnostics[i] = diag.Diagnostic{File: generateErr.File, Span: e.Span, Code: builder.CodeGenerate, Message: e.Message}
+		}
+		return diagnostics
+	}
+	var buildErr *builder.BuildError
+	if !errors.As(err, &buildErr) {
+		return []diag.Diagnostic{{File: filename, Code: builder.CodeBuild, Message: err.Error()}}
+	}
+	var diagnostics []diag.Diagnostic
+	for _, m := range buildErr.Messages() {
+		d := diag.Diagnostic{File: m.File, Code: builder.CodeGoBuild, Message: m.Text, Notes: m.Details}
+		if m.Line > 0 {
+			d.Span = ast.Span{StartLine: m.Line, StartCol: max(m.Column, 1), EndLine: m.Line, EndCol: max(m.Column, 1)}
+		}
+		diagnostics = append(diagnostics, d)
+	}
+	return diagnostics
+}
+
+// testDiagnostics convierte los resultados de las pruebas: una prueba que
+// falla es un error, con el código del error que la hizo fallar si lo
+// tiene, y una omitida, un aviso.
+func testDiagnostics(summary *testrunner.Summary) []diag.Diagnostic {
+	var diagnostics []diag.Diagnostic
+	for _, r := range summary.Results {
+		var parseErr *modules.ParseError
+		var runtimeErr *evaluator.RuntimeError
+		switch {
+		case r.Status == testrunner.Skip:
+			message := "prueba omitida: " + r.Name
+			if r.Message != "" {
+				message += " (" + r.Message + ")"
+			}
+			diagnostics = append(diagnostics, diag.Diagnostic{
+				File: r.File, Span: r.Span, Severity: diag.Warning, Code: testrunner.CodeSkipped, Message: message,
+			})
+		case r.Status != testrunner.Fail:
+			continue
+		case errors.As(r.Err, &parseErr):
+			diagnostics = append(diagnostics, parseDiagnostics(parseErr.Path, parseErr.Diagnostics)...)
+		case errors.As(r.Err, &runtimeErr):
+			d := runtimeDiagnostic(runtimeErr)
+			d.Notes = append([]string{"en la prueba " + r.Name}, d.Notes...)
+			diagnostics = append(diagnostics, d)
+		default:
+			diagnostics = append(diagnostics, diag.Diagnostic{
+				File: r.File, Span: r.Span, Code: testrunner.CodeFailed, Message: r.Name + ": " + r.Message,
+			})
+		}
+	}
+	return diagnostics
+}
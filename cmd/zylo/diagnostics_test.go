@@ -0,0 +1,61 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zylo-lang/zylo/internal/builder"
+	"github.com/zylo-lang/zylo/internal/evaluator"
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/parser"
+)
+
+func TestRuntimeDiagnosticCollapsesStack(t *testing.T) {
+	// Una recursión hasta el límite de profundidad y, encima, una cadena de
+	// llamadas distintas más larga que maxCalls
+	stack := []evaluator.StackFrame{{Function: "<programa>", File: "app.zylo", Line: 30, Column: 1}}
+	for i := 0; i < 2*maxCalls; i++ {
+		stack = append(stack, evaluator.StackFrame{Function: "f" + string(rune('a'+i)), File: "app.zylo", Line: i + 1, Column: 5})
+	}
+	for i := 0; i < 10000; i++ {
+		stack = append(stack, evaluator.StackFrame{Function: "r", File: "app.zylo", Line: 40, Column: 12})
+	}
+	d := runtimeDiagnostic(&evaluator.RuntimeError{Message: "profundidad máxima de llamadas excedida (10000)", File: "app.zylo", Stack: stack})
+
+	if len(d.Notes) > maxCalls+2 {
+		t.Errorf("expected at most %d notes, got %d:\n%s", maxCalls+2, len(d.Notes), strings.Join(d.Notes, "\n"))
+	}
+	want := []string{
+		"r fue llamada desde app.zylo:40:12, en r",
+		"… 9998 llamadas más a r desde el mismo punto",
+		"r fue llamada desde app.zylo:20:5, en ft",
+	}
+	for i, note := range want {
+		if i >= len(d.Notes) || d.Notes[i] != note {
+			t.Errorf("note %d: expected %q, got %q", i, note, d.Notes)
+		}
+	}
+	if last := d.Notes[len(d.Notes)-1]; last != "… 12 llamadas más antiguas" {
+		t.Errorf("expected the last note to count the omitted calls, got %q", last)
+	}
+}
+
+func TestBuildDiagnosticsPerGenerateError(t *testing.T) {
+	input := "var x = 1\nvar y = (x = 2)\nshow.log(spawn print(x))\n"
+	program := parser.New(lexer.New(input)).ParseProgram()
+	_, _, err := builder.Sources("app.zylo", program, nil)
+	if err == nil {
+		t.Fatal("expected the Go backend to reject the program")
+	}
+
+	diagnostics := buildDiagnostics("app.zylo", err)
+	if len(diagnostics) != 2 {
+		t.Fatalf("expected one diagnostic per error, got %+v", diagnostics)
+	}
+	for i, line := range []int{2, 3} {
+		d := diagnostics[i]
+		if d.File != "app.zylo" || d.Code != builder.CodeGenerate || d.Span.StartLine != line || d.Span.StartCol != 10 {
+			t.Errorf("diagnostic %d: expected app.zylo:%d:10 with code %s, got %+v", i, line, builder.CodeGenerate, d)
+		}
+	}
+}
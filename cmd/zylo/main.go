@@ -1,13 +1,27 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
 
-	"github.com/zylo-lang/zylo/internal/codegen"
+	"github.com/zylo-lang/zylo/internal/ast"
+	"github.com/zylo-lang/zylo/internal/builder"
+	"github.com/zylo-lang/zylo/internal/compiler"
+	"github.com/zylo-lang/zylo/internal/dap"
+	"github.com/zylo-lang/zylo/internal/diag"
 	"github.com/zylo-lang/zylo/internal/evaluator"
-	"github.com/zylo-lang/zylo/internal/lexer"
-	"github.com/zylo-lang/zylo/internal/parser"
+	"github.com/zylo-lang/zylo/internal/format"
+	"github.com/zylo-lang/zylo/internal/lsp"
+	"github.com/zylo-lang/zylo/internal/modules"
+	"github.com/zylo-lang/zylo/internal/repl"
+	"github.com/zylo-lang/zylo/internal/testrunner"
+	"github.com/zylo-lang/zylo/internal/vm"
 )
 
 func main() {
@@ -20,17 +34,42 @@ func main() {
 
 	switch command {
 	case "build":
-		if len(os.Args) < 3 {
-			fmt.Println("Error: Debes especificar un archivo .zylo")
+		filename, opts, out, err := parseBuildArgs(os.Args[2:])
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
 			os.Exit(1)
 		}
-		buildFile(os.Args[2])
+		os.Exit(buildFile(filename, opts, out))
+	case "check":
+		os.Exit(checkFiles(os.Args[2:]))
 	case "run":
-		if len(os.Args) < 3 {
-			fmt.Println("Error: Debes especificar un archivo .zylo")
+		filename, opts, err := parseRunArgs(os.Args[2:])
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		runFile(filename, opts)
+	case "repl":
+		if err := repl.New(os.Stdin, os.Stdout, repl.DefaultHistoryFile()).Run(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	case "fmt":
+		os.Exit(formatFiles(os.Args[2:]))
+	case "test":
+		os.Exit(runTests(os.Args[2:]))
+	case "lsp":
+		// La salida estándar es del protocolo: los errores van a stderr
+		if err := lsp.NewServer(os.Stdin, os.Stdout).Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	case "debug":
+		// Igual que lsp: la salida estándar es del protocolo
+		if err := dap.NewServer(os.Stdin, os.Stdout).Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
-		runFile(os.Args[2])
 	case "help":
 		printUsage()
 	default:
@@ -43,67 +82,412 @@ func main() {
 func printUsage() {
 	fmt.Println("Uso: zylo <comando> [archivo]")
 	fmt.Println("Comandos:")
-	fmt.Println("  build <archivo.zylo>  - Compila un archivo Zylo a Go")
+	fmt.Println("  build <archivo.zylo>  - Compila un archivo Zylo a un ejecutable nativo")
+	fmt.Println("      -o <archivo>      Ruta del ejecutable (por defecto: el nombre sin .zylo)")
+	fmt.Println("      --keep-go         Conserva el módulo Go generado")
+	fmt.Println("      --source-map      Conserva el módulo con un .map.json por archivo Go")
+	fmt.Println("      --goos, --goarch  Sistema y arquitectura destino (o GOOS y GOARCH)")
+	fmt.Println("      -ldflags <flags>  Opciones para el enlazador de Go")
+	fmt.Println("  check [rutas...]      - Busca errores en los archivos .zylo sin ejecutarlos")
 	fmt.Println("  run <archivo.zylo>    - Ejecuta un archivo Zylo directamente")
+	fmt.Println("      --engine=tree|vm  Motor de ejecución (por defecto: tree)")
+	fmt.Println("  repl                  - Inicia una sesión interactiva")
+	fmt.Println("  test [rutas...]       - Ejecuta las funciones test_* de los archivos *_test.zylo")
+	fmt.Println("      --run <regexp>    Solo ejecuta las pruebas cuyo nombre coincide")
+	fmt.Println("      --junit <archivo> Escribe los resultados en formato JUnit XML")
+	fmt.Println("  build, check y test aceptan además:")
+	fmt.Println("      --format=text|json|sarif  Formato de los diagnósticos (por defecto: text)")
+	fmt.Println("      --fail-on=error|warning|note|none")
+	fmt.Println("                        Gravedad a partir de la cual fallan (por defecto: error)")
+	fmt.Println("  fmt <archivo.zylo>... - Formatea los archivos en su sitio")
+	fmt.Println("      --check           Lista los archivos sin formatear y falla si hay alguno")
+	fmt.Println("      --diff            Muestra los cambios sin escribir los archivos")
+	fmt.Println("  lsp                   - Inicia el servidor LSP para editores (por stdio)")
+	fmt.Println("  debug                 - Inicia el depurador DAP para editores (por stdio)")
 	fmt.Println("  help                  - Muestra esta ayuda")
 }
 
-func buildFile(filename string) {
+// parseBuildArgs separa el archivo de las opciones de 'zylo build', que
+// pueden ir antes o después del archivo.
+func parseBuildArgs(args []string) (string, builder.Options, outputOptions, error) {
+	var opts builder.Options
+	out := defaultOutputOptions()
+	filename := ""
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, value, hasValue := strings.Cut(arg, "=")
+		switch name {
+		case "-o", "--goos", "--goarch", "-ldflags", "--ldflags", "--format", "--fail-on":
+			if !hasValue {
+				if i+1 >= len(args) {
+					return "", opts, out, fmt.Errorf("%s requiere un valor", arg)
+				}
+				i++
+				value = args[i]
+			}
+			switch name {
+			case "-o":
+				opts.Output = value
+			case "--goos":
+				opts.GOOS = value
+			case "--goarch":
+				opts.GOARCH = value
+			case "--format", "--fail-on":
+				if _, err := out.set(name, value); err != nil {
+					return "", opts, out, err
+				}
+			default:
+				opts.LDFlags = value
+			}
+			continue
+		case "--keep-go":
+			opts.KeepGo = true
+			continue
+		case "--source-map":
+			opts.SourceMap = true
+			continue
+		}
+		switch {
+		case strings.HasPrefix(arg, "-"):
+			return "", opts, out, fmt.Errorf("opción desconocida: %s", arg)
+		case filename == "":
+			filename = arg
+		default:
+			return "", opts, out, fmt.Errorf("argumento inesperado: %s", arg)
+		}
+	}
+	if filename == "" {
+		return "", opts, out, fmt.Errorf("Debes especificar un archivo .zylo")
+	}
+	return filename, opts, out, nil
+}
+
+// buildFile implementa 'zylo build': compila filename a un ejecutable nativo.
+// Devuelve el código de salida.
+func buildFile(filename string, opts builder.Options, out outputOptions) int {
+	log := out.log()
 	// Verificar que el archivo existe
 	if _, err := os.Stat(filename); os.IsNotExist(err) {
-		fmt.Printf("Error: El archivo '%s' no existe\n", filename)
-		os.Exit(1)
+		fmt.Fprintf(log, "Error: El archivo '%s' no existe\n", filename)
+		return 1
 	}
 
 	// Verificar extensión
 	if len(filename) < 5 || filename[len(filename)-5:] != ".zylo" {
-		fmt.Printf("Error: El archivo debe tener extensión .zylo\n")
-		os.Exit(1)
+		fmt.Fprintf(log, "Error: El archivo debe tener extensión .zylo\n")
+		return 1
 	}
 
-	fmt.Printf("Compilando %s...\n", filename)
+	fmt.Fprintf(log, "Compilando %s...\n", filename)
 
 	// Leer archivo
 	content, err := os.ReadFile(filename)
 	if err != nil {
-		fmt.Printf("Error leyendo archivo: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(log, "Error leyendo archivo: %v\n", err)
+		return 1
 	}
 
-	// Parsear
-	l := lexer.New(string(content))
-	p := parser.New(l)
-	program := p.ParseProgram()
+	// Parsear y analizar
+	printer := newPrinter(filename, string(content))
+	program, diagnostics := checkSource(filename, string(content))
+	if failed(diagnostics) {
+		out.emit(printer, diagnostics)
+		return out.exitCode(diagnostics)
+	}
 
-	if len(p.Errors()) > 0 {
-		fmt.Printf("Errores de parsing:\n")
-		for _, err := range p.Errors() {
-			fmt.Printf("  %s\n", err)
+	// Cargar los módulos importados
+	imported, err := modules.Load(filename, program, modules.SearchPath())
+	if err != nil {
+		diagnostics = append(diagnostics, loadDiagnostics(filename, err)...)
+		out.emit(printer, diagnostics)
+		return out.exitCode(diagnostics)
+	}
+
+	// Generar el código Go y compilarlo
+	result, err := builder.Build(filename, program, imported, opts)
+	if result != nil && result.Dir != "" {
+		fmt.Fprintf(log, "Código Go generado en: %s\n", result.Dir)
+	}
+	if err != nil {
+		diagnostics = append(diagnostics, buildDiagnostics(filename, err)...)
+		out.emit(printer, diagnostics)
+		return out.exitCode(diagnostics)
+	}
+	fmt.Fprintf(log, "Ejecutable generado en: %s\n", result.Output)
+	out.emit(printer, diagnostics)
+	return out.exitCode(diagnostics)
+}
+
+// checkFiles implementa 'zylo check': parsea los archivos .zylo de args (o
+// del directorio actual), resuelve sus nombres y comprueba sus tipos, sin
+// ejecutarlos. Devuelve 1 si algún diagnóstico es tan grave como --fail-on
+// y 2 si no se pudo leer algún archivo.
+func checkFiles(args []string) int {
+	out := defaultOutputOptions()
+	var paths []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, value, hasValue := strings.Cut(arg, "=")
+		if name == "--format" || name == "--fail-on" {
+			if !hasValue {
+				if i+1 >= len(args) {
+					fmt.Printf("Error: %s requiere un valor\n", arg)
+					return 2
+				}
+				i++
+				value = args[i]
+			}
+			if _, err := out.set(name, value); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return 2
+			}
+			continue
 		}
-		os.Exit(1)
+		if strings.HasPrefix(arg, "-") {
+			fmt.Printf("Error: opción desconocida: %s\n", arg)
+			return 2
+		}
+		paths = append(paths, arg)
+	}
+	if len(paths) == 0 {
+		paths = []string{"."}
 	}
 
-	// Generar código Go
-	cg := codegen.NewCodeGenerator()
-	goCode, err := cg.Generate(program)
+	files, err := zyloFiles(paths)
 	if err != nil {
-		fmt.Printf("Error generando código: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(out.log(), "Error: %v\n", err)
+		return 2
+	}
+	status := 0
+	printer := diag.NewPrinter(os.Stdout, diag.ColorEnabled(os.Stdout))
+	var diagnostics []diag.Diagnostic
+	for _, filename := range files {
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			fmt.Fprintf(out.log(), "Error leyendo archivo: %v\n", err)
+			status = 2
+			continue
+		}
+		printer.SetSource(filename, string(content))
+		_, found := checkSource(filename, string(content))
+		diagnostics = append(diagnostics, found...)
 	}
+	out.emit(printer, diagnostics)
+	return max(status, out.exitCode(diagnostics))
+}
 
-	// Crear archivo de salida
-	outputFile := filename[:len(filename)-5] + ".go"
-	err = os.WriteFile(outputFile, []byte(goCode), 0644)
+// zyloFiles devuelve los archivos .zylo de paths, ordenados. Un directorio
+// se recorre entero; un archivo se usa tal cual.
+func zyloFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		err = filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && strings.HasSuffix(file, modules.Extension) {
+				files = append(files, file)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// formatFiles implementa 'zylo fmt' y devuelve el código de salida. Sin
+// opciones reescribe cada archivo con su formato canónico. Con --check o
+// --diff no escribe nada y devuelve 1 si algún archivo cambiaría, para usarlo
+// en hooks de pre-commit.
+func formatFiles(args []string) int {
+	check, diff := false, false
+	var files []string
+	for _, arg := range args {
+		switch {
+		case arg == "--check":
+			check = true
+		case arg == "--diff":
+			diff = true
+		case strings.HasPrefix(arg, "-"):
+			fmt.Printf("Error: opción desconocida: %s\n", arg)
+			return 2
+		default:
+			files = append(files, arg)
+		}
+	}
+	if len(files) == 0 {
+		fmt.Println("Error: Debes especificar al menos un archivo .zylo")
+		return 2
+	}
+
+	status := 0
+	for _, filename := range files {
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			fmt.Printf("Error leyendo archivo: %v\n", err)
+			status = 2
+			continue
+		}
+		formatted, err := format.Source(string(content))
+		if err != nil {
+			fmt.Printf("%s: %v\n", filename, err)
+			status = 2
+			continue
+		}
+		if formatted == string(content) {
+			continue
+		}
+
+		switch {
+		case check || diff:
+			if check {
+				fmt.Println(filename)
+			}
+			if diff {
+				fmt.Print(format.Diff(filename, string(content), formatted))
+			}
+			status = max(status, 1)
+		default:
+			if err := os.WriteFile(filename, []byte(formatted), 0644); err != nil {
+				fmt.Printf("Error escribiendo archivo: %v\n", err)
+				status = 2
+			}
+		}
+	}
+	return status
+}
+
+// runTests implementa 'zylo test' y devuelve el código de salida: 1 si
+// alguna prueba falla o, con --fail-on=warning, si alguna se omite.
+func runTests(args []string) int {
+	var paths []string
+	var filter *regexp.Regexp
+	junitFile := ""
+	out := defaultOutputOptions()
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, value, hasValue := strings.Cut(arg, "=")
+		switch {
+		case name == "--format" || name == "--fail-on":
+			if !hasValue {
+				if i+1 >= len(args) {
+					fmt.Printf("Error: %s requiere un valor\n", arg)
+					return 2
+				}
+				i++
+				value = args[i]
+			}
+			if _, err := out.set(name, value); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return 2
+			}
+		case arg == "--run" || arg == "--junit":
+			if i+1 >= len(args) {
+				fmt.Printf("Error: %s requiere un valor\n", arg)
+				return 2
+			}
+			i++
+			if arg == "--junit" {
+				junitFile = args[i]
+				continue
+			}
+			re, err := regexp.Compile(args[i])
+			if err != nil {
+				fmt.Printf("Error: --run: %v\n", err)
+				return 2
+			}
+			filter = re
+		case strings.HasPrefix(arg, "-"):
+			fmt.Printf("Error: opción desconocida: %s\n", arg)
+			return 2
+		default:
+			paths = append(paths, arg)
+		}
+	}
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	files, err := testrunner.Discover(paths)
 	if err != nil {
-		fmt.Printf("Error escribiendo archivo: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(out.log(), "Error: %v\n", err)
+		return 2
+	}
+	summary := testrunner.Run(files, filter)
+	testrunner.Report(out.log(), summary)
+	diagnostics := testDiagnostics(summary)
+	if out.machine() {
+		out.emit(nil, diagnostics)
 	}
 
-	fmt.Printf("Código generado en: %s\n", outputFile)
-	fmt.Printf("Para ejecutar: go run %s\n", outputFile)
+	if junitFile != "" {
+		f, err := os.Create(junitFile)
+		if err != nil {
+			fmt.Fprintf(out.log(), "Error escribiendo archivo: %v\n", err)
+			return 2
+		}
+		defer f.Close()
+		if err := testrunner.WriteJUnit(f, summary); err != nil {
+			fmt.Fprintf(out.log(), "Error escribiendo archivo: %v\n", err)
+			return 2
+		}
+	}
+	return out.exitCode(diagnostics)
+}
+
+// runOptions son las opciones de 'zylo run'.
+type runOptions struct {
+	engine string // "tree" (evaluador) o "vm" (bytecode)
+	debug  bool
 }
 
-func runFile(filename string) {
+// parseRunArgs separa el archivo de las opciones de 'zylo run', que pueden ir
+// antes o después del archivo.
+func parseRunArgs(args []string) (string, runOptions, error) {
+	opts := runOptions{engine: "tree"}
+	filename := ""
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--debug":
+			opts.debug = true
+		case arg == "--engine":
+			if i+1 >= len(args) {
+				return "", opts, fmt.Errorf("--engine requiere un valor (tree o vm)")
+			}
+			i++
+			opts.engine = args[i]
+		case strings.HasPrefix(arg, "--engine="):
+			opts.engine = strings.TrimPrefix(arg, "--engine=")
+		case strings.HasPrefix(arg, "-"):
+			return "", opts, fmt.Errorf("opción desconocida: %s", arg)
+		case filename == "":
+			filename = arg
+		default:
+			return "", opts, fmt.Errorf("argumento inesperado: %s", arg)
+		}
+	}
+	if filename == "" {
+		return "", opts, fmt.Errorf("Debes especificar un archivo .zylo")
+	}
+	if opts.engine != "tree" && opts.engine != "vm" {
+		return "", opts, fmt.Errorf("motor desconocido: %s (usa tree o vm)", opts.engine)
+	}
+	return filename, opts, nil
+}
+
+func runFile(filename string, opts runOptions) {
 	// Verificar que el archivo existe
 	if _, err := os.Stat(filename); os.IsNotExist(err) {
 		fmt.Printf("Error: El archivo '%s' no existe\n", filename)
@@ -125,21 +509,16 @@ func runFile(filename string) {
 		os.Exit(1)
 	}
 
-	// Parsear
-	l := lexer.New(string(content))
-	p := parser.New(l)
-	program := p.ParseProgram()
-
-	if len(p.Errors()) > 0 {
-		fmt.Printf("Errores de parsing:\n")
-		for _, err := range p.Errors() {
-			fmt.Printf("  %s\n", err)
-		}
+	// Parsear y analizar
+	printer := newPrinter(filename, string(content))
+	program, diagnostics := checkSource(filename, string(content))
+	if len(diagnostics) > 0 {
+		report(printer, diagnostics)
 		os.Exit(1)
 	}
 
 	// Debug: Imprimir AST generado
-	if len(os.Args) > 3 && os.Args[3] == "--debug" {
+	if opts.debug {
 		fmt.Printf("AST generado: %+v\n", program)
 		fmt.Printf("Número de statements: %d\n", len(program.Statements))
 	}
@@ -147,11 +526,36 @@ func runFile(filename string) {
 	// Ejecutar directamente con el evaluador
 	eval := evaluator.NewEvaluator()
 	// InitBuiltins ya se llama en NewEvaluator()
-	err = eval.EvaluateProgram(program)
+	eval.SetFile(filename)
+	if opts.engine == "vm" {
+		err = runVM(filename, program, eval)
+	} else {
+		err = eval.EvaluateProgram(program)
+	}
 	if err != nil {
-		fmt.Printf("Error de ejecución: %v\n", err)
+		var runtimeErr *evaluator.RuntimeError
+		var compileErr *compiler.Error
+		if errors.As(err, &runtimeErr) {
+			report(printer, []diag.Diagnostic{runtimeDiagnostic(runtimeErr)})
+		} else if errors.As(err, &compileErr) {
+			report(printer, []diag.Diagnostic{compileDiagnostic(filename, compileErr)})
+		} else {
+			fmt.Printf("Error de ejecución: %v\n", err)
+		}
 		os.Exit(1)
 	}
 
 	fmt.Println("\n✅ Programa ejecutado exitosamente!")
 }
+
+// runVM compila el programa a bytecode y lo ejecuta en la VM, usando eval para
+// los built-ins y la entrada/salida.
+func runVM(filename string, program *ast.Program, eval *evaluator.Evaluator) error {
+	bytecode, err := compiler.New().Compile(program)
+	if err != nil {
+		return fmt.Errorf("%s:%w", filename, err)
+	}
+	machine := vm.New(bytecode, eval)
+	machine.SetFile(filename)
+	return machine.Run()
+}
@@ -10,6 +10,7 @@ import (
 type Node interface {
 	TokenLiteral() string // Devuelve el literal del token asociado al nodo.
 	String() string       // Devuelve una representación en string del nodo para debugging.
+	Pos() lexer.Token     // Devuelve el token principal del nodo, con su posición.
 }
 
 // Statement es una interfaz para todos los nodos de sentencia.
@@ -36,6 +37,13 @@ func (p *Program) TokenLiteral() string {
 	return ""
 }
 
+func (p *Program) Pos() lexer.Token {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Pos()
+	}
+	return lexer.Token{}
+}
+
 func (p *Program) String() string {
 	var out string
 	for _, s := range p.Statements {
@@ -44,33 +52,82 @@ func (p *Program) String() string {
 	return out
 }
 
-// ImportStatement representa una declaración de import (e.g., import zyloruntime).
+// ImportStatement representa una declaración de import: 'import utils',
+// 'import "lib/utils"' o 'from utils import Split, Len'.
 type ImportStatement struct {
-	Token      lexer.Token // El token 'import'.
-	ModuleName *Identifier // El nombre del módulo a importar.
+	Token      lexer.Token   // El token 'import' o 'from'.
+	ModuleName *Identifier   // El nombre con el que se liga el módulo (e.g. utils).
+	Path       string        // La ruta entre comillas, vacía si el módulo se nombró con un identificador.
+	Names      []*Identifier // Los nombres de 'from ... import', nil en un import simple.
 }
 
 func (is *ImportStatement) statementNode()       {}
 func (is *ImportStatement) expressionNode()      {} // Also implement Expression interface
 func (is *ImportStatement) TokenLiteral() string { return is.Token.Lexeme }
-func (is *ImportStatement) String() string {
-	out := "import "
+func (is *ImportStatement) Pos() lexer.Token     { return is.Token }
+
+// Module devuelve el módulo tal como se escribió: la ruta o el nombre.
+func (is *ImportStatement) Module() string {
+	if is.Path != "" {
+		return is.Path
+	}
 	if is.ModuleName != nil {
-		out += is.ModuleName.String()
+		return is.ModuleName.Value
 	}
-	out += ";"
-	return out
+	return ""
+}
+
+func (is *ImportStatement) String() string {
+	module := is.Module()
+	if is.Path != "" {
+		module = fmt.Sprintf("%q", is.Path)
+	}
+	if is.Names != nil {
+		names := make([]string, len(is.Names))
+		for i, name := range is.Names {
+			names[i] = name.Value
+		}
+		return fmt.Sprintf("from %s import %s;", module, formatStrings(names))
+	}
+	return "import " + module + ";"
+}
+
+// ExportStatement representa una declaración exportada por un módulo (e.g.,
+// export func Split(s, sep) { ... }).
+type ExportStatement struct {
+	Token     lexer.Token // El token 'export'.
+	Statement Statement   // La declaración de función, variable o clase.
+}
+
+func (es *ExportStatement) statementNode()       {}
+func (es *ExportStatement) TokenLiteral() string { return es.Token.Lexeme }
+func (es *ExportStatement) Pos() lexer.Token     { return es.Token }
+func (es *ExportStatement) String() string       { return "export " + es.Statement.String() }
+
+// Name devuelve el nombre que exporta la declaración, o "" si no lo tiene.
+func (es *ExportStatement) Name() string {
+	switch s := es.Statement.(type) {
+	case *FuncStatement:
+		return s.Name.Value
+	case *VarStatement:
+		return s.Name.Value
+	case *ClassStatement:
+		return s.Name.Value
+	}
+	return ""
 }
 
 // VarStatement representa una declaración de variable (e.g., var x = 5;).
 type VarStatement struct {
 	Token lexer.Token // El token 'var'.
 	Name  *Identifier
+	Type  string // Anotación de tipo opcional (e.g., "Array<String>").
 	Value Expression
 }
 
 func (vs *VarStatement) statementNode()       {}
 func (vs *VarStatement) TokenLiteral() string { return vs.Token.Lexeme }
+func (vs *VarStatement) Pos() lexer.Token     { return vs.Token }
 func (vs *VarStatement) String() string {
 	var out string
 	out += vs.TokenLiteral() + " "
@@ -89,10 +146,12 @@ func (vs *VarStatement) String() string {
 type Identifier struct {
 	Token lexer.Token // El token IDENTIFIER.
 	Value string
+	Type  string // Anotación de tipo de un parámetro, vacía si no tiene.
 }
 
 func (i *Identifier) expressionNode()      {}
 func (i *Identifier) TokenLiteral() string { return i.Token.Lexeme }
+func (i *Identifier) Pos() lexer.Token     { return i.Token }
 func (i *Identifier) String() string       { return i.Value }
 
 // ExpressionStatement es una sentencia que consiste en una sola expresión.
@@ -103,6 +162,7 @@ type ExpressionStatement struct {
 
 func (es *ExpressionStatement) statementNode()       {}
 func (es *ExpressionStatement) TokenLiteral() string { return es.Token.Lexeme }
+func (es *ExpressionStatement) Pos() lexer.Token     { return es.Token }
 func (es *ExpressionStatement) String() string {
 	if es.Expression != nil {
 		return es.Expression.String()
@@ -115,11 +175,14 @@ type FuncStatement struct {
 	Token      lexer.Token // El token 'func'.
 	Name       *Identifier
 	Parameters []*Identifier // Cambiado de []*Variable a []*Identifier
-	ReturnType string      // Nuevo campo para el tipo de retorno
+	ReturnType string        // Nuevo campo para el tipo de retorno
 	Body       *BlockStatement
+	Async      bool // Declarada con 'async func': devuelve un Future
 }
+
 func (fs *FuncStatement) statementNode()       {}
 func (fs *FuncStatement) TokenLiteral() string { return fs.Token.Lexeme }
+func (fs *FuncStatement) Pos() lexer.Token     { return fs.Token }
 func (fs *FuncStatement) String() string {
 	params := []string{}
 	for _, p := range fs.Parameters {
@@ -129,7 +192,11 @@ func (fs *FuncStatement) String() string {
 	if fs.ReturnType != "" {
 		returnType = fmt.Sprintf(": %s", fs.ReturnType)
 	}
-	return fmt.Sprintf("%s %s(%s)%s %s", fs.TokenLiteral(), fs.Name.String(), formatStrings(params), returnType, fs.Body.String())
+	async := ""
+	if fs.Async {
+		async = "async "
+	}
+	return fmt.Sprintf("%s%s %s(%s)%s %s", async, fs.TokenLiteral(), fs.Name.String(), formatStrings(params), returnType, fs.Body.String())
 }
 
 // ReturnStatement representa una sentencia de retorno.
@@ -140,6 +207,7 @@ type ReturnStatement struct {
 
 func (rs *ReturnStatement) statementNode()       {}
 func (rs *ReturnStatement) TokenLiteral() string { return rs.Token.Lexeme }
+func (rs *ReturnStatement) Pos() lexer.Token     { return rs.Token }
 func (rs *ReturnStatement) String() string {
 	var out string
 	out += rs.TokenLiteral() + " "
@@ -154,10 +222,12 @@ func (rs *ReturnStatement) String() string {
 type BlockStatement struct {
 	Token      lexer.Token // El token '{'.
 	Statements []Statement
+	RBrace     lexer.Token // El token '}' que cierra el bloque; vacío si falta.
 }
 
 func (bs *BlockStatement) statementNode()       {}
 func (bs *BlockStatement) TokenLiteral() string { return bs.Token.Lexeme }
+func (bs *BlockStatement) Pos() lexer.Token     { return bs.Token }
 func (bs *BlockStatement) String() string {
 	var out string
 	for _, s := range bs.Statements {
@@ -168,14 +238,15 @@ func (bs *BlockStatement) String() string {
 
 // ForInStatement representa una sentencia 'for' con iteración sobre rangos o listas.
 type ForInStatement struct {
-	Token      lexer.Token // El token 'for'.
-	Identifier *Identifier // El identificador de la variable de iteración (e.g., 'x' in 'for x in ...').
-	Iterable   Expression  // La expresión que evalúa a la lista o rango sobre el que iterar.
+	Token      lexer.Token     // El token 'for'.
+	Identifier *Identifier     // El identificador de la variable de iteración (e.g., 'x' in 'for x in ...').
+	Iterable   Expression      // La expresión que evalúa a la lista o rango sobre el que iterar.
 	Body       *BlockStatement // El cuerpo del bucle.
 }
 
 func (fs *ForInStatement) statementNode()       {}
 func (fs *ForInStatement) TokenLiteral() string { return fs.Token.Lexeme }
+func (fs *ForInStatement) Pos() lexer.Token     { return fs.Token }
 func (fs *ForInStatement) String() string {
 	out := "for "
 	if fs.Identifier != nil {
@@ -194,14 +265,15 @@ func (fs *ForInStatement) String() string {
 
 // TryStatement representa una sentencia 'try-catch'.
 type TryStatement struct {
-	Token       lexer.Token // El token 'try'.
-	TryBlock    *BlockStatement
-	CatchClause *CatchClause // Puede ser nil si solo hay finally.
+	Token        lexer.Token // El token 'try'.
+	TryBlock     *BlockStatement
+	CatchClause  *CatchClause    // Puede ser nil si solo hay finally.
 	FinallyBlock *BlockStatement // Puede ser nil.
 }
 
 func (ts *TryStatement) statementNode()       {}
 func (ts *TryStatement) TokenLiteral() string { return ts.Token.Lexeme }
+func (ts *TryStatement) Pos() lexer.Token     { return ts.Token }
 func (ts *TryStatement) String() string {
 	out := "try "
 	if ts.TryBlock != nil {
@@ -225,6 +297,7 @@ type CatchClause struct {
 
 func (cc *CatchClause) statementNode()       {} // CatchClause es parte de TryStatement, no una sentencia independiente.
 func (cc *CatchClause) TokenLiteral() string { return cc.Token.Lexeme }
+func (cc *CatchClause) Pos() lexer.Token     { return cc.Token }
 func (cc *CatchClause) String() string {
 	if cc.Parameter == nil || cc.CatchBlock == nil {
 		return "catch (invalid) { invalid }"
@@ -240,6 +313,7 @@ type ThrowStatement struct {
 
 func (ths *ThrowStatement) statementNode()       {}
 func (ths *ThrowStatement) TokenLiteral() string { return ths.Token.Lexeme }
+func (ths *ThrowStatement) Pos() lexer.Token     { return ths.Token }
 func (ths *ThrowStatement) String() string {
 	var out string
 	out += ths.TokenLiteral() + " "
@@ -258,6 +332,7 @@ type NumberLiteral struct {
 
 func (nl *NumberLiteral) expressionNode()      {}
 func (nl *NumberLiteral) TokenLiteral() string { return nl.Token.Lexeme }
+func (nl *NumberLiteral) Pos() lexer.Token     { return nl.Token }
 func (nl *NumberLiteral) String() string       { return nl.Token.Lexeme }
 
 // StringLiteral representa un literal de cadena.
@@ -268,6 +343,7 @@ type StringLiteral struct {
 
 func (sl *StringLiteral) expressionNode()      {}
 func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Lexeme }
+func (sl *StringLiteral) Pos() lexer.Token     { return sl.Token }
 func (sl *StringLiteral) String() string       { return sl.Token.Lexeme }
 
 // BooleanLiteral representa un literal booleano.
@@ -278,6 +354,7 @@ type BooleanLiteral struct {
 
 func (bl *BooleanLiteral) expressionNode()      {}
 func (bl *BooleanLiteral) TokenLiteral() string { return bl.Token.Lexeme }
+func (bl *BooleanLiteral) Pos() lexer.Token     { return bl.Token }
 func (bl *BooleanLiteral) String() string       { return bl.Token.Lexeme }
 
 // NullLiteral representa un literal null.
@@ -287,6 +364,7 @@ type NullLiteral struct {
 
 func (nl *NullLiteral) expressionNode()      {}
 func (nl *NullLiteral) TokenLiteral() string { return nl.Token.Lexeme }
+func (nl *NullLiteral) Pos() lexer.Token     { return nl.Token }
 func (nl *NullLiteral) String() string       { return "null" }
 
 // PrefixExpression representa una expresión con un operador prefijo.
@@ -298,6 +376,7 @@ type PrefixExpression struct {
 
 func (pe *PrefixExpression) expressionNode()      {}
 func (pe *PrefixExpression) TokenLiteral() string { return pe.Token.Lexeme }
+func (pe *PrefixExpression) Pos() lexer.Token     { return pe.Token }
 func (pe *PrefixExpression) String() string {
 	if pe.Right == nil {
 		return fmt.Sprintf("(%sINVALID)", pe.Operator)
@@ -315,6 +394,7 @@ type InfixExpression struct {
 
 func (ie *InfixExpression) expressionNode()      {}
 func (ie *InfixExpression) TokenLiteral() string { return ie.Token.Lexeme }
+func (ie *InfixExpression) Pos() lexer.Token     { return ie.Token }
 func (ie *InfixExpression) String() string {
 	if ie.Left == nil || ie.Right == nil {
 		return fmt.Sprintf("(INVALID %s INVALID)", ie.Operator)
@@ -327,10 +407,12 @@ type CallExpression struct {
 	Token     lexer.Token // El token '(' o el identificador de la función.
 	Function  Expression  // La expresión que evalúa a la función.
 	Arguments []Expression
+	RParen    lexer.Token // El token ')' que cierra los argumentos.
 }
 
 func (ce *CallExpression) expressionNode()      {}
 func (ce *CallExpression) TokenLiteral() string { return ce.Token.Lexeme }
+func (ce *CallExpression) Pos() lexer.Token     { return ce.Token }
 func (ce *CallExpression) String() string {
 	if ce.Function == nil {
 		return "INVALID()"
@@ -347,6 +429,7 @@ type IndexExpression struct {
 
 func (ie *IndexExpression) expressionNode()      {}
 func (ie *IndexExpression) TokenLiteral() string { return ie.Token.Lexeme }
+func (ie *IndexExpression) Pos() lexer.Token     { return ie.Token }
 func (ie *IndexExpression) String() string {
 	if ie.Left == nil || ie.Index == nil {
 		return "(INVALID[INVALID])"
@@ -363,6 +446,7 @@ type MemberExpression struct {
 
 func (me *MemberExpression) expressionNode()      {}
 func (me *MemberExpression) TokenLiteral() string { return me.Token.Lexeme }
+func (me *MemberExpression) Pos() lexer.Token     { return me.Token }
 func (me *MemberExpression) String() string {
 	if me.Object == nil || me.Property == nil {
 		return "(INVALID.INVALID)"
@@ -378,6 +462,7 @@ type BlockExpression struct {
 
 func (be *BlockExpression) expressionNode()      {}
 func (be *BlockExpression) TokenLiteral() string { return be.Token.Lexeme }
+func (be *BlockExpression) Pos() lexer.Token     { return be.Token }
 func (be *BlockExpression) String() string {
 	if be.Block == nil {
 		return "{INVALID}"
@@ -396,6 +481,7 @@ type IfStatement struct {
 
 func (is *IfStatement) statementNode()       {}
 func (is *IfStatement) TokenLiteral() string { return is.Token.Lexeme }
+func (is *IfStatement) Pos() lexer.Token     { return is.Token }
 func (is *IfStatement) String() string {
 	out := "if "
 	if is.Condition != nil {
@@ -418,6 +504,7 @@ type BreakStatement struct {
 
 func (bs *BreakStatement) statementNode()       {}
 func (bs *BreakStatement) TokenLiteral() string { return bs.Token.Lexeme }
+func (bs *BreakStatement) Pos() lexer.Token     { return bs.Token }
 func (bs *BreakStatement) String() string       { return bs.Token.Lexeme + ";" }
 
 // ContinueStatement representa una sentencia 'continue'.
@@ -427,17 +514,19 @@ type ContinueStatement struct {
 
 func (cs *ContinueStatement) statementNode()       {}
 func (cs *ContinueStatement) TokenLiteral() string { return cs.Token.Lexeme }
+func (cs *ContinueStatement) Pos() lexer.Token     { return cs.Token }
 func (cs *ContinueStatement) String() string       { return cs.Token.Lexeme + ";" }
 
 // WhileStatement representa una sentencia 'while'.
 type WhileStatement struct {
-	Token     lexer.Token // El token 'while'.
-	Condition Expression  // La condición del bucle.
+	Token     lexer.Token     // El token 'while'.
+	Condition Expression      // La condición del bucle.
 	Body      *BlockStatement // El cuerpo del bucle.
 }
 
 func (ws *WhileStatement) statementNode()       {}
 func (ws *WhileStatement) TokenLiteral() string { return ws.Token.Lexeme }
+func (ws *WhileStatement) Pos() lexer.Token     { return ws.Token }
 func (ws *WhileStatement) String() string {
 	out := "while "
 	if ws.Condition != nil {
@@ -454,18 +543,24 @@ func (ws *WhileStatement) String() string {
 type ClassStatement struct {
 	Token      lexer.Token // El token 'class'.
 	Name       *Identifier
-	Attributes []*VarStatement // Atributos de la clase
+	SuperClass *Identifier      // Clase padre en 'extends', nil si no hereda
+	Attributes []*VarStatement  // Atributos de la clase
 	Methods    []*FuncStatement // Métodos de la clase
-	InitMethod *FuncStatement // Método constructor (init)
+	InitMethod *FuncStatement   // Método constructor (init)
+	RBrace     lexer.Token      // El token '}' que cierra la clase
 }
 
 func (cs *ClassStatement) statementNode()       {}
 func (cs *ClassStatement) TokenLiteral() string { return cs.Token.Lexeme }
+func (cs *ClassStatement) Pos() lexer.Token     { return cs.Token }
 func (cs *ClassStatement) String() string {
 	out := "class "
 	if cs.Name != nil {
 		out += cs.Name.String()
 	}
+	if cs.SuperClass != nil {
+		out += " extends " + cs.SuperClass.String()
+	}
 	out += " {\n"
 	for _, attr := range cs.Attributes {
 		out += "    " + attr.String() + "\n"
@@ -485,12 +580,14 @@ type ListLiteral struct {
 
 func (ll *ListLiteral) expressionNode()      {}
 func (ll *ListLiteral) TokenLiteral() string { return ll.Token.Lexeme }
+func (ll *ListLiteral) Pos() lexer.Token     { return ll.Token }
 func (ll *ListLiteral) String() string {
 	if ll.Elements == nil {
 		return "[]"
 	}
 	return fmt.Sprintf("[%s]", formatExpressions(ll.Elements))
 }
+
 // HashLiteral representa un literal de hash (e.g., {key: value}).
 type HashLiteral struct {
 	Token lexer.Token // El token '{'.
@@ -499,6 +596,7 @@ type HashLiteral struct {
 
 func (hl *HashLiteral) expressionNode()      {}
 func (hl *HashLiteral) TokenLiteral() string { return hl.Token.Lexeme }
+func (hl *HashLiteral) Pos() lexer.Token     { return hl.Token }
 func (hl *HashLiteral) String() string {
 	if hl.Pairs == nil {
 		return "{}"
@@ -519,6 +617,7 @@ type ClassInstantiation struct {
 
 func (ci *ClassInstantiation) expressionNode()      {}
 func (ci *ClassInstantiation) TokenLiteral() string { return ci.Token.Lexeme }
+func (ci *ClassInstantiation) Pos() lexer.Token     { return ci.Token }
 func (ci *ClassInstantiation) String() string {
 	if ci.ClassName == nil {
 		return "INVALID()"
@@ -533,8 +632,42 @@ type ThisExpression struct {
 
 func (te *ThisExpression) expressionNode()      {}
 func (te *ThisExpression) TokenLiteral() string { return te.Token.Lexeme }
+func (te *ThisExpression) Pos() lexer.Token     { return te.Token }
 func (te *ThisExpression) String() string       { return "this" }
 
+// SuperExpression representa la expresión 'super' dentro de un método.
+type SuperExpression struct {
+	Token lexer.Token // El token 'super'.
+}
+
+func (se *SuperExpression) expressionNode()      {}
+func (se *SuperExpression) TokenLiteral() string { return se.Token.Lexeme }
+func (se *SuperExpression) Pos() lexer.Token     { return se.Token }
+func (se *SuperExpression) String() string       { return "super" }
+
+// SpawnExpression representa 'spawn f(x)': ejecuta la llamada en una tarea
+// concurrente.
+type SpawnExpression struct {
+	Token lexer.Token     // El token 'spawn'.
+	Call  *CallExpression // La llamada que se ejecuta en la tarea.
+}
+
+func (se *SpawnExpression) expressionNode()      {}
+func (se *SpawnExpression) TokenLiteral() string { return se.Token.Lexeme }
+func (se *SpawnExpression) Pos() lexer.Token     { return se.Token }
+func (se *SpawnExpression) String() string       { return "spawn " + se.Call.String() }
+
+// AwaitExpression representa 'await expr': espera a que un Future se resuelva.
+type AwaitExpression struct {
+	Token lexer.Token // El token 'await'.
+	Value Expression  // La expresión que produce el Future.
+}
+
+func (ae *AwaitExpression) expressionNode()      {}
+func (ae *AwaitExpression) TokenLiteral() string { return ae.Token.Lexeme }
+func (ae *AwaitExpression) Pos() lexer.Token     { return ae.Token }
+func (ae *AwaitExpression) String() string       { return "await " + ae.Value.String() }
+
 // Helper para formatear listas de expresiones en strings.
 func formatExpressions(exps []Expression) string {
 	var parts []string
@@ -19,6 +19,9 @@ func (v *Variable) expressionNode() {}
 // TokenLiteral devuelve el literal del token de la variable.
 func (v *Variable) TokenLiteral() string { return v.Token.Lexeme }
 
+// Pos devuelve el token de la variable.
+func (v *Variable) Pos() lexer.Token { return v.Token }
+
 // String devuelve una representación en string de la variable.
 func (v *Variable) String() string {
 	if v.Type != "" {
@@ -26,3 +29,45 @@ func (v *Variable) String() string {
 	}
 	return v.Name
 }
+
+// FunctionLiteral representa una función anónima: func(a, b) { ... } o una
+// lambda flecha como (a, b) => a + b. El cuerpo de una lambda con expresión se
+// guarda como un bloque con un único 'return'.
+type FunctionLiteral struct {
+	Token      lexer.Token // El token 'func' o '=>'.
+	Parameters []*Identifier
+	ReturnType string
+	Body       *BlockStatement
+}
+
+// expressionNode implementa la interfaz Expression.
+func (fl *FunctionLiteral) expressionNode() {}
+
+// TokenLiteral devuelve el literal del token de la función.
+func (fl *FunctionLiteral) TokenLiteral() string { return fl.Token.Lexeme }
+
+// Pos devuelve el token 'func' o '=>'.
+func (fl *FunctionLiteral) Pos() lexer.Token { return fl.Token }
+
+// IsArrow indica si la función se escribió como lambda flecha.
+func (fl *FunctionLiteral) IsArrow() bool { return fl.Token.Type == lexer.ARROW }
+
+// String devuelve una representación en string de la función.
+func (fl *FunctionLiteral) String() string {
+	params := []string{}
+	for _, p := range fl.Parameters {
+		params = append(params, p.String())
+	}
+	body := "{}"
+	if fl.Body != nil {
+		body = fl.Body.String()
+	}
+	if fl.IsArrow() {
+		return fmt.Sprintf("(%s) => %s", formatStrings(params), body)
+	}
+	returnType := ""
+	if fl.ReturnType != "" {
+		returnType = fmt.Sprintf(": %s", fl.ReturnType)
+	}
+	return fmt.Sprintf("func(%s)%s %s", formatStrings(params), returnType, body)
+}
@@ -0,0 +1,95 @@
+package ast
+
+import "github.com/zylo-lang/zylo/internal/lexer"
+
+// Span delimita una región del código fuente. Las líneas y columnas empiezan
+// en 1 y el final es inclusivo, igual que en lexer.Token.
+type Span struct {
+	StartLine int
+	StartCol  int
+	EndLine   int
+	EndCol    int
+}
+
+// TokenSpan devuelve la región que ocupa un token.
+func TokenSpan(tok lexer.Token) Span {
+	return Span{
+		StartLine: tok.StartLine,
+		StartCol:  tok.StartCol,
+		EndLine:   tok.EndLine,
+		EndCol:    tok.EndCol,
+	}
+}
+
+// SpanOf calcula la región que ocupa un nodo. Para las expresiones compuestas
+// la región va desde su primer subnodo hasta el último; para el resto se usa
+// el token principal del nodo.
+func SpanOf(node Node) Span {
+	if node == nil {
+		return Span{}
+	}
+
+	switch n := node.(type) {
+	case *InfixExpression:
+		if n.Left != nil && n.Right != nil {
+			return joinSpans(SpanOf(n.Left), SpanOf(n.Right))
+		}
+	case *PrefixExpression:
+		if n.Right != nil {
+			return joinSpans(TokenSpan(n.Token), SpanOf(n.Right))
+		}
+	case *CallExpression:
+		if n.Function != nil {
+			end := TokenSpan(n.RParen)
+			if end.StartLine == 0 && len(n.Arguments) > 0 && n.Arguments[len(n.Arguments)-1] != nil {
+				end = SpanOf(n.Arguments[len(n.Arguments)-1])
+			}
+			return joinSpans(SpanOf(n.Function), end)
+		}
+	case *SpawnExpression:
+		if n.Call != nil {
+			return joinSpans(TokenSpan(n.Token), SpanOf(n.Call))
+		}
+	case *AwaitExpression:
+		if n.Value != nil {
+			return joinSpans(TokenSpan(n.Token), SpanOf(n.Value))
+		}
+	case *MemberExpression:
+		if n.Object != nil {
+			return joinSpans(SpanOf(n.Object), TokenSpan(n.Token))
+		}
+	case *IndexExpression:
+		if n.Left != nil && n.Index != nil {
+			return joinSpans(SpanOf(n.Left), SpanOf(n.Index))
+		}
+	case *ExpressionStatement:
+		if n.Expression != nil {
+			return SpanOf(n.Expression)
+		}
+	case *VarStatement:
+		if n.Value != nil {
+			return joinSpans(TokenSpan(n.Token), SpanOf(n.Value))
+		}
+	case *ReturnStatement:
+		if n.ReturnValue != nil {
+			return joinSpans(TokenSpan(n.Token), SpanOf(n.ReturnValue))
+		}
+	case *ThrowStatement:
+		if n.Exception != nil {
+			return joinSpans(TokenSpan(n.Token), SpanOf(n.Exception))
+		}
+	}
+
+	return TokenSpan(node.Pos())
+}
+
+// joinSpans devuelve la región que va desde el inicio de a hasta el final de b.
+func joinSpans(a, b Span) Span {
+	if a.StartLine == 0 {
+		return b
+	}
+	if b.StartLine == 0 {
+		return a
+	}
+	return Span{StartLine: a.StartLine, StartCol: a.StartCol, EndLine: b.EndLine, EndCol: b.EndCol}
+}
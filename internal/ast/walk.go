@@ -0,0 +1,145 @@
+package ast
+
+import "sort"
+
+// Inspect recorre node y sus subnodos en profundidad, en el orden en que
+// aparecen en el código fuente, y llama a f con cada uno. Si f devuelve false
+// no se recorren los subnodos de ese nodo.
+func Inspect(node Node, f func(Node) bool) {
+	if isNil(node) || !f(node) {
+		return
+	}
+	for _, child := range Children(node) {
+		Inspect(child, f)
+	}
+}
+
+// Children devuelve los subnodos directos de node, en orden. Las claves de un
+// hash se ordenan por su posición.
+func Children(node Node) []Node {
+	var children []Node
+	add := func(nodes ...Node) {
+		for _, n := range nodes {
+			if !isNil(n) {
+				children = append(children, n)
+			}
+		}
+	}
+	addStatements := func(statements []Statement) {
+		for _, stmt := range statements {
+			add(stmt)
+		}
+	}
+	addExpressions := func(expressions []Expression) {
+		for _, exp := range expressions {
+			add(exp)
+		}
+	}
+	addIdentifiers := func(idents []*Identifier) {
+		for _, ident := range idents {
+			add(ident)
+		}
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		addStatements(n.Statements)
+	case *ImportStatement:
+		add(n.ModuleName)
+		addIdentifiers(n.Names)
+	case *ExportStatement:
+		add(n.Statement)
+	case *VarStatement:
+		add(n.Name, n.Value)
+	case *ExpressionStatement:
+		add(n.Expression)
+	case *FuncStatement:
+		add(n.Name)
+		addIdentifiers(n.Parameters)
+		add(n.Body)
+	case *FunctionLiteral:
+		addIdentifiers(n.Parameters)
+		add(n.Body)
+	case *ReturnStatement:
+		add(n.ReturnValue)
+	case *BlockStatement:
+		addStatements(n.Statements)
+	case *ForInStatement:
+		add(n.Identifier, n.Iterable, n.Body)
+	case *TryStatement:
+		add(n.TryBlock, n.CatchClause, n.FinallyBlock)
+	case *CatchClause:
+		add(n.Parameter, n.CatchBlock)
+	case *ThrowStatement:
+		add(n.Exception)
+	case *PrefixExpression:
+		add(n.Right)
+	case *InfixExpression:
+		add(n.Left, n.Right)
+	case *CallExpression:
+		add(n.Function)
+		addExpressions(n.Arguments)
+	case *IndexExpression:
+		add(n.Left, n.Index)
+	case *MemberExpression:
+		add(n.Object, n.Property)
+	case *BlockExpression:
+		add(n.Block)
+	case *IfStatement:
+		add(n.Condition, n.Consequence, n.Alternative)
+	case *WhileStatement:
+		add(n.Condition, n.Body)
+	case *ClassStatement:
+		add(n.Name, n.SuperClass)
+		for _, attr := range n.Attributes {
+			add(attr)
+		}
+		for _, method := range n.Methods {
+			add(method)
+		}
+	case *ListLiteral:
+		addExpressions(n.Elements)
+	case *HashLiteral:
+		keys := make([]Expression, 0, len(n.Pairs))
+		for key := range n.Pairs {
+			keys = append(keys, key)
+		}
+		sort.SliceStable(keys, func(i, j int) bool {
+			a, b := SpanOf(keys[i]), SpanOf(keys[j])
+			return a.StartLine < b.StartLine || (a.StartLine == b.StartLine && a.StartCol < b.StartCol)
+		})
+		for _, key := range keys {
+			add(key, n.Pairs[key])
+		}
+	case *ClassInstantiation:
+		add(n.ClassName)
+		addExpressions(n.Arguments)
+	case *SpawnExpression:
+		add(n.Call)
+	case *AwaitExpression:
+		add(n.Value)
+	}
+	return children
+}
+
+// isNil indica si node es nil o un puntero nil guardado en la interfaz.
+func isNil(node Node) bool {
+	if node == nil {
+		return true
+	}
+	switch n := node.(type) {
+	case *Identifier:
+		return n == nil
+	case *BlockStatement:
+		return n == nil
+	case *CatchClause:
+		return n == nil
+	case *CallExpression:
+		return n == nil
+	case *VarStatement:
+		return n == nil
+	case *FuncStatement:
+		return n == nil
+	}
+	return false
+}
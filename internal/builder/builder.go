@@ -0,0 +1,401 @@
+// Package builder compila programas Zylo a ejecutables nativos: genera el
+// código Go en un módulo temporal que usa el runtime de este repositorio y
+// lo compila con la herramienta go instalada.
+package builder
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+	"github.com/zylo-lang/zylo/internal/codegen"
+	"github.com/zylo-lang/zylo/internal/modules"
+)
+
+// ModulePath es la ruta del módulo Go de este repositorio, que el módulo
+// generado requiere para usar el runtime.
+const ModulePath = "github.com/zylo-lang/zylo"
+
+// Options son las opciones de la compilación.
+type Options struct {
+	Output  string // Ruta del ejecutable; por defecto, el nombre del archivo sin .zylo
+	KeepGo  bool   // Conservar el módulo Go generado en vez de borrarlo
+	GOOS    string // Sistema operativo destino; vacío para el del entorno
+	GOARCH  string // Arquitectura destino; vacía para la del entorno
+	LDFlags string // Opciones para el enlazador, como en go build -ldflags
+
+	// SourceMap escribe junto a cada archivo Go del módulo conservado un
+	// <archivo>.map.json con su mapa al código Zylo. Implica KeepGo.
+	SourceMap bool
+}
+
+// Result es el resultado de una compilación.
+type Result struct {
+	Output string // Ruta del ejecutable generado
+	Dir    string // Directorio del módulo Go, si se conservó
+}
+
+// Sources genera el código Go de program, leído de filename, y de los
+// módulos que importa, formateado con go/format: main.go y un
+// <módulo>/<módulo>.go por cada módulo. Las claves son rutas relativas al
+// directorio del módulo Go. Las directivas //line del código apuntan a los
+// archivos Zylo, y maps tiene el mapa de cada archivo Go.
+func Sources(filename string, program *ast.Program, imported []*modules.File) (map[string][]byte, map[string]*codegen.SourceMap, error) {
+	files := make(map[string][]byte)
+	maps := make(map[string]*codegen.SourceMap)
+	generate := func(name, source string, gen func(cg *codegen.CodeGenerator) (string, error)) error {
+		cg := codegen.NewCodeGenerator()
+		for _, module := range imported {
+			cg.AddModule(module.Name, module.Program)
+		}
+		path, err := filepath.Abs(source)
+		if err != nil {
+			return err
+		}
+		cg.SourceFile = path
+		code, err := gen(cg)
+		var generateErrs codegen.Errors
+		if errors.As(err, &generateErrs) {
+			return &GenerateError{File: source, Errors: generateErrs}
+		}
+		if err != nil {
+			return err
+		}
+		if files[name], err = gofmt(name, code); err != nil {
+			return err
+		}
+		maps[name], err = cg.SourceMap(name, files[name])
+		return err
+	}
+
+	err := generate("main.go", filename, func(cg *codegen.CodeGenerator) (string, error) {
+		return cg.Generate(program)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	paths := make(map[string]string) // Nombre del paquete -> archivo del módulo
+	for _, module := range imported {
+		if other, ok := paths[module.Name]; ok {
+			return nil, nil, fmt.Errorf("modules %s and %s would generate the same package %s", other, module.Path, module.Name)
+		}
+		paths[module.Name] = module.Path
+
+		name := module.Name + "/" + module.Name + ".go"
+		err := generate(name, module.Path, func(cg *codegen.CodeGenerator) (string, error) {
+			return cg.GenerateModule(module.Name, module.Program)
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return files, maps, nil
+}
+
+// gofmt formatea el código Go generado para el archivo name.
+func gofmt(name, code string) ([]byte, error) {
+	formatted, err := format.Source([]byte(code))
+	if err != nil {
+		return nil, fmt.Errorf("generated %s is not valid Go: %v", name, err)
+	}
+	return formatted, nil
+}
+
+// GoMod devuelve el go.mod del módulo generado, que usa el runtime del
+// repositorio que está en root.
+func GoMod(root string) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "module %s\n\n", codegen.DefaultModulePath)
+	b.WriteString("go 1.24\n\n")
+	fmt.Fprintf(&b, "require %s v0.0.0\n\n", ModulePath)
+	fmt.Fprintf(&b, "replace %s => %s\n", ModulePath, filepath.ToSlash(root))
+	return b.Bytes()
+}
+
+// RuntimeRoot devuelve el directorio de este repositorio, donde está el
+// paquete runtime que importa el código generado. Se busca en la variable
+// de entorno ZYLO_ROOT, en el directorio donde se compiló zylo y en el
+// directorio actual y sus padres.
+func RuntimeRoot() (string, error) {
+	if root := os.Getenv("ZYLO_ROOT"); root != "" {
+		if !isRepository(root) {
+			return "", fmt.Errorf("ZYLO_ROOT=%s does not contain the %s module", root, ModulePath)
+		}
+		return filepath.Abs(root)
+	}
+
+	candidates := []string{}
+	if _, file, _, ok := runtime.Caller(0); ok {
+		candidates = append(candidates, filepath.Join(filepath.Dir(file), "..", ".."))
+	}
+	if dir, err := os.Getwd(); err == nil {
+		for {
+			candidates = append(candidates, dir)
+			parent := filepath.Dir(dir)
+			if parent == dir {
+				break
+			}
+			dir = parent
+		}
+	}
+	for _, dir := range candidates {
+		if isRepository(dir) {
+			return filepath.Abs(dir)
+		}
+	}
+	return "", fmt.Errorf("cannot find the %s module; set ZYLO_ROOT to its directory", ModulePath)
+}
+
+// isRepository indica si dir es la raíz del módulo de este repositorio.
+func isRepository(dir string) bool {
+	content, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return false
+	}
+	firstLine, _, _ := strings.Cut(string(content), "\n")
+	return strings.TrimSpace(firstLine) == "module "+ModulePath
+}
+
+// DefaultOutput devuelve la ruta del ejecutable de filename si no se indica
+// otra: el nombre sin .zylo, con .exe si el destino es Windows.
+func DefaultOutput(filename, goos string) string {
+	output := strings.TrimSuffix(filename, ".zylo")
+	if goos == "" {
+		goos = os.Getenv("GOOS")
+	}
+	if goos == "" {
+		goos = runtime.GOOS
+	}
+	if goos == "windows" {
+		output += ".exe"
+	}
+	return output
+}
+
+// Build compila program, leído de filename, a un ejecutable nativo.
+func Build(filename string, program *ast.Program, imported []*modules.File, opts Options) (*Result, error) {
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		return nil, fmt.Errorf("the go tool is required to build executables: %v", err)
+	}
+	root, err := RuntimeRoot()
+	if err != nil {
+		return nil, err
+	}
+	files, maps, err := Sources(filename, program, imported)
+	if err != nil {
+		return nil, err
+	}
+	files["go.mod"] = GoMod(root)
+	if opts.SourceMap {
+		opts.KeepGo = true
+		for name, sourceMap := range maps {
+			if files[name+".map.json"], err = json.MarshalIndent(sourceMap, "", "  "); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	output := opts.Output
+	if output == "" {
+		output = DefaultOutput(filename, opts.GOOS)
+	}
+	if output, err = filepath.Abs(output); err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "zylo-build-")
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{Output: output}
+	if opts.KeepGo {
+		result.Dir = dir
+	} else {
+		defer os.RemoveAll(dir)
+	}
+	if err := writeFiles(dir, files); err != nil {
+		return nil, err
+	}
+
+	args := []string{"build", "-o", output}
+	if opts.LDFlags != "" {
+		args = append(args, "-ldflags", opts.LDFlags)
+	}
+	args = append(args, ".")
+	cmd := exec.Command(goTool, args...)
+	cmd.Dir = dir
+	cmd.Env = buildEnv(opts)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return result, &BuildError{Output: RewriteErrors(out.String(), dir, maps)}
+	}
+	return result, nil
+}
+
+// buildEnv devuelve el entorno de go build: el del proceso, sin el workspace
+// de Go que pudiera haber en el directorio actual y con el destino de opts.
+func buildEnv(opts Options) []string {
+	env := append(os.Environ(), "GOWORK=off")
+	if opts.GOOS != "" {
+		env = append(env, "GOOS="+opts.GOOS)
+	}
+	if opts.GOARCH != "" {
+		env = append(env, "GOARCH="+opts.GOARCH)
+	}
+	return env
+}
+
+// writeFiles escribe files en dir, en orden para que los errores sean
+// reproducibles.
+func writeFiles(dir string, files map[string][]byte) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, files[name], 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BuildError es el error de go build al compilar el código generado, con
+// los mensajes en coordenadas del código Zylo.
+type BuildError struct {
+	Output string
+}
+
+func (e *BuildError) Error() string {
+	return "go build failed:\n" + e.Output
+}
+
+// GenerateError son los errores del generador en el archivo Zylo File: lo
+// que el backend de Go no soporta.
+type GenerateError struct {
+	File   string
+	Errors codegen.Errors
+}
+
+func (e *GenerateError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = e.File + ":" + err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Códigos de los errores de Build para los diagnósticos. No cambian entre
+// versiones.
+const (
+	CodeGoBuild  = "B001" // Un mensaje de go build sobre el código generado
+	CodeBuild    = "B002" // Cualquier otro fallo al generar o compilar
+	CodeGenerate = "B003" // Algo que el backend de Go no soporta
+)
+
+// Message es un mensaje de Output. Las líneas sin posición que lo siguen,
+// como las que go build indenta bajo un error, van en Details.
+type Message struct {
+	File    string // "" si el mensaje no tiene posición
+	Line    int
+	Column  int // 0 si go build no la da
+	Text    string
+	Details []string
+}
+
+// Messages separa Output en mensajes.
+func (e *BuildError) Messages() []Message {
+	var messages []Message
+	for _, line := range strings.Split(strings.TrimRight(e.Output, "\n"), "\n") {
+		match := positionPattern.FindStringSubmatch(line)
+		switch {
+		case strings.TrimSpace(line) == "":
+			continue
+		case match != nil:
+			m := Message{File: match[1], Text: line[len(match[0]):]}
+			m.Line, _ = strconv.Atoi(match[2])
+			m.Column, _ = strconv.Atoi(match[3])
+			messages = append(messages, m)
+		case strings.TrimLeft(line, " \t") != line && len(messages) > 0:
+			last := &messages[len(messages)-1]
+			last.Details = append(last.Details, strings.TrimSpace(line))
+		default:
+			messages = append(messages, Message{Text: line})
+		}
+	}
+	return messages
+}
+
+// positionPattern reconoce la posición con la que empiezan los mensajes del
+// compilador de Go: archivo:línea:columna: o archivo:línea:.
+var positionPattern = regexp.MustCompile(`^(\S+\.(?:go|zylo)):(\d+)(?::(\d+))?: `)
+
+// RewriteErrors pasa a coordenadas del código Zylo los mensajes de go build
+// compilando el módulo de dir. Las posiciones en archivos .zylo, que salen
+// de las directivas //line, solo se hacen relativas al directorio actual;
+// las de los archivos Go se traducen con su mapa. Las líneas "# paquete"
+// que go build escribe antes de los errores de cada paquete se quitan.
+func RewriteErrors(output, dir string, maps map[string]*codegen.SourceMap) string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimRight(output, "\n"), "\n") {
+		if strings.HasPrefix(line, "# ") {
+			continue
+		}
+		match := positionPattern.FindStringSubmatch(line)
+		if match == nil {
+			lines = append(lines, line)
+			continue
+		}
+		path, message := match[1], line[len(match[0]):]
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		position := match[2]
+		if match[3] != "" {
+			position += ":" + match[3]
+		}
+
+		if rel, err := filepath.Rel(dir, path); err == nil && strings.HasSuffix(path, ".go") {
+			if sourceMap, ok := maps[filepath.ToSlash(rel)]; ok {
+				goLine, _ := strconv.Atoi(match[2])
+				if span, ok := sourceMap.Lookup(goLine); ok {
+					path = sourceMap.Source
+					position = fmt.Sprintf("%d:%d", span.StartLine, span.StartCol)
+				}
+			}
+		}
+		lines = append(lines, fmt.Sprintf("%s:%s: %s", displayPath(path), position, message))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// displayPath devuelve path relativa al directorio actual si está dentro de
+// él, o absoluta si no.
+func displayPath(path string) string {
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel
+		}
+	}
+	return path
+}
@@ -0,0 +1,171 @@
+package builder
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zylo-lang/zylo/internal/codegen"
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/modules"
+	"github.com/zylo-lang/zylo/internal/parser"
+)
+
+func TestBuild(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.zylo": `import "lib/greet"
+var names = ["ana", "luis"]
+for name in names {
+    show.log(greet.hello(name))
+}
+`,
+		"lib/greet.zylo": `export func hello(name: string) {
+    return "hola " + name
+}
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	filename := filepath.Join(dir, "main.zylo")
+	p := parser.New(lexer.New(files["main.zylo"]))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+	imported, err := modules.Load(filename, program, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(dir, "app")
+	result, err := Build(filename, program, imported, Options{Output: output, KeepGo: true, LDFlags: "-s -w"})
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	defer os.RemoveAll(result.Dir)
+
+	got, err := exec.Command(result.Output).CombinedOutput()
+	if err != nil {
+		t.Fatalf("executable failed: %v\n%s", err, got)
+	}
+	if want := "hola ana\nhola luis\n"; string(got) != want {
+		t.Errorf("expected output %q, got %q", want, got)
+	}
+
+	// --keep-go conserva el módulo, con el código formateado
+	for _, name := range []string{"go.mod", "main.go", "greet/greet.go"} {
+		if _, err := os.Stat(filepath.Join(result.Dir, filepath.FromSlash(name))); err != nil {
+			t.Errorf("kept module is missing %s: %v", name, err)
+		}
+	}
+	mainCode, _ := os.ReadFile(filepath.Join(result.Dir, "main.go"))
+	if !strings.Contains(string(mainCode), "\tfor _, name := range names {") {
+		t.Errorf("main.go is not gofmt-formatted:\n%s", mainCode)
+	}
+}
+
+func TestBuildCrossCompile(t *testing.T) {
+	if testing.Short() {
+		t.Skip("cross-compiling the standard library is slow")
+	}
+	program := parser.New(lexer.New(`show.log("hola")`)).ParseProgram()
+	dir := t.TempDir()
+	result, err := Build(filepath.Join(dir, "hola.zylo"), program, nil, Options{GOOS: "windows", GOARCH: "amd64"})
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if want := filepath.Join(dir, "hola.exe"); result.Output != want {
+		t.Errorf("expected output %s, got %s", want, result.Output)
+	}
+	content, err := os.ReadFile(result.Output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(content), "MZ") {
+		t.Errorf("expected a Windows executable")
+	}
+	if result.Dir != "" {
+		t.Errorf("expected the Go module to be removed, got %s", result.Dir)
+	}
+}
+
+func TestGoMod(t *testing.T) {
+	got := string(GoMod("/src/zylo"))
+	for _, want := range []string{
+		"module zyloapp\n",
+		"require github.com/zylo-lang/zylo v0.0.0\n",
+		"replace github.com/zylo-lang/zylo => /src/zylo\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("go.mod does not contain %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestPanicsPointToZylo(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "boom.zylo")
+	program := parser.New(lexer.New("var items = [1, 2]\nshow.log(items[5])\n")).ParseProgram()
+	result, err := Build(filename, program, nil, Options{Output: filepath.Join(dir, "boom")})
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	output, err := exec.Command(result.Output).CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected the executable to panic, got %s", output)
+	}
+	if want := filename + ":2"; !strings.Contains(string(output), want) {
+		t.Errorf("expected the stack trace to contain %s:\n%s", want, output)
+	}
+}
+
+func TestRewriteErrors(t *testing.T) {
+	sourceMap := &codegen.SourceMap{
+		File:   "main.go",
+		Source: "/src/app.zylo",
+		Mappings: []codegen.Mapping{{
+			Go:   codegen.Span{StartLine: 10, StartCol: 1, EndLine: 12, EndCol: 2},
+			Zylo: codegen.Span{StartLine: 4, StartCol: 5, EndLine: 4, EndCol: 20},
+		}},
+	}
+	output := "# zyloapp\n" +
+		"./main.go:11:3: undefined: x\n" +
+		"../../src/lib.zylo:7:2: declared and not used: y\n" +
+		"./main.go:3:2: \"fmt\" imported and not used\n"
+	got := RewriteErrors(output, "/tmp/build", map[string]*codegen.SourceMap{"main.go": sourceMap})
+	want := "/src/app.zylo:4:5: undefined: x\n" +
+		"/src/lib.zylo:7:2: declared and not used: y\n" +
+		"/tmp/build/main.go:3:2: \"fmt\" imported and not used"
+	if got != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestBuildErrorMessages(t *testing.T) {
+	err := &BuildError{Output: "app.zylo:4:5: cannot use x (variable of type int) as string value\n" +
+		"\thave int\n" +
+		"\twant string\n" +
+		"app.zylo:7: too many errors\n" +
+		"go: cannot find GOROOT directory\n"}
+	got := err.Messages()
+	want := []Message{
+		{File: "app.zylo", Line: 4, Column: 5, Text: "cannot use x (variable of type int) as string value", Details: []string{"have int", "want string"}},
+		{File: "app.zylo", Line: 7, Text: "too many errors"},
+		{Text: "go: cannot find GOROOT directory"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
@@ -2,93 +2,521 @@ package codegen
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/zylo-lang/zylo/internal/ast"
+	"github.com/zylo-lang/zylo/internal/modules"
+	"github.com/zylo-lang/zylo/internal/sema"
 )
 
+// DefaultModulePath es la ruta del módulo Go del programa generado si no se
+// indica otra.
+const DefaultModulePath = "zyloapp"
+
+// RuntimePath es la ruta del paquete zyloruntime que importa el código
+// generado.
+const RuntimePath = "github.com/zylo-lang/zylo/runtime"
+
 // CodeGenerator es el struct principal para la generación de código Go.
+//
+// Los tipos del código generado salen del comprobador de tipos: las
+// variables, parámetros y resultados con un tipo inferido o anotado usan el
+// tipo Go correspondiente, y el resto zyloruntime.Value, con las funciones
+// del runtime para operar con ellos.
 type CodeGenerator struct {
-	output      strings.Builder
-	indentation int
-	classNames  []string
+	output       strings.Builder
+	indentation  int
+	classNames   []string
+	classes      map[string]*ast.ClassStatement // Clases del programa por nombre
+	currentClass *ast.ClassStatement            // Clase cuyos métodos se generan
+
+	// ModulePath es la ruta del módulo Go del programa generado. Cada módulo
+	// de Zylo es un paquete: utils se importa como ModulePath + "/utils".
+	ModulePath string
+
+	// SourceFile es la ruta del archivo Zylo que se genera. Si no está vacía,
+	// cada sentencia va precedida de una directiva //line con su posición
+	// en ese archivo, para que los errores de Go apunten al código Zylo.
+	SourceFile string
+
+	imports       map[string]bool         // Paquetes Go que usa el código generado
+	knownModules  map[string]*moduleInfo  // Módulos registrados con AddModule
+	packages      map[string]string       // Nombre con el que se importó un módulo -> paquete Go
+	names         map[string]importedName // Nombres importados con 'from'
+	exported      map[string]string       // En un módulo, nombres exportados -> nombre Go
+	classPackages map[*sema.Class]string  // Clases de los módulos importados -> paquete Go
+	builtinNames  map[string]bool         // Módulos incorporados importados, como zyloruntime
+
+	checker *sema.Checker              // Tipos del programa que se genera
+	isMain  bool                       // Se genera el paquete main
+	globals map[*ast.VarStatement]bool // Variables de nivel superior declaradas en el paquete
+	fn      *funcContext               // Función cuyo cuerpo se genera, nil fuera de ellas
+	loops   int                        // Bucles abiertos en el cuerpo que se genera
+	tries   []*tryContext              // Bloques try abiertos en el cuerpo que se genera, el último el más interno
+	jumps   int                        // Variables de salto declaradas, para que sus nombres no se repitan
+	rest    []ast.Statement            // Sentencias que siguen a la actual en su bloque
+	spans   []ast.Span                 // Posiciones de las directivas //line escritas, en orden
+	errors  Errors
+}
+
+// moduleInfo es lo que el generador sabe de un módulo importado: qué clases
+// exporta, porque se instancian con su constructor New<Clase>, y el tipo de
+// cada nombre exportado.
+type moduleInfo struct {
+	classes map[string]bool
+	types   map[string]*sema.Type
+}
+
+// importedName es un nombre importado con 'from utils import Split'.
+type importedName struct {
+	pkg   string // Paquete Go del módulo
+	name  string // Nombre Go del valor exportado
+	class bool
+}
+
+// funcContext es la función cuyo cuerpo se genera.
+type funcContext struct {
+	result *sema.Type // Tipo del resultado, nil si no devuelve nada
 }
 
+// tryContext es un bloque try cuyo cuerpo se genera. El try, el catch y el
+// finally son closures, así que un return, o un break o continue de un
+// bucle de fuera, no puede saltar directamente: guarda el salto en la
+// variable jump y vuelve del closure, y el código que sigue al try lo
+// repite.
+type tryContext struct {
+	jump   string       // Variable con el salto pendiente, 0 si no hay
+	result string       // Variable con el valor del return pendiente
+	loops  int          // Bucles abiertos fuera del try
+	used   map[int]bool // Saltos que hace el cuerpo
+}
+
+// Saltos que guarda tryContext.jump.
+const (
+	jumpReturn = iota + 1
+	jumpBreak
+	jumpContinue
+)
+
 // NewCodeGenerator crea un nuevo CodeGenerator.
 func NewCodeGenerator() *CodeGenerator {
-	return &CodeGenerator{classNames: make([]string, 0)}
+	return &CodeGenerator{
+		classNames:    make([]string, 0),
+		classes:       make(map[string]*ast.ClassStatement),
+		ModulePath:    DefaultModulePath,
+		imports:       make(map[string]bool),
+		knownModules:  make(map[string]*moduleInfo),
+		packages:      make(map[string]string),
+		names:         make(map[string]importedName),
+		exported:      make(map[string]string),
+		classPackages: make(map[*sema.Class]string),
+		builtinNames:  make(map[string]bool),
+		globals:       make(map[*ast.VarStatement]bool),
+	}
+}
+
+// AddModule registra un módulo que el programa importa, para saber qué
+// clases exporta y de qué tipo es lo que exporta. Se llama antes de Generate
+// con cada módulo importado.
+func (cg *CodeGenerator) AddModule(name string, program *ast.Program) {
+	checker := sema.NewChecker()
+	checker.Check(program)
+
+	info := &moduleInfo{classes: make(map[string]bool), types: make(map[string]*sema.Type)}
+	for _, stmt := range program.Statements {
+		export, ok := stmt.(*ast.ExportStatement)
+		if !ok {
+			continue
+		}
+		switch s := export.Statement.(type) {
+		case *ast.ClassStatement:
+			info.classes[export.Name()] = true
+			if class, ok := checker.Class(s.Name.Value); ok {
+				info.types[export.Name()] = &sema.Type{Kind: sema.KindClass, Class: class}
+				cg.classPackages[class] = name
+			}
+		case *ast.FuncStatement:
+			info.types[export.Name()] = checker.TypeOfName(s.Name)
+		case *ast.VarStatement:
+			info.types[export.Name()] = checker.TypeOfName(s.Name)
+		}
+	}
+	cg.knownModules[name] = info
+}
+
+// GoName devuelve el nombre Go de un valor exportado por un módulo, o de un
+// atributo o un método de una clase: el mismo con la primera letra en
+// mayúscula, para que Go lo exporte del paquete.
+func GoName(name string) string {
+	if name == "" {
+		return name
+	}
+	runes := []rune(name)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// use registra que el código generado usa el paquete Go path.
+func (cg *CodeGenerator) use(path string) {
+	cg.imports[path] = true
+}
+
+// header devuelve la cláusula package y los imports que se usaron.
+func (cg *CodeGenerator) header(pkg string) string {
+	paths := make([]string, 0, len(cg.imports))
+	for path := range cg.imports {
+		paths = append(paths, path)
+	}
+	// Primero la biblioteca estándar y después el runtime y los módulos
+	sort.Slice(paths, func(i, j int) bool {
+		iStd, jStd := cg.isStandard(paths[i]), cg.isStandard(paths[j])
+		if iStd != jStd {
+			return iStd
+		}
+		return paths[i] < paths[j]
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	if len(paths) > 0 {
+		b.WriteString("import (\n")
+		for i, path := range paths {
+			if i > 0 && cg.isStandard(paths[i-1]) && !cg.isStandard(path) {
+				b.WriteString("\n")
+			}
+			if path == RuntimePath {
+				// El paquete no se llama como su directorio
+				fmt.Fprintf(&b, "    zyloruntime %q\n", path)
+				continue
+			}
+			fmt.Fprintf(&b, "    %q\n", path)
+		}
+		b.WriteString(")\n\n")
+	}
+	return b.String()
+}
+
+// isStandard indica si path es un paquete de la biblioteca estándar de Go.
+func (cg *CodeGenerator) isStandard(path string) bool {
+	return !strings.Contains(path, ".") && !strings.HasPrefix(path, cg.ModulePath+"/")
+}
+
+// registerImports prepara los nombres de los módulos que importa program.
+func (cg *CodeGenerator) registerImports(program *ast.Program) {
+	for _, stmt := range program.Statements {
+		if imp, ok := stmt.(*ast.ImportStatement); ok && imp.ModuleName != nil && modules.IsBuiltin(imp.Module()) {
+			cg.builtinNames[imp.ModuleName.Value] = true
+		}
+	}
+	for _, imp := range modules.Imports(program) {
+		pkg := imp.ModuleName.Value
+		info := cg.knownModules[pkg]
+		if imp.Names == nil {
+			cg.packages[pkg] = pkg
+			continue
+		}
+		for _, name := range imp.Names {
+			cg.names[name.Value] = importedName{
+				pkg:   pkg,
+				name:  GoName(name.Value),
+				class: info != nil && info.classes[name.Value],
+			}
+		}
+	}
+}
+
+// moduleType devuelve el tipo del nombre name exportado por el paquete pkg,
+// o any si el módulo no se registró con AddModule.
+func (cg *CodeGenerator) moduleType(pkg, name string) *sema.Type {
+	if info := cg.knownModules[pkg]; info != nil {
+		if t, ok := info.types[name]; ok {
+			return t
+		}
+	}
+	return sema.Any
+}
+
+// qualified escribe el nombre Go de name exportado por el paquete pkg y
+// registra el import del paquete.
+func (cg *CodeGenerator) qualified(pkg, name string) string {
+	cg.use(cg.ModulePath + "/" + pkg)
+	return pkg + "." + name
+}
+
+// identifier devuelve el nombre Go de un identificador: los importados con
+// 'from' se califican con su paquete, los exportados por el módulo que se
+// genera llevan la primera letra en mayúscula y los que chocan con Go
+// terminan en '_'. main es zyloMain, porque el main de Go lo llama al final.
+func (cg *CodeGenerator) identifier(name string) string {
+	if imp, ok := cg.names[name]; ok {
+		return cg.qualified(imp.pkg, imp.name)
+	}
+	if goName, ok := cg.exported[name]; ok {
+		return goName
+	}
+	if name == "main" && cg.isMain {
+		return "zyloMain"
+	}
+	if goKeywords[name] {
+		return name + "_"
+	}
+	return name
+}
+
+// isBuiltin indica si ident se refiere a un built-in y no a un nombre del
+// programa. Importar un módulo incorporado no oculta sus built-ins.
+func (cg *CodeGenerator) isBuiltin(ident *ast.Identifier) bool {
+	return !cg.checker.Declared(ident) || cg.builtinNames[ident.Value]
+}
+
+// Error es una construcción que el backend de Go no soporta, con su
+// posición.
+type Error struct {
+	Span    ast.Span
+	Message string
 }
 
-// Generate genera código Go a partir de un AST.
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Span.StartLine, e.Span.StartCol, e.Message)
+}
+
+// Errors son los errores de una generación, en el orden en que se
+// encontraron. Generate y GenerateModule los devuelven todos juntos.
+type Errors []*Error
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// fail registra que node usa algo que el backend de Go no soporta.
+func (cg *CodeGenerator) fail(node ast.Node, format string, args ...interface{}) {
+	cg.errors = append(cg.errors, &Error{Span: ast.SpanOf(node), Message: fmt.Sprintf(format, args...)})
+}
+
+// err devuelve los errores de la generación como un Errors, o nil si no hubo.
+func (cg *CodeGenerator) err() error {
+	if len(cg.errors) == 0 {
+		return nil
+	}
+	return cg.errors
+}
+
+// check obtiene los tipos de program.
+func (cg *CodeGenerator) check(program *ast.Program) {
+	cg.checker = sema.NewChecker()
+	cg.checker.Check(program)
+}
+
+// declarations devuelve las sentencias de nivel superior con las
+// declaraciones exportadas desenvueltas.
+func declarations(program *ast.Program) []ast.Statement {
+	statements := make([]ast.Statement, len(program.Statements))
+	for i, stmt := range program.Statements {
+		if export, ok := stmt.(*ast.ExportStatement); ok {
+			stmt = export.Statement
+		}
+		statements[i] = stmt
+	}
+	return statements
+}
+
+// Generate genera código Go a partir de un AST. Las variables de nivel
+// superior se declaran en el paquete, para que las funciones las vean, y se
+// inicializan en main junto con el resto de sentencias; al final main llama
+// a la función main de Zylo si existe, como el evaluador.
 func (cg *CodeGenerator) Generate(program *ast.Program) (string, error) {
 	if program == nil {
 		return "", fmt.Errorf("program is nil")
 	}
 
-	cg.writeString("package main\n\n")
-	cg.writeString("import (\n")
-	cg.writeString("    \"fmt\"\n")
-	cg.writeString(")\n\n")
+	cg.isMain = true
+	cg.registerImports(program)
+	cg.check(program)
+	statements := declarations(program)
 
-	// First pass: generate all function and class declarations
-	for _, stmt := range program.Statements {
-		if stmt != nil {
-			if funcStmt, ok := stmt.(*ast.FuncStatement); ok {
-				if funcStmt.Name.Value != "main" {
-					cg.generateStatement(stmt)
-				}
-			} else if classStmt, ok := stmt.(*ast.ClassStatement); ok {
-				cg.classNames = append(cg.classNames, classStmt.Name.Value)
-				cg.generateStatement(stmt)
-			}
+	// Registrar las clases antes de generar, para resolver las superclases
+	// aunque se declaren después de sus subclases
+	for _, stmt := range statements {
+		if classStmt, ok := stmt.(*ast.ClassStatement); ok && classStmt.Name != nil {
+			cg.classes[classStmt.Name.Value] = classStmt
+		}
+	}
+
+	// First pass: generate all variable, function and class declarations
+	hasMain := false
+	for _, stmt := range statements {
+		switch s := stmt.(type) {
+		case *ast.VarStatement:
+			cg.globals[s] = true
+			cg.lineDirective(s)
+			cg.writeString(fmt.Sprintf("var %s %s\n\n", cg.identifier(s.Name.Value), cg.goType(cg.checker.TypeOfName(s.Name))))
+		case *ast.FuncStatement:
+			hasMain = hasMain || s.Name.Value == "main"
+			cg.generateStatement(stmt)
+		case *ast.ClassStatement:
+			cg.classNames = append(cg.classNames, s.Name.Value)
+			cg.generateStatement(stmt)
 		}
 	}
 
 	// Generate main function with executable statements
 	cg.writeString("func main() {\n")
 	cg.indent()
+	cg.fn = &funcContext{}
+	var executable []ast.Statement
+	for _, stmt := range statements {
+		switch stmt.(type) {
+		case *ast.FuncStatement, *ast.ClassStatement:
+			// Skip function and class declarations in main
+		default:
+			executable = append(executable, stmt)
+		}
+	}
+	cg.generateStatements(executable)
+	if hasMain {
+		cg.writeString("zyloMain()\n")
+	}
+	cg.fn = nil
+	cg.dedent()
+	cg.writeString("}\n")
 
-	for _, stmt := range program.Statements {
-		// Skip function and class declarations in main
-		if stmt != nil {
-			if _, ok := stmt.(*ast.FuncStatement); ok {
-				continue
-			} else if _, ok := stmt.(*ast.ClassStatement); ok {
-				continue
-			}
+	return cg.header("main") + cg.output.String(), cg.err()
+}
+
+// GenerateModule genera el paquete Go name a partir del módulo program. Los
+// nombres exportados se escriben con la primera letra en mayúscula y las
+// sentencias de nivel superior que no son declaraciones se ejecutan en init.
+func (cg *CodeGenerator) GenerateModule(name string, program *ast.Program) (string, error) {
+	if program == nil {
+		return "", fmt.Errorf("program is nil")
+	}
+
+	cg.registerImports(program)
+	cg.check(program)
+	for _, export := range modules.Exports(program) {
+		cg.exported[export] = GoName(export)
+	}
+	statements := declarations(program)
+	for _, stmt := range statements {
+		if classStmt, ok := stmt.(*ast.ClassStatement); ok && classStmt.Name != nil {
+			cg.classes[classStmt.Name.Value] = classStmt
+			cg.classNames = append(cg.classNames, classStmt.Name.Value)
+		}
+	}
+
+	var initStatements []ast.Statement
+	for _, stmt := range statements {
+		switch stmt.(type) {
+		case *ast.FuncStatement, *ast.ClassStatement, *ast.VarStatement:
 			cg.generateStatement(stmt)
+		case *ast.ImportStatement:
+		default:
+			initStatements = append(initStatements, stmt)
 		}
 	}
 
-	cg.dedent()
-	cg.writeString("}\n")
+	if len(initStatements) > 0 {
+		cg.writeString("func init() {\n")
+		cg.indent()
+		cg.fn = &funcContext{}
+		cg.generateStatements(initStatements)
+		cg.fn = nil
+		cg.dedent()
+		cg.writeString("}\n")
+	}
+
+	return cg.header(name) + cg.output.String(), cg.err()
+}
+
+// generateStatements genera las sentencias de un bloque.
+func (cg *CodeGenerator) generateStatements(statements []ast.Statement) {
+	outer := cg.rest
+	for i, stmt := range statements {
+		cg.rest = statements[i+1:]
+		cg.generateStatement(stmt)
+	}
+	cg.rest = outer
+}
 
-	return cg.output.String(), nil
+// generateBody genera las sentencias de un bloque que puede ser nil.
+func (cg *CodeGenerator) generateBody(block *ast.BlockStatement) {
+	if block != nil {
+		cg.generateStatements(block.Statements)
+	}
 }
 
 // generateBreakStatement genera código Go para una sentencia 'break'.
 func (cg *CodeGenerator) generateBreakStatement(stmt *ast.BreakStatement) {
-	cg.writeString("break\n")
+	cg.jump(jumpBreak, "")
 }
 
 // generateContinueStatement genera código Go para una sentencia 'continue'.
 func (cg *CodeGenerator) generateContinueStatement(stmt *ast.ContinueStatement) {
-	cg.writeString("continue\n")
+	cg.jump(jumpContinue, "")
 }
 
-// generateForInStatement genera código Go para una sentencia 'for in'.
-func (cg *CodeGenerator) generateForInStatement(stmt *ast.ForInStatement) {
-	cg.writeString(fmt.Sprintf("for _, %s := range ", stmt.Identifier.Value))
-	cg.generateExpression(stmt.Iterable)
-	cg.writeString(" {\n")
-	cg.indent()
-
-	if stmt.Body != nil {
-		for _, bodyStmt := range stmt.Body.Statements {
-			cg.generateStatement(bodyStmt)
+// jump escribe un return con el resultado result (vacío si la función no
+// devuelve nada), un break o un continue. Si el salto sale del try más
+// interno, lo guarda en su variable de salto y vuelve del closure.
+func (cg *CodeGenerator) jump(kind int, result string) {
+	if len(cg.tries) == 0 || (kind != jumpReturn && cg.loops > 0) {
+		switch kind {
+		case jumpReturn:
+			if result != "" {
+				result = " " + result
+			}
+			cg.writeString("return" + result + "\n")
+		case jumpBreak:
+			cg.writeString("break\n")
+		case jumpContinue:
+			cg.writeString("continue\n")
 		}
+		return
+	}
+
+	try := cg.tries[len(cg.tries)-1]
+	try.used[kind] = true
+	if result != "" {
+		cg.writeString(fmt.Sprintf("%s = %s\n", try.result, result))
+	}
+	cg.writeString(fmt.Sprintf("%s = %d\n", try.jump, kind))
+	cg.writeString("return\n")
+}
+
+// generateForInStatement genera código Go para una sentencia 'for in'. Las
+// listas se recorren directamente; los strings por caracteres y los hashes
+// por sus claves ordenadas, como en el evaluador.
+func (cg *CodeGenerator) generateForInStatement(stmt *ast.ForInStatement) {
+	code, t := cg.expression(stmt.Iterable)
+	elem := cg.checker.TypeOfName(stmt.Identifier)
+	switch t.Kind {
+	case sema.KindList:
+		code = cg.convert(code, t, sema.ListOf(elem))
+	case sema.KindString:
+		cg.use("strings")
+		code = fmt.Sprintf("strings.Split(%s, \"\")", code)
+	case sema.KindMap:
+		code = fmt.Sprintf("%s(%s)", cg.runtime("Keys"), code)
+	default:
+		code = cg.convert(fmt.Sprintf("%s(%s)", cg.runtime("Iterate"), code), sema.ListOf(sema.Any), sema.ListOf(elem))
 	}
 
+	if stmt.Body != nil && used(stmt.Identifier.Value, stmt.Body.Statements) {
+		cg.writeString(fmt.Sprintf("for _, %s := range %s {\n", cg.identifier(stmt.Identifier.Value), code))
+	} else {
+		cg.writeString(fmt.Sprintf("for range %s {\n", code))
+	}
+	cg.indent()
+	cg.loops++
+	cg.generateBody(stmt.Body)
+	cg.loops--
 	cg.dedent()
 	cg.writeString("}\n")
 }
@@ -98,425 +526,1050 @@ func (cg *CodeGenerator) generateStatement(stmt ast.Statement) {
 	if stmt == nil {
 		return
 	}
+	switch stmt.(type) {
+	case *ast.ExportStatement, *ast.ImportStatement:
+	default:
+		cg.lineDirective(stmt)
+	}
 
 	switch s := stmt.(type) {
 	case *ast.VarStatement:
-		if s != nil {
-			cg.generateVarStatement(s)
-		}
+		cg.generateVarStatement(s)
 	case *ast.ExpressionStatement:
-		if s != nil {
-			cg.generateExpressionStatement(s)
-		}
+		cg.generateExpressionStatement(s)
 	case *ast.FuncStatement:
-		if s != nil {
-			cg.generateFuncStatement(s)
-		}
+		cg.generateFuncStatement(s)
 	case *ast.ReturnStatement:
-		if s != nil {
-			cg.generateReturnStatement(s)
-		}
+		cg.generateReturnStatement(s)
 	case *ast.IfStatement:
-		if s != nil {
-			cg.generateIfStatement(s)
-		}
+		cg.generateIfStatement(s)
 	case *ast.WhileStatement:
-		if s != nil {
-			cg.generateWhileStatement(s)
-		}
+		cg.generateWhileStatement(s)
 	case *ast.ForInStatement:
-		if s != nil {
-			cg.generateForInStatement(s)
-		}
+		cg.generateForInStatement(s)
 	case *ast.TryStatement:
-		if s != nil {
-			cg.generateTryStatement(s)
-		}
+		cg.generateTryStatement(s)
 	case *ast.ThrowStatement:
-		if s != nil {
-			cg.generateThrowStatement(s)
-		}
+		cg.generateThrowStatement(s)
 	case *ast.BlockStatement:
-		if s != nil {
-			cg.generateBlockStatement(s)
-		}
+		cg.generateBlockStatement(s)
 	case *ast.BreakStatement:
-		if s != nil {
-			cg.generateBreakStatement(s)
-		}
+		cg.generateBreakStatement(s)
 	case *ast.ContinueStatement:
-		if s != nil {
-			cg.generateContinueStatement(s)
-		}
+		cg.generateContinueStatement(s)
 	case *ast.ClassStatement:
-		if s != nil {
-			cg.generateClassStatement(s)
+		if cg.fn != nil {
+			cg.fail(s, "class declarations inside functions are not supported by the Go backend")
+			return
 		}
+		cg.generateClassStatement(s)
+	case *ast.ExportStatement:
+		cg.generateStatement(s.Statement)
+	case *ast.ImportStatement:
+		// Los módulos se importan como paquetes en la cabecera del archivo
 	default:
-		// TODO: Manejar otros tipos de sentencias.
-		cg.writeString(fmt.Sprintf("// TODO: Sentencia no soportada: %T\n", s))
+		cg.fail(s, "%T is not supported by the Go backend", s)
 	}
 }
 
 // generateWhileStatement genera código Go para una sentencia 'while'.
 func (cg *CodeGenerator) generateWhileStatement(stmt *ast.WhileStatement) {
-	cg.writeString("for ")
-	cg.generateExpression(stmt.Condition)
-	cg.writeString(" {\n")
+	cg.writeString(fmt.Sprintf("for %s {\n", cg.condition(stmt.Condition)))
 	cg.indent()
-
-	if stmt.Body != nil {
-		for _, bodyStmt := range stmt.Body.Statements {
-			cg.generateStatement(bodyStmt)
-		}
-	}
-
+	cg.loops++
+	cg.generateBody(stmt.Body)
+	cg.loops--
 	cg.dedent()
 	cg.writeString("}\n")
 }
 
-// generateVarStatement genera código Go para una declaración de variable.
+// generateVarStatement genera código Go para una declaración de variable,
+// con el tipo que le asignó el comprobador. Las variables de nivel superior
+// ya están declaradas en el paquete y aquí solo se inicializan.
 func (cg *CodeGenerator) generateVarStatement(stmt *ast.VarStatement) {
-	cg.writeString(fmt.Sprintf("var %s ", stmt.Name.Value))
+	name := cg.identifier(stmt.Name.Value)
+	t := cg.checker.TypeOfName(stmt.Name)
+	rest := cg.rest
+
+	value := ""
 	if stmt.Value != nil {
-		cg.writeString("= ")
-		cg.generateExpression(stmt.Value)
+		code, valueType := cg.expression(stmt.Value)
+		value = cg.convert(code, valueType, t)
+	}
+
+	if cg.globals[stmt] {
+		if value != "" {
+			cg.writeString(fmt.Sprintf("%s = %s\n", name, value))
+		}
+		return
+	}
+	if value == "" {
+		cg.writeString(fmt.Sprintf("var %s %s\n", name, cg.goType(t)))
 	} else {
-		// Si no hay valor, declarar con el tipo cero (implícito en Go).
-		// Para Zylo, podríamos necesitar un tipo explícito o inferir "any".
-		// Por ahora, dejamos que Go maneje el tipo cero.
+		cg.writeString(fmt.Sprintf("var %s %s = %s\n", name, cg.goType(t), value))
+	}
+	// Go no admite variables locales sin usar
+	if cg.fn != nil && !used(stmt.Name.Value, rest) {
+		cg.writeString(fmt.Sprintf("_ = %s\n", name))
 	}
-	cg.writeString("\n")
 }
 
 // generateExpressionStatement genera código Go para una sentencia de expresión.
 func (cg *CodeGenerator) generateExpressionStatement(stmt *ast.ExpressionStatement) {
-	// CRÍTICO: Verificar que stmt y stmt.Expression no sean nil
 	if stmt == nil || stmt.Expression == nil {
 		return // No generar nada si no hay expresión
 	}
-	if _, ok := stmt.Expression.(*ast.NumberLiteral); ok {
+
+	switch e := stmt.Expression.(type) {
+	case *ast.NumberLiteral:
 		return // Skip standalone number literals
+	case *ast.InfixExpression:
+		if e.Operator == "=" {
+			cg.writeString(cg.assignment(e) + "\n")
+			return
+		}
+	case *ast.CallExpression:
+		if code, ok := cg.mutation(e); ok {
+			cg.writeString(code + "\n")
+			return
+		}
+	case *ast.SpawnExpression:
+		// spawn f(x) se traduce a una goroutine
+		code, _ := cg.call(e.Call)
+		cg.writeString("go " + code + "\n")
+		return
+	}
+
+	code, t := cg.expression(stmt.Expression)
+	if t == void {
+		cg.writeString(code + "\n")
+	} else {
+		cg.writeString("_ = " + code + "\n")
 	}
-	cg.generateExpression(stmt.Expression)
-	cg.writeString("\n")
 }
 
 // generateFuncStatement genera código Go para una declaración de función.
+// Las funciones declaradas dentro de otra son closures guardados en una
+// variable, declarada antes para que puedan ser recursivas.
 func (cg *CodeGenerator) generateFuncStatement(stmt *ast.FuncStatement) {
 	if stmt == nil || stmt.Name == nil {
 		return
 	}
 
-	cg.writeString(fmt.Sprintf("func %s(", stmt.Name.Value))
+	name := cg.identifier(stmt.Name.Value)
+	sig := cg.checker.TypeOfName(stmt.Name)
+	if sig.Kind != sema.KindFunc {
+		sig = sema.Func(sema.Any)
+	}
 
-	// Generar parámetros
-	for i, param := range stmt.Parameters {
-		if i > 0 {
-			cg.writeString(", ")
-		}
-		if param != nil {
-			cg.writeString(fmt.Sprintf("%s int", param.Value))
-		}
+	if cg.fn == nil {
+		cg.writeString(fmt.Sprintf("func %s%s {\n", name, cg.signature(stmt.Parameters, sig)))
+		cg.generateFunctionBody(sig, stmt.Async, stmt.Body)
+		cg.writeString("}\n\n")
+		return
+	}
+
+	rest := cg.rest
+	cg.writeString(fmt.Sprintf("var %s %s\n", name, cg.goType(sig)))
+	cg.writeString(fmt.Sprintf("%s = func%s {\n", name, cg.signature(stmt.Parameters, sig)))
+	cg.generateFunctionBody(sig, stmt.Async, stmt.Body)
+	cg.writeString("}\n")
+	if !used(stmt.Name.Value, rest) {
+		cg.writeString(fmt.Sprintf("_ = %s\n", name))
 	}
+}
 
-	// Generar tipo de retorno
-		returnType := " int"
-		if stmt.ReturnType != "" {
-			returnType = fmt.Sprintf(" %s", stmt.ReturnType)
+// signature devuelve los parámetros y el resultado de una función de tipo
+// sig, como "(a int, b string) bool".
+func (cg *CodeGenerator) signature(params []*ast.Identifier, sig *sema.Type) string {
+	parts := make([]string, len(params))
+	for i, param := range params {
+		t := sema.Any
+		if i < len(sig.Params) {
+			t = sig.Params[i]
 		}
+		parts[i] = fmt.Sprintf("%s %s", cg.identifier(param.Value), cg.goType(t))
+	}
+	return "(" + strings.Join(parts, ", ") + ")" + cg.resultType(sig.Result)
+}
 
-	cg.writeString(fmt.Sprintf(")%s {\n", returnType))
+// generateFunctionBody genera el cuerpo de una función de tipo sig, con un
+// 'return' al final si la función devuelve algo y el cuerpo puede terminar
+// sin él.
+func (cg *CodeGenerator) generateFunctionBody(sig *sema.Type, async bool, body *ast.BlockStatement) {
+	outerFn, outerLoops, outerTries := cg.fn, cg.loops, cg.tries
+	cg.loops, cg.tries = 0, nil
 	cg.indent()
 
-	// Generar cuerpo de la función
-	if stmt.Body != nil {
-		for _, bodyStmt := range stmt.Body.Statements {
-			if bodyStmt != nil {
-				cg.generateStatement(bodyStmt)
-			}
+	if async {
+		cg.generateAsyncBody(futureType(sig.Result), body)
+	} else {
+		cg.fn = &funcContext{}
+		if sig.Result.Kind != sema.KindNull {
+			cg.fn.result = sig.Result
 		}
+		cg.generateBody(body)
+		cg.finishBody(body)
 	}
 
 	cg.dedent()
-	cg.writeString("}\n")
+	cg.fn, cg.loops, cg.tries = outerFn, outerLoops, outerTries
+}
+
+// finishBody añade el 'return' que Go exige al final de una función con
+// resultado si body puede terminar sin devolver nada.
+func (cg *CodeGenerator) finishBody(body *ast.BlockStatement) {
+	if cg.fn.result == nil || (body != nil && terminates(body.Statements)) {
+		return
+	}
+	cg.writeString("return " + cg.zeroValue(cg.fn.result) + "\n")
 }
 
-// generateExpression genera código Go para una expresión del AST.
+// terminates indica si un bloque termina siempre con un 'return', según las
+// reglas de Go.
+func terminates(statements []ast.Statement) bool {
+	if len(statements) == 0 {
+		return false
+	}
+	switch s := statements[len(statements)-1].(type) {
+	case *ast.ReturnStatement:
+		return true
+	case *ast.BlockStatement:
+		return terminates(s.Statements)
+	case *ast.IfStatement:
+		return s.Consequence != nil && s.Alternative != nil &&
+			terminates(s.Consequence.Statements) && terminates(s.Alternative.Statements)
+	}
+	return false
+}
+
+// generateAsyncBody genera el cuerpo de una función async: zyloruntime.Async
+// lo ejecuta en una goroutine y el future recibe su resultado o la
+// excepción que lance, que 'await' vuelve a lanzar.
+func (cg *CodeGenerator) generateAsyncBody(result *sema.Type, body *ast.BlockStatement) {
+	cg.writeString(fmt.Sprintf("return %s(func() %s {\n", cg.runtime("Async"), cg.goType(result)))
+	cg.indent()
+
+	cg.fn = &funcContext{result: result}
+	cg.generateBody(body)
+	cg.finishBody(body)
+
+	cg.dedent()
+	cg.writeString("})\n")
+}
+
+// generateExpression escribe el código Go de una expresión.
 func (cg *CodeGenerator) generateExpression(exp ast.Expression) {
+	code, _ := cg.expression(exp)
+	cg.writeString(code)
+}
+
+// expression devuelve el código Go de una expresión y su tipo. El tipo es
+// el del código generado, que puede ser más preciso que el que anotó el
+// comprobador, y void para las llamadas sin resultado.
+func (cg *CodeGenerator) expression(exp ast.Expression) (string, *sema.Type) {
 	if exp == nil {
-		cg.writeString("// TODO: Expresión no soportada: <nil>")
-		return
+		return "nil", sema.Null
 	}
 
 	switch e := exp.(type) {
 	case *ast.Identifier:
-		if e.Value == "HASH_LITERAL" {
-			cg.writeString("make(map[string]interface{})")
-		} else {
-			cg.writeString(e.Value)
-		}
+		return cg.identifierExpression(e)
 	case *ast.StringLiteral:
-		cg.writeString(fmt.Sprintf("%q", e.Value))
+		return strconv.Quote(e.Value), sema.String
 	case *ast.NumberLiteral:
-		cg.writeString(fmt.Sprintf("%d", e.Value)) // Asumiendo int64 por ahora
-	case *ast.BooleanLiteral:
-		if e.Value {
-			cg.writeString("true")
-		} else {
-			cg.writeString("false")
+		if f, ok := e.Value.(float64); ok {
+			code := strconv.FormatFloat(f, 'g', -1, 64)
+			if !strings.ContainsAny(code, ".eE") {
+				code += ".0"
+			}
+			return code, sema.Float
 		}
+		return fmt.Sprintf("%d", e.Value), sema.Int
+	case *ast.BooleanLiteral:
+		return strconv.FormatBool(e.Value), sema.Bool
+	case *ast.NullLiteral:
+		return "nil", sema.Null
 	case *ast.ListLiteral:
-		cg.writeString("zyloruntime.NewList()")
-		for _, element := range e.Elements {
-			cg.writeString(".Append(")
-			cg.generateExpression(element)
-			cg.writeString(")")
+		t := cg.checker.TypeOf(e)
+		if t.Kind != sema.KindList {
+			t = sema.ListOf(sema.Any)
+		}
+		elements := make([]string, len(e.Elements))
+		for i, element := range e.Elements {
+			code, elemType := cg.expression(element)
+			elements[i] = cg.convert(code, elemType, t.Elem)
 		}
+		return fmt.Sprintf("%s{%s}", cg.goType(t), strings.Join(elements, ", ")), t
 	case *ast.HashLiteral:
-		cg.writeString("make(map[string]interface{})")
+		return cg.hashLiteral(e)
 	case *ast.ClassInstantiation:
-		// For now, treat as a function call to the class constructor
-		if e.ClassName != nil {
-			cg.writeString(fmt.Sprintf("New%s(", e.ClassName.Value))
-			for i, arg := range e.Arguments {
-				cg.generateExpression(arg)
-				if i < len(e.Arguments)-1 {
-					cg.writeString(", ")
-				}
+		return cg.construct(e.ClassName, e.Arguments)
+	case *ast.CallExpression:
+		return cg.call(e)
+	case *ast.InfixExpression:
+		return cg.generateInfixExpression(e)
+	case *ast.PrefixExpression:
+		return cg.prefix(e)
+	case *ast.MemberExpression:
+		return cg.member(e)
+	case *ast.IndexExpression:
+		return cg.index(e)
+	case *ast.FunctionLiteral:
+		return cg.functionLiteral(e)
+	case *ast.ThisExpression:
+		return cg.generateThisExpression(e), cg.checker.TypeOf(e)
+	case *ast.SuperExpression:
+		return cg.generateSuperExpression(e), cg.checker.TypeOf(e)
+	case *ast.AwaitExpression:
+		code, t := cg.expression(e.Value)
+		if t.Kind == sema.KindFuture {
+			return code + ".Await()", futureType(t)
+		}
+		return fmt.Sprintf("%s(%s)", cg.runtime("Await"), code), sema.Any
+	case *ast.SpawnExpression:
+		cg.fail(e, "spawn can only be used as a statement in the Go backend")
+	default:
+		cg.fail(e, "%T is not supported by the Go backend", e)
+	}
+	return "nil", sema.Any
+}
+
+// identifierExpression devuelve el código y el tipo de un identificador.
+// Una clase usada como valor es su constructor.
+func (cg *CodeGenerator) identifierExpression(e *ast.Identifier) (string, *sema.Type) {
+	if e.Value == "HASH_LITERAL" {
+		return fmt.Sprintf("map[string]%s{}", cg.runtime("Value")), sema.MapOf(sema.Any)
+	}
+	if e.Value == "null" && cg.isBuiltin(e) {
+		return "nil", sema.Null
+	}
+	if imp, ok := cg.names[e.Value]; ok {
+		if imp.class {
+			return cg.qualified(imp.pkg, "New"+imp.name), sema.Any
+		}
+		return cg.identifier(e.Value), cg.moduleType(imp.pkg, e.Value)
+	}
+	t := cg.checker.TypeOf(e)
+	if t.Kind == sema.KindClass {
+		return "New" + cg.className(t.Class), sema.Any
+	}
+	return cg.identifier(e.Value), t
+}
+
+// hashLiteral devuelve el código de un hash: un map[string]T con las claves
+// en el orden en que se escribieron.
+func (cg *CodeGenerator) hashLiteral(e *ast.HashLiteral) (string, *sema.Type) {
+	t := cg.checker.TypeOf(e)
+	if t.Kind != sema.KindMap {
+		t = sema.MapOf(sema.Any)
+	}
+	keys := make([]ast.Expression, 0, len(e.Pairs))
+	for key := range e.Pairs {
+		keys = append(keys, key)
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		a, b := ast.SpanOf(keys[i]), ast.SpanOf(keys[j])
+		return a.StartLine < b.StartLine || (a.StartLine == b.StartLine && a.StartCol < b.StartCol)
+	})
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		keyCode := cg.stringValue(key)
+		value, valueType := cg.expression(e.Pairs[key])
+		pairs[i] = fmt.Sprintf("%s: %s", keyCode, cg.convert(value, valueType, t.Elem))
+	}
+	return fmt.Sprintf("%s{%s}", cg.goType(t), strings.Join(pairs, ", ")), t
+}
+
+// stringValue devuelve el código de exp convertido a string como lo
+// muestra el evaluador.
+func (cg *CodeGenerator) stringValue(exp ast.Expression) string {
+	code, t := cg.expression(exp)
+	if t.Kind == sema.KindString {
+		return code
+	}
+	return fmt.Sprintf("%s(%s)", cg.runtime("Format"), cg.convert(code, t, sema.Any))
+}
+
+// condition devuelve el código de exp como bool para un if o un while.
+func (cg *CodeGenerator) condition(exp ast.Expression) string {
+	code, t := cg.expression(exp)
+	if t.Kind == sema.KindBool {
+		return code
+	}
+	return fmt.Sprintf("%s(%s)", cg.runtime("Truthy"), cg.convert(code, t, sema.Any))
+}
+
+// arguments devuelve el código de los argumentos de una llamada convertidos
+// a los tipos de los parámetros, o a zyloruntime.Value si params es nil.
+func (cg *CodeGenerator) arguments(args []ast.Expression, params []*sema.Type) []string {
+	codes := make([]string, len(args))
+	for i, arg := range args {
+		code, t := cg.expression(arg)
+		param := sema.Any
+		if i < len(params) {
+			param = params[i]
+		}
+		codes[i] = cg.convert(code, t, param)
+	}
+	return codes
+}
+
+// call devuelve el código de una llamada a una función, un método, un
+// built-in o el constructor de una clase.
+func (cg *CodeGenerator) call(e *ast.CallExpression) (string, *sema.Type) {
+	switch fn := e.Function.(type) {
+	case *ast.Identifier:
+		if cg.isBuiltin(fn) {
+			if code, t, ok := cg.builtinCall(fn.Value, e); ok {
+				return code, t
 			}
-			cg.writeString(")")
 		}
-	case *ast.CallExpression:
-		// Manejar funciones especiales del runtime
-		if ident, ok := e.Function.(*ast.Identifier); ok {
-			switch ident.Value {
-			case "show.log":
-				cg.writeString("fmt.Println(") // Usar fmt.Println
-				for i, arg := range e.Arguments {
-					if arg != nil {
-						cg.generateExpression(arg)
-					}
-					if i < len(e.Arguments)-1 {
-						cg.writeString(", ")
-					}
+		if imp, ok := cg.names[fn.Value]; ok {
+			t := cg.moduleType(imp.pkg, fn.Value)
+			if t.Kind == sema.KindClass {
+				return cg.newInstance(t.Class, e.Arguments)
+			}
+			return cg.callValue(cg.identifier(fn.Value), t, e.Arguments)
+		}
+		if t := cg.checker.TypeOf(fn); t.Kind == sema.KindClass {
+			return cg.newInstance(t.Class, e.Arguments)
+		}
+		return cg.callValue(cg.identifier(fn.Value), cg.checker.TypeOf(fn), e.Arguments)
+
+	case *ast.MemberExpression:
+		if object, ok := fn.Object.(*ast.Identifier); ok {
+			if cg.isBuiltin(object) {
+				if code, t, ok := cg.builtinCall(object.Value+"."+fn.Property.Value, e); ok {
+					return code, t
 				}
-				cg.writeString(")")
-			case "read.line":
-				cg.writeString("fmt.Scanln()")
-			case "read.int":
-				cg.writeString("fmt.Scanf(\"%d\")")
-			default:
-				// Si es una llamada a una función definida por el usuario, simplemente llamarla.
-				oldIndent := cg.indentation
-				cg.indentation = 0
-				cg.generateExpression(e.Function)
-				cg.writeString("(")
-				for i, arg := range e.Arguments {
-					if arg != nil {
-						cg.generateExpression(arg)
-					}
-					if i < len(e.Arguments)-1 {
-						cg.writeString(", ")
-					}
+			}
+			// utils.double(1) llama a la función exportada por el paquete utils
+			if pkg, ok := cg.packages[object.Value]; ok {
+				t := cg.moduleType(pkg, fn.Property.Value)
+				if t.Kind == sema.KindClass {
+					return cg.newInstance(t.Class, e.Arguments)
 				}
-				cg.writeString(")")
-				cg.indentation = oldIndent
+				return cg.callValue(cg.qualified(pkg, GoName(fn.Property.Value)), t, e.Arguments)
 			}
-		} else if member, ok := e.Function.(*ast.MemberExpression); ok {
-			// Handle member access calls like obj.method(...)
-			if member.Object != nil && member.Property != nil {
-				// Generate as obj.method(...)
-				oldIndent := cg.indentation
-				cg.indentation = 0
-				cg.generateExpression(e.Function)
-				cg.writeString("(")
-				for i, arg := range e.Arguments {
-					if arg != nil {
-						cg.generateExpression(arg)
-					}
-					if i < len(e.Arguments)-1 {
-						cg.writeString(", ")
-					}
+		}
+		object, objectType := cg.expression(fn.Object)
+		if objectType.Kind == sema.KindInstance {
+			if t, ok := objectType.Class.Member(fn.Property.Value); ok {
+				if method, ok := cg.method(fn, object, objectType.Class, t); ok {
+					return cg.callValue(method, t, e.Arguments)
 				}
-				cg.writeString(")")
-				cg.indentation = oldIndent
+				// Las redefiniciones no tienen la misma firma en Go: la
+				// llamada se resuelve al ejecutar
+				object, objectType = object+".ZyloSelf", sema.Any
 			}
-		} else {
-			// Check if it's a class instantiation
-			if ident, ok := e.Function.(*ast.Identifier); ok {
-				for _, class := range cg.classNames {
-					if ident.Value == class {
-						cg.writeString(fmt.Sprintf("New%s(", class))
-						for i, arg := range e.Arguments {
-							if arg != nil {
-								cg.generateExpression(arg)
-							}
-							if i < len(e.Arguments)-1 {
-								cg.writeString(", ")
-							}
-						}
-						cg.writeString(")")
-						return
+			// init no es un miembro, pero super.init(args) llama al de la superclase
+			if fn.Property.Value == "init" {
+				for class := objectType.Class; class != nil; class = class.Super {
+					if class.Init != nil {
+						return cg.callValue(object+".Init", class.Init, e.Arguments)
 					}
 				}
 			}
+		}
+		if method, ok := sema.CollectionMethod(objectType, fn.Property.Value); ok {
+			return cg.collectionCall(e, object, objectType, method)
+		}
+		if objectType.Kind == sema.KindAny && GoName(fn.Property.Value) == "Append" && !cg.hasMember("Append") {
+			// Sin una clase con Append, el objeto solo puede ser una lista,
+			// y una lista sin tipo no se puede ampliar en Go
+			cg.fail(e, "cannot append to a list of unknown type in the Go backend; declare it with a type such as list<int>")
+			return "nil", sema.Any
+		}
+		args := append([]string{cg.convert(object, objectType, sema.Any), strconv.Quote(GoName(fn.Property.Value))}, cg.arguments(e.Arguments, nil)...)
+		return fmt.Sprintf("%s(%s)", cg.runtime("CallMethod"), strings.Join(args, ", ")), sema.Any
+	}
 
-			// Handle other function calls
-			cg.generateExpression(e.Function)
-			cg.writeString("(")
-			for i, arg := range e.Arguments {
-				if arg != nil {
-					cg.generateExpression(arg)
-				}
-				if i < len(e.Arguments)-1 {
-					cg.writeString(", ")
-				}
+	code, t := cg.expression(e.Function)
+	return cg.callValue(code, t, e.Arguments)
+}
+
+// hasMember indica si alguna clase del programa o de los módulos importados
+// tiene un atributo o un método que en Go se llama name.
+func (cg *CodeGenerator) hasMember(name string) bool {
+	var classes []*sema.Class
+	for className := range cg.classes {
+		if class, ok := cg.checker.Class(className); ok {
+			classes = append(classes, class)
+		}
+	}
+	for _, info := range cg.knownModules {
+		for _, t := range info.types {
+			if t.Kind == sema.KindClass {
+				classes = append(classes, t.Class)
 			}
-			cg.writeString(")")
 		}
-	case *ast.InfixExpression:
-		cg.generateInfixExpression(e)
-	case *ast.MemberExpression:
-		// Handle special cases like show.log()
-		if e.Object != nil && e.Property != nil {
-			if objId, ok := e.Object.(*ast.Identifier); ok && objId.Value == "show" {
-				if e.Property.Value == "log" {
-					cg.writeString("fmt.Println")
-					return
-				}
+	}
+	for _, class := range classes {
+		for member := range class.Fields {
+			if GoName(member) == name {
+				return true
+			}
+		}
+		for member := range class.Methods {
+			if GoName(member) == name {
+				return true
 			}
 		}
+	}
+	return false
+}
+
+// collectionCall devuelve el código de la llamada al método de una lista o
+// un hash de tipo conocido: l.Get(i) es l[i] y l.Len() es len(l). Append y
+// Set modifican la colección, así que solo se generan como sentencia, en
+// mutation.
+func (cg *CodeGenerator) collectionCall(e *ast.CallExpression, object string, objectType, method *sema.Type) (string, *sema.Type) {
+	name := e.Function.(*ast.MemberExpression).Property.Value
+	if len(e.Arguments) != len(method.Params) {
+		cg.fail(e, "%s expects %d arguments, got %d", name, len(method.Params), len(e.Arguments))
+		return "nil", sema.Any
+	}
+	args := cg.arguments(e.Arguments, method.Params)
+	switch strings.ToLower(name) {
+	case "get":
+		return fmt.Sprintf("%s[%s]", object, args[0]), objectType.Elem
+	case "len":
+		return fmt.Sprintf("len(%s)", object), sema.Int
+	}
+	cg.fail(e, "%s can only be used as a statement in the Go backend", name)
+	return "nil", sema.Any
+}
+
+// mutation devuelve el código de l.Append(x) o de h.Set(k, v) sobre una
+// lista o un hash de tipo conocido, que en Go son las asignaciones
+// l = append(l, x) y h[k] = v. ok es false si call no es una de ellas.
+func (cg *CodeGenerator) mutation(call *ast.CallExpression) (code string, ok bool) {
+	fn, ok := call.Function.(*ast.MemberExpression)
+	if !ok {
+		return "", false
+	}
+	name := strings.ToLower(fn.Property.Value)
+	if method, ok := sema.CollectionMethod(cg.checker.TypeOf(fn.Object), name); !ok || name != "append" && name != "set" || len(call.Arguments) != len(method.Params) {
+		return "", false
+	}
+	if name == "append" {
+		switch fn.Object.(type) {
+		case *ast.Identifier, *ast.MemberExpression, *ast.IndexExpression:
+		default:
+			cg.fail(call, "%s needs a variable, an attribute or an element in the Go backend", fn.Property.Value)
+			return "", true
+		}
+	}
+	object, objectType := cg.expression(fn.Object)
+	method, ok := sema.CollectionMethod(objectType, name)
+	if !ok {
+		// Un atributo redefinido en una subclase no tiene tipo en Go
+		args := append([]string{cg.convert(object, objectType, sema.Any), strconv.Quote(GoName(fn.Property.Value))}, cg.arguments(call.Arguments, nil)...)
+		return fmt.Sprintf("%s(%s)", cg.runtime("CallMethod"), strings.Join(args, ", ")), true
+	}
+	args := cg.arguments(call.Arguments, method.Params)
+	if name == "append" {
+		return fmt.Sprintf("%s = append(%s, %s)", object, object, args[0]), true
+	}
+	return fmt.Sprintf("%s[%s] = %s", object, args[0], args[1]), true
+}
+
+// callValue devuelve el código de la llamada a code, de tipo t. Si t no es
+// una función con esos parámetros la llamada se hace con zyloruntime.Call.
+func (cg *CodeGenerator) callValue(code string, t *sema.Type, args []ast.Expression) (string, *sema.Type) {
+	if t.Kind == sema.KindFunc && !t.Variadic && len(t.Params) == len(args) {
+		call := fmt.Sprintf("%s(%s)", code, strings.Join(cg.arguments(args, t.Params), ", "))
+		if t.Result.Kind == sema.KindNull {
+			return call, void
+		}
+		return call, t.Result
+	}
+	callArgs := append([]string{cg.convert(code, t, sema.Any)}, cg.arguments(args, nil)...)
+	return fmt.Sprintf("%s(%s)", cg.runtime("Call"), strings.Join(callArgs, ", ")), sema.Any
+}
 
-		// Generate member expression without intermediate indentation
-		oldIndent := cg.indentation
-		cg.indentation = 0
-		if e.Object != nil {
-			cg.generateExpression(e.Object)
+// construct devuelve el código de 'Clase(args)'.
+func (cg *CodeGenerator) construct(className *ast.Identifier, args []ast.Expression) (string, *sema.Type) {
+	if className == nil {
+		return "nil", sema.Any
+	}
+	if imp, ok := cg.names[className.Value]; ok {
+		if t := cg.moduleType(imp.pkg, className.Value); t.Kind == sema.KindClass {
+			return cg.newInstance(t.Class, args)
 		}
-		cg.writeString(".")
-		if e.Property != nil {
-			cg.writeString(e.Property.Value)
+	}
+	if class, ok := cg.checker.Class(className.Value); ok {
+		return cg.newInstance(class, args)
+	}
+	cg.fail(className, "unknown class %s", className.Value)
+	return "nil", sema.Any
+}
+
+// newInstance devuelve el código de la llamada al constructor de class, con
+// los argumentos convertidos a los parámetros de su init o del heredado.
+func (cg *CodeGenerator) newInstance(class *sema.Class, args []ast.Expression) (string, *sema.Type) {
+	var params []*sema.Type
+	for c := class; c != nil; c = c.Super {
+		if c.Init != nil {
+			params = c.Init.Params
+			break
 		}
-		cg.indentation = oldIndent
-	case *ast.ThisExpression:
-		cg.generateThisExpression(e)
+	}
+	name := "New" + GoName(class.Name)
+	if pkg, ok := cg.classPackages[class]; ok {
+		name = cg.qualified(pkg, name)
+	} else {
+		name = "New" + cg.identifier(class.Name)
+	}
+	return fmt.Sprintf("%s(%s)", name, strings.Join(cg.arguments(args, params), ", ")), sema.InstanceOf(class)
+}
+
+// builtinCall devuelve el código de una llamada al built-in name, si el
+// backend de Go lo soporta.
+func (cg *CodeGenerator) builtinCall(name string, e *ast.CallExpression) (string, *sema.Type, bool) {
+	args := e.Arguments
+	arg := func(i int, t *sema.Type) string {
+		if i >= len(args) {
+			return cg.zeroValue(t)
+		}
+		code, argType := cg.expression(args[i])
+		return cg.convert(code, argType, t)
+	}
+
+	switch name {
+	case "show.log":
+		cg.use("fmt")
+		parts := make([]string, len(args))
+		for i, a := range args {
+			code, t := cg.expression(a)
+			if isBasic(t) {
+				parts[i] = code
+			} else {
+				parts[i] = fmt.Sprintf("%s(%s)", cg.runtime("Format"), cg.convert(code, t, sema.Any))
+			}
+		}
+		return fmt.Sprintf("fmt.Println(%s)", strings.Join(parts, ", ")), void, true
+	case "read.line", "getInput":
+		return cg.runtime("ReadLine") + "()", sema.String, true
+	case "read.int":
+		return cg.runtime("ReadInt") + "()", sema.Int, true
+	case "string":
+		if len(args) == 1 {
+			return cg.stringValue(args[0]), sema.String, true
+		}
+	case "len":
+		if len(args) == 1 {
+			code, t := cg.expression(args[0])
+			switch t.Kind {
+			case sema.KindString, sema.KindList, sema.KindMap:
+				return "len(" + code + ")", sema.Int, true
+			}
+			return fmt.Sprintf("%s(%s)", cg.runtime("Len"), cg.convert(code, t, sema.Any)), sema.Int, true
+		}
+	case "split", "zyloruntime.Split":
+		if len(args) == 2 {
+			cg.use("strings")
+			return fmt.Sprintf("strings.Split(%s, %s)", arg(0, sema.String), arg(1, sema.String)), sema.ListOf(sema.String), true
+		}
+	case "to_number":
+		if len(args) == 1 {
+			return fmt.Sprintf("%s(%s)", cg.runtime("ToNumber"), arg(0, sema.String)), sema.Any, true
+		}
+	case "sleep":
+		if len(args) == 1 {
+			return fmt.Sprintf("%s(%s)", cg.runtime("Sleep"), arg(0, sema.Int)), void, true
+		}
+	case "add", "subtract", "multiply", "divide":
+		if len(args) == 2 {
+			op := map[string]string{"add": "+", "subtract": "-", "multiply": "*", "divide": "/"}[name]
+			return fmt.Sprintf("%s(%q, %s, %s)", cg.runtime("Operate"), op, arg(0, sema.Any), arg(1, sema.Any)), sema.Any, true
+		}
+	case "newList", "newMap":
+		if len(args) == 0 {
+			t := sema.BuiltinType(name).Result
+			return cg.goType(t) + "{}", t, true
+		}
+	case "readFile":
+		if len(args) == 1 {
+			return fmt.Sprintf("%s(%s)", cg.runtime("ReadFile"), arg(0, sema.String)), sema.String, true
+		}
+	case "writeFile":
+		if len(args) == 2 {
+			return fmt.Sprintf("%s(%s, %s)", cg.runtime("WriteFile"), arg(0, sema.String), arg(1, sema.String)), void, true
+		}
+	case "toJSON":
+		if len(args) == 1 {
+			return fmt.Sprintf("%s(%s)", cg.runtime("ToJSON"), arg(0, sema.Any)), sema.String, true
+		}
+	case "fromJSON":
+		if len(args) == 1 {
+			return fmt.Sprintf("%s(%s)", cg.runtime("FromJSON"), arg(0, sema.String)), sema.Any, true
+		}
+	case "all", "race":
+		if len(args) == 1 {
+			return fmt.Sprintf("%s(%s)", cg.runtime(GoName(name)), arg(0, sema.Any)), sema.FutureOf(sema.Any), true
+		}
+	case "timeout":
+		if len(args) == 2 {
+			return fmt.Sprintf("%s(%s, %s)", cg.runtime("Timeout"), arg(0, sema.Any), arg(1, sema.Int)), sema.FutureOf(sema.Any), true
+		}
+	case "try", "channel", "waitgroup", "select", "assert", "assertEqual", "assertThrows":
+		cg.fail(e, "%s() is not supported by the Go backend", name)
+		return "nil", sema.Any, true
 	default:
-		// TODO: Manejar otros tipos de expresiones.
-		cg.writeString(fmt.Sprintf("// TODO: Expresión no soportada: %T", e))
+		return "", nil, false
+	}
+	cg.fail(e, "wrong number of arguments to %s()", name)
+	return "nil", sema.Any, true
+}
+
+// member devuelve el código del acceso a un atributo o un método.
+func (cg *CodeGenerator) member(e *ast.MemberExpression) (string, *sema.Type) {
+	if objId, ok := e.Object.(*ast.Identifier); ok {
+		// show.log usado como valor
+		if cg.isBuiltin(objId) && objId.Value == "show" && e.Property.Value == "log" {
+			cg.use("fmt")
+			return "fmt.Println", sema.Any
+		}
+		// utils.Split se refiere al valor exportado por el paquete utils;
+		// una clase exportada se usa con su constructor
+		if pkg, ok := cg.packages[objId.Value]; ok {
+			t := cg.moduleType(pkg, e.Property.Value)
+			if t.Kind == sema.KindClass {
+				return cg.qualified(pkg, "New"+GoName(e.Property.Value)), sema.Any
+			}
+			return cg.qualified(pkg, GoName(e.Property.Value)), t
+		}
+	}
+
+	object, objectType := cg.expression(e.Object)
+	if objectType.Kind == sema.KindInstance {
+		if t, ok := objectType.Class.Member(e.Property.Value); ok {
+			if method, ok := cg.method(e, object, objectType.Class, t); ok {
+				return method, t
+			}
+			object, objectType = object+".ZyloSelf", sema.Any
+		}
+	}
+	return fmt.Sprintf("%s(%s, %q)", cg.runtime("Member"), cg.convert(object, objectType, sema.Any), GoName(e.Property.Value)), sema.Any
+}
+
+// method devuelve el código del atributo o el método e.Property de object,
+// una instancia de class, si tiene un tipo estático. Los métodos que una
+// subclase redefine se llaman a través de ZyloSelf, la instancia completa,
+// para que se ejecute la redefinición aunque object sea la struct de la
+// superclase; super.m() llama siempre al de la superclase. Devuelve false si
+// las redefiniciones tienen otra firma en Go.
+func (cg *CodeGenerator) method(e *ast.MemberExpression, object string, class *sema.Class, t *sema.Type) (string, bool) {
+	name := e.Property.Value
+	direct := object + "." + GoName(name)
+	if _, isSuper := e.Object.(*ast.SuperExpression); isSuper || !isMethod(class, name) {
+		return direct, true
+	}
+
+	overrides := cg.overrides(class, name)
+	// Las subclases de una clase exportada pueden estar en otros módulos
+	_, exported := cg.exported[class.Name]
+	if len(overrides) == 0 && !exported {
+		return direct, true
+	}
+	for _, override := range overrides {
+		if !cg.sameGoType(override, t) {
+			return "", false
+		}
+	}
+	signature := strings.TrimPrefix(cg.goType(t), "func")
+	return fmt.Sprintf("%s.ZyloSelf.(interface{ %s%s }).%s", object, GoName(name), signature, GoName(name)), true
+}
+
+// isMethod indica si name es un método de class o de sus superclases, y no
+// un atributo.
+func isMethod(class *sema.Class, name string) bool {
+	for c := class; c != nil; c = c.Super {
+		if _, ok := c.Fields[name]; ok {
+			return false
+		}
+		if _, ok := c.Methods[name]; ok {
+			return true
+		}
 	}
+	return false
+}
+
+// overrides devuelve los tipos de las redefiniciones del método name en las
+// subclases de class del programa que se genera.
+func (cg *CodeGenerator) overrides(class *sema.Class, name string) []*sema.Type {
+	var types []*sema.Type
+	for className := range cg.classes {
+		sub, ok := cg.checker.Class(className)
+		if !ok || sub == class || !sub.IsSubclassOf(class) {
+			continue
+		}
+		if t, ok := sub.Methods[name]; ok {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// index devuelve el código de 'x[i]'.
+func (cg *CodeGenerator) index(e *ast.IndexExpression) (string, *sema.Type) {
+	left, leftType := cg.expression(e.Left)
+	switch leftType.Kind {
+	case sema.KindList:
+		index, indexType := cg.expression(e.Index)
+		return fmt.Sprintf("%s[%s]", left, cg.convert(index, indexType, sema.Int)), leftType.Elem
+	case sema.KindString:
+		index, indexType := cg.expression(e.Index)
+		return fmt.Sprintf("string(%s[%s])", left, cg.convert(index, indexType, sema.Int)), sema.String
+	case sema.KindMap:
+		return fmt.Sprintf("%s[%s]", left, cg.stringValue(e.Index)), leftType.Elem
+	}
+	index, indexType := cg.expression(e.Index)
+	return fmt.Sprintf("%s(%s, %s)", cg.runtime("Index"), cg.convert(left, leftType, sema.Any), cg.convert(index, indexType, sema.Any)), sema.Any
+}
+
+// functionLiteral devuelve el código de una función anónima. El cuerpo se
+// genera aparte y se inserta en la expresión.
+func (cg *CodeGenerator) functionLiteral(e *ast.FunctionLiteral) (string, *sema.Type) {
+	sig := cg.checker.TypeOf(e)
+	if sig.Kind != sema.KindFunc {
+		sig = sema.Func(sema.Any)
+	}
+
+	outer := cg.output
+	cg.output = strings.Builder{}
+	cg.writeString(fmt.Sprintf("func%s {\n", cg.signature(e.Parameters, sig)))
+	cg.generateFunctionBody(sig, false, e.Body)
+	cg.writeString("}")
+	code := strings.TrimLeft(cg.output.String(), " ")
+	cg.output = outer
+	return code, sig
+}
+
+// assignment devuelve el código de una asignación a una variable, a un
+// atributo o a un elemento, con el valor convertido al tipo del destino.
+func (cg *CodeGenerator) assignment(e *ast.InfixExpression) string {
+	value, valueType := cg.expression(e.Right)
+
+	switch target := e.Left.(type) {
+	case *ast.Identifier:
+		code, t := cg.identifierExpression(target)
+		return fmt.Sprintf("%s = %s", code, cg.convert(value, valueType, t))
+	case *ast.MemberExpression:
+		object, objectType := cg.expression(target.Object)
+		if objectType.Kind == sema.KindInstance {
+			if t, ok := objectType.Class.Member(target.Property.Value); ok {
+				return fmt.Sprintf("%s.%s = %s", object, GoName(target.Property.Value), cg.convert(value, valueType, t))
+			}
+		}
+		return fmt.Sprintf("%s(%s, %q, %s)", cg.runtime("SetMember"), cg.convert(object, objectType, sema.Any), GoName(target.Property.Value), cg.convert(value, valueType, sema.Any))
+	case *ast.IndexExpression:
+		left, leftType := cg.expression(target.Left)
+		switch leftType.Kind {
+		case sema.KindList:
+			index, indexType := cg.expression(target.Index)
+			return fmt.Sprintf("%s[%s] = %s", left, cg.convert(index, indexType, sema.Int), cg.convert(value, valueType, leftType.Elem))
+		case sema.KindMap:
+			return fmt.Sprintf("%s[%s] = %s", left, cg.stringValue(target.Index), cg.convert(value, valueType, leftType.Elem))
+		}
+		index, indexType := cg.expression(target.Index)
+		return fmt.Sprintf("%s(%s, %s, %s)", cg.runtime("SetIndex"), cg.convert(left, leftType, sema.Any), cg.convert(index, indexType, sema.Any), cg.convert(value, valueType, sema.Any))
+	}
+	cg.fail(e, "cannot assign to %s", e.Left)
+	return "_ = " + cg.convert(value, valueType, sema.Any)
 }
 
 // generateReturnStatement genera código Go para una sentencia de retorno.
 func (cg *CodeGenerator) generateReturnStatement(stmt *ast.ReturnStatement) {
-	cg.writeString("return")
-	if stmt.ReturnValue != nil {
-		cg.writeString(" ")
-		cg.generateExpression(stmt.ReturnValue)
+	if cg.fn == nil || cg.fn.result == nil {
+		// La función no devuelve nada: el valor, si lo hay, solo se evalúa
+		if stmt.ReturnValue != nil {
+			if _, isNull := stmt.ReturnValue.(*ast.NullLiteral); !isNull {
+				cg.generateExpressionStatement(&ast.ExpressionStatement{Expression: stmt.ReturnValue})
+			}
+		}
+		cg.jump(jumpReturn, "")
+		return
 	}
-	cg.writeString("\n")
+
+	if stmt.ReturnValue == nil {
+		cg.jump(jumpReturn, cg.zeroValue(cg.fn.result))
+		return
+	}
+	code, t := cg.expression(stmt.ReturnValue)
+	cg.jump(jumpReturn, cg.convert(code, t, cg.fn.result))
 }
 
 // generateIfStatement genera código Go para una sentencia 'if'.
 func (cg *CodeGenerator) generateIfStatement(stmt *ast.IfStatement) {
-	cg.writeString("if ")
-	cg.generateExpression(stmt.Condition)
-	cg.writeString(" {\n")
+	cg.writeString(fmt.Sprintf("if %s {\n", cg.condition(stmt.Condition)))
 	cg.indent()
-
-	if stmt.Consequence != nil {
-		for _, bodyStmt := range stmt.Consequence.Statements {
-			cg.generateStatement(bodyStmt)
-		}
-	}
-
+	cg.generateBody(stmt.Consequence)
 	cg.dedent()
 	cg.writeString("}")
 
 	if stmt.Alternative != nil {
 		cg.writeString(" else {\n")
 		cg.indent()
-
-		for _, bodyStmt := range stmt.Alternative.Statements {
-			cg.generateStatement(bodyStmt)
-		}
-
+		cg.generateBody(stmt.Alternative)
 		cg.dedent()
 		cg.writeString("}")
 	}
 	cg.writeString("\n")
 }
 
-// generateTryStatement genera código Go para una sentencia 'try-catch'.
+// generateTryStatement genera código Go para una sentencia 'try-catch'. El
+// try y el catch son closures que recibe zyloruntime.Try; el finally es un
+// defer en otro closure que los envuelve, para que se ejecute aunque el catch
+// vuelva a lanzar. Los saltos que salen del try se repiten después (ver
+// tryContext); uno en el finally, como en el evaluador, descarta además la
+// excepción que se estuviera propagando.
 func (cg *CodeGenerator) generateTryStatement(stmt *ast.TryStatement) {
-	cg.writeString("zyloruntime.Try(func() {\n")
-	cg.indent()
+	cg.jumps++
+	try := &tryContext{
+		jump:   fmt.Sprintf("zyloJump%d", cg.jumps),
+		result: fmt.Sprintf("zyloResult%d", cg.jumps),
+		loops:  cg.loops,
+		used:   make(map[int]bool),
+	}
+	cg.loops = 0
+	cg.tries = append(cg.tries, try)
 
-	if stmt.TryBlock != nil {
-		for _, bodyStmt := range stmt.TryBlock.Statements {
-			cg.generateStatement(bodyStmt)
+	// El try se genera aparte porque las variables de salto se declaran
+	// antes, solo si se usan
+	outer := cg.output
+	cg.output = strings.Builder{}
+
+	if stmt.FinallyBlock != nil {
+		cg.writeString("func() {\n")
+		cg.indent()
+		cg.writeString("defer func() {\n")
+		cg.indent()
+		if jumps(stmt.FinallyBlock.Statements) {
+			cg.writeString("func() {\n")
+			cg.indent()
+			cg.generateBody(stmt.FinallyBlock)
+			cg.dedent()
+			cg.writeString("}()\n")
+			cg.writeString(fmt.Sprintf("if %s != 0 {\n", try.jump))
+			cg.indent()
+			cg.writeString("recover()\n")
+			cg.dedent()
+			cg.writeString("}\n")
+		} else {
+			cg.generateBody(stmt.FinallyBlock)
 		}
+		cg.dedent()
+		cg.writeString("}()\n")
 	}
 
+	cg.writeString(fmt.Sprintf("%s(func() {\n", cg.runtime("Try")))
+	cg.indent()
+	cg.generateBody(stmt.TryBlock)
 	cg.dedent()
 	cg.writeString("}, func(err error) {\n")
 	cg.indent()
 
-	if stmt.CatchClause != nil && stmt.CatchClause.CatchBlock != nil {
+	if clause := stmt.CatchClause; clause != nil {
 		// Declarar la variable de error si hay un parámetro
-		if stmt.CatchClause.Parameter != nil {
-			cg.writeString(fmt.Sprintf("var %s = err\n", stmt.CatchClause.Parameter.Value)) // Usar param.Value
-		}
-
-		for _, bodyStmt := range stmt.CatchClause.CatchBlock.Statements {
-			cg.generateStatement(bodyStmt)
+		if clause.Parameter != nil && clause.CatchBlock != nil && used(clause.Parameter.Value, clause.CatchBlock.Statements) {
+			cg.writeString(fmt.Sprintf("var %s %s = %s(err)\n", cg.identifier(clause.Parameter.Value), cg.runtime("Value"), cg.runtime("Caught")))
 		}
+		cg.generateBody(clause.CatchBlock)
+	} else {
+		// Sin catch la excepción sigue su curso después del finally
+		cg.writeString("panic(err)\n")
 	}
 
 	cg.dedent()
 	cg.writeString("})\n")
 
-	// Manejar finally block si existe
 	if stmt.FinallyBlock != nil {
-		cg.writeString("defer func() {\n")
-		cg.indent()
+		cg.dedent()
+		cg.writeString("}()\n")
+	}
 
-		for _, bodyStmt := range stmt.FinallyBlock.Statements {
-			cg.generateStatement(bodyStmt)
-		}
+	code := cg.output.String()
+	cg.output = outer
+	cg.tries = cg.tries[:len(cg.tries)-1]
+	cg.loops = try.loops
 
+	if len(try.used) > 0 {
+		cg.writeString(fmt.Sprintf("var %s int\n", try.jump))
+	}
+	result := ""
+	if try.used[jumpReturn] && cg.fn != nil && cg.fn.result != nil {
+		cg.writeString(fmt.Sprintf("var %s %s\n", try.result, cg.goType(cg.fn.result)))
+		result = try.result
+	}
+	cg.output.WriteString(code)
+	for _, kind := range []int{jumpReturn, jumpBreak, jumpContinue} {
+		if !try.used[kind] {
+			continue
+		}
+		cg.writeString(fmt.Sprintf("if %s == %d {\n", try.jump, kind))
+		cg.indent()
+		cg.jump(kind, result)
 		cg.dedent()
-		cg.writeString("}()\n")
+		cg.writeString("}\n")
 	}
 }
 
+// jumps indica si statements tienen un return, o un break o continue fuera
+// de sus bucles, que salga del bloque.
+func jumps(statements []ast.Statement) bool {
+	found := false
+	var visit func(inLoop bool) func(ast.Node) bool
+	visit = func(inLoop bool) func(ast.Node) bool {
+		return func(node ast.Node) bool {
+			switch n := node.(type) {
+			case *ast.ReturnStatement:
+				found = true
+			case *ast.BreakStatement, *ast.ContinueStatement:
+				found = found || !inLoop
+			case *ast.FuncStatement, *ast.FunctionLiteral, *ast.ClassStatement:
+				return false
+			case *ast.WhileStatement:
+				ast.Inspect(n.Body, visit(true))
+				return false
+			case *ast.ForInStatement:
+				ast.Inspect(n.Body, visit(true))
+				return false
+			}
+			return !found
+		}
+	}
+	for _, stmt := range statements {
+		ast.Inspect(stmt, visit(false))
+	}
+	return found
+}
+
 // generateThrowStatement genera código Go para una sentencia 'throw'.
 func (cg *CodeGenerator) generateThrowStatement(stmt *ast.ThrowStatement) {
-	cg.writeString("zyloruntime.Throw(")
+	value := `""`
 	if stmt.Exception != nil {
-		cg.generateExpression(stmt.Exception)
-	} else {
-		cg.writeString("\"\"")
+		code, t := cg.expression(stmt.Exception)
+		value = cg.convert(code, t, sema.Any)
 	}
-	cg.writeString(")\n")
+	cg.writeString(fmt.Sprintf("%s(%s)\n", cg.runtime("Throw"), value))
 }
 
 // generateBlockStatement genera código Go para un bloque de sentencias.
 func (cg *CodeGenerator) generateBlockStatement(stmt *ast.BlockStatement) {
 	cg.writeString("{\n")
 	cg.indent()
-
-	for _, bodyStmt := range stmt.Statements {
-		cg.generateStatement(bodyStmt)
-	}
-
+	cg.generateStatements(stmt.Statements)
 	cg.dedent()
 	cg.writeString("}\n")
 }
 
 // writeString escribe una cadena en la salida con la indentación actual.
 func (cg *CodeGenerator) writeString(s string) {
-	if len(s) > 0 && s[0] != '\n' { // No indentar si la línea comienza con un salto de línea
+	if len(s) > 0 && s[0] != '\n' && s[0] != ' ' && endsLine(&cg.output) {
 		for i := 0; i < cg.indentation; i++ {
 			cg.output.WriteString("    ") // 4 espacios por nivel de indentación
 		}
@@ -524,6 +1577,12 @@ func (cg *CodeGenerator) writeString(s string) {
 	cg.output.WriteString(s)
 }
 
+// endsLine indica si la salida está al principio de una línea.
+func endsLine(output *strings.Builder) bool {
+	s := output.String()
+	return s == "" || s[len(s)-1] == '\n'
+}
+
 // indent aumenta el nivel de indentación.
 func (cg *CodeGenerator) indent() {
 	cg.indentation++
@@ -537,174 +1596,325 @@ func (cg *CodeGenerator) dedent() {
 }
 
 // generateClassStatement genera código Go para una declaración de clase.
+// La herencia se traduce a embedding: el struct de la subclase embebe al de
+// la superclase, así que los campos y métodos heredados se promueven y los
+// redefinidos los ocultan. Como un método de la superclase recibe solo su
+// struct, las llamadas a métodos redefinidos pasan por el campo ZyloSelf.
+// Los atributos y métodos llevan la primera letra en mayúscula para que se
+// puedan usar desde otros paquetes y desde el runtime.
 func (cg *CodeGenerator) generateClassStatement(stmt *ast.ClassStatement) {
 	if stmt.Name == nil {
 		return
 	}
+	class, ok := cg.checker.Class(stmt.Name.Value)
+	if !ok {
+		return
+	}
 
-	className := stmt.Name.Value
+	className := cg.identifier(stmt.Name.Value)
+	cg.currentClass = stmt
+	defer func() { cg.currentClass = nil }()
 
 	// Generate struct definition
 	cg.writeString(fmt.Sprintf("type %s struct {\n", className))
 	cg.indent()
 
+	// Embed the superclass; la clase raíz guarda la instancia completa
+	if stmt.SuperClass != nil {
+		cg.writeString(fmt.Sprintf("%s\n", cg.identifier(stmt.SuperClass.Value)))
+	} else {
+		cg.writeString(fmt.Sprintf("ZyloSelf %s\n", cg.runtime("Value")))
+	}
+
 	// Generate attributes
 	for _, attr := range stmt.Attributes {
 		if attr.Name != nil {
-			cg.writeString(fmt.Sprintf("%s interface{}\n", attr.Name.Value))
+			cg.writeString(fmt.Sprintf("%s %s\n", GoName(attr.Name.Value), cg.goType(class.Fields[attr.Name.Value])))
 		}
 	}
 
 	cg.dedent()
 	cg.writeString("}\n\n")
 
-	// Generate constructor function
-		cg.writeString(fmt.Sprintf("func New%s(", className))
-		// Add parameters for init method if it exists
-		if stmt.InitMethod != nil && len(stmt.InitMethod.Parameters) > 0 {
-			for i, param := range stmt.InitMethod.Parameters {
-				if i > 0 {
-					cg.writeString(", ")
-				}
-				cg.writeString(fmt.Sprintf("%s int", param.Value))
-			}
+	// Método marcador para instanceof: las subclases lo heredan por embedding
+	cg.writeString(fmt.Sprintf("func (obj *%s) %s() {}\n\n", className, classMarker(className)))
+
+	cg.generateConstructor(stmt, class, className)
+
+	// Generate methods
+	for _, method := range stmt.Methods {
+		if method.Name == nil {
+			continue
 		}
-	cg.writeString(fmt.Sprintf(") *%s {\n", className))
-	cg.indent()
+		sig := class.Methods[method.Name.Value]
+		if method.Name.Value == "init" {
+			sig = class.Init
+		}
+		cg.lineDirective(method)
+		cg.writeString(fmt.Sprintf("func (obj *%s) %s%s {\n", className, GoName(method.Name.Value), cg.signature(method.Parameters, sig)))
+		cg.generateFunctionBody(sig, method.Async, method.Body)
+		cg.writeString("}\n\n")
+	}
+}
+
+// generateConstructor genera New<Clase>: crea la instancia, asigna los
+// valores iniciales de los atributos, empezando por los de las superclases,
+// y llama al init de la clase o al heredado.
+func (cg *CodeGenerator) generateConstructor(stmt *ast.ClassStatement, class *sema.Class, className string) {
+	initMethod := cg.findInitMethod(stmt)
+	var initSig *sema.Type
+	for c := class; c != nil && initSig == nil; c = c.Super {
+		initSig = c.Init
+	}
+	var params []*ast.Identifier
+	if initMethod != nil && initSig != nil {
+		params = initMethod.Parameters
+	} else {
+		initSig = sema.Func(sema.Null)
+	}
 
+	cg.writeString(fmt.Sprintf("func New%s%s {\n", className, cg.signature(params, sema.Func(sema.InstanceOf(class), initSig.Params...))))
+	cg.indent()
+	outer := cg.fn
+	cg.fn = &funcContext{}
 	cg.writeString(fmt.Sprintf("obj := &%s{}\n", className))
+	cg.writeString("obj.ZyloSelf = obj\n")
+
+	var chain []*ast.ClassStatement
+	seen := make(map[*ast.ClassStatement]bool)
+	for c := stmt; c != nil && !seen[c]; {
+		seen[c] = true
+		chain = append([]*ast.ClassStatement{c}, chain...)
+		if c.SuperClass == nil {
+			break
+		}
+		c = cg.classes[c.SuperClass.Value]
+	}
+	for _, c := range chain {
+		declaring, _ := cg.checker.Class(c.Name.Value)
+		for _, attr := range c.Attributes {
+			if attr.Value == nil || declaring == nil {
+				continue
+			}
+			cg.currentClass = c
+			value, valueType := cg.expression(attr.Value)
+			cg.writeString(fmt.Sprintf("obj.%s = %s\n", GoName(attr.Name.Value), cg.convert(value, valueType, declaring.Fields[attr.Name.Value])))
+		}
+	}
+	cg.currentClass = stmt
 
 	// Call init method if it exists
-	if stmt.InitMethod != nil {
-		cg.writeString(fmt.Sprintf("obj.%s(", stmt.InitMethod.Name.Value))
-		if len(stmt.InitMethod.Parameters) > 0 {
-			for i, param := range stmt.InitMethod.Parameters {
-				if i > 0 {
-					cg.writeString(", ")
-				}
-				cg.writeString(param.Value)
-			}
+	if initMethod != nil {
+		args := make([]string, len(initMethod.Parameters))
+		for i, param := range initMethod.Parameters {
+			args[i] = cg.identifier(param.Value)
 		}
-		cg.writeString(")\n")
+		cg.writeString(fmt.Sprintf("obj.%s(%s)\n", GoName(initMethod.Name.Value), strings.Join(args, ", ")))
 	}
 
 	cg.writeString("return obj\n")
+	cg.fn = outer
 	cg.dedent()
 	cg.writeString("}\n\n")
+}
 
-	// Generate methods
-	for _, method := range stmt.Methods {
-		if method.Name == nil {
-			continue
+// findInitMethod devuelve el init de la clase o, si no tiene, el de la
+// superclase más cercana que lo defina.
+func (cg *CodeGenerator) findInitMethod(stmt *ast.ClassStatement) *ast.FuncStatement {
+	seen := make(map[*ast.ClassStatement]bool)
+	for class := stmt; class != nil && !seen[class]; {
+		seen[class] = true
+		if class.InitMethod != nil {
+			return class.InitMethod
 		}
-
-		cg.writeString(fmt.Sprintf("func (obj *%s) %s(", className, method.Name.Value))
-
-		// Add parameters
-				for i, param := range method.Parameters {
-					if i > 0 {
-						cg.writeString(", ")
-					}
-					cg.writeString(fmt.Sprintf("%s int", param.Value))
-				}
-
-		cg.writeString(")")
-
-		// Add return type if specified
-		if method.ReturnType != "" {
-			cg.writeString(fmt.Sprintf(" %s", method.ReturnType))
+		if class.SuperClass == nil {
+			return nil
 		}
+		class = cg.classes[class.SuperClass.Value]
+	}
+	return nil
+}
 
-		cg.writeString(" {\n")
-		cg.indent()
+// classMarker es el nombre del método que identifica a las instancias de una
+// clase y de sus subclases.
+func classMarker(className string) string {
+	return "is" + className
+}
 
-		// Generate method body
-		if method.Body != nil {
-			for _, bodyStmt := range method.Body.Statements {
-				cg.generateStatement(bodyStmt)
-			}
+// floatOperand devuelve code, el operando float exp de una operación. Go
+// calcula las operaciones entre constantes sin redondear, así que 0.1 + 0.2
+// daría 0.3; con float64(0.1) la constante se redondea antes, como en el
+// evaluador.
+func floatOperand(exp ast.Expression, code string) string {
+	if literal, ok := exp.(*ast.NumberLiteral); ok {
+		if _, ok := literal.Value.(float64); ok {
+			return "float64(" + code + ")"
 		}
-
-		cg.dedent()
-		cg.writeString("}\n\n")
 	}
+	return code
 }
 
-// generateInfixExpression genera código Go para una expresión infija
-func (cg *CodeGenerator) generateInfixExpression(exp *ast.InfixExpression) {
-	// Manejar expresiones infijas con operadores correctos
-	if exp == nil {
-		return
+// generateInfixExpression devuelve el código de una expresión infija. Con
+// operandos de tipo conocido se usa el operador de Go, convirtiendo un int a
+// float64 si el otro operando es float; con operandos dinámicos, las
+// funciones del runtime que aplican las reglas del evaluador.
+func (cg *CodeGenerator) generateInfixExpression(exp *ast.InfixExpression) (string, *sema.Type) {
+	switch exp.Operator {
+	case "instanceof":
+		// x instanceof Animal comprueba el método marcador que se promueve
+		// a todas las subclases de Animal
+		className := "INVALID"
+		if ident, ok := exp.Right.(*ast.Identifier); ok {
+			className = cg.identifier(ident.Value)
+		}
+		left, _ := cg.expression(exp.Left)
+		return fmt.Sprintf("func() bool { _, ok := interface{}(%s).(interface{ %s() }); return ok }()", left, classMarker(className)), sema.Bool
+	case "=":
+		cg.fail(exp, "assignment used as a value is not supported by the Go backend")
+		return "nil", sema.Any
+	case "&&", "||", "and", "or":
+		op := "&&"
+		if exp.Operator == "||" || exp.Operator == "or" {
+			op = "||"
+		}
+		return fmt.Sprintf("(%s %s %s)", cg.condition(exp.Left), op, cg.condition(exp.Right)), sema.Bool
 	}
 
-	if exp.Operator == "=" {
-		if exp.Left != nil {
-			cg.generateExpression(exp.Left)
+	left, leftType := cg.expression(exp.Left)
+	right, rightType := cg.expression(exp.Right)
+	numeric := leftType.IsNumeric() && rightType.IsNumeric()
+	// Con un float, el int se convierte
+	promote := func() (string, string, *sema.Type) {
+		if leftType.Kind == sema.KindInt && rightType.Kind == sema.KindInt {
+			return left, right, sema.Int
 		}
-		cg.writeString(" = ")
-		if exp.Right != nil {
-			cg.generateExpression(exp.Right)
+		return floatOperand(exp.Left, cg.convert(left, leftType, sema.Float)), floatOperand(exp.Right, cg.convert(right, rightType, sema.Float)), sema.Float
+	}
+	dynamic := func(fn string) string {
+		return fmt.Sprintf("%s(%q, %s, %s)", cg.runtime(fn), exp.Operator, cg.convert(left, leftType, sema.Any), cg.convert(right, rightType, sema.Any))
+	}
+
+	switch exp.Operator {
+	case "==", "!=":
+		sameKind := leftType.Kind == rightType.Kind && (leftType.Kind == sema.KindString || leftType.Kind == sema.KindBool)
+		if sameKind {
+			return fmt.Sprintf("(%s %s %s)", left, exp.Operator, right), sema.Bool
 		}
-	} else {
-		if exp.Operator == "+" {
-			cg.writeString("fmt.Sprintf(\"%v%v\", ")
-			if exp.Left != nil {
-				cg.generateExpression(exp.Left)
-			} else {
-				cg.writeString("nil")
-			}
-			cg.writeString(", ")
-			if exp.Right != nil {
-				cg.generateExpression(exp.Right)
-			} else {
-				cg.writeString("nil")
-			}
-			cg.writeString(")")
-		} else {
-			if exp.Left != nil {
-				cg.generateExpression(exp.Left)
-			} else {
-				cg.writeString("nil")
+		if numeric {
+			l, r, _ := promote()
+			return fmt.Sprintf("(%s %s %s)", l, exp.Operator, r), sema.Bool
+		}
+		equal := fmt.Sprintf("%s(%s, %s)", cg.runtime("Equal"), cg.convert(left, leftType, sema.Any), cg.convert(right, rightType, sema.Any))
+		if exp.Operator == "!=" {
+			return "!" + equal, sema.Bool
+		}
+		return equal, sema.Bool
+	case "<", ">", "<=", ">=":
+		if numeric {
+			l, r, _ := promote()
+			return fmt.Sprintf("(%s %s %s)", l, exp.Operator, r), sema.Bool
+		}
+		return dynamic("Compare"), sema.Bool
+	case "..":
+		return fmt.Sprintf("%s(%s, %s)", cg.runtime("Range"), cg.convert(left, leftType, sema.Int), cg.convert(right, rightType, sema.Int)), sema.ListOf(sema.Int)
+	case "+":
+		// string + número concatena en cualquier orden
+		leftString, rightString := leftType.Kind == sema.KindString, rightType.Kind == sema.KindString
+		if (leftString && (rightString || rightType.IsNumeric())) || (rightString && leftType.IsNumeric()) {
+			if !leftString {
+				left = fmt.Sprintf("%s(%s)", cg.runtime("Format"), left)
 			}
-
-			// Convertir operadores de Zylo a Go
-			switch exp.Operator {
-			case "==":
-				cg.writeString(" == ")
-			case "!=":
-				cg.writeString(" != ")
-			case "-":
-				cg.writeString(" - ")
-			case "*":
-				cg.writeString(" * ")
-			case "/":
-				cg.writeString(" / ")
-			case "<":
-				cg.writeString(" < ")
-			case ">":
-				cg.writeString(" > ")
-			case "<=":
-				cg.writeString(" <= ")
-			case ">=":
-				cg.writeString(" >= ")
-			case "&&":
-				cg.writeString(" && ")
-			case "||":
-				cg.writeString(" || ")
-			default:
-				cg.writeString(" " + exp.Operator + " ")
+			if !rightString {
+				right = fmt.Sprintf("%s(%s)", cg.runtime("Format"), right)
 			}
+			return fmt.Sprintf("(%s + %s)", left, right), sema.String
+		}
+		fallthrough
+	case "-", "*", "/", "%":
+		if numeric && (exp.Operator != "%" || (leftType.Kind == sema.KindInt && rightType.Kind == sema.KindInt)) {
+			l, r, t := promote()
+			return fmt.Sprintf("(%s %s %s)", l, exp.Operator, r), t
+		}
+		return dynamic("Operate"), sema.Any
+	}
+	cg.fail(exp, "operator %s is not supported by the Go backend", exp.Operator)
+	return "nil", sema.Any
+}
 
-			if exp.Right != nil {
-				cg.generateExpression(exp.Right)
-			} else {
-				cg.writeString("nil")
-			}
+// prefix devuelve el código de una expresión prefija.
+func (cg *CodeGenerator) prefix(exp *ast.PrefixExpression) (string, *sema.Type) {
+	switch exp.Operator {
+	case "!":
+		return "!" + cg.condition(exp.Right), sema.Bool
+	case "-":
+		code, t := cg.expression(exp.Right)
+		if t.IsNumeric() {
+			return "(-" + code + ")", t
 		}
+		return fmt.Sprintf("%s(%s)", cg.runtime("Negate"), cg.convert(code, t, sema.Any)), sema.Any
+	case "+":
+		return cg.expression(exp.Right)
 	}
+	cg.fail(exp, "operator %s is not supported by the Go backend", exp.Operator)
+	return "nil", sema.Any
 }
 
 // generateThisExpression genera código Go para una expresión 'this'
-func (cg *CodeGenerator) generateThisExpression(exp *ast.ThisExpression) {
-	cg.writeString("obj")
+func (cg *CodeGenerator) generateThisExpression(exp *ast.ThisExpression) string {
+	return "obj"
+}
+
+// generateSuperExpression genera código Go para una expresión 'super': el
+// struct embebido de la superclase dentro del receptor.
+func (cg *CodeGenerator) generateSuperExpression(exp *ast.SuperExpression) string {
+	if cg.currentClass == nil || cg.currentClass.SuperClass == nil {
+		cg.fail(exp, "'super' outside of a subclass")
+		return "nil"
+	}
+	return "obj." + cg.identifier(cg.currentClass.SuperClass.Value)
+}
+
+// used indica si alguna de statements lee la variable name. Las
+// declaraciones y las asignaciones a la variable no cuentan, como en Go.
+func used(name string, statements []ast.Statement) bool {
+	found := false
+	var visit func(ast.Node) bool
+	visit = func(node ast.Node) bool {
+		if found {
+			return false
+		}
+		switch n := node.(type) {
+		case *ast.Identifier:
+			found = n.Value == name
+		case *ast.MemberExpression:
+			ast.Inspect(n.Object, visit)
+			return false
+		case *ast.InfixExpression:
+			if _, ok := n.Left.(*ast.Identifier); ok && n.Operator == "=" {
+				ast.Inspect(n.Right, visit)
+				return false
+			}
+		case *ast.VarStatement:
+			ast.Inspect(n.Value, visit)
+			return false
+		case *ast.FuncStatement:
+			ast.Inspect(n.Body, visit)
+			return false
+		case *ast.FunctionLiteral:
+			ast.Inspect(n.Body, visit)
+			return false
+		case *ast.ForInStatement:
+			ast.Inspect(n.Iterable, visit)
+			ast.Inspect(n.Body, visit)
+			return false
+		case *ast.CatchClause:
+			ast.Inspect(n.CatchBlock, visit)
+			return false
+		}
+		return true
+	}
+	for _, stmt := range statements {
+		ast.Inspect(stmt, visit)
+	}
+	return found
 }
@@ -6,16 +6,19 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
+	"github.com/zylo-lang/zylo/internal/ast"
 	"github.com/zylo-lang/zylo/internal/lexer"
 	"github.com/zylo-lang/zylo/internal/parser"
+	"github.com/zylo-lang/zylo/internal/sema"
 )
 
 func TestHelloWorld(t *testing.T) {
 	input := `
 var message = "Hola desde Zylo!";
-print(message);
+show.log(message);
 `
 	expectedOutput := "Hola desde Zylo!\n"
 
@@ -33,39 +36,60 @@ print(message);
 		t.Fatalf("Code generation failed: %v", err)
 	}
 
-	// Crear un directorio temporal para el código Go generado.
-	tempDir, err := os.MkdirTemp("", "zylo_codegen_test")
-	if err != nil {
-		t.Fatalf("Failed to create temp directory: %v", err)
+	output := buildAndRun(t, goCode)
+
+	// Verificar la salida.
+	if output != expectedOutput {
+		t.Errorf("Unexpected output.\nExpected: %q\nGot: %q", expectedOutput, output)
 	}
-	defer os.RemoveAll(tempDir) // Limpiar al final.
+}
 
-	goFilePath := filepath.Join(tempDir, "main.go")
-	err = os.WriteFile(goFilePath, []byte(goCode), 0644)
+// buildAndRun compila el código Go generado y devuelve la salida del binario.
+func buildAndRun(t *testing.T, goCode string) string {
+	t.Helper()
+	return runModule(t, map[string]string{"main.go": goCode})
+}
+
+// runModule escribe files en un módulo Go temporal que usa el runtime de
+// este repositorio, lo ejecuta y devuelve su salida.
+func runModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	root, err := filepath.Abs(filepath.Join("..", ".."))
 	if err != nil {
-		t.Fatalf("Failed to write Go code to file: %v", err)
+		t.Fatal(err)
 	}
-
-	// Ejecutar gofmt para formatear el código generado (opcional, pero buena práctica).
-	cmdFmt := exec.Command("go", "fmt", goFilePath)
-	if err := cmdFmt.Run(); err != nil {
-		t.Logf("gofmt failed (non-fatal): %v", err)
+	dir := t.TempDir()
+	files["go.mod"] = "module " + DefaultModulePath + "\n\ngo 1.24\n\n" +
+		"require github.com/zylo-lang/zylo v0.0.0\n\n" +
+		"replace github.com/zylo-lang/zylo => " + filepath.ToSlash(root) + "\n"
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
 	}
 
 	// Compilar el código Go generado.
-	outputBinaryPath := filepath.Join(tempDir, "output")
+	outputBinaryPath := filepath.Join(dir, "output")
 	if runtime.GOOS == "windows" {
 		outputBinaryPath += ".exe"
 	}
-	cmdBuild := exec.Command("go", "build", "-o", outputBinaryPath, goFilePath)
+	cmdBuild := exec.Command("go", "build", "-o", outputBinaryPath, ".")
+	cmdBuild.Dir = dir
 	var buildErr bytes.Buffer
 	cmdBuild.Stderr = &buildErr
 	if err := cmdBuild.Run(); err != nil {
-		t.Fatalf("Go build failed: %v\nOutput:\n%s", err, buildErr.String())
+		t.Fatalf("Go build failed: %v\nOutput:\n%s\nCode:\n%s", err, buildErr.String(), files["main.go"])
 	}
 
-	// Ejecutar el binario generado.
+	// Ejecutar el binario generado en el directorio temporal, donde escribe
+	// los archivos que cree.
 	cmdRun := exec.Command(outputBinaryPath)
+	cmdRun.Dir = dir
 	var runOutput bytes.Buffer
 	cmdRun.Stdout = &runOutput
 	cmdRun.Stderr = &runOutput // Capturar stderr también
@@ -73,8 +97,423 @@ print(message);
 		t.Fatalf("Generated binary execution failed: %v\nOutput:\n%s", err, runOutput.String())
 	}
 
-	// Verificar la salida.
-	if runOutput.String() != expectedOutput {
-		t.Errorf("Unexpected output.\nExpected: %q\nGot: %q", expectedOutput, runOutput.String())
+	return runOutput.String()
+}
+
+func TestClassInheritance(t *testing.T) {
+	input := `
+class Animal {
+    var legs: int
+    func init(n: int) {
+        this.legs = n
+    }
+    func speak() {
+        show.log("animal", this.legs)
+    }
+}
+class Dog extends Animal {
+    func speak() {
+        super.speak()
+        show.log("guau")
+    }
+}
+var d = Dog(4)
+d.speak()
+show.log(d instanceof Animal)
+`
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+
+	goCode, err := NewCodeGenerator().Generate(program)
+	if err != nil {
+		t.Fatalf("Code generation failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"type Dog struct {\n    Animal\n",
+		"func (obj *Animal) isAnimal() {}",
+		"func NewDog(n int) *Dog {",
+		"obj.Animal.Speak()",
+	} {
+		if !strings.Contains(goCode, want) {
+			t.Errorf("generated code does not contain %q:\n%s", want, goCode)
+		}
+	}
+
+	if output := buildAndRun(t, goCode); output != "animal 4\nguau\ntrue\n" {
+		t.Errorf("Unexpected output: %q", output)
+	}
+}
+
+func TestAsyncAwait(t *testing.T) {
+	input := `
+async func double(x: int) {
+    return x * 2
+}
+async func fail() {
+    throw "nope"
+}
+var f = double(21)
+show.log(await f)
+try {
+    await fail()
+} catch (e) {
+    show.log("caught " + e)
+}
+`
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+
+	goCode, err := NewCodeGenerator().Generate(program)
+	if err != nil {
+		t.Fatalf("Code generation failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"func double(x int) *zyloruntime.Future[int] {",
+		"return zyloruntime.Async(func() int {",
+		"f.Await()",
+	} {
+		if !strings.Contains(goCode, want) {
+			t.Errorf("generated code does not contain %q:\n%s", want, goCode)
+		}
+	}
+
+	if got := buildAndRun(t, goCode); got != "42\ncaught nope\n" {
+		t.Errorf("expected output %q, got %q", "42\ncaught nope\n", got)
+	}
+}
+
+func TestModules(t *testing.T) {
+	parse := func(input string) *ast.Program {
+		t.Helper()
+		p := parser.New(lexer.New(input))
+		program := p.ParseProgram()
+		if len(p.Errors()) > 0 {
+			t.Fatalf("Parser errors: %v", p.Errors())
+		}
+		return program
+	}
+	utils := parse(`
+show.log("init utils")
+func twice(x: int) {
+    return x * 2
+}
+export func double(x: int) {
+    return twice(x)
+}
+export var base = 20
+export class Box {
+    var size: int
+    func init(n: int) {
+        this.size = n
+    }
+}
+`)
+	main := parse(`
+import "lib/utils"
+from "lib/utils" import double, Box
+show.log(utils.double(utils.base))
+show.log(double(1), Box(3), utils.Box(4))
+`)
+
+	cg := NewCodeGenerator()
+	cg.AddModule("utils", utils)
+	mainCode, err := cg.Generate(main)
+	if err != nil {
+		t.Fatalf("Code generation failed: %v", err)
+	}
+	cg = NewCodeGenerator()
+	utilsCode, err := cg.GenerateModule("utils", utils)
+	if err != nil {
+		t.Fatalf("Code generation failed: %v", err)
+	}
+
+	for code, wants := range map[string][]string{
+		mainCode:  {`"zyloapp/utils"`, "utils.Double(utils.Base)", "utils.NewBox(3)", "utils.NewBox(4)"},
+		utilsCode: {"package utils", "func Double(x int) int", "func twice(x int) int", "var Base int = 20", "func NewBox(n int) *Box {", "func init() {"},
+	} {
+		for _, want := range wants {
+			if !strings.Contains(code, want) {
+				t.Errorf("generated code does not contain %q:\n%s", want, code)
+			}
+		}
+	}
+
+	output := runModule(t, map[string]string{
+		"main.go":        mainCode,
+		"utils/utils.go": utilsCode,
+	})
+	if want := "init utils\n40\n2 instance of Box instance of Box\n"; output != want {
+		t.Errorf("expected output %q, got %q", want, output)
+	}
+}
+
+func TestTypedDeclarations(t *testing.T) {
+	input := `
+var count = 3
+var ratio = 1.5
+var names = ["ana", "luis"]
+var ages = {"ana": 30}
+func scale(x: int): float {
+    return x * ratio
+}
+show.log(scale(count), names[1], ages["ana"], len(names))
+`
+	goCode := generate(t, input)
+	for _, want := range []string{
+		"var count int",
+		"var ratio float64",
+		"var names []string",
+		"var ages map[string]int",
+		"func scale(x int) float64 {",
+		"return (float64(x) * ratio)",
+	} {
+		if !strings.Contains(goCode, want) {
+			t.Errorf("generated code does not contain %q:\n%s", want, goCode)
+		}
+	}
+	if got := buildAndRun(t, goCode); got != "4.5 luis 30 2\n" {
+		t.Errorf("expected output %q, got %q", "4.5 luis 30 2\n", got)
+	}
+}
+
+func TestDynamicValues(t *testing.T) {
+	input := `
+func add(a, b) {
+    return a + b
+}
+func describe(x) {
+    if x {
+        return "sí"
+    }
+    return "no"
+}
+var items = [1, "dos", 3.5, null]
+for item in items {
+    show.log(item)
+}
+show.log(add(1, 2), add("a", 1), add(1.5, 2))
+show.log(describe(0), describe("x"), describe(items))
+show.log([1, 2] == [1, 2], add(2, 2) == 4)
+`
+	goCode := generate(t, input)
+	for _, want := range []string{
+		"func add(a zyloruntime.Value, b zyloruntime.Value) zyloruntime.Value {",
+		"zyloruntime.Operate(\"+\", a, b)",
+		"zyloruntime.Truthy(x)",
+		"var items []zyloruntime.Value",
+	} {
+		if !strings.Contains(goCode, want) {
+			t.Errorf("generated code does not contain %q:\n%s", want, goCode)
+		}
+	}
+	want := "1\ndos\n3.5\nnull\n3 a1 3.5\nno sí sí\nfalse true\n"
+	if got := buildAndRun(t, goCode); got != want {
+		t.Errorf("expected output %q, got %q", want, got)
+	}
+}
+
+func TestCollectionMethods(t *testing.T) {
+	input := `
+var items = [1, 2]
+items.Append(3)
+var ages = {"ana": 30}
+ages.set("luis", 25)
+show.log(items.Len(), items.Get(2), ages.get("luis"), ages.Len())
+`
+	goCode := generate(t, input)
+	for _, want := range []string{
+		"items = append(items, 3)",
+		"ages[\"luis\"] = 25",
+		"len(items)",
+	} {
+		if !strings.Contains(goCode, want) {
+			t.Errorf("generated code does not contain %q:\n%s", want, goCode)
+		}
+	}
+	if got := buildAndRun(t, goCode); got != "3 3 25 2\n" {
+		t.Errorf("expected output %q, got %q", "3 3 25 2\n", got)
+	}
+
+	// Append no se puede traducir en una expresión ni sobre una lista sin tipo
+	for _, input := range []string{
+		"var items = [1]\nvar x = items.Append(2)\n",
+		"func add(list) {\n    list.Append(1)\n}\n",
+	} {
+		p := parser.New(lexer.New(input))
+		program := p.ParseProgram()
+		if len(p.Errors()) > 0 {
+			t.Fatalf("Parser errors: %v", p.Errors())
+		}
+		if _, err := NewCodeGenerator().Generate(program); err == nil {
+			t.Errorf("expected an error generating %q", input)
+		}
+	}
+}
+
+func TestTryCatch(t *testing.T) {
+	input := `
+func divide(a: int, b: int) {
+    if b == 0 {
+        throw "división por cero"
+    }
+    return a / b
+}
+try {
+    show.log(divide(10, 2))
+    show.log(divide(1, 0))
+} catch (e) {
+    show.log("error:", e)
+} finally {
+    show.log("fin")
+}
+`
+	goCode := generate(t, input)
+	want := "5\nerror: división por cero\nfin\n"
+	if got := buildAndRun(t, goCode); got != want {
+		t.Errorf("expected output %q, got %q", want, got)
+	}
+}
+
+func TestJumpsInsideTry(t *testing.T) {
+	input := `
+func find(items: list<int>, wanted: int): int {
+    for item in items {
+        try {
+            if item == wanted {
+                return item * 10
+            }
+            if item < 0 {
+                break
+            }
+        } finally {
+            show.log("visto", item)
+        }
+    }
+    return 0
+}
+show.log(find([1, 2, 3], 2))
+show.log(find([1, -1, 3], 3))
+`
+	goCode := generate(t, input)
+	for _, want := range []string{"zyloJump1 = 1", "zyloResult1 = (item * 10)", "if zyloJump1 == 2 {"} {
+		if !strings.Contains(goCode, want) {
+			t.Errorf("generated code does not contain %q:\n%s", want, goCode)
+		}
+	}
+	want := "visto 1\nvisto 2\n20\nvisto 1\nvisto -1\n0\n"
+	if got := buildAndRun(t, goCode); got != want {
+		t.Errorf("expected output %q, got %q", want, got)
+	}
+}
+
+// TestReadmeExamples compila y ejecuta los ejemplos del README, que deben
+// ser programas completos.
+func TestReadmeExamples(t *testing.T) {
+	readme, err := os.ReadFile(filepath.Join("..", "..", "README.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blocks := strings.Split(string(readme), "```zylo")
+	for i, block := range blocks[1:] {
+		end := strings.Index(block, "```")
+		if end < 0 {
+			continue
+		}
+		source := block[:end]
+
+		p := parser.New(lexer.New(source))
+		program := p.ParseProgram()
+		if len(p.Errors()) > 0 {
+			t.Errorf("README block %d: parser errors: %v", i+1, p.Errors())
+			continue
+		}
+		analyzer := sema.NewSemanticAnalyzer()
+		analyzer.Analyze(program)
+		if len(analyzer.Errors()) > 0 {
+			t.Errorf("README block %d: %v", i+1, analyzer.Errors())
+			continue
+		}
+
+		goCode, err := NewCodeGenerator().Generate(program)
+		if err != nil {
+			t.Errorf("README block %d: code generation failed: %v", i+1, err)
+			continue
+		}
+		buildAndRun(t, goCode)
+	}
+	if len(blocks) < 2 {
+		t.Error("README has no zylo examples")
+	}
+}
+
+// generate genera el código Go de input.
+func generate(t *testing.T, input string) string {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+	goCode, err := NewCodeGenerator().Generate(program)
+	if err != nil {
+		t.Fatalf("Code generation failed: %v", err)
+	}
+	return goCode
+}
+
+func TestLineDirectives(t *testing.T) {
+	input := `var items = [1, 2]
+func first(list) {
+    return list[5]
+}
+show.log(first(items))
+`
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+	cg := NewCodeGenerator()
+	cg.SourceFile = "items.zylo"
+	goCode, err := cg.Generate(program)
+	if err != nil {
+		t.Fatalf("Code generation failed: %v", err)
+	}
+	for _, want := range []string{
+		"//line items.zylo:1:1\nvar items",
+		"//line items.zylo:2:1\nfunc first(",
+		"//line items.zylo:3:4\n    return",
+		"//line items.zylo:5:1\n    show.log",
+	} {
+		want = strings.Replace(want, "show.log", "fmt.Println", 1)
+		if !strings.Contains(goCode, want) {
+			t.Errorf("generated code does not contain %q:\n%s", want, goCode)
+		}
+	}
+
+	sourceMap, err := cg.SourceMap("main.go", []byte(goCode))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(goCode, "\n")
+	for i, line := range lines {
+		if strings.Contains(line, "return zyloruntime.Index") {
+			span, ok := sourceMap.Lookup(i + 1)
+			if !ok || span.StartLine != 3 || span.StartCol != 5 {
+				t.Errorf("expected Go line %d to map to 3:5, got %+v (%t)", i+1, span, ok)
+			}
+		}
+	}
+	if _, ok := sourceMap.Lookup(1); ok {
+		t.Errorf("the package clause should not map to Zylo code")
 	}
 }
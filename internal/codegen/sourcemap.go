@@ -0,0 +1,108 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+)
+
+// SourceMap relaciona las regiones de un archivo Go generado con las del
+// código Zylo del que salen. Se guarda junto al archivo Go como JSON.
+type SourceMap struct {
+	Version  int       `json:"version"`
+	File     string    `json:"file"`   // Archivo Go generado
+	Source   string    `json:"source"` // Archivo Zylo
+	Mappings []Mapping `json:"mappings"`
+}
+
+// Mapping relaciona las líneas de Go que genera una sentencia con la región
+// que ocupa la sentencia en el código Zylo.
+type Mapping struct {
+	Go   Span `json:"go"`
+	Zylo Span `json:"zylo"`
+}
+
+// Span es una región de un archivo: líneas y columnas desde 1, ambas
+// inclusive.
+type Span struct {
+	StartLine int `json:"startLine"`
+	StartCol  int `json:"startCol"`
+	EndLine   int `json:"endLine"`
+	EndCol    int `json:"endCol"`
+}
+
+// lineDirective escribe una directiva //line que hace que el compilador de
+// Go atribuya las líneas siguientes a la posición de node en SourceFile. La
+// columna descuenta la indentación, que gofmt escribe con un tabulador por
+// nivel, para que la sentencia de Go empiece en la columna de la de Zylo.
+func (cg *CodeGenerator) lineDirective(node ast.Node) {
+	if cg.SourceFile == "" || !endsLine(&cg.output) {
+		return
+	}
+	span := ast.SpanOf(node)
+	if span.StartLine == 0 {
+		return
+	}
+	col := span.StartCol - cg.indentation
+	if col < 1 {
+		col = 1
+	}
+	// Las directivas van en la columna 1, sin indentación
+	cg.output.WriteString(fmt.Sprintf("//line %s:%d:%d\n", cg.SourceFile, span.StartLine, col))
+	cg.spans = append(cg.spans, span)
+}
+
+// SourceMap devuelve el mapa de goCode, el código que generó cg, guardado en
+// goFile. goCode puede estar formateado: gofmt conserva las directivas //line
+// y su orden. Cada sentencia ocupa las líneas de Go desde su directiva hasta
+// la siguiente.
+func (cg *CodeGenerator) SourceMap(goFile string, goCode []byte) (*SourceMap, error) {
+	sourceMap := &SourceMap{Version: 1, File: goFile, Source: cg.SourceFile, Mappings: []Mapping{}}
+	lines := strings.Split(strings.TrimSuffix(string(goCode), "\n"), "\n")
+
+	next := 0 // Siguiente región de cg.spans
+	var open *Mapping
+	closeMapping := func(end int) {
+		if open == nil {
+			return
+		}
+		if end >= open.Go.StartLine {
+			open.Go.EndLine = end
+			open.Go.EndCol = len(lines[end-1]) + 1
+			sourceMap.Mappings = append(sourceMap.Mappings, *open)
+		}
+		open = nil
+	}
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "//line ") {
+			continue
+		}
+		closeMapping(i)
+		if next >= len(cg.spans) {
+			return nil, fmt.Errorf("%s has more //line directives than the generated code", goFile)
+		}
+		span := cg.spans[next]
+		next++
+		open = &Mapping{
+			Go:   Span{StartLine: i + 2, StartCol: 1},
+			Zylo: Span{StartLine: span.StartLine, StartCol: span.StartCol, EndLine: span.EndLine, EndCol: span.EndCol},
+		}
+	}
+	closeMapping(len(lines))
+	if next != len(cg.spans) {
+		return nil, fmt.Errorf("%s has fewer //line directives than the generated code", goFile)
+	}
+	return sourceMap, nil
+}
+
+// Lookup devuelve la posición en el código Zylo de la línea line del archivo
+// Go, o false si la línea no sale de ninguna sentencia.
+func (m *SourceMap) Lookup(line int) (Span, bool) {
+	for _, mapping := range m.Mappings {
+		if mapping.Go.StartLine <= line && line <= mapping.Go.EndLine {
+			return mapping.Zylo, true
+		}
+	}
+	return Span{}, false
+}
@@ -0,0 +1,159 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zylo-lang/zylo/internal/sema"
+)
+
+// void es el tipo de una llamada a una función que no devuelve nada, que en
+// Go no se puede usar como valor.
+var void = &sema.Type{Kind: sema.KindNull}
+
+// runtime devuelve el nombre calificado de name en el paquete zyloruntime y
+// registra el import.
+func (cg *CodeGenerator) runtime(name string) string {
+	cg.use(RuntimePath)
+	return "zyloruntime." + name
+}
+
+// goType devuelve el tipo Go de los valores de tipo t: int, float64, string,
+// bool, []T para las listas, map[string]T para los hashes,
+// *zyloruntime.Future[T] para los futures, funciones y punteros a las
+// structs de las clases. Lo que no tiene
+// un tipo estático es zyloruntime.Value.
+func (cg *CodeGenerator) goType(t *sema.Type) string {
+	switch t.Kind {
+	case sema.KindBool:
+		return "bool"
+	case sema.KindInt:
+		return "int"
+	case sema.KindFloat:
+		return "float64"
+	case sema.KindString:
+		return "string"
+	case sema.KindList:
+		return "[]" + cg.goType(t.Elem)
+	case sema.KindMap:
+		return "map[string]" + cg.goType(t.Elem)
+	case sema.KindFuture:
+		return "*" + cg.runtime("Future") + "[" + cg.goType(t.Elem) + "]"
+	case sema.KindFunc:
+		if t.Variadic {
+			break
+		}
+		params := make([]string, len(t.Params))
+		for i, param := range t.Params {
+			params[i] = cg.goType(param)
+		}
+		return "func(" + strings.Join(params, ", ") + ")" + cg.resultType(t.Result)
+	case sema.KindInstance:
+		return "*" + cg.className(t.Class)
+	}
+	return cg.runtime("Value")
+}
+
+// resultType devuelve el tipo de resultado de una función en Go, precedido de
+// un espacio, o "" si no devuelve nada.
+func (cg *CodeGenerator) resultType(result *sema.Type) string {
+	if result.Kind == sema.KindNull {
+		return ""
+	}
+	return " " + cg.goType(result)
+}
+
+// futureType devuelve el tipo del valor de un future. Un future de una
+// función sin resultado lleva null.
+func futureType(result *sema.Type) *sema.Type {
+	if result.Elem == nil || result.Elem.Kind == sema.KindNull {
+		return sema.Any
+	}
+	return result.Elem
+}
+
+// className devuelve el nombre Go de una clase, calificado con su paquete si
+// la exporta un módulo importado.
+func (cg *CodeGenerator) className(class *sema.Class) string {
+	if pkg, ok := cg.classPackages[class]; ok {
+		return cg.qualified(pkg, GoName(class.Name))
+	}
+	return cg.identifier(class.Name)
+}
+
+// zeroValue devuelve el valor cero del tipo Go de t.
+func (cg *CodeGenerator) zeroValue(t *sema.Type) string {
+	switch t.Kind {
+	case sema.KindBool:
+		return "false"
+	case sema.KindInt, sema.KindFloat:
+		return "0"
+	case sema.KindString:
+		return `""`
+	}
+	return "nil"
+}
+
+// convert adapta code, de tipo from, al tipo to donde se usa. Los tipos que
+// el comprobador acepta como compatibles pero que en Go son distintos se
+// convierten con zyloruntime.As, que falla al ejecutar si el valor no sirve.
+func (cg *CodeGenerator) convert(code string, from, to *sema.Type) string {
+	switch {
+	case from == void:
+		// Una llamada sin resultado usada como valor vale null
+		return fmt.Sprintf("func() %s { %s; return %s }()", cg.goType(to), code, cg.zeroValue(to))
+	case from.Kind == sema.KindNull:
+		return cg.zeroValue(to)
+	case to.Kind == sema.KindAny || to.Kind == sema.KindNull || to.Kind == sema.KindClass:
+		// Cualquier valor de Go cabe en un zyloruntime.Value
+		return code
+	case cg.sameGoType(from, to):
+		return code
+	case from.Kind == sema.KindInt && to.Kind == sema.KindFloat:
+		return "float64(" + code + ")"
+	case from.Kind == sema.KindInstance && to.Kind == sema.KindInstance && from.Class.IsSubclassOf(to.Class):
+		// La superclase está embebida en la subclase
+		for class := from.Class; class != to.Class; class = class.Super {
+			code += "." + cg.className(class.Super)
+		}
+		return "&" + code
+	}
+	return fmt.Sprintf("%s[%s](%s)", cg.runtime("As"), cg.goType(to), code)
+}
+
+// sameGoType indica si los valores de tipo a y b tienen el mismo tipo Go,
+// sin registrar los imports que usaría escribirlos.
+func (cg *CodeGenerator) sameGoType(a, b *sema.Type) bool {
+	imports := cg.imports
+	cg.imports = make(map[string]bool)
+	defer func() { cg.imports = imports }()
+	return cg.goType(a) == cg.goType(b)
+}
+
+// isBasic indica si los valores de tipo t se muestran igual con fmt que en
+// el evaluador.
+func isBasic(t *sema.Type) bool {
+	switch t.Kind {
+	case sema.KindBool, sema.KindInt, sema.KindFloat, sema.KindString:
+		return true
+	}
+	return false
+}
+
+// goKeywords son los nombres que no pueden usarse tal cual como
+// identificadores en el código generado: palabras reservadas de Go, los
+// nombres predeclarados que usa el código generado y los nombres que
+// declara él mismo.
+var goKeywords = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true,
+	"default": true, "defer": true, "else": true, "fallthrough": true, "for": true,
+	"func": true, "go": true, "goto": true, "if": true, "import": true,
+	"interface": true, "map": true, "package": true, "range": true, "return": true,
+	"select": true, "struct": true, "switch": true, "type": true, "var": true,
+
+	"bool": true, "float64": true, "int": true, "string": true, "len": true,
+	"nil": true, "true": true, "false": true, "make": true, "new": true,
+	"append": true, "panic": true, "init": true,
+
+	"fmt": true, "strings": true, "zyloruntime": true, "obj": true, "err": true,
+}
@@ -0,0 +1,239 @@
+package compiler
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// Instructions es una secuencia de instrucciones de bytecode codificadas.
+type Instructions []byte
+
+// Opcode identifica una instrucción de la VM.
+type Opcode byte
+
+const (
+	OpConstant Opcode = iota // Apila la constante [u16]
+	OpNull                   // Apila null
+	OpTrue                   // Apila true
+	OpFalse                  // Apila false
+	OpPop                    // Descarta la cima de la pila
+	OpDup                    // Duplica la cima de la pila
+
+	OpAdd
+	OpSub
+	OpMul
+	OpDiv
+	OpEqual
+	OpNotEqual
+	OpLess
+	OpGreater
+	OpLessEqual
+	OpGreaterEqual
+	OpAnd
+	OpOr
+	OpRange // Lista de enteros de start a end, sin incluir end
+	OpMinus // Negación aritmética
+	OpNot   // Negación lógica
+
+	OpJump        // Salta a [u16]
+	OpJumpIfFalse // Desapila la condición y salta a [u16] si es falsa
+
+	OpGetGlobal // Apila el global [u16]
+	OpSetGlobal // Desapila en el global [u16]
+	OpGetLocal  // Apila el local [u16]
+	OpSetLocal  // Desapila en el local [u16]
+
+	OpList      // Crea una lista con los [u16] elementos de la cima
+	OpHash      // Crea un hash con los [u16] pares clave/valor de la cima
+	OpIndex     // objeto, índice -> elemento
+	OpSetIndex  // objeto, índice, valor -> valor
+	OpGetMember // objeto -> miembro con el nombre de la constante [u16]
+	OpSetMember // objeto, valor -> valor; nombre en la constante [u16]
+
+	OpCall        // Llama a la función bajo los [u8] argumentos de la cima
+	OpReturnValue // Devuelve la cima de la pila
+	OpReturn      // Devuelve null
+
+	OpIterStart // Reemplaza el iterable de la cima por un iterador
+	OpIterNext  // Apila el siguiente elemento del iterador o salta a [u16]
+
+	OpTry     // Instala un manejador de excepciones en [u16]
+	OpEndTry  // Retira el manejador más reciente
+	OpThrow   // Lanza la cima de la pila como excepción
+	OpCatch   // Convierte la excepción pendiente en el valor del catch; las que no se capturan saltan a [u16]
+	OpRethrow // Relanza la excepción pendiente de la cima
+
+	OpCheckType // Comprueba la cima contra la anotación de la constante [u16]
+)
+
+// Definition describe el nombre y el ancho de los operandos de un opcode.
+type Definition struct {
+	Name          string
+	OperandWidths []int
+}
+
+var definitions = map[Opcode]*Definition{
+	OpConstant: {"OpConstant", []int{2}},
+	OpNull:     {"OpNull", nil},
+	OpTrue:     {"OpTrue", nil},
+	OpFalse:    {"OpFalse", nil},
+	OpPop:      {"OpPop", nil},
+	OpDup:      {"OpDup", nil},
+
+	OpAdd:          {"OpAdd", nil},
+	OpSub:          {"OpSub", nil},
+	OpMul:          {"OpMul", nil},
+	OpDiv:          {"OpDiv", nil},
+	OpEqual:        {"OpEqual", nil},
+	OpNotEqual:     {"OpNotEqual", nil},
+	OpLess:         {"OpLess", nil},
+	OpGreater:      {"OpGreater", nil},
+	OpLessEqual:    {"OpLessEqual", nil},
+	OpGreaterEqual: {"OpGreaterEqual", nil},
+	OpAnd:          {"OpAnd", nil},
+	OpOr:           {"OpOr", nil},
+	OpRange:        {"OpRange", nil},
+	OpMinus:        {"OpMinus", nil},
+	OpNot:          {"OpNot", nil},
+
+	OpJump:        {"OpJump", []int{2}},
+	OpJumpIfFalse: {"OpJumpIfFalse", []int{2}},
+
+	OpGetGlobal: {"OpGetGlobal", []int{2}},
+	OpSetGlobal: {"OpSetGlobal", []int{2}},
+	OpGetLocal:  {"OpGetLocal", []int{2}},
+	OpSetLocal:  {"OpSetLocal", []int{2}},
+
+	OpList:      {"OpList", []int{2}},
+	OpHash:      {"OpHash", []int{2}},
+	OpIndex:     {"OpIndex", nil},
+	OpSetIndex:  {"OpSetIndex", nil},
+	OpGetMember: {"OpGetMember", []int{2}},
+	OpSetMember: {"OpSetMember", []int{2}},
+
+	OpCall:        {"OpCall", []int{1}},
+	OpReturnValue: {"OpReturnValue", nil},
+	OpReturn:      {"OpReturn", nil},
+
+	OpIterStart: {"OpIterStart", nil},
+	OpIterNext:  {"OpIterNext", []int{2}},
+
+	OpTry:     {"OpTry", []int{2}},
+	OpEndTry:  {"OpEndTry", nil},
+	OpThrow:   {"OpThrow", nil},
+	OpCatch:   {"OpCatch", []int{2}},
+	OpRethrow: {"OpRethrow", nil},
+
+	OpCheckType: {"OpCheckType", []int{2}},
+}
+
+// binaryOperators asocia los operadores infijos de Zylo con su opcode.
+var binaryOperators = map[string]Opcode{
+	"+":  OpAdd,
+	"-":  OpSub,
+	"*":  OpMul,
+	"/":  OpDiv,
+	"==": OpEqual,
+	"!=": OpNotEqual,
+	"<":  OpLess,
+	">":  OpGreater,
+	"<=": OpLessEqual,
+	">=": OpGreaterEqual,
+	"&&": OpAnd,
+	"||": OpOr,
+	"..": OpRange,
+}
+
+// operatorNames es el inverso de binaryOperators.
+var operatorNames = func() map[Opcode]string {
+	names := make(map[Opcode]string, len(binaryOperators))
+	for operator, op := range binaryOperators {
+		names[op] = operator
+	}
+	return names
+}()
+
+// Operator devuelve el operador de Zylo que implementa un opcode binario.
+func Operator(op Opcode) string {
+	return operatorNames[op]
+}
+
+// Lookup devuelve la definición de un opcode.
+func Lookup(op Opcode) (*Definition, error) {
+	def, ok := definitions[op]
+	if !ok {
+		return nil, fmt.Errorf("opcode %d no definido", op)
+	}
+	return def, nil
+}
+
+// Make codifica una instrucción con sus operandos.
+func Make(op Opcode, operands ...int) []byte {
+	def, ok := definitions[op]
+	if !ok {
+		return []byte{}
+	}
+
+	length := 1
+	for _, w := range def.OperandWidths {
+		length += w
+	}
+
+	instruction := make([]byte, length)
+	instruction[0] = byte(op)
+	offset := 1
+	for i, operand := range operands {
+		switch def.OperandWidths[i] {
+		case 2:
+			binary.BigEndian.PutUint16(instruction[offset:], uint16(operand))
+		case 1:
+			instruction[offset] = byte(operand)
+		}
+		offset += def.OperandWidths[i]
+	}
+	return instruction
+}
+
+// ReadOperands decodifica los operandos de una instrucción y devuelve cuántos
+// bytes ocupan.
+func ReadOperands(def *Definition, ins Instructions) ([]int, int) {
+	operands := make([]int, len(def.OperandWidths))
+	offset := 0
+	for i, width := range def.OperandWidths {
+		switch width {
+		case 2:
+			operands[i] = int(ReadUint16(ins[offset:]))
+		case 1:
+			operands[i] = int(ins[offset])
+		}
+		offset += width
+	}
+	return operands, offset
+}
+
+// ReadUint16 lee un operando de 2 bytes.
+func ReadUint16(ins Instructions) uint16 {
+	return binary.BigEndian.Uint16(ins)
+}
+
+// String desensambla las instrucciones, una por línea.
+func (ins Instructions) String() string {
+	var out strings.Builder
+	for i := 0; i < len(ins); {
+		def, err := Lookup(Opcode(ins[i]))
+		if err != nil {
+			fmt.Fprintf(&out, "ERROR: %s\n", err)
+			i++
+			continue
+		}
+		operands, read := ReadOperands(def, ins[i+1:])
+		fmt.Fprintf(&out, "%04d %s", i, def.Name)
+		for _, operand := range operands {
+			fmt.Fprintf(&out, " %d", operand)
+		}
+		out.WriteString("\n")
+		i += 1 + read
+	}
+	return out.String()
+}
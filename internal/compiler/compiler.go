@@ -0,0 +1,742 @@
+// Package compiler traduce un ast.Program a bytecode para la VM de Zylo
+// (internal/vm): instrucciones compactas, un pool de constantes y variables
+// resueltas a slots en tiempo de compilación.
+package compiler
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+	"github.com/zylo-lang/zylo/internal/evaluator"
+)
+
+// mainName es el nombre del código de nivel superior en las trazas, igual que
+// en el evaluador.
+const mainName = "<programa>"
+
+// maxOperand es el mayor valor que cabe en un operando de 2 bytes.
+const maxOperand = 1<<16 - 1
+
+// CompiledFunction es una función compilada; también representa el código de
+// nivel superior del programa.
+type CompiledFunction struct {
+	Name         string
+	Instructions Instructions
+	NumParams    int
+	NumLocals    int
+	LocalNames   []string   // Nombre de cada slot local
+	Positions    []Position // Posición en el código fuente, ordenada por Offset
+}
+
+func (f *CompiledFunction) Type() string    { return "COMPILED_FUNCTION_OBJ" }
+func (f *CompiledFunction) Inspect() string { return fmt.Sprintf("<func %s>", f.Name) }
+
+// Position asocia las instrucciones a partir de Offset con una región del código fuente.
+type Position struct {
+	Offset int
+	Span   ast.Span
+}
+
+// SpanAt devuelve la región del código fuente de la instrucción en offset.
+func (f *CompiledFunction) SpanAt(offset int) ast.Span {
+	i := sort.Search(len(f.Positions), func(i int) bool {
+		return f.Positions[i].Offset > offset
+	})
+	if i == 0 {
+		return ast.Span{}
+	}
+	return f.Positions[i-1].Span
+}
+
+// Bytecode es el resultado de compilar un programa.
+type Bytecode struct {
+	Main      *CompiledFunction
+	Constants []evaluator.Value
+	Globals   []string // Nombre de cada slot global
+}
+
+// CodeCompile es el código de Error en los diagnósticos. No cambia entre
+// versiones.
+const CodeCompile = "C001"
+
+// Error es un error de compilación con su posición.
+type Error struct {
+	Span    ast.Span
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Span.StartLine, e.Span.StartCol, e.Message)
+}
+
+// loopContext guarda los saltos pendientes de un bucle.
+type loopContext struct {
+	continueTarget int
+	breakJumps     []int
+	tryDepth       int // Bloques try abiertos al entrar al bucle
+}
+
+// tryContext describe un bloque try abierto en el punto de compilación actual.
+type tryContext struct {
+	stmt          *ast.TryStatement
+	finally       *ast.BlockStatement
+	handlerActive bool // Hay un manejador instalado que se debe retirar al salir
+	pending       bool // Hay una excepción pendiente en la pila que se debe descartar
+}
+
+// compilationScope es el estado de compilación de una función.
+type compilationScope struct {
+	fn         *CompiledFunction
+	loops      []*loopContext
+	tries      []tryContext
+	returnType string // Anotación del tipo de retorno de la función
+}
+
+// Compiler compila un programa a Bytecode.
+type Compiler struct {
+	constants []evaluator.Value
+	symbols   *SymbolTable
+	scopes    []*compilationScope
+}
+
+// New crea un compilador.
+func New() *Compiler {
+	return &Compiler{symbols: NewSymbolTable()}
+}
+
+// Compile compila program. Las construcciones que la VM aún no soporta
+// (clases, import) producen un *Error.
+func (c *Compiler) Compile(program *ast.Program) (*Bytecode, error) {
+	c.enterScope(mainName)
+
+	// Los nombres de nivel superior se reservan antes de compilar para que las
+	// funciones puedan usar globales declarados más abajo.
+	hasMain := false
+	for _, stmt := range program.Statements {
+		switch s := stmt.(type) {
+		case *ast.VarStatement:
+			c.symbols.Define(s.Name.Value)
+		case *ast.FuncStatement:
+			c.symbols.Define(s.Name.Value)
+			hasMain = hasMain || s.Name.Value == "main"
+		}
+	}
+
+	for _, stmt := range program.Statements {
+		if err := c.compileStatement(stmt); err != nil {
+			return nil, err
+		}
+	}
+
+	// Como el evaluador, ejecutar main() si el programa la define. La llamada
+	// no tiene posición, así que no aparece en las trazas.
+	if hasMain {
+		symbol, _, _ := c.symbols.Resolve("main")
+		c.emit(nil, OpGetGlobal, symbol.Index)
+		c.emit(nil, OpCall, 0)
+		c.emit(nil, OpPop)
+	}
+	c.emit(program, OpReturn)
+
+	// Los operandos de 16 bits limitan el tamaño de cada tabla
+	if len(c.constants) > maxOperand || len(c.symbols.GlobalNames()) > maxOperand || c.offset() > maxOperand {
+		return nil, c.errorf(program, "programa demasiado grande para el motor vm")
+	}
+
+	return &Bytecode{
+		Main:      c.leaveScope(c.symbols),
+		Constants: c.constants,
+		Globals:   c.symbols.GlobalNames(),
+	}, nil
+}
+
+func (c *Compiler) scope() *compilationScope {
+	return c.scopes[len(c.scopes)-1]
+}
+
+func (c *Compiler) enterScope(name string) {
+	c.scopes = append(c.scopes, &compilationScope{fn: &CompiledFunction{Name: name}})
+}
+
+func (c *Compiler) leaveScope(symbols *SymbolTable) *CompiledFunction {
+	fn := c.scope().fn
+	fn.NumLocals = symbols.NumLocals()
+	fn.LocalNames = symbols.LocalNames()
+	c.scopes = c.scopes[:len(c.scopes)-1]
+	return fn
+}
+
+// emit añade una instrucción con la posición de node y devuelve su offset.
+func (c *Compiler) emit(node ast.Node, op Opcode, operands ...int) int {
+	fn := c.scope().fn
+	offset := len(fn.Instructions)
+	span := ast.SpanOf(node)
+	if n := len(fn.Positions); n == 0 || fn.Positions[n-1].Span != span {
+		fn.Positions = append(fn.Positions, Position{Offset: offset, Span: span})
+	}
+	fn.Instructions = append(fn.Instructions, Make(op, operands...)...)
+	return offset
+}
+
+// offset devuelve la posición de la próxima instrucción.
+func (c *Compiler) offset() int {
+	return len(c.scope().fn.Instructions)
+}
+
+// patchJump apunta el salto emitido en offset a la posición actual.
+func (c *Compiler) patchJump(offset int) {
+	ins := c.scope().fn.Instructions
+	copy(ins[offset+1:], Make(Opcode(ins[offset]), c.offset())[1:])
+}
+
+func (c *Compiler) addConstant(value evaluator.Value) int {
+	c.constants = append(c.constants, value)
+	return len(c.constants) - 1
+}
+
+func (c *Compiler) errorf(node ast.Node, format string, args ...interface{}) error {
+	return &Error{Span: ast.SpanOf(node), Message: fmt.Sprintf(format, args...)}
+}
+
+// withBlockScope compila fn en un ámbito de bloque nuevo.
+func (c *Compiler) withBlockScope(fn func() error) error {
+	outer := c.symbols
+	c.symbols = NewBlockTable(outer)
+	defer func() { c.symbols = outer }()
+	return fn()
+}
+
+func (c *Compiler) compileBlock(block *ast.BlockStatement) error {
+	if block == nil {
+		return nil
+	}
+	return c.withBlockScope(func() error {
+		return c.compileStatements(block.Statements)
+	})
+}
+
+func (c *Compiler) compileStatements(statements []ast.Statement) error {
+	for _, stmt := range statements {
+		if err := c.compileStatement(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Compiler) compileStatement(stmt ast.Statement) error {
+	switch s := stmt.(type) {
+	case *ast.ExpressionStatement:
+		if s.Expression == nil {
+			return nil
+		}
+		if err := c.compileExpression(s.Expression); err != nil {
+			return err
+		}
+		c.emit(s, OpPop)
+	case *ast.VarStatement:
+		if s.Value != nil {
+			if err := c.compileExpression(s.Value); err != nil {
+				return err
+			}
+		} else {
+			c.emit(s, OpNull)
+		}
+		c.emitCheckType(s, s.Type)
+		c.emitSet(s, c.symbols.DefineTyped(s.Name.Value, s.Type))
+	case *ast.FuncStatement:
+		if s.Async {
+			return c.errorf(s, "el motor vm no soporta funciones async")
+		}
+		symbol := c.symbols.Define(s.Name.Value)
+		fn, err := c.compileFunction(s)
+		if err != nil {
+			return err
+		}
+		c.emit(s, OpConstant, c.addConstant(fn))
+		c.emitSet(s, symbol)
+	case *ast.ReturnStatement:
+		if s.ReturnValue != nil {
+			if err := c.compileExpression(s.ReturnValue); err != nil {
+				return err
+			}
+		} else {
+			c.emit(s, OpNull)
+		}
+		c.emitCheckType(s, c.scope().returnType)
+		if err := c.unwindTries(0); err != nil {
+			return err
+		}
+		c.emit(s, OpReturnValue)
+	case *ast.BlockStatement:
+		return c.compileBlock(s)
+	case *ast.IfStatement:
+		return c.compileIf(s)
+	case *ast.WhileStatement:
+		return c.compileWhile(s)
+	case *ast.ForInStatement:
+		return c.compileForIn(s)
+	case *ast.BreakStatement:
+		loop, err := c.currentLoop(s, "break")
+		if err != nil {
+			return err
+		}
+		if err := c.unwindTries(loop.tryDepth); err != nil {
+			return err
+		}
+		loop.breakJumps = append(loop.breakJumps, c.emit(s, OpJump, 0))
+	case *ast.ContinueStatement:
+		loop, err := c.currentLoop(s, "continue")
+		if err != nil {
+			return err
+		}
+		if err := c.unwindTries(loop.tryDepth); err != nil {
+			return err
+		}
+		c.emit(s, OpJump, loop.continueTarget)
+	case *ast.TryStatement:
+		return c.compileTry(s)
+	case *ast.ThrowStatement:
+		if err := c.compileExpression(s.Exception); err != nil {
+			return err
+		}
+		c.emit(s, OpThrow)
+	case *ast.ClassStatement:
+		return c.errorf(s, "el motor vm no soporta clases")
+	case *ast.ImportStatement:
+		return c.errorf(s, "el motor vm no soporta import")
+	case *ast.ExportStatement:
+		return c.compileStatement(s.Statement)
+	default:
+		return c.errorf(stmt, "sentencia no soportada por el motor vm: %T", stmt)
+	}
+	return nil
+}
+
+// emitSet guarda la cima de la pila en la variable symbol.
+func (c *Compiler) emitSet(node ast.Node, symbol Symbol) {
+	if symbol.Scope == GlobalScope {
+		c.emit(node, OpSetGlobal, symbol.Index)
+	} else {
+		c.emit(node, OpSetLocal, symbol.Index)
+	}
+}
+
+// emitCheckType comprueba que la cima de la pila cumple la anotación de tipo
+// annotation, si la hay. Como en el evaluador, el comprobador de tipos no
+// conoce el tipo de los valores de tipo any.
+func (c *Compiler) emitCheckType(node ast.Node, annotation string) {
+	if annotation != "" {
+		c.emit(node, OpCheckType, c.addConstant(&evaluator.String{Value: annotation}))
+	}
+}
+
+// emitGet apila el valor de la variable symbol.
+func (c *Compiler) emitGet(node ast.Node, symbol Symbol) {
+	if symbol.Scope == GlobalScope {
+		c.emit(node, OpGetGlobal, symbol.Index)
+	} else {
+		c.emit(node, OpGetLocal, symbol.Index)
+	}
+}
+
+func (c *Compiler) compileFunction(stmt *ast.FuncStatement) (*CompiledFunction, error) {
+	outer := c.symbols
+	c.symbols = NewFunctionTable(outer)
+	defer func() { c.symbols = outer }()
+
+	c.enterScope(stmt.Name.Value)
+	c.scope().returnType = stmt.ReturnType
+	for _, param := range stmt.Parameters {
+		symbol := c.symbols.DefineTyped(param.Value, param.Type)
+		if param.Type != "" {
+			c.emitGet(param, symbol)
+			c.emitCheckType(param, param.Type)
+			c.emitSet(param, symbol)
+		}
+	}
+	if err := c.compileBlock(stmt.Body); err != nil {
+		c.leaveScope(c.symbols)
+		return nil, err
+	}
+	c.emit(stmt, OpReturn)
+	if c.offset() > maxOperand || c.symbols.NumLocals() > maxOperand {
+		c.leaveScope(c.symbols)
+		return nil, c.errorf(stmt, "función demasiado grande para el motor vm: %s", stmt.Name.Value)
+	}
+
+	fn := c.leaveScope(c.symbols)
+	fn.NumParams = len(stmt.Parameters)
+	return fn, nil
+}
+
+func (c *Compiler) compileIf(stmt *ast.IfStatement) error {
+	if err := c.compileExpression(stmt.Condition); err != nil {
+		return err
+	}
+	jumpIfFalse := c.emit(stmt, OpJumpIfFalse, 0)
+	if err := c.compileBlock(stmt.Consequence); err != nil {
+		return err
+	}
+	if stmt.Alternative == nil {
+		c.patchJump(jumpIfFalse)
+		return nil
+	}
+	jumpToEnd := c.emit(stmt, OpJump, 0)
+	c.patchJump(jumpIfFalse)
+	if err := c.compileBlock(stmt.Alternative); err != nil {
+		return err
+	}
+	c.patchJump(jumpToEnd)
+	return nil
+}
+
+func (c *Compiler) compileWhile(stmt *ast.WhileStatement) error {
+	start := c.offset()
+	if err := c.compileExpression(stmt.Condition); err != nil {
+		return err
+	}
+	exit := c.emit(stmt, OpJumpIfFalse, 0)
+
+	// Como en el evaluador, el cuerpo del while no abre un ámbito propio
+	loop := c.enterLoop(start)
+	if stmt.Body != nil {
+		if err := c.compileStatements(stmt.Body.Statements); err != nil {
+			return err
+		}
+	}
+	c.emit(stmt, OpJump, start)
+	c.patchJump(exit)
+	c.leaveLoop(loop)
+	return nil
+}
+
+func (c *Compiler) compileForIn(stmt *ast.ForInStatement) error {
+	if err := c.compileExpression(stmt.Iterable); err != nil {
+		return err
+	}
+	c.emit(stmt.Iterable, OpIterStart)
+
+	// La variable de iteración vive en un ámbito propio del bucle, como en
+	// el evaluador: no reemplaza a una variable de fuera con el mismo nombre
+	start := c.emit(stmt, OpIterNext, 0)
+	loop := c.enterLoop(start)
+	err := c.withBlockScope(func() error {
+		c.emitSet(stmt, c.symbols.Define(stmt.Identifier.Value))
+		return c.compileBlock(stmt.Body)
+	})
+	if err != nil {
+		return err
+	}
+	c.emit(stmt, OpJump, start)
+	c.patchJump(start)
+	c.leaveLoop(loop)
+	c.emit(stmt, OpPop) // Descartar el iterador
+	return nil
+}
+
+func (c *Compiler) enterLoop(continueTarget int) *loopContext {
+	scope := c.scope()
+	loop := &loopContext{continueTarget: continueTarget, tryDepth: len(scope.tries)}
+	scope.loops = append(scope.loops, loop)
+	return loop
+}
+
+// leaveLoop cierra el bucle y apunta sus break a la posición actual.
+func (c *Compiler) leaveLoop(loop *loopContext) {
+	scope := c.scope()
+	scope.loops = scope.loops[:len(scope.loops)-1]
+	for _, jump := range loop.breakJumps {
+		c.patchJump(jump)
+	}
+}
+
+func (c *Compiler) currentLoop(node ast.Node, keyword string) (*loopContext, error) {
+	loops := c.scope().loops
+	if len(loops) == 0 {
+		return nil, c.errorf(node, "'%s' fuera de un bucle", keyword)
+	}
+	return loops[len(loops)-1], nil
+}
+
+// compileTry genera:
+//
+//	OpTry catch; <try>; OpEndTry; OpJump finally
+//	catch:   OpCatch rethrow; <variable>; [OpTry rethrow]; <catch>; [OpEndTry]; OpJump finally
+//	rethrow: <finally>; OpRethrow
+//	finally: <finally>
+//
+// Los break, continue y return que salen del bloque ejecutan el finally antes
+// de saltar (ver unwindTries). Los errores que el catch no captura, como la
+// cancelación, saltan de OpCatch a rethrow, o se relanzan si no hay finally.
+func (c *Compiler) compileTry(stmt *ast.TryStatement) error {
+	scope := c.scope()
+	finally := stmt.FinallyBlock
+	var toFinally []int
+
+	handler := c.emit(stmt, OpTry, 0)
+	scope.tries = append(scope.tries, tryContext{stmt: stmt, finally: finally, handlerActive: true})
+	if err := c.compileBlock(stmt.TryBlock); err != nil {
+		return err
+	}
+	scope.tries = scope.tries[:len(scope.tries)-1]
+	c.emit(stmt, OpEndTry)
+	toFinally = append(toFinally, c.emit(stmt, OpJump, 0))
+	c.patchJump(handler)
+
+	if stmt.CatchClause != nil {
+		var catch, rethrow int
+		err := c.withBlockScope(func() error {
+			catch = c.emit(stmt.CatchClause, OpCatch, 0)
+			if stmt.CatchClause.Parameter != nil {
+				c.emitSet(stmt.CatchClause, c.symbols.Define(stmt.CatchClause.Parameter.Value))
+			} else {
+				c.emit(stmt.CatchClause, OpPop)
+			}
+			if finally != nil {
+				rethrow = c.emit(stmt.CatchClause, OpTry, 0)
+			}
+			if stmt.CatchClause.CatchBlock == nil {
+				return nil
+			}
+			scope.tries = append(scope.tries, tryContext{stmt: stmt, finally: finally, handlerActive: finally != nil})
+			defer func() { scope.tries = scope.tries[:len(scope.tries)-1] }()
+			return c.compileStatements(stmt.CatchClause.CatchBlock.Statements)
+		})
+		if err != nil {
+			return err
+		}
+		if finally != nil {
+			c.emit(stmt.CatchClause, OpEndTry)
+			toFinally = append(toFinally, c.emit(stmt.CatchClause, OpJump, 0))
+			c.patchJump(catch)
+			c.patchJump(rethrow)
+		} else {
+			toFinally = append(toFinally, c.emit(stmt.CatchClause, OpJump, 0))
+		}
+	}
+
+	if finally != nil {
+		// Camino de la excepción: ejecutar el finally y relanzar. La excepción
+		// pendiente queda en la pila mientras tanto.
+		scope.tries = append(scope.tries, tryContext{stmt: stmt, pending: true})
+		err := c.compileBlock(finally)
+		scope.tries = scope.tries[:len(scope.tries)-1]
+		if err != nil {
+			return err
+		}
+		c.emit(finally, OpRethrow)
+	}
+
+	for _, jump := range toFinally {
+		c.patchJump(jump)
+	}
+	return c.compileBlock(finally)
+}
+
+// unwindTries sale de los bloques try abiertos por encima de depth: retira
+// sus manejadores y ejecuta sus bloques finally, del más interno al más externo.
+func (c *Compiler) unwindTries(depth int) error {
+	scope := c.scope()
+	tries := scope.tries
+	defer func() { scope.tries = tries }()
+
+	for i := len(tries) - 1; i >= depth; i-- {
+		if tries[i].handlerActive {
+			c.emit(tries[i].stmt, OpEndTry)
+		}
+		if tries[i].pending {
+			c.emit(tries[i].stmt, OpPop)
+		}
+		if tries[i].finally != nil {
+			scope.tries = tries[:i]
+			if err := c.compileBlock(tries[i].finally); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Compiler) compileExpression(exp ast.Expression) error {
+	switch ex := exp.(type) {
+	case *ast.Identifier:
+		symbol, err := c.resolve(ex, ex.Value)
+		if err != nil {
+			return err
+		}
+		c.emitGet(ex, symbol)
+	case *ast.NumberLiteral:
+		switch v := ex.Value.(type) {
+		case float64:
+			c.emit(ex, OpConstant, c.addConstant(&evaluator.Float{Value: v}))
+		case int64:
+			c.emit(ex, OpConstant, c.addConstant(&evaluator.Integer{Value: v}))
+		default:
+			c.emit(ex, OpConstant, c.addConstant(&evaluator.Integer{Value: 0}))
+		}
+	case *ast.StringLiteral:
+		c.emit(ex, OpConstant, c.addConstant(&evaluator.String{Value: ex.Value}))
+	case *ast.BooleanLiteral:
+		if ex.Value {
+			c.emit(ex, OpTrue)
+		} else {
+			c.emit(ex, OpFalse)
+		}
+	case *ast.NullLiteral:
+		c.emit(ex, OpNull)
+	case *ast.PrefixExpression:
+		if err := c.compileExpression(ex.Right); err != nil {
+			return err
+		}
+		switch ex.Operator {
+		case "!":
+			c.emit(ex, OpNot)
+		case "-":
+			c.emit(ex, OpMinus)
+		default:
+			return c.errorf(ex, "operador prefijo no soportado: %s", ex.Operator)
+		}
+	case *ast.InfixExpression:
+		if ex.Operator == "=" {
+			return c.compileAssignment(ex)
+		}
+		op, ok := binaryOperators[ex.Operator]
+		if !ok {
+			return c.errorf(ex, "operador no soportado: %s", ex.Operator)
+		}
+		// Como en el evaluador, && y || evalúan ambos operandos
+		if err := c.compileExpression(ex.Left); err != nil {
+			return err
+		}
+		if err := c.compileExpression(ex.Right); err != nil {
+			return err
+		}
+		c.emit(ex, op)
+	case *ast.CallExpression:
+		if len(ex.Arguments) > 255 {
+			return c.errorf(ex, "demasiados argumentos en la llamada (%d)", len(ex.Arguments))
+		}
+		if err := c.compileExpression(ex.Function); err != nil {
+			return err
+		}
+		for _, arg := range ex.Arguments {
+			if err := c.compileExpression(arg); err != nil {
+				return err
+			}
+		}
+		c.emit(ex, OpCall, len(ex.Arguments))
+	case *ast.MemberExpression:
+		// Built-ins con punto como show.log se guardan con el nombre completo
+		if ident, ok := ex.Object.(*ast.Identifier); ok {
+			if _, found, _ := c.symbols.Resolve(ident.Value); !found {
+				c.emitGet(ex, c.symbols.Global(ident.Value+"."+ex.Property.Value))
+				return nil
+			}
+		}
+		if err := c.compileExpression(ex.Object); err != nil {
+			return err
+		}
+		c.emit(ex, OpGetMember, c.addConstant(&evaluator.String{Value: ex.Property.Value}))
+	case *ast.IndexExpression:
+		if err := c.compileExpression(ex.Left); err != nil {
+			return err
+		}
+		if err := c.compileExpression(ex.Index); err != nil {
+			return err
+		}
+		c.emit(ex, OpIndex)
+	case *ast.ListLiteral:
+		for _, el := range ex.Elements {
+			if err := c.compileExpression(el); err != nil {
+				return err
+			}
+		}
+		c.emit(ex, OpList, len(ex.Elements))
+	case *ast.HashLiteral:
+		keys := make([]ast.Expression, 0, len(ex.Pairs))
+		for key := range ex.Pairs {
+			keys = append(keys, key)
+		}
+		// Orden del código fuente, para que la compilación sea determinista
+		sort.Slice(keys, func(i, j int) bool {
+			a, b := ast.SpanOf(keys[i]), ast.SpanOf(keys[j])
+			return a.StartLine < b.StartLine || (a.StartLine == b.StartLine && a.StartCol < b.StartCol)
+		})
+		for _, key := range keys {
+			if err := c.compileExpression(key); err != nil {
+				return err
+			}
+			if err := c.compileExpression(ex.Pairs[key]); err != nil {
+				return err
+			}
+		}
+		c.emit(ex, OpHash, len(keys))
+	case *ast.ThisExpression, *ast.SuperExpression:
+		return c.errorf(ex, "el motor vm no soporta clases")
+	case *ast.FunctionLiteral:
+		return c.errorf(ex, "el motor vm no soporta funciones anónimas")
+	case *ast.SpawnExpression:
+		return c.errorf(ex, "el motor vm no soporta spawn")
+	case *ast.AwaitExpression:
+		return c.errorf(ex, "el motor vm no soporta await")
+	default:
+		return c.errorf(exp, "expresión no soportada por el motor vm: %T", exp)
+	}
+	return nil
+}
+
+func (c *Compiler) compileAssignment(exp *ast.InfixExpression) error {
+	switch target := exp.Left.(type) {
+	case *ast.Identifier:
+		symbol, found, err := c.symbols.Resolve(target.Value)
+		if err != nil {
+			return c.errorf(target, "%s", err)
+		}
+		if !found {
+			return c.errorf(target, "variable no definida: %s", target.Value)
+		}
+		if err := c.compileExpression(exp.Right); err != nil {
+			return err
+		}
+		c.emitCheckType(exp, symbol.Type)
+		c.emit(exp, OpDup)
+		c.emitSet(exp, symbol)
+	case *ast.MemberExpression:
+		if err := c.compileExpression(target.Object); err != nil {
+			return err
+		}
+		if err := c.compileExpression(exp.Right); err != nil {
+			return err
+		}
+		c.emit(exp, OpSetMember, c.addConstant(&evaluator.String{Value: target.Property.Value}))
+	case *ast.IndexExpression:
+		if err := c.compileExpression(target.Left); err != nil {
+			return err
+		}
+		if err := c.compileExpression(target.Index); err != nil {
+			return err
+		}
+		if err := c.compileExpression(exp.Right); err != nil {
+			return err
+		}
+		c.emit(exp, OpSetIndex)
+	default:
+		return c.errorf(exp, "invalid assignment target: %T", exp.Left)
+	}
+	return nil
+}
+
+// resolve busca name; los nombres desconocidos se tratan como globales que
+// pueden definirse en tiempo de ejecución (built-ins incluidos).
+func (c *Compiler) resolve(node ast.Node, name string) (Symbol, error) {
+	symbol, found, err := c.symbols.Resolve(name)
+	if err != nil {
+		return Symbol{}, c.errorf(node, "%s", err)
+	}
+	if !found {
+		symbol = c.symbols.Global(name)
+	}
+	return symbol, nil
+}
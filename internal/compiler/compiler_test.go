@@ -0,0 +1,120 @@
+package compiler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/parser"
+)
+
+func compile(t *testing.T, input string) (*Bytecode, error) {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+	return New().Compile(program)
+}
+
+func TestMakeAndReadOperands(t *testing.T) {
+	tests := []struct {
+		op       Opcode
+		operands []int
+		bytes    []byte
+	}{
+		{OpConstant, []int{65534}, []byte{byte(OpConstant), 255, 254}},
+		{OpCall, []int{3}, []byte{byte(OpCall), 3}},
+		{OpAdd, nil, []byte{byte(OpAdd)}},
+	}
+
+	for _, tt := range tests {
+		ins := Make(tt.op, tt.operands...)
+		if string(ins) != string(tt.bytes) {
+			t.Errorf("Make(%d): expected %v, got %v", tt.op, tt.bytes, ins)
+		}
+		def, err := Lookup(tt.op)
+		if err != nil {
+			t.Fatal(err)
+		}
+		operands, read := ReadOperands(def, ins[1:])
+		if read != len(tt.bytes)-1 {
+			t.Errorf("%s: read %d bytes, expected %d", def.Name, read, len(tt.bytes)-1)
+		}
+		for i, want := range tt.operands {
+			if operands[i] != want {
+				t.Errorf("%s: operand %d: expected %d, got %d", def.Name, i, want, operands[i])
+			}
+		}
+	}
+}
+
+func TestCompileResolvesSlots(t *testing.T) {
+	bytecode, err := compile(t, `
+var total = 0
+func add(a, b) {
+    var sum = a + b
+    return sum
+}
+total = add(1, 2)
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := strings.Join(bytecode.Globals, ","); got != "total,add" {
+		t.Errorf("unexpected globals: %s", got)
+	}
+
+	var add *CompiledFunction
+	for _, constant := range bytecode.Constants {
+		if fn, ok := constant.(*CompiledFunction); ok && fn.Name == "add" {
+			add = fn
+		}
+	}
+	if add == nil {
+		t.Fatal("function add not found in the constant pool")
+	}
+	if add.NumParams != 2 || add.NumLocals != 3 {
+		t.Errorf("expected 2 params and 3 locals, got %d and %d", add.NumParams, add.NumLocals)
+	}
+
+	expected := `0000 OpGetLocal 0
+0003 OpGetLocal 1
+0006 OpAdd
+0007 OpSetLocal 2
+0010 OpGetLocal 2
+0013 OpReturnValue
+0014 OpReturn
+`
+	if got := add.Instructions.String(); got != expected {
+		t.Errorf("unexpected instructions:\n%s", got)
+	}
+	if span := add.SpanAt(6); span.StartLine != 4 || span.StartCol != 15 {
+		t.Errorf("expected OpAdd at 4:15, got %d:%d", span.StartLine, span.StartCol)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"class A {\n}", "no soporta clases"},
+		{"break", "'break' fuera de un bucle"},
+		{"missing = 1", "variable no definida: missing"},
+		{"func outer() {\n    var x = 1\n    func inner() {\n        return x\n    }\n}", "no soporta closures"},
+		{"var f = x => x * 2", "no soporta funciones anónimas"},
+		{"func f() {\n}\nspawn f()", "no soporta spawn"},
+		{"async func f() {\n}", "no soporta funciones async"},
+		{"var x = await 1", "no soporta await"},
+	}
+
+	for _, tt := range tests {
+		_, err := compile(t, tt.input)
+		if err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("%q: expected error containing %q, got %v", tt.input, tt.expected, err)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package compiler
+
+import "fmt"
+
+// SymbolScope indica dónde vive una variable en tiempo de ejecución.
+type SymbolScope int
+
+const (
+	GlobalScope SymbolScope = iota // Slot del arreglo de globales de la VM
+	LocalScope                     // Slot del marco de la función en curso
+)
+
+// Symbol es una variable resuelta a un slot.
+type Symbol struct {
+	Name  string
+	Scope SymbolScope
+	Index int
+	Type  string // Anotación de tipo, que comprueban las asignaciones
+}
+
+// globalSlots reparte los slots globales de un programa. Los nombres de nivel
+// superior comparten slot; las variables de bloques de nivel superior reciben
+// uno propio aunque se llamen igual.
+type globalSlots struct {
+	names  []string
+	byName map[string]int
+}
+
+func (g *globalSlots) named(name string) int {
+	if index, ok := g.byName[name]; ok {
+		return index
+	}
+	index := g.fresh(name)
+	g.byName[name] = index
+	return index
+}
+
+func (g *globalSlots) fresh(name string) int {
+	g.names = append(g.names, name)
+	return len(g.names) - 1
+}
+
+// functionSlots reparte los slots locales de una función entre todos sus bloques.
+type functionSlots struct {
+	names []string
+}
+
+// SymbolTable es un ámbito léxico: el global, el de una función o el de un bloque.
+type SymbolTable struct {
+	outer   *SymbolTable
+	store   map[string]Symbol
+	globals *globalSlots
+	fn      *functionSlots // nil fuera de las funciones
+}
+
+// NewSymbolTable crea el ámbito global de un programa.
+func NewSymbolTable() *SymbolTable {
+	return &SymbolTable{
+		store:   make(map[string]Symbol),
+		globals: &globalSlots{byName: make(map[string]int)},
+	}
+}
+
+// NewBlockTable crea un ámbito de bloque dentro de outer.
+func NewBlockTable(outer *SymbolTable) *SymbolTable {
+	return &SymbolTable{
+		outer:   outer,
+		store:   make(map[string]Symbol),
+		globals: outer.globals,
+		fn:      outer.fn,
+	}
+}
+
+// NewFunctionTable crea el ámbito del cuerpo de una función declarada en outer.
+func NewFunctionTable(outer *SymbolTable) *SymbolTable {
+	return &SymbolTable{
+		outer:   outer,
+		store:   make(map[string]Symbol),
+		globals: outer.globals,
+		fn:      &functionSlots{},
+	}
+}
+
+// Define declara name en este ámbito. Redeclarar un nombre reutiliza su slot.
+func (s *SymbolTable) Define(name string) Symbol {
+	if symbol, ok := s.store[name]; ok {
+		return symbol
+	}
+
+	var symbol Symbol
+	switch {
+	case s.fn != nil:
+		s.fn.names = append(s.fn.names, name)
+		symbol = Symbol{Name: name, Scope: LocalScope, Index: len(s.fn.names) - 1}
+	case s.outer == nil:
+		symbol = Symbol{Name: name, Scope: GlobalScope, Index: s.globals.named(name)}
+	default:
+		symbol = Symbol{Name: name, Scope: GlobalScope, Index: s.globals.fresh(name)}
+	}
+	s.store[name] = symbol
+	return symbol
+}
+
+// DefineTyped declara name como Define, con la anotación de tipo annotation.
+func (s *SymbolTable) DefineTyped(name, annotation string) Symbol {
+	symbol := s.Define(name)
+	symbol.Type = annotation
+	s.store[name] = symbol
+	return symbol
+}
+
+// Resolve busca name desde este ámbito hacia afuera. Falla si name es un
+// local de otra función, porque la VM no captura variables (closures).
+func (s *SymbolTable) Resolve(name string) (Symbol, bool, error) {
+	for table := s; table != nil; table = table.outer {
+		symbol, ok := table.store[name]
+		if !ok {
+			continue
+		}
+		if symbol.Scope == LocalScope && table.fn != s.fn {
+			return Symbol{}, false, fmt.Errorf("la función no puede usar la variable '%s' de la función que la contiene: el motor vm no soporta closures", name)
+		}
+		return symbol, true, nil
+	}
+	return Symbol{}, false, nil
+}
+
+// Global devuelve el slot global de nivel superior de name, creándolo si no existe.
+func (s *SymbolTable) Global(name string) Symbol {
+	return Symbol{Name: name, Scope: GlobalScope, Index: s.globals.named(name)}
+}
+
+// NumLocals devuelve cuántos slots locales usa la función de este ámbito.
+func (s *SymbolTable) NumLocals() int {
+	if s.fn == nil {
+		return 0
+	}
+	return len(s.fn.names)
+}
+
+// LocalNames devuelve el nombre de cada slot local, para los mensajes de error.
+func (s *SymbolTable) LocalNames() []string {
+	if s.fn == nil {
+		return nil
+	}
+	return s.fn.names
+}
+
+// GlobalNames devuelve el nombre de cada slot global.
+func (s *SymbolTable) GlobalNames() []string {
+	return s.globals.names
+}
@@ -0,0 +1,281 @@
+package dap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+	"github.com/zylo-lang/zylo/internal/evaluator"
+)
+
+// stepMode es lo que hace el programa al continuar.
+type stepMode int
+
+const (
+	stepContinue stepMode = iota // Hasta el próximo breakpoint
+	stepEntry                    // Se detiene en la primera sentencia
+	stepIn                       // Se detiene en la próxima sentencia
+	stepOver                     // En la próxima sentencia del mismo marco o de uno anterior
+	stepOut                      // En la próxima sentencia de un marco anterior
+)
+
+var (
+	errNotRunning    = errors.New("the program is not running")
+	errNotStopped    = errors.New("the program is not stopped")
+	errDisconnected  = errors.New("debugger disconnected")
+	errInvalidFrame  = errors.New("invalid frame id")
+	errInvalidHandle = errors.New("invalid variables reference")
+)
+
+// execution es la ejecución del programa en su propia goroutine. Los hooks
+// del evaluador deciden dónde detenerse; mientras está detenido, el
+// programa espera en resume la orden de continuar del servidor.
+type execution struct {
+	server *Server
+	eval   *evaluator.Evaluator
+	cancel context.CancelFunc
+	resume chan stepMode
+	done   chan struct{} // Se cierra al terminar el programa
+	pause  atomic.Bool   // El cliente pidió detener el programa
+
+	// Estado de la goroutine del programa
+	mode     stepMode
+	depth    int          // Profundidad de la pila al continuar, para los pasos
+	at       location     // Sentencia donde se detuvo por última vez
+	call     string       // Función con breakpoint en la que se acaba de entrar
+	returned *returnValue // Último valor devuelto por una función
+}
+
+// location es la posición de una sentencia en la pila de llamadas.
+type location struct {
+	file      string
+	line, col int
+	depth     int
+}
+
+// returnValue es el resultado de la última función que terminó.
+type returnValue struct {
+	function string
+	value    evaluator.Value
+}
+
+// stop es el estado del programa detenido, con los marcos del más reciente
+// al más antiguo y las referencias que el cliente puede expandir.
+type stop struct {
+	eval     *evaluator.Evaluator
+	frames   []evaluator.Frame
+	returned *returnValue // Valor devuelto justo antes de detenerse en un paso
+	refs     []reference
+}
+
+// start ejecuta el programa en cuanto el cliente lo lanzó y terminó de
+// configurar los breakpoints. El programa no lee la entrada estándar, que
+// es del protocolo, y su salida se envía al cliente como eventos.
+func (s *Server) start() {
+	if s.program == nil || !s.configured || s.run != nil {
+		return
+	}
+	eval := evaluator.NewEvaluatorWithIO(strings.NewReader(""), &output{s, "stdout"}, &output{s, "stderr"})
+	eval.SetFile(s.path)
+	ctx, cancel := context.WithCancel(context.Background())
+	eval.SetContext(ctx)
+
+	x := &execution{
+		server: s,
+		eval:   eval,
+		cancel: cancel,
+		resume: make(chan stepMode),
+		done:   make(chan struct{}),
+	}
+	if s.stopOnEntry {
+		x.mode = stepEntry
+	}
+	eval.SetHooks(evaluator.Hooks{
+		BeforeStatement: x.beforeStatement,
+		OnCall:          x.onCall,
+		OnReturn:        x.onReturn,
+	})
+	s.run = x
+	go x.execute(s.program)
+}
+
+// execute ejecuta program y avisa al cliente cuando termina.
+func (x *execution) execute(program *ast.Program) {
+	defer close(x.done)
+	defer x.cancel()
+	exitCode := 0
+	if err := x.eval.EvaluateProgram(program); err != nil {
+		exitCode = 1
+		var runtimeErr *evaluator.RuntimeError
+		if errors.As(err, &runtimeErr) {
+			x.server.event("output", outputBody{Category: "stderr", Output: runtimeErr.Traceback() + "\n"})
+		} else {
+			x.server.event("output", outputBody{Category: "stderr", Output: err.Error() + "\n"})
+		}
+	}
+	x.server.event("exited", exitedBody{ExitCode: exitCode})
+	x.server.event("terminated", nil)
+}
+
+// terminate detiene el programa si está en ejecución y espera a que
+// termine. Los eventos posteriores ya no se envían.
+func (s *Server) terminate() {
+	s.closing.Store(true)
+	if s.run == nil {
+		return
+	}
+	s.mu.Lock()
+	s.stopped = nil
+	s.mu.Unlock()
+	s.run.cancel()
+	close(s.run.resume)
+	<-s.run.done
+	s.run = nil
+}
+
+// resume continúa el programa detenido con el modo que pide req.
+func (s *Server) resume(req *request) error {
+	modes := map[string]stepMode{"continue": stepContinue, "next": stepOver, "stepIn": stepIn, "stepOut": stepOut}
+	s.mu.Lock()
+	stopped := s.stopped
+	s.stopped = nil
+	s.mu.Unlock()
+	if stopped == nil {
+		return s.respond(req, nil, errNotStopped)
+	}
+
+	var body interface{}
+	if req.Command == "continue" {
+		body = continueBody{AllThreadsContinued: true}
+	}
+	if err := s.respond(req, body, nil); err != nil {
+		return err
+	}
+	s.run.resume <- modes[req.Command]
+	return nil
+}
+
+// beforeStatement decide si el programa se detiene antes de stmt.
+func (x *execution) beforeStatement(e *evaluator.Evaluator, stmt ast.Statement) error {
+	span := ast.SpanOf(stmt)
+	frames := e.Frames()
+	here := location{file: frames[len(frames)-1].File, line: span.StartLine, col: span.StartCol, depth: len(frames)}
+
+	// Las sentencias anidadas en la línea donde se detuvo no vuelven a
+	// detenerlo, como el cuerpo de un 'if' escrito en una sola línea
+	at := x.at
+	if here.file == at.file && here.depth == at.depth && here.line == at.line && here.col > at.col {
+		return nil
+	}
+	x.at = location{}
+	returned := x.returned
+	x.returned = nil
+
+	reason := ""
+	switch {
+	case x.pause.Swap(false):
+		reason = "pause"
+	case x.call != "":
+		reason = "function breakpoint"
+	case x.mode == stepEntry:
+		reason = "entry"
+	case x.mode == stepIn,
+		x.mode == stepOver && here.depth <= x.depth,
+		x.mode == stepOut && here.depth < x.depth:
+		reason = "step"
+	case x.hitsBreakpoint(e, here, frames[len(frames)-1].Env):
+		reason = "breakpoint"
+	default:
+		return nil
+	}
+	x.call = ""
+	if reason != "step" {
+		returned = nil
+	}
+	return x.stop(e, frames, here, reason, returned)
+}
+
+// hitsBreakpoint indica si hay un breakpoint en here cuya condición, si la
+// tiene, es verdadera en env. Si la condición falla se detiene igualmente y
+// se informa el error.
+func (x *execution) hitsBreakpoint(e *evaluator.Evaluator, here location, env *evaluator.Environment) bool {
+	bp, ok := x.server.lineBreakpoint(here.file, here.line)
+	if !ok {
+		return false
+	}
+	return x.holds(e, bp.condition, env)
+}
+
+// holds evalúa la condición de un breakpoint. Una condición vacía siempre
+// se cumple.
+func (x *execution) holds(e *evaluator.Evaluator, condition string, env *evaluator.Environment) bool {
+	if condition == "" {
+		return true
+	}
+	value, err := e.EvaluateIn(env, condition)
+	if err != nil {
+		x.server.event("output", outputBody{
+			Category: "console",
+			Output:   fmt.Sprintf("breakpoint condition %q: %v\n", condition, err),
+		})
+		return true
+	}
+	return e.IsTruthy(value)
+}
+
+// onCall recuerda la entrada en una función con breakpoint para detenerse
+// en su primera sentencia.
+func (x *execution) onCall(e *evaluator.Evaluator, function string, callSite ast.Span) error {
+	bp, ok := x.server.functionBreakpoint(function)
+	if ok {
+		frames := e.Frames()
+		if x.holds(e, bp.condition, frames[len(frames)-1].Env) {
+			x.call = function
+		}
+	}
+	return nil
+}
+
+// onReturn recuerda el valor devuelto, que se muestra al detenerse después
+// de un paso.
+func (x *execution) onReturn(e *evaluator.Evaluator, function string, result evaluator.Value, err error) {
+	if err == nil {
+		x.returned = &returnValue{function: function, value: result}
+	}
+}
+
+// stop detiene el programa en here, avisa al cliente y espera la orden de
+// continuar.
+func (x *execution) stop(e *evaluator.Evaluator, frames []evaluator.Frame, here location, reason string, returned *returnValue) error {
+	stopped := &stop{eval: e, returned: returned}
+	for i := len(frames) - 1; i >= 0; i-- {
+		stopped.frames = append(stopped.frames, frames[i])
+	}
+	x.server.mu.Lock()
+	x.server.stopped = stopped
+	x.server.mu.Unlock()
+	x.server.event("stopped", stoppedBody{Reason: reason, ThreadID: threadID, AllThreadsStopped: true})
+
+	mode, ok := <-x.resume
+	if !ok {
+		return errDisconnected
+	}
+	x.mode, x.depth, x.at = mode, here.depth, here
+	return nil
+}
+
+// output envía lo que escribe el programa como eventos 'output'.
+type output struct {
+	server   *Server
+	category string
+}
+
+func (o *output) Write(p []byte) (int, error) {
+	if err := o.server.event("output", outputBody{Category: o.category, Output: string(p)}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
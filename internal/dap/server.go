@@ -0,0 +1,304 @@
+// Package dap implementa un servidor del Debug Adapter Protocol para Zylo.
+// Ejecuta el programa con el evaluador y lo sigue con sus hooks: breakpoints
+// de línea (también condicionales) y de función, pasos, la pila de llamadas,
+// las variables de cada marco y la evaluación de expresiones en un marco.
+package dap
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+	"github.com/zylo-lang/zylo/internal/modules"
+)
+
+// threadID es el único hilo que ve el cliente: la tarea principal.
+const threadID = 1
+
+// Server es un servidor DAP conectado a un cliente por in y out. Depura un
+// único programa por sesión.
+type Server struct {
+	in  *bufio.Reader
+	out io.Writer
+
+	writing sync.Mutex  // Serializa los mensajes del servidor y de la ejecución
+	seq     int         // Número del último mensaje enviado
+	closing atomic.Bool // El cliente se desconectó: los eventos se descartan
+
+	program     *ast.Program // Programa lanzado, nil hasta 'launch'
+	path        string       // Ruta absoluta del programa
+	stopOnEntry bool
+	configured  bool // Se recibió 'configurationDone'
+	run         *execution
+
+	mu          sync.Mutex // Protege lo que sigue, compartido con la ejecución
+	breakpoints map[string][]breakpoint
+	functions   map[string]breakpoint
+	nextID      int   // Último ID de breakpoint asignado
+	stopped     *stop // Estado del programa detenido; nil mientras corre
+}
+
+// breakpoint es un breakpoint de línea o de función.
+type breakpoint struct {
+	line      int
+	condition string
+}
+
+// errDisconnect termina Run después de responder a 'disconnect'.
+var errDisconnect = errors.New("disconnect")
+
+// NewServer crea un servidor que lee las peticiones de in y escribe las
+// respuestas y los eventos en out.
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return &Server{
+		in:          bufio.NewReader(in),
+		out:         out,
+		breakpoints: make(map[string][]breakpoint),
+		functions:   make(map[string]breakpoint),
+	}
+}
+
+// Run atiende las peticiones hasta que el cliente envía 'disconnect' o
+// cierra la entrada, y entonces termina el programa si sigue en ejecución.
+// Devuelve un error si la conexión falla.
+func (s *Server) Run() error {
+	defer s.terminate()
+	for {
+		body, err := readMessage(s.in)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			return fmt.Errorf("invalid message: %w", err)
+		}
+		if err := s.handle(&req); err != nil {
+			if err == errDisconnect {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// handle atiende una petición.
+func (s *Server) handle(req *request) error {
+	var body interface{}
+	var err error
+	switch req.Command {
+	case "initialize":
+		body = capabilities{
+			SupportsConfigurationDoneRequest: true,
+			SupportsConditionalBreakpoints:   true,
+			SupportsFunctionBreakpoints:      true,
+			SupportsEvaluateForHovers:        true,
+		}
+		if err := s.respond(req, body, nil); err != nil {
+			return err
+		}
+		// El cliente envía los breakpoints al recibir 'initialized'
+		return s.event("initialized", nil)
+	case "launch":
+		err = s.launch(req.Arguments)
+	case "setBreakpoints":
+		body, err = s.setBreakpoints(req.Arguments)
+	case "setFunctionBreakpoints":
+		body, err = s.setFunctionBreakpoints(req.Arguments)
+	case "configurationDone":
+		s.configured = true
+	case "threads":
+		body = threadsBody{Threads: []Thread{{ID: threadID, Name: "main"}}}
+	case "stackTrace":
+		body, err = s.stackTrace()
+	case "scopes":
+		var args frameArguments
+		if err = json.Unmarshal(req.Arguments, &args); err == nil {
+			body, err = s.scopes(args.FrameID)
+		}
+	case "variables":
+		var args variablesArguments
+		if err = json.Unmarshal(req.Arguments, &args); err == nil {
+			body, err = s.variables(args.VariablesReference)
+		}
+	case "evaluate":
+		var args evaluateArguments
+		if err = json.Unmarshal(req.Arguments, &args); err == nil {
+			body, err = s.evaluate(args)
+		}
+	case "continue", "next", "stepIn", "stepOut":
+		return s.resume(req)
+	case "pause":
+		if s.run == nil {
+			err = errNotRunning
+		} else {
+			s.run.pause.Store(true)
+		}
+	case "disconnect":
+		s.terminate()
+		if err := s.respond(req, nil, nil); err != nil {
+			return err
+		}
+		return errDisconnect
+	default:
+		err = fmt.Errorf("unsupported command: %s", req.Command)
+	}
+	if err := s.respond(req, body, err); err != nil {
+		return err
+	}
+	if req.Command == "launch" || req.Command == "configurationDone" {
+		s.start()
+	}
+	return nil
+}
+
+// launch carga el programa que pide el cliente. Se ejecuta cuando llegan
+// tanto 'launch' como 'configurationDone'.
+func (s *Server) launch(raw json.RawMessage) error {
+	if s.program != nil {
+		return fmt.Errorf("a program was already launched")
+	}
+	var args launchArguments
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return err
+	}
+	if args.Program == "" {
+		return fmt.Errorf("missing program to debug")
+	}
+	path, err := filepath.Abs(args.Program)
+	if err != nil {
+		return err
+	}
+	program, err := modules.Parse(path)
+	if err != nil {
+		return err
+	}
+	s.program, s.path, s.stopOnEntry = program, path, args.StopOnEntry
+	return nil
+}
+
+// setBreakpoints reemplaza los breakpoints de línea de un archivo. Solo se
+// verifican los que están en una línea donde empieza una sentencia.
+func (s *Server) setBreakpoints(raw json.RawMessage) (interface{}, error) {
+	var args setBreakpointsArguments
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil, err
+	}
+	path, err := filepath.Abs(args.Source.Path)
+	if err != nil {
+		return nil, err
+	}
+	lines := statementLines(path)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]Breakpoint, 0, len(args.Breakpoints))
+	var breakpoints []breakpoint
+	for _, bp := range args.Breakpoints {
+		s.nextID++
+		status := Breakpoint{ID: s.nextID, Verified: true, Line: bp.Line}
+		if lines != nil && !lines[bp.Line] {
+			status.Verified = false
+			status.Message = "no statement starts on this line"
+		} else {
+			breakpoints = append(breakpoints, breakpoint{line: bp.Line, condition: bp.Condition})
+		}
+		result = append(result, status)
+	}
+	s.breakpoints[path] = breakpoints
+	return breakpointsBody{Breakpoints: result}, nil
+}
+
+// setFunctionBreakpoints reemplaza los breakpoints de función.
+func (s *Server) setFunctionBreakpoints(raw json.RawMessage) (interface{}, error) {
+	var args setFunctionBreakpointsArguments
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]Breakpoint, 0, len(args.Breakpoints))
+	s.functions = make(map[string]breakpoint)
+	for _, bp := range args.Breakpoints {
+		s.nextID++
+		s.functions[bp.Name] = breakpoint{condition: bp.Condition}
+		result = append(result, Breakpoint{ID: s.nextID, Verified: true})
+	}
+	return breakpointsBody{Breakpoints: result}, nil
+}
+
+// statementLines devuelve las líneas de path donde empieza una sentencia, o
+// nil si no se puede analizar.
+func statementLines(path string) map[int]bool {
+	program, err := modules.Parse(path)
+	if err != nil {
+		return nil
+	}
+	lines := make(map[int]bool)
+	ast.Inspect(program, func(node ast.Node) bool {
+		if _, ok := node.(*ast.BlockStatement); ok {
+			return true
+		}
+		if stmt, ok := node.(ast.Statement); ok {
+			if line := ast.SpanOf(stmt).StartLine; line > 0 {
+				lines[line] = true
+			}
+		}
+		return true
+	})
+	return lines
+}
+
+// lineBreakpoint devuelve el breakpoint de la línea line de file.
+func (s *Server) lineBreakpoint(file string, line int) (breakpoint, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, bp := range s.breakpoints[file] {
+		if bp.line == line {
+			return bp, true
+		}
+	}
+	return breakpoint{}, false
+}
+
+// functionBreakpoint devuelve el breakpoint de la función function.
+func (s *Server) functionBreakpoint(function string) (breakpoint, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bp, ok := s.functions[function]
+	return bp, ok
+}
+
+// respond responde a req con body, o con el mensaje de err si falló.
+func (s *Server) respond(req *request, body interface{}, err error) error {
+	resp := response{Type: "response", RequestSeq: req.Seq, Success: err == nil, Command: req.Command, Body: body}
+	if err != nil {
+		resp.Body, resp.Message = nil, err.Error()
+	}
+	s.writing.Lock()
+	defer s.writing.Unlock()
+	s.seq++
+	resp.Seq = s.seq
+	return writeMessage(s.out, resp)
+}
+
+// event envía un evento al cliente. Después de 'disconnect' no envía nada.
+func (s *Server) event(name string, body interface{}) error {
+	if s.closing.Load() {
+		return nil
+	}
+	s.writing.Lock()
+	defer s.writing.Unlock()
+	s.seq++
+	return writeMessage(s.out, event{Seq: s.seq, Type: "event", Event: name, Body: body})
+}
@@ -0,0 +1,370 @@
+package dap
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// message es cualquier mensaje del servidor: una respuesta o un evento.
+type message struct {
+	Type       string          `json:"type"`
+	RequestSeq int             `json:"request_seq"`
+	Success    bool            `json:"success"`
+	Command    string          `json:"command"`
+	Message    string          `json:"message"`
+	Event      string          `json:"event"`
+	Body       json.RawMessage `json:"body"`
+}
+
+// client es un cliente DAP con guion: envía peticiones al servidor y lee
+// sus respuestas y eventos como lo haría un editor.
+type client struct {
+	t      *testing.T
+	in     *io.PipeWriter
+	out    *bufio.Reader
+	seq    int
+	events []message // Eventos leídos mientras se esperaba una respuesta
+	done   chan error
+}
+
+func startServer(t *testing.T) *client {
+	t.Helper()
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	c := &client{t: t, in: clientOut, out: bufio.NewReader(clientIn), done: make(chan error, 1)}
+	go func() {
+		err := NewServer(serverIn, serverOut).Run()
+		serverOut.Close()
+		c.done <- err
+	}()
+	t.Cleanup(func() { clientOut.Close() })
+	c.request("initialize", map[string]string{"adapterID": "zylo"}, nil)
+	c.event("initialized")
+	return c
+}
+
+func (c *client) read() message {
+	c.t.Helper()
+	body, err := readMessage(c.out)
+	if err != nil {
+		c.t.Fatalf("read: %v", err)
+	}
+	var msg message
+	if err := json.Unmarshal(body, &msg); err != nil {
+		c.t.Fatalf("invalid message %s: %v", body, err)
+	}
+	return msg
+}
+
+// send envía una petición y devuelve su respuesta. Los eventos que lleguen
+// antes se guardan.
+func (c *client) send(command string, args interface{}) message {
+	c.t.Helper()
+	c.seq++
+	req := map[string]interface{}{"seq": c.seq, "type": "request", "command": command, "arguments": args}
+	if err := writeMessage(c.in, req); err != nil {
+		c.t.Fatalf("write: %v", err)
+	}
+	for {
+		msg := c.read()
+		if msg.Type == "event" {
+			c.events = append(c.events, msg)
+			continue
+		}
+		if msg.RequestSeq != c.seq || msg.Command != command {
+			c.t.Fatalf("expected the response to %s, got %+v", command, msg)
+		}
+		return msg
+	}
+}
+
+// request envía una petición que debe tener éxito y decodifica el cuerpo de
+// su respuesta en body.
+func (c *client) request(command string, args interface{}, body interface{}) {
+	c.t.Helper()
+	msg := c.send(command, args)
+	if !msg.Success {
+		c.t.Fatalf("%s failed: %s", command, msg.Message)
+	}
+	if body != nil {
+		if err := json.Unmarshal(msg.Body, body); err != nil {
+			c.t.Fatalf("invalid %s body %s: %v", command, msg.Body, err)
+		}
+	}
+}
+
+// event espera el siguiente evento name y devuelve su cuerpo. Los eventos
+// 'output' anteriores se descartan.
+func (c *client) event(name string) json.RawMessage {
+	c.t.Helper()
+	for {
+		var msg message
+		if len(c.events) > 0 {
+			msg, c.events = c.events[0], c.events[1:]
+		} else {
+			msg = c.read()
+		}
+		if msg.Type != "event" {
+			c.t.Fatalf("expected event %s, got %+v", name, msg)
+		}
+		if msg.Event == name {
+			return msg.Body
+		}
+		if msg.Event != "output" {
+			c.t.Fatalf("expected event %s, got %s", name, msg.Event)
+		}
+	}
+}
+
+// stopped espera a que el programa se detenga y devuelve el motivo y la
+// función y la línea del marco más reciente.
+func (c *client) stopped() (reason, function string, line int) {
+	c.t.Helper()
+	var body stoppedBody
+	json.Unmarshal(c.event("stopped"), &body)
+	var trace stackTraceBody
+	c.request("stackTrace", map[string]int{"threadId": threadID}, &trace)
+	return body.Reason, trace.StackFrames[0].Name, trace.StackFrames[0].Line
+}
+
+// variables devuelve las variables de un ámbito o un valor por nombre.
+func (c *client) variables(ref int) map[string]Variable {
+	c.t.Helper()
+	var body variablesBody
+	c.request("variables", map[string]int{"variablesReference": ref}, &body)
+	vars := make(map[string]Variable)
+	for _, v := range body.Variables {
+		vars[v.Name] = v
+	}
+	return vars
+}
+
+// scopes devuelve los ámbitos de un marco por nombre.
+func (c *client) scopes(frameID int) map[string]int {
+	c.t.Helper()
+	var body scopesBody
+	c.request("scopes", map[string]int{"frameId": frameID}, &body)
+	scopes := make(map[string]int)
+	for _, scope := range body.Scopes {
+		scopes[scope.Name] = scope.VariablesReference
+	}
+	return scopes
+}
+
+func (c *client) evaluate(expression string, frameID int) string {
+	c.t.Helper()
+	var body evaluateBody
+	c.request("evaluate", map[string]interface{}{"expression": expression, "frameId": frameID, "context": "repl"}, &body)
+	return body.Result
+}
+
+const source = `func area(w, h) {
+    var a = w * h
+    return a
+}
+var total = 0
+var sizes = [1, 2, 3]
+var i = 0
+while i < len(sizes) {
+    total = total + area(sizes[i], 2)
+    i = i + 1
+}
+show.log(total)
+`
+
+func writeProgram(t *testing.T, source string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "areas.zylo")
+	if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestBreakpointsAndVariables(t *testing.T) {
+	path := writeProgram(t, source)
+	c := startServer(t)
+	c.request("launch", map[string]string{"program": path}, nil)
+
+	var bps breakpointsBody
+	c.request("setBreakpoints", map[string]interface{}{
+		"source":      Source{Path: path},
+		"breakpoints": []SourceBreakpoint{{Line: 2, Condition: "w == 2"}, {Line: 4}},
+	}, &bps)
+	if len(bps.Breakpoints) != 2 || !bps.Breakpoints[0].Verified || bps.Breakpoints[1].Verified {
+		t.Fatalf("expected only the breakpoint on line 2 to be verified, got %+v", bps.Breakpoints)
+	}
+	c.request("configurationDone", nil, nil)
+
+	// La condición se cumple en la segunda llamada
+	if reason, function, line := c.stopped(); reason != "breakpoint" || function != "area" || line != 2 {
+		t.Fatalf("expected to stop at the breakpoint in area, got %s in %s:%d", reason, function, line)
+	}
+	var trace stackTraceBody
+	c.request("stackTrace", map[string]int{"threadId": threadID}, &trace)
+	if len(trace.StackFrames) != 2 || trace.StackFrames[1].Name != "<programa>" || trace.StackFrames[1].Line != 9 {
+		t.Errorf("expected area called from line 9, got %+v", trace.StackFrames)
+	}
+	if source := trace.StackFrames[0].Source; source == nil || source.Path != path {
+		t.Errorf("expected frames in %s, got %+v", path, source)
+	}
+
+	locals := c.variables(c.scopes(1)["Locales"])
+	if locals["w"].Value != "2" || locals["h"].Value != "2" || locals["w"].Type != "int" {
+		t.Errorf("unexpected locals %+v", locals)
+	}
+	globals := c.variables(c.scopes(1)["Globales"])
+	if globals["total"].Value != "2" || globals["i"].Value != "1" {
+		t.Errorf("unexpected globals %+v", globals)
+	}
+	if _, ok := globals["show.log"]; ok {
+		t.Errorf("expected the globals without built-ins")
+	}
+	sizes := globals["sizes"]
+	if sizes.VariablesReference == 0 {
+		t.Fatalf("expected sizes to be expandable, got %+v", sizes)
+	}
+	if items := c.variables(sizes.VariablesReference); len(items) != 3 || items["[2]"].Value != "3" {
+		t.Errorf("unexpected items of sizes %+v", items)
+	}
+
+	if got := c.evaluate("w * h + total", 1); got != "6" {
+		t.Errorf("expected w * h + total to be 6 in area, got %s", got)
+	}
+	if got := c.evaluate("sizes[i]", 2); got != "2" {
+		t.Errorf("expected sizes[i] to be 2 in the caller, got %s", got)
+	}
+	if msg := c.send("evaluate", map[string]interface{}{"expression": "nope", "frameId": 1}); msg.Success {
+		t.Errorf("expected an unknown identifier to fail, got %+v", msg)
+	}
+
+	c.request("continue", map[string]int{"threadId": threadID}, nil)
+	var output outputBody
+	json.Unmarshal(c.event("output"), &output)
+	if output.Category != "stdout" || output.Output != "12\n" {
+		t.Errorf("expected the program output, got %+v", output)
+	}
+	var exited exitedBody
+	json.Unmarshal(c.event("exited"), &exited)
+	if exited.ExitCode != 0 {
+		t.Errorf("expected exit code 0, got %d", exited.ExitCode)
+	}
+	c.event("terminated")
+
+	c.request("disconnect", nil, nil)
+	if err := <-c.done; err != nil {
+		t.Errorf("expected a clean exit, got %v", err)
+	}
+}
+
+func TestStepping(t *testing.T) {
+	path := writeProgram(t, source)
+	c := startServer(t)
+	c.request("launch", map[string]interface{}{"program": path, "stopOnEntry": true}, nil)
+	c.request("configurationDone", nil, nil)
+
+	if reason, _, line := c.stopped(); reason != "entry" || line != 1 {
+		t.Fatalf("expected to stop on entry at line 1, got %s at %d", reason, line)
+	}
+	steps := []struct {
+		command  string
+		function string
+		line     int
+	}{
+		{"next", "<programa>", 5},
+		{"next", "<programa>", 6},
+		{"next", "<programa>", 7},
+		{"next", "<programa>", 8},
+		{"stepIn", "<programa>", 9},
+		{"stepIn", "area", 2},
+		{"next", "area", 3},
+		{"stepOut", "<programa>", 10},
+		{"next", "<programa>", 9},
+		{"next", "<programa>", 10},
+	}
+	for _, step := range steps {
+		c.request(step.command, map[string]int{"threadId": threadID}, nil)
+		reason, function, line := c.stopped()
+		if reason != "step" || function != step.function || line != step.line {
+			t.Fatalf("%s: expected to stop in %s:%d, got %s in %s:%d", step.command, step.function, step.line, reason, function, line)
+		}
+		if step.command == "stepOut" {
+			// Después de salir se ve lo que devolvió la función
+			locals := c.variables(c.scopes(1)["Locales"])
+			if got := locals["(retorno de area)"]; got.Value != "2" {
+				t.Errorf("expected the value returned by area, got %+v", locals)
+			}
+		}
+	}
+
+	// Salir del nivel superior ejecuta el resto del programa
+	c.request("stepOut", map[string]int{"threadId": threadID}, nil)
+	c.event("exited")
+	c.event("terminated")
+}
+
+func TestFunctionBreakpoints(t *testing.T) {
+	path := writeProgram(t, source)
+	c := startServer(t)
+	c.request("launch", map[string]string{"program": path}, nil)
+	c.request("setFunctionBreakpoints", map[string]interface{}{
+		"breakpoints": []FunctionBreakpoint{{Name: "area", Condition: "w == 3"}},
+	}, nil)
+	c.request("configurationDone", nil, nil)
+
+	if reason, function, line := c.stopped(); reason != "function breakpoint" || function != "area" || line != 2 {
+		t.Fatalf("expected to stop entering area, got %s in %s:%d", reason, function, line)
+	}
+	if got := c.evaluate("w", 0); got != "3" {
+		t.Errorf("expected the third call, got w = %s", got)
+	}
+
+	// Desconectarse con el programa detenido lo termina
+	c.request("disconnect", nil, nil)
+	if err := <-c.done; err != nil {
+		t.Errorf("expected a clean exit, got %v", err)
+	}
+}
+
+func TestRuntimeError(t *testing.T) {
+	path := writeProgram(t, "func dividir(a, b) {\n    return a / b\n}\nshow.log(dividir(1, 0))\n")
+	c := startServer(t)
+	if msg := c.send("threads", nil); !msg.Success {
+		t.Errorf("expected threads before launching, got %+v", msg)
+	}
+	if msg := c.send("continue", map[string]int{"threadId": threadID}); msg.Success {
+		t.Errorf("expected continue to fail before launching")
+	}
+	c.request("launch", map[string]string{"program": path}, nil)
+	c.request("configurationDone", nil, nil)
+
+	var output outputBody
+	json.Unmarshal(c.event("output"), &output)
+	if output.Category != "stderr" || !strings.Contains(output.Output, "en dividir") {
+		t.Errorf("expected the traceback, got %+v", output)
+	}
+	var exited exitedBody
+	json.Unmarshal(c.event("exited"), &exited)
+	if exited.ExitCode != 1 {
+		t.Errorf("expected exit code 1, got %d", exited.ExitCode)
+	}
+}
+
+func TestLaunchErrors(t *testing.T) {
+	c := startServer(t)
+	if msg := c.send("launch", map[string]string{"program": filepath.Join(t.TempDir(), "missing.zylo")}); msg.Success {
+		t.Errorf("expected launching a missing program to fail")
+	}
+	path := writeProgram(t, "var x = (1 +\n")
+	if msg := c.send("launch", map[string]string{"program": path}); msg.Success || !strings.Contains(msg.Message, "parsing") {
+		t.Errorf("expected a parse error, got %+v", msg)
+	}
+	if msg := c.send("restartFrame", nil); msg.Success {
+		t.Errorf("expected an unsupported command to fail")
+	}
+}
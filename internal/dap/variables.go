@@ -0,0 +1,256 @@
+package dap
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zylo-lang/zylo/internal/evaluator"
+)
+
+// reference es lo que el cliente puede expandir con 'variables': un ámbito
+// de un marco o un valor con elementos.
+type reference struct {
+	scope    *evaluator.Environment // Ámbito: sus variables y las de sus padres
+	global   bool                   // El ámbito es el global, sin los built-ins
+	returned *returnValue           // Valor devuelto que se muestra entre las locales
+	value    evaluator.Value
+}
+
+// current devuelve el estado del programa detenido.
+func (s *Server) current() (*stop, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped == nil {
+		return nil, errNotStopped
+	}
+	return s.stopped, nil
+}
+
+// frame devuelve el marco con el ID id: 1 es el más reciente.
+func (st *stop) frame(id int) (evaluator.Frame, error) {
+	if id < 1 || id > len(st.frames) {
+		return evaluator.Frame{}, errInvalidFrame
+	}
+	return st.frames[id-1], nil
+}
+
+// reference registra ref y devuelve el número con el que el cliente la pide.
+// Los números valen hasta que el programa continúa.
+func (st *stop) reference(ref reference) int {
+	st.refs = append(st.refs, ref)
+	return len(st.refs)
+}
+
+func (s *Server) stackTrace() (interface{}, error) {
+	st, err := s.current()
+	if err != nil {
+		return nil, err
+	}
+	frames := make([]StackFrame, len(st.frames))
+	for i, frame := range st.frames {
+		frames[i] = StackFrame{
+			ID:     i + 1,
+			Name:   frame.Function,
+			Line:   frame.Span.StartLine,
+			Column: frame.Span.StartCol,
+		}
+		if frame.File != "" {
+			frames[i].Source = &Source{Name: filepath.Base(frame.File), Path: frame.File}
+		}
+	}
+	return stackTraceBody{StackFrames: frames, TotalFrames: len(frames)}, nil
+}
+
+// scopes devuelve los ámbitos de un marco: las variables locales, que
+// recorren la cadena de entornos hasta el global, y las globales.
+func (s *Server) scopes(frameID int) (interface{}, error) {
+	st, err := s.current()
+	if err != nil {
+		return nil, err
+	}
+	frame, err := st.frame(frameID)
+	if err != nil {
+		return nil, err
+	}
+
+	var scopes []Scope
+	local := reference{scope: frame.Env}
+	if frameID == 1 {
+		local.returned = st.returned
+	}
+	if frame.Env.Parent() != nil || local.returned != nil {
+		scopes = append(scopes, Scope{Name: "Locales", VariablesReference: st.reference(local)})
+	}
+	global := frame.Env
+	for global.Parent() != nil {
+		global = global.Parent()
+	}
+	scopes = append(scopes, Scope{Name: "Globales", VariablesReference: st.reference(reference{scope: global, global: true})})
+	return scopesBody{Scopes: scopes}, nil
+}
+
+func (s *Server) variables(id int) (interface{}, error) {
+	st, err := s.current()
+	if err != nil {
+		return nil, err
+	}
+	if id < 1 || id > len(st.refs) {
+		return nil, errInvalidHandle
+	}
+	ref := st.refs[id-1]
+
+	variables := []Variable{}
+	switch {
+	case ref.global:
+		for _, name := range ref.scope.Names() {
+			value, _ := ref.scope.Get(name)
+			if _, ok := value.(*evaluator.BuiltinFunction); !ok {
+				variables = append(variables, st.variable(name, value))
+			}
+		}
+	case ref.scope != nil:
+		if ref.returned != nil {
+			variables = append(variables, st.variable("(retorno de "+ref.returned.function+")", ref.returned.value))
+		}
+		// Un nombre de un entorno interior oculta al de sus padres
+		seen := make(map[string]bool)
+		var locals []Variable
+		for env := ref.scope; env != nil && env.Parent() != nil; env = env.Parent() {
+			for _, name := range env.Names() {
+				if !seen[name] {
+					seen[name] = true
+					value, _ := env.Get(name)
+					locals = append(locals, st.variable(name, value))
+				}
+			}
+		}
+		sort.Slice(locals, func(i, j int) bool { return locals[i].Name < locals[j].Name })
+		variables = append(variables, locals...)
+	default:
+		variables = st.children(ref.value)
+	}
+	return variablesBody{Variables: variables}, nil
+}
+
+// variable describe value con el nombre name.
+func (st *stop) variable(name string, value evaluator.Value) Variable {
+	v := Variable{Name: name, Value: display(value), Type: typeName(value)}
+	if hasChildren(value) {
+		v.VariablesReference = st.reference(reference{value: value})
+	}
+	return v
+}
+
+// children devuelve los elementos de una lista o un map, o los atributos de
+// una instancia.
+func (st *stop) children(value evaluator.Value) []Variable {
+	var variables []Variable
+	switch v := value.(type) {
+	case *evaluator.List:
+		for i, item := range v.Elements() {
+			variables = append(variables, st.variable(fmt.Sprintf("[%d]", i), item))
+		}
+	case *evaluator.Hash:
+		pairs := v.Entries()
+		for _, key := range sortedKeys(pairs) {
+			variables = append(variables, st.variable(key, pairs[key]))
+		}
+	case *evaluator.ZyloInstance:
+		fields := v.FieldEntries()
+		for _, name := range sortedKeys(fields) {
+			variables = append(variables, st.variable(name, fields[name]))
+		}
+	}
+	return variables
+}
+
+func hasChildren(value evaluator.Value) bool {
+	switch v := value.(type) {
+	case *evaluator.List:
+		return v.Len() > 0
+	case *evaluator.Hash:
+		return v.Len() > 0
+	case *evaluator.ZyloInstance:
+		return len(v.FieldEntries()) > 0
+	}
+	return false
+}
+
+func sortedKeys(values map[string]evaluator.Value) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// evaluate evalúa una expresión en un marco del programa detenido, el más
+// reciente si el cliente no indica ninguno.
+func (s *Server) evaluate(args evaluateArguments) (interface{}, error) {
+	st, err := s.current()
+	if err != nil {
+		return nil, err
+	}
+	id := args.FrameID
+	if id == 0 {
+		id = 1
+	}
+	frame, err := st.frame(id)
+	if err != nil {
+		return nil, err
+	}
+	value, err := st.eval.EvaluateIn(frame.Env, args.Expression)
+	if err != nil {
+		return nil, err
+	}
+	result := st.variable("", value)
+	return evaluateBody{Result: result.Value, Type: result.Type, VariablesReference: result.VariablesReference}, nil
+}
+
+// display muestra un valor como en el código: los strings van entre
+// comillas.
+func display(value evaluator.Value) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case *evaluator.String:
+		return strconv.Quote(v.Value)
+	case evaluator.ZyloObject:
+		return v.Inspect()
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// typeName devuelve el tipo de un valor con los nombres de los tipos de
+// Zylo.
+func typeName(value evaluator.Value) string {
+	switch v := value.(type) {
+	case nil, *evaluator.Null:
+		return "null"
+	case *evaluator.Boolean:
+		return "bool"
+	case *evaluator.Integer:
+		return "int"
+	case *evaluator.Float:
+		return "float"
+	case *evaluator.String:
+		return "string"
+	case *evaluator.List:
+		return "list"
+	case *evaluator.Hash:
+		return "map"
+	case *evaluator.ZyloFunction, *evaluator.BoundMethod, *evaluator.BuiltinFunction:
+		return "func"
+	case *evaluator.ZyloClass:
+		return "class " + v.Name
+	case *evaluator.ZyloInstance:
+		return v.Class.Name
+	case evaluator.ZyloObject:
+		return strings.ToLower(strings.TrimSuffix(v.Type(), "_OBJ"))
+	}
+	return fmt.Sprintf("%T", value)
+}
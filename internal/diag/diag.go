@@ -0,0 +1,105 @@
+// Package diag muestra los errores del parser, de sema y del evaluador como
+// los compiladores: la posición, la línea del código fuente con la región
+// del error subrayada y, si las hay, notas y una sugerencia. También los
+// escribe en JSON y en SARIF para otras herramientas.
+package diag
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+)
+
+// Severity es la gravedad de un diagnóstico. El valor cero es Error.
+type Severity int
+
+const (
+	Error Severity = iota
+	Warning
+	Note
+)
+
+// severityNames son los nombres de las gravedades en JSON, en SARIF y en
+// --fail-on.
+var severityNames = []string{"error", "warning", "note"}
+
+func (s Severity) String() string {
+	if s < Error || s > Note {
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+	return severityNames[s]
+}
+
+// ParseSeverity devuelve la gravedad con el nombre name.
+func ParseSeverity(name string) (Severity, error) {
+	for i, severity := range severityNames {
+		if name == severity {
+			return Severity(i), nil
+		}
+	}
+	return Error, fmt.Errorf("unknown severity %q (expected error, warning or note)", name)
+}
+
+// AtLeast indica si s es tan grave como threshold o más.
+func (s Severity) AtLeast(threshold Severity) bool {
+	return s <= threshold
+}
+
+// Diagnostic es un error, un aviso o una nota en una región de un archivo.
+type Diagnostic struct {
+	File       string   // "" si el código no viene de un archivo
+	Span       ast.Span // Sin posición si StartLine es 0
+	Severity   Severity
+	Code       string // Código estable del diagnóstico, como "P003"
+	Message    string
+	Notes      []string // Información adicional, una línea por nota
+	Suggestion string   // Un nombre parecido al que se escribió, o ""
+}
+
+// Suggest devuelve el candidato más parecido a name, si se parece lo
+// suficiente para que name sea una errata: la distancia de edición no pasa
+// de un tercio de la longitud de name (al menos 1). Con varios candidatos a
+// la misma distancia devuelve el primero en orden alfabético.
+func Suggest(name string, candidates []string) (string, bool) {
+	limit := len([]rune(name)) / 3
+	if limit < 1 {
+		limit = 1
+	}
+	sorted := append([]string(nil), candidates...)
+	sort.Strings(sorted)
+
+	best, bestDistance := "", limit+1
+	for _, candidate := range sorted {
+		if candidate == name {
+			continue
+		}
+		if d := Distance(name, candidate); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best, best != ""
+}
+
+// Distance devuelve la distancia de Levenshtein entre a y b: cuántas
+// inserciones, borrados o sustituciones de runas convierten una en otra.
+func Distance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	prev := make([]int, len(t)+1)
+	cur := make([]int, len(t)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		cur[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(t)]
+}
@@ -0,0 +1,300 @@
+package diag
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"show.lg", "show.log", 1},
+		{"cuenat", "cuenta", 2},
+		{"kitten", "sitting", 3},
+		{"año", "ano", 1},
+	}
+	for _, tt := range tests {
+		if got := Distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("Distance(%q, %q) = %d, expected %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"show.log", "read.line", "len", "cuenta", "total", "tota"}
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"show.lg", "show.log"},
+		{"cuenat", "cuenta"},
+		{"lem", "len"},
+		{"totl", "tota"}, // Empate a distancia 1: el primero en orden alfabético
+		{"x", ""},
+		{"undefined", ""},
+	}
+	for _, tt := range tests {
+		got, ok := Suggest(tt.name, candidates)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("Suggest(%q) = %q, %v, expected %q", tt.name, got, ok, tt.want)
+		}
+	}
+}
+
+func TestPrint(t *testing.T) {
+	source := "var x = 1\n\tshow.lg(x)\nfunc f() {\n"
+	tests := []struct {
+		name string
+		d    Diagnostic
+		want string
+	}{
+		{
+			name: "token with code and suggestion",
+			d: Diagnostic{
+				File:       "main.zylo",
+				Span:       ast.Span{StartLine: 2, StartCol: 2, EndLine: 2, EndCol: 8},
+				Code:       "S001",
+				Message:    "identifier not found: show.lg",
+				Suggestion: "show.log",
+			},
+			want: "main.zylo:2:2: error[S001]: identifier not found: show.lg\n" +
+				"  |\n" +
+				"2 |     show.lg(x)\n" +
+				"  |     ^^^^^^^\n" +
+				"  = ayuda: ¿quisiste decir `show.log`?\n\n",
+		},
+		{
+			name: "span over several lines",
+			d: Diagnostic{
+				File:    "main.zylo",
+				Span:    ast.Span{StartLine: 1, StartCol: 9, EndLine: 2, EndCol: 3},
+				Message: "m",
+				Notes:   []string{"una nota"},
+			},
+			want: "main.zylo:1:9: error: m\n" +
+				"  |\n" +
+				"1 | var x = 1\n" +
+				"  |         ^\n" +
+				"  = nota: una nota\n\n",
+		},
+		{
+			name: "end of file",
+			d:    Diagnostic{File: "main.zylo", Span: ast.Span{StartLine: 4, StartCol: 1, EndLine: 4, EndCol: 1}, Message: "m"},
+			want: "main.zylo:4:1: error: m\n" +
+				"  |\n" +
+				"4 | \n" +
+				"  | ^\n\n",
+		},
+		{
+			name: "line outside the source",
+			d:    Diagnostic{File: "main.zylo", Span: ast.Span{StartLine: 9, StartCol: 1}, Message: "m"},
+			want: "main.zylo:9:1: error: m\n\n",
+		},
+		{
+			name: "without position",
+			d:    Diagnostic{Message: "m"},
+			want: "error: m\n\n",
+		},
+	}
+	for _, tt := range tests {
+		var out strings.Builder
+		printer := NewPrinter(&out, false)
+		printer.SetSource("main.zylo", source)
+		printer.Print(tt.d)
+		if out.String() != tt.want {
+			t.Errorf("%s: expected\n%s\ngot\n%s", tt.name, tt.want, out.String())
+		}
+	}
+}
+
+func TestPrintColor(t *testing.T) {
+	var out strings.Builder
+	printer := NewPrinter(&out, true)
+	printer.SetSource("main.zylo", "var x = y\n")
+	printer.Print(Diagnostic{File: "main.zylo", Span: ast.Span{StartLine: 1, StartCol: 9, EndLine: 1, EndCol: 9}, Message: "m"})
+	if !strings.Contains(out.String(), red+"error"+reset) || !strings.Contains(out.String(), red+"^"+reset) {
+		t.Errorf("expected colored output, got %q", out.String())
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	for _, severity := range []Severity{Error, Warning, Note} {
+		got, err := ParseSeverity(severity.String())
+		if err != nil || got != severity {
+			t.Errorf("ParseSeverity(%q) = %v, %v", severity.String(), got, err)
+		}
+	}
+	if _, err := ParseSeverity("fatal"); err == nil {
+		t.Errorf("expected an error for an unknown severity")
+	}
+	if !Error.AtLeast(Warning) || Note.AtLeast(Warning) || !Warning.AtLeast(Warning) {
+		t.Errorf("unexpected severity order")
+	}
+}
+
+var encoded = []Diagnostic{
+	{
+		File:       "src/main.zylo",
+		Span:       ast.Span{StartLine: 2, StartCol: 1, EndLine: 2, EndCol: 7},
+		Code:       "N001",
+		Message:    "identifier not found: show.lg",
+		Suggestion: "show.log",
+	},
+	{File: "lib.zylo", Span: ast.Span{StartLine: 3, StartCol: 4}, Severity: Warning, Code: "X002", Message: "m", Notes: []string{"n"}},
+	{File: "lib.zylo", Code: "M001", Message: "sin posición"},
+}
+
+func TestWriteJSON(t *testing.T) {
+	var out strings.Builder
+	if err := WriteJSON(&out, encoded); err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "diagnostics": [
+    {
+      "code": "N001",
+      "severity": "error",
+      "file": "src/main.zylo",
+      "range": {
+        "start": {
+          "line": 2,
+          "column": 1
+        },
+        "end": {
+          "line": 2,
+          "column": 8
+        }
+      },
+      "message": "identifier not found: show.lg",
+      "suggestion": "show.log"
+    },
+    {
+      "code": "X002",
+      "severity": "warning",
+      "file": "lib.zylo",
+      "range": {
+        "start": {
+          "line": 3,
+          "column": 4
+        },
+        "end": {
+          "line": 3,
+          "column": 5
+        }
+      },
+      "message": "m",
+      "notes": [
+        "n"
+      ]
+    },
+    {
+      "code": "M001",
+      "severity": "error",
+      "file": "lib.zylo",
+      "message": "sin posición"
+    }
+  ]
+}
+`
+	if out.String() != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, out.String())
+	}
+
+	out.Reset()
+	WriteJSON(&out, nil)
+	if out.String() != "{\n  \"diagnostics\": []\n}\n" {
+		t.Errorf("expected an empty list, got %s", out.String())
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	var out strings.Builder
+	if err := WriteSARIF(&out, encoded); err != nil {
+		t.Fatal(err)
+	}
+	var log struct {
+		Version string
+		Runs    []struct {
+			ColumnKind string
+			Tool       struct {
+				Driver struct {
+					Name  string
+					Rules []struct{ ID string }
+				}
+			}
+			Results []struct {
+				RuleID    string
+				Level     string
+				Message   struct{ Text string }
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct{ URI string }
+						Region           *struct{ StartLine, StartColumn, EndLine, EndColumn int }
+					}
+				}
+				Properties struct {
+					Notes      []string
+					Suggestion string
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal([]byte(out.String()), &log); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected log %s", out.String())
+	}
+	run := log.Runs[0]
+	if run.Tool.Driver.Name != "zylo" || run.ColumnKind != "unicodeCodePoints" {
+		t.Errorf("unexpected run %+v", run)
+	}
+	var rules []string
+	for _, rule := range run.Tool.Driver.Rules {
+		rules = append(rules, rule.ID)
+	}
+	if strings.Join(rules, ",") != "M001,N001,X002" {
+		t.Errorf("expected the rules M001,N001,X002, got %v", rules)
+	}
+	if len(run.Results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(run.Results))
+	}
+
+	first := run.Results[0]
+	location := first.Locations[0].PhysicalLocation
+	if first.RuleID != "N001" || first.Level != "error" || location.ArtifactLocation.URI != "src/main.zylo" {
+		t.Errorf("unexpected result %+v", first)
+	}
+	if r := location.Region; r == nil || r.StartLine != 2 || r.StartColumn != 1 || r.EndLine != 2 || r.EndColumn != 8 {
+		t.Errorf("unexpected region %+v", r)
+	}
+	if first.Properties.Suggestion != "show.log" {
+		t.Errorf("expected the suggestion in the properties, got %+v", first.Properties)
+	}
+	if second := run.Results[1]; second.Level != "warning" || len(second.Properties.Notes) != 1 {
+		t.Errorf("unexpected result %+v", second)
+	}
+	if third := run.Results[2]; third.Locations[0].PhysicalLocation.Region != nil {
+		t.Errorf("expected no region without a position, got %+v", third)
+	}
+}
+
+func TestFileURI(t *testing.T) {
+	tests := map[string]string{
+		"main.zylo":           "main.zylo",
+		"dir/with space.zylo": "dir/with%20space.zylo",
+		"/abs/main.zylo":      "file:///abs/main.zylo",
+	}
+	for file, want := range tests {
+		if got := fileURI(file); got != want {
+			t.Errorf("fileURI(%q) = %q, expected %q", file, got, want)
+		}
+	}
+}
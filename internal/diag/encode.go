@@ -0,0 +1,207 @@
+package diag
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"sort"
+)
+
+// Las columnas de las regiones son de runas, como las de ast.Span, y el
+// final de una región es exclusivo: la columna siguiente a la última.
+
+type jsonPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+type jsonRange struct {
+	Start jsonPosition `json:"start"`
+	End   jsonPosition `json:"end"`
+}
+
+type jsonDiagnostic struct {
+	Code       string     `json:"code"`
+	Severity   string     `json:"severity"`
+	File       string     `json:"file,omitempty"`
+	Range      *jsonRange `json:"range,omitempty"`
+	Message    string     `json:"message"`
+	Notes      []string   `json:"notes,omitempty"`
+	Suggestion string     `json:"suggestion,omitempty"`
+}
+
+// WriteJSON escribe diagnostics en w como un objeto JSON:
+//
+//	{"diagnostics": [{"code": "P003", "severity": "error", "file": "main.zylo",
+//	  "range": {"start": {"line": 1, "column": 5}, "end": {"line": 1, "column": 6}},
+//	  "message": "..."}]}
+//
+// range falta en los diagnósticos sin posición, y notes y suggestion cuando
+// están vacíos.
+func WriteJSON(w io.Writer, diagnostics []Diagnostic) error {
+	out := struct {
+		Diagnostics []jsonDiagnostic `json:"diagnostics"`
+	}{Diagnostics: []jsonDiagnostic{}}
+	for _, d := range diagnostics {
+		jd := jsonDiagnostic{
+			Code:       d.Code,
+			Severity:   d.Severity.String(),
+			File:       d.File,
+			Message:    d.Message,
+			Notes:      d.Notes,
+			Suggestion: d.Suggestion,
+		}
+		if d.Span.StartLine > 0 {
+			endLine, endCol := end(d)
+			jd.Range = &jsonRange{
+				Start: jsonPosition{Line: d.Span.StartLine, Column: d.Span.StartCol},
+				End:   jsonPosition{Line: endLine, Column: endCol},
+			}
+		}
+		out.Diagnostics = append(out.Diagnostics, jd)
+	}
+	return encode(w, out)
+}
+
+// end devuelve la posición exclusiva del final de la región de d. Una
+// región sin final ocupa una columna.
+func end(d Diagnostic) (line, col int) {
+	if d.Span.EndLine < d.Span.StartLine || (d.Span.EndLine == d.Span.StartLine && d.Span.EndCol < d.Span.StartCol) {
+		return d.Span.StartLine, d.Span.StartCol + 1
+	}
+	return d.Span.EndLine, d.Span.EndCol + 1
+}
+
+// Tipos del formato SARIF 2.1.0, solo con las propiedades que se usan.
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool       sarifTool     `json:"tool"`
+	ColumnKind string        `json:"columnKind"`
+	Results    []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID     string           `json:"ruleId,omitempty"`
+	Level      string           `json:"level"`
+	Message    sarifMessage     `json:"message"`
+	Locations  []sarifLocation  `json:"locations,omitempty"`
+	Properties *sarifProperties `json:"properties,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+}
+
+// sarifProperties guarda en el resultado lo que SARIF no tiene dónde poner.
+type sarifProperties struct {
+	Notes      []string `json:"notes,omitempty"`
+	Suggestion string   `json:"suggestion,omitempty"`
+}
+
+// WriteSARIF escribe diagnostics en w como un log SARIF 2.1.0 con una
+// ejecución de la herramienta zylo. Cada código es una regla y las notas y
+// la sugerencia van en las propiedades del resultado.
+func WriteSARIF(w io.Writer, diagnostics []Diagnostic) error {
+	run := sarifRun{
+		Tool:       sarifTool{Driver: sarifDriver{Name: "zylo", Rules: []sarifRule{}}},
+		ColumnKind: "unicodeCodePoints",
+		Results:    []sarifResult{},
+	}
+	codes := make(map[string]bool)
+	for _, d := range diagnostics {
+		result := sarifResult{
+			RuleID:  d.Code,
+			Level:   d.Severity.String(),
+			Message: sarifMessage{Text: d.Message},
+		}
+		if d.File != "" {
+			location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: fileURI(d.File)},
+			}}
+			if d.Span.StartLine > 0 {
+				endLine, endCol := end(d)
+				location.PhysicalLocation.Region = &sarifRegion{
+					StartLine:   d.Span.StartLine,
+					StartColumn: d.Span.StartCol,
+					EndLine:     endLine,
+					EndColumn:   endCol,
+				}
+			}
+			result.Locations = []sarifLocation{location}
+		}
+		if len(d.Notes) > 0 || d.Suggestion != "" {
+			result.Properties = &sarifProperties{Notes: d.Notes, Suggestion: d.Suggestion}
+		}
+		run.Results = append(run.Results, result)
+		if d.Code != "" && !codes[d.Code] {
+			codes[d.Code] = true
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: d.Code})
+		}
+	}
+	sort.Slice(run.Tool.Driver.Rules, func(i, j int) bool {
+		return run.Tool.Driver.Rules[i].ID < run.Tool.Driver.Rules[j].ID
+	})
+	return encode(w, sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs:    []sarifRun{run},
+	})
+}
+
+// fileURI devuelve la URI de file: relativa, con '/', si la ruta es
+// relativa, y file:// si es absoluta.
+func fileURI(file string) string {
+	if filepath.IsAbs(file) {
+		u := url.URL{Scheme: "file", Path: filepath.ToSlash(file)}
+		if u.Path[0] != '/' {
+			u.Path = "/" + u.Path // C:/... en Windows
+		}
+		return u.String()
+	}
+	return (&url.URL{Path: filepath.ToSlash(file)}).String()
+}
+
+func encode(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,163 @@
+package diag
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Secuencias ANSI de los colores de Printer.
+const (
+	bold   = "\x1b[1m"
+	red    = "\x1b[1;31m"
+	yellow = "\x1b[1;33m"
+	blue   = "\x1b[1;34m"
+	cyan   = "\x1b[1;36m"
+	reset  = "\x1b[0m"
+)
+
+// tabWidth es el ancho con el que se muestra un tabulador en las líneas de
+// código fuente, para que el subrayado quede alineado.
+const tabWidth = 4
+
+// Printer escribe diagnósticos con la línea de código fuente en la que se
+// producen:
+//
+//	main.zylo:3:5: error[P003]: expected identifier after 'var', got =
+//	  |
+//	3 | var = 1
+//	  |     ^
+//	  = ayuda: ¿quisiste decir `show.log`?
+//
+// Las líneas de un archivo salen de SetSource o, si no se dio, del archivo
+// en disco.
+type Printer struct {
+	w       io.Writer
+	color   bool
+	sources map[string][]string
+}
+
+// NewPrinter crea un Printer que escribe en w, con colores ANSI si color es
+// true.
+func NewPrinter(w io.Writer, color bool) *Printer {
+	return &Printer{w: w, color: color, sources: make(map[string][]string)}
+}
+
+// ColorEnabled indica si se deben usar colores al escribir en f: si es una
+// terminal y la variable de entorno NO_COLOR no está definida.
+func ColorEnabled(f *os.File) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// SetSource registra el código fuente de file, para no leerlo del disco.
+func (p *Printer) SetSource(file, source string) {
+	p.sources[file] = strings.Split(source, "\n")
+}
+
+// Print escribe d seguido de una línea en blanco.
+func (p *Printer) Print(d Diagnostic) {
+	var out strings.Builder
+	file := d.File
+	if file == "" {
+		file = "<entrada>"
+	}
+	label, color := "error", red
+	switch d.Severity {
+	case Warning:
+		label, color = "aviso", yellow
+	case Note:
+		label, color = "nota", cyan
+	}
+	if d.Code != "" {
+		label += "[" + d.Code + "]"
+	}
+	if d.Span.StartLine > 0 {
+		fmt.Fprintf(&out, "%s:%d:%d: ", file, d.Span.StartLine, d.Span.StartCol)
+	} else if d.File != "" {
+		fmt.Fprintf(&out, "%s: ", file)
+	}
+	fmt.Fprintf(&out, "%s: %s\n", p.paint(color, label), p.paint(bold, d.Message))
+
+	gutter := strings.Repeat(" ", len(strconv.Itoa(d.Span.StartLine)))
+	if line, ok := p.line(d.File, d.Span.StartLine); ok {
+		text, start, width := underline(line, d)
+		bar := p.paint(blue, "|")
+		fmt.Fprintf(&out, "%s %s\n", gutter, bar)
+		fmt.Fprintf(&out, "%s %s %s\n", p.paint(blue, strconv.Itoa(d.Span.StartLine)), bar, text)
+		fmt.Fprintf(&out, "%s %s %s%s\n", gutter, bar, strings.Repeat(" ", start), p.paint(color, strings.Repeat("^", width)))
+	}
+	for _, note := range d.Notes {
+		fmt.Fprintf(&out, "%s %s %s %s\n", gutter, p.paint(blue, "="), p.paint(bold, "nota:"), note)
+	}
+	if d.Suggestion != "" {
+		fmt.Fprintf(&out, "%s %s %s ¿quisiste decir `%s`?\n", gutter, p.paint(blue, "="), p.paint(cyan, "ayuda:"), d.Suggestion)
+	}
+	out.WriteString("\n")
+	io.WriteString(p.w, out.String())
+}
+
+// paint envuelve text con el color si los colores están activados.
+func (p *Printer) paint(color, text string) string {
+	if !p.color {
+		return text
+	}
+	return color + text + reset
+}
+
+// line devuelve la línea number (desde 1) de file.
+func (p *Printer) line(file string, number int) (string, bool) {
+	lines, ok := p.sources[file]
+	if !ok && file != "" {
+		if content, err := os.ReadFile(file); err == nil {
+			lines = strings.Split(string(content), "\n")
+		}
+		p.sources[file] = lines
+	}
+	if number < 1 || number > len(lines) {
+		return "", false
+	}
+	return strings.TrimRight(lines[number-1], "\r"), true
+}
+
+// underline devuelve line con los tabuladores expandidos y la columna y el
+// ancho, en la línea expandida, de la región de d. Una región que sigue en
+// otras líneas se subraya hasta el final de la primera.
+func underline(line string, d Diagnostic) (text string, start, width int) {
+	first, last := d.Span.StartCol, d.Span.EndCol
+	if d.Span.EndLine != d.Span.StartLine || last < first {
+		last = len([]rune(line))
+	}
+
+	var out strings.Builder
+	col := 0
+	for i, r := range []rune(line) {
+		if i+1 == first {
+			start = col
+		}
+		n := 1
+		if r == '\t' {
+			n = tabWidth
+			out.WriteString(strings.Repeat(" ", tabWidth))
+		} else {
+			out.WriteRune(r)
+		}
+		if i+1 >= first && i+1 <= last {
+			width += n
+		}
+		col += n
+	}
+	if first > len([]rune(line)) {
+		// La región empieza al final de la línea, como un EOF inesperado
+		start = col
+	}
+	if width == 0 {
+		width = 1
+	}
+	return out.String(), start, width
+}
@@ -0,0 +1,288 @@
+// Package difftest compara los dos backends de Zylo: ejecuta cada programa
+// con el evaluador y con el ejecutable que genera el backend de Go, y
+// compara la salida estándar y si terminan con error. Para los programas en
+// los que no coinciden busca un reproductor mínimo quitando sentencias.
+package difftest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+	"github.com/zylo-lang/zylo/internal/builder"
+	"github.com/zylo-lang/zylo/internal/evaluator"
+	"github.com/zylo-lang/zylo/internal/format"
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/modules"
+	"github.com/zylo-lang/zylo/internal/parser"
+	"github.com/zylo-lang/zylo/internal/sema"
+)
+
+// Status es el resultado de comparar un programa.
+type Status string
+
+const (
+	Match   Status = "IGUAL"
+	Diverge Status = "DISTINTO"
+	Skip    Status = "OMITIDO" // El programa no es válido o no termina
+)
+
+// Kind es la forma en que difieren los backends.
+type Kind string
+
+const (
+	KindBuild  Kind = "build"  // El backend de Go no genera o no compila el programa
+	KindStdout Kind = "stdout" // La salida es distinta
+	KindExit   Kind = "exit"   // Solo uno de los dos termina con error
+)
+
+// Outcome es lo que hizo un programa en uno de los backends.
+type Outcome struct {
+	Stdout string
+	Failed bool   // Terminó con un error
+	Error  string // El error, o la salida de error del ejecutable
+}
+
+// Result es la comparación de un programa.
+type Result struct {
+	File       string
+	Status     Status
+	Kind       Kind   // Solo si Status es Diverge
+	Reason     string // Por qué se omitió
+	Evaluator  Outcome
+	Compiled   Outcome
+	Reproducer string // Programa mínimo con la misma divergencia, si se encontró
+}
+
+// Options configura la comparación.
+type Options struct {
+	Timeout time.Duration // Tiempo máximo de cada ejecución
+	Reduce  bool          // Buscar un reproductor mínimo de las divergencias
+	Budget  int           // Máximo de programas que prueba la reducción
+}
+
+// DefaultOptions son las opciones por defecto.
+var DefaultOptions = Options{Timeout: 10 * time.Second, Reduce: true, Budget: 60}
+
+// errTimeout es el error de una ejecución que superó Options.Timeout.
+var errTimeout = errors.New("timeout")
+
+// Files devuelve los archivos .zylo de paths, que pueden ser archivos o
+// directorios (sin recorrer subdirectorios). Los *_test.zylo, que son
+// pruebas de 'zylo test', no se incluyen.
+func Files(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(path, "*.zylo"))
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			if !strings.HasSuffix(match, "_test.zylo") {
+				files = append(files, match)
+			}
+		}
+	}
+	return files, nil
+}
+
+// CompareFile compara los backends con el programa de filename.
+func CompareFile(filename string, opts Options) *Result {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return &Result{File: filename, Status: Skip, Reason: err.Error()}
+	}
+	result := Compare(filename, string(src), opts)
+	if result.Status == Diverge && opts.Reduce {
+		result.Reproducer = Reduce(string(src), opts.Budget, func(code string) bool {
+			r := Compare(filename, code, opts)
+			return r.Status == Diverge && r.Kind == result.Kind
+		})
+	}
+	return result
+}
+
+// Compare compara los backends con src, el código de filename. filename
+// sirve para resolver los imports.
+func Compare(filename, src string, opts Options) *Result {
+	result := &Result{File: filename}
+	program, imported, err := load(filename, src)
+	if err != nil {
+		result.Status, result.Reason = Skip, err.Error()
+		return result
+	}
+
+	result.Evaluator = runEvaluator(filename, program, opts.Timeout)
+	result.Compiled = runCompiled(filename, program, imported, opts.Timeout)
+	if result.Evaluator.Error == errTimeout.Error() || result.Compiled.Error == errTimeout.Error() {
+		result.Status, result.Reason = Skip, "timeout"
+		return result
+	}
+
+	switch {
+	case result.Compiled.Failed && strings.HasPrefix(result.Compiled.Error, buildPrefix):
+		result.Status, result.Kind = Diverge, KindBuild
+	case result.Evaluator.Stdout != result.Compiled.Stdout:
+		result.Status, result.Kind = Diverge, KindStdout
+	case result.Evaluator.Failed != result.Compiled.Failed:
+		result.Status, result.Kind = Diverge, KindExit
+	default:
+		result.Status = Match
+	}
+	return result
+}
+
+// load parsea y analiza src, y carga los módulos que importa.
+func load(filename, src string) (*ast.Program, []*modules.File, error) {
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+	if errs := p.Errors(); len(errs) > 0 {
+		return nil, nil, fmt.Errorf("errores de parsing: %s", strings.Join(errs, "; "))
+	}
+	analyzer := sema.NewSemanticAnalyzer()
+	analyzer.Analyze(program)
+	if errs := analyzer.Errors(); len(errs) > 0 {
+		return nil, nil, fmt.Errorf("errores semánticos: %s", strings.Join(errs, "; "))
+	}
+	checker := sema.NewChecker()
+	checker.Check(program)
+	if errs := checker.Errors(); len(errs) > 0 {
+		return nil, nil, fmt.Errorf("errores de tipos: %v", errs[0])
+	}
+	imported, err := modules.Load(filename, program, modules.SearchPath())
+	if err != nil {
+		return nil, nil, err
+	}
+	return program, imported, nil
+}
+
+// runEvaluator ejecuta program con el evaluador, sin entrada estándar.
+func runEvaluator(filename string, program *ast.Program, timeout time.Duration) Outcome {
+	var stdout, stderr syncBuffer
+	eval := evaluator.NewEvaluatorWithIO(strings.NewReader(""), &stdout, &stderr)
+	eval.SetFile(filename)
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- eval.EvaluateProgram(program)
+	}()
+
+	select {
+	case err := <-done:
+		outcome := Outcome{Stdout: stdout.String()}
+		if err != nil {
+			outcome.Failed, outcome.Error = true, err.Error()
+		}
+		return outcome
+	case <-time.After(timeout):
+		// La goroutine sigue ejecutándose: el evaluador no se puede parar
+		return Outcome{Stdout: stdout.String(), Failed: true, Error: errTimeout.Error()}
+	}
+}
+
+// buildPrefix empieza el error de un programa que el backend de Go no pudo
+// generar o compilar.
+const buildPrefix = "build: "
+
+// runCompiled compila program con el backend de Go y ejecuta el resultado,
+// sin entrada estándar.
+func runCompiled(filename string, program *ast.Program, imported []*modules.File, timeout time.Duration) Outcome {
+	dir, err := os.MkdirTemp("", "zylo-difftest-")
+	if err != nil {
+		return Outcome{Failed: true, Error: buildPrefix + err.Error()}
+	}
+	defer os.RemoveAll(dir)
+
+	output := filepath.Join(dir, "program")
+	result, err := builder.Build(filename, program, imported, builder.Options{Output: output})
+	if err != nil {
+		return Outcome{Failed: true, Error: buildPrefix + err.Error()}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, result.Output)
+	cmd.Stdin = strings.NewReader("")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	outcome := Outcome{Stdout: stdout.String(), Error: stderr.String()}
+	if ctx.Err() != nil {
+		outcome.Failed, outcome.Error = true, errTimeout.Error()
+	} else if err != nil {
+		outcome.Failed = true
+		if outcome.Error == "" {
+			outcome.Error = err.Error()
+		}
+	}
+	return outcome
+}
+
+// Reduce busca el programa más pequeño derivado de src para el que keep
+// sigue siendo cierto, quitando sentencias de una en una. Prueba como mucho
+// budget programas y devuelve "" si no consigue quitar ninguna sentencia.
+func Reduce(src string, budget int, keep func(code string) bool) string {
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		return ""
+	}
+
+	blocks := []*[]ast.Statement{&program.Statements}
+	ast.Inspect(program, func(node ast.Node) bool {
+		if block, ok := node.(*ast.BlockStatement); ok {
+			blocks = append(blocks, &block.Statements)
+		}
+		return true
+	})
+
+	current := format.Program(src, program)
+	reduced := false
+	attempts := 0
+	for changed := true; changed && attempts < budget; {
+		changed = false
+		for _, block := range blocks {
+			for i := len(*block) - 1; i >= 0 && attempts < budget; i-- {
+				statements := *block
+				*block = append(append([]ast.Statement{}, statements[:i]...), statements[i+1:]...)
+				code := format.Program(src, program)
+				if code == current {
+					// La sentencia ya no se escribe: estaba dentro de otra quitada
+					*block = statements
+					continue
+				}
+				attempts++
+				if keep(code) {
+					current, reduced, changed = code, true, true
+					continue
+				}
+				*block = statements
+			}
+		}
+	}
+	if !reduced {
+		return ""
+	}
+	return current
+}
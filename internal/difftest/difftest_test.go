@@ -0,0 +1,97 @@
+package difftest
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var report = flag.String("report", "", "escribe el informe de los ejemplos en este archivo")
+
+// TestConformance comprueba que los dos backends coinciden en el corpus de
+// conformidad.
+func TestConformance(t *testing.T) {
+	files, err := Files([]string{filepath.Join("testdata", "conformance")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("empty conformance corpus")
+	}
+	for _, file := range files {
+		file := file
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			t.Parallel()
+			result := CompareFile(file, DefaultOptions)
+			if result.Status != Match {
+				var b strings.Builder
+				WriteReport(&b, []*Result{result})
+				t.Errorf("%s", b.String())
+			}
+		})
+	}
+}
+
+// TestExamples compara los backends con los programas de examples/. Las
+// divergencias no hacen fallar la prueba: se escriben en el informe, en el
+// archivo de -report o en el log.
+func TestExamples(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiles every example")
+	}
+	files, err := Files([]string{filepath.Join("..", "..", "examples")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var results []*Result
+	for _, file := range files {
+		results = append(results, CompareFile(file, DefaultOptions))
+	}
+
+	var b strings.Builder
+	if err := WriteReport(&b, results); err != nil {
+		t.Fatal(err)
+	}
+	if *report != "" {
+		if err := os.WriteFile(*report, []byte(b.String()), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	t.Log("\n" + b.String())
+}
+
+func TestReduce(t *testing.T) {
+	src := `var a = 1
+var b = 2
+func f(x) {
+    show.log("f")
+    show.log(x + b)
+    return x
+}
+show.log(a)
+f(a)
+`
+	// Se conserva mientras el programa siga mostrando x + b
+	got := Reduce(src, 100, func(code string) bool {
+		return strings.Contains(code, "show.log(x + b)") && strings.Contains(code, "f(a)") &&
+			strings.Contains(code, "var a") && strings.Contains(code, "var b")
+	})
+	want := "var a = 1\nvar b = 2\nfunc f(x) {\n    show.log(x + b)\n}\nf(a)\n"
+	if got != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestCompareDetectsDivergence(t *testing.T) {
+	// Los built-ins de concurrencia solo existen en el evaluador
+	result := Compare("wait.zylo", "var wg = waitgroup()\nshow.log(\"ok\")\n", DefaultOptions)
+	if result.Status != Diverge || result.Kind != KindBuild {
+		t.Fatalf("expected a build divergence, got %s %s (%s)", result.Status, result.Kind, result.Reason)
+	}
+	if !result.Compiled.Failed || result.Evaluator.Failed {
+		t.Errorf("expected only the compiled program to fail: %+v", result)
+	}
+}
@@ -0,0 +1,83 @@
+package difftest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// WriteReport escribe en w un informe en Markdown con los programas en los
+// que los backends difieren: las dos salidas y el reproductor mínimo, si se
+// encontró. Los programas omitidos se listan con el motivo.
+func WriteReport(w io.Writer, results []*Result) error {
+	counts := make(map[Status]int)
+	for _, r := range results {
+		counts[r.Status]++
+	}
+
+	var b strings.Builder
+	b.WriteString("# Evaluador vs. Go generado\n\n")
+	fmt.Fprintf(&b, "%d programas: %d iguales, %d distintos, %d omitidos\n",
+		len(results), counts[Match], counts[Diverge], counts[Skip])
+
+	for _, r := range results {
+		if r.Status != Diverge {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %s (%s)\n\n", r.File, r.Kind)
+		writeOutcome(&b, "Evaluador", r.Evaluator)
+		writeOutcome(&b, "Go generado", r.Compiled)
+		if r.Reproducer != "" {
+			b.WriteString("Reproductor mínimo:\n\n```zylo\n" + r.Reproducer + "```\n")
+		}
+	}
+
+	if counts[Skip] > 0 {
+		b.WriteString("\n## Omitidos\n\n")
+		for _, r := range results {
+			if r.Status == Skip {
+				fmt.Fprintf(&b, "- %s: %s\n", r.File, r.Reason)
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeOutcome escribe la salida de un backend y su error, si lo hubo.
+func writeOutcome(b *strings.Builder, backend string, o Outcome) {
+	status := "ok"
+	if o.Failed {
+		status = "error"
+	}
+	fmt.Fprintf(b, "%s (%s):\n\n```\n%s", backend, status, o.Stdout)
+	if o.Stdout != "" && !strings.HasSuffix(o.Stdout, "\n") {
+		b.WriteString("\n")
+	}
+	b.WriteString("```\n\n")
+	if o.Failed && o.Error != "" {
+		fmt.Fprintf(b, "```\n%s\n```\n\n", strings.TrimRight(o.Error, "\n"))
+	}
+}
+
+// syncBuffer es un bytes.Buffer que se puede escribir desde varias
+// goroutines, como las tareas del evaluador.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (s *syncBuffer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.Write(p)
+}
+
+func (s *syncBuffer) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.String()
+}
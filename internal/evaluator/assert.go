@@ -0,0 +1,143 @@
+package evaluator
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrAssertion es la causa de los errores de assert, assertEqual y
+// assertThrows. El error de ejecución que la envuelve lleva la posición de la
+// llamada que falló.
+var ErrAssertion = errors.New("assertion failed")
+
+// describe devuelve value como aparece en un mensaje de aserción: los
+// strings entre comillas para distinguir "1" de 1.
+func describe(value Value) string {
+	if s, ok := value.(*String); ok {
+		return strconv.Quote(s.Value)
+	}
+	return inspectValue(value)
+}
+
+// valuesEqual compara dos valores para assertEqual. A diferencia de '==',
+// compara listas y hashes elemento a elemento y considera iguales dos null.
+func (e *Evaluator) valuesEqual(a, b Value) bool {
+	switch x := a.(type) {
+	case *Null:
+		_, ok := b.(*Null)
+		return ok
+	case *List:
+		y, ok := b.(*List)
+		if !ok {
+			return false
+		}
+		xs, ys := x.Elements(), y.Elements()
+		if len(xs) != len(ys) {
+			return false
+		}
+		for i := range xs {
+			if !e.valuesEqual(xs[i], ys[i]) {
+				return false
+			}
+		}
+		return true
+	case *Hash:
+		y, ok := b.(*Hash)
+		if !ok {
+			return false
+		}
+		xs, ys := x.Entries(), y.Entries()
+		if len(xs) != len(ys) {
+			return false
+		}
+		for key, value := range xs {
+			other, ok := ys[key]
+			if !ok || !e.valuesEqual(value, other) {
+				return false
+			}
+		}
+		return true
+	case *String, *Integer, *Float, *Boolean:
+		result, err := e.applyOperator("==", a, b)
+		return err == nil && e.isTruthy(result)
+	}
+	return a == b
+}
+
+// assertionMessage devuelve el mensaje opcional de una aserción, que es el
+// argumento en la posición i.
+func assertionMessage(args []Value, i int) string {
+	if len(args) <= i {
+		return ""
+	}
+	if s, ok := args[i].(*String); ok {
+		return ": " + s.Value
+	}
+	return ": " + inspectValue(args[i])
+}
+
+// initAssertBuiltins registra assert, assertEqual y assertThrows, que usa
+// 'zylo test'. Un fallo es un error de ejecución con la posición de la
+// llamada y los valores involucrados.
+func (e *Evaluator) initAssertBuiltins() {
+	e.env.Set("assert", &BuiltinFunction{
+		Name: "assert",
+		EvalFn: func(e *Evaluator, args []Value) (Value, error) {
+			if len(args) < 1 || len(args) > 2 {
+				return nil, fmt.Errorf("assert() expects 1 or 2 arguments")
+			}
+			if !e.isTruthy(args[0]) {
+				return nil, fmt.Errorf("%w%s: got %s", ErrAssertion, assertionMessage(args, 1), describe(args[0]))
+			}
+			return &Null{}, nil
+		},
+	})
+
+	e.env.Set("assertEqual", &BuiltinFunction{
+		Name: "assertEqual",
+		EvalFn: func(e *Evaluator, args []Value) (Value, error) {
+			if len(args) < 2 || len(args) > 3 {
+				return nil, fmt.Errorf("assertEqual() expects 2 or 3 arguments")
+			}
+			actual, expected := args[0], args[1]
+			if !e.valuesEqual(actual, expected) {
+				return nil, fmt.Errorf("%w%s: expected %s, got %s", ErrAssertion, assertionMessage(args, 2), describe(expected), describe(actual))
+			}
+			return &Null{}, nil
+		},
+	})
+
+	e.env.Set("assertThrows", &BuiltinFunction{
+		Name: "assertThrows",
+		EvalFn: func(e *Evaluator, args []Value) (Value, error) {
+			if len(args) < 1 || len(args) > 2 {
+				return nil, fmt.Errorf("assertThrows() expects 1 or 2 arguments")
+			}
+			if !isCallable(args[0]) {
+				return nil, fmt.Errorf("first argument to assertThrows() must be a function")
+			}
+			_, err := e.callFunction(args[0], []Value{})
+			if uncatchable(err) {
+				return nil, err
+			}
+			if err == nil {
+				return nil, fmt.Errorf("%w: expected an error, but nothing was thrown", ErrAssertion)
+			}
+
+			// El valor lanzado se devuelve para poder inspeccionarlo
+			thrown := ExceptionValue(err)
+			if len(args) == 2 {
+				want, ok := args[1].(*String)
+				if !ok {
+					return nil, fmt.Errorf("second argument to assertThrows() must be a string")
+				}
+				if !strings.Contains(inspectValue(thrown), want.Value) {
+					return nil, fmt.Errorf("%w: expected an error containing %s, got %s", ErrAssertion, describe(want), describe(thrown))
+				}
+			}
+			return thrown, nil
+		},
+	})
+}
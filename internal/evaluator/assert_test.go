@@ -0,0 +1,50 @@
+package evaluator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAssertions(t *testing.T) {
+	passing := `
+assert(true)
+assert(1 < 2, "ordered")
+assertEqual(1 + 1, 2)
+assertEqual(2, 2.0)
+assertEqual([1, "a", nil, [true]], [1, "a", nil, [true]])
+assertEqual({"a": 1, "b": [2]}, {"b": [2], "a": 1})
+var result = assertThrows(() => { throw "boom" }, "oo")
+`
+	eval, err := runProgram(t, passing)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectGlobal(t, eval, "result", "boom")
+
+	failing := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"assert", "var x = 0\nassert(x, \"x is set\")", "<entrada>:2:1: assertion failed: x is set: got 0"},
+		{"assertEqual quotes strings", "assertEqual(\"1\", 1)", "<entrada>:1:1: assertion failed: expected 1, got \"1\""},
+		{"assertEqual compares lists deeply", "assertEqual([1, [2]], [1, [3]])", "<entrada>:1:1: assertion failed: expected [1, [3]], got [1, [2]]"},
+		{"assertThrows without error", "assertThrows(() => 1)", "<entrada>:1:1: assertion failed: expected an error, but nothing was thrown"},
+		{"assertThrows with another error", "assertThrows(() => 1 / 0, \"boom\")", "<entrada>:1:1: assertion failed: expected an error containing \"boom\", got \"división por cero\""},
+	}
+	for _, tt := range failing {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runProgram(t, tt.input)
+			if !errors.Is(err, ErrAssertion) {
+				t.Fatalf("expected ErrAssertion, got %v", err)
+			}
+			if err.Error() != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, err.Error())
+			}
+			var runtimeErr *RuntimeError
+			if !errors.As(err, &runtimeErr) || runtimeErr.Code() != CodeAssertion {
+				t.Errorf("expected a *RuntimeError with code %s, got %v", CodeAssertion, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,540 @@
+package evaluator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+)
+
+// ErrDeadlock indica que todas las tareas de una ejecución quedaron
+// bloqueadas esperando canales o wait groups que nadie va a desbloquear.
+var ErrDeadlock = errors.New("todas las tareas están bloqueadas (deadlock)")
+
+// deadlockGrace es cuánto tiempo deben seguir todas las tareas bloqueadas, sin
+// ningún progreso, antes de declarar un deadlock.
+const deadlockGrace = 50 * time.Millisecond
+
+// Channel es un canal de Zylo para comunicar tareas lanzadas con spawn.
+type Channel struct {
+	ch       chan Value
+	capacity int
+}
+
+// NewChannel crea un canal con capacity valores de buffer; 0 crea un canal
+// sin buffer.
+func NewChannel(capacity int) *Channel {
+	return &Channel{ch: make(chan Value, capacity), capacity: capacity}
+}
+
+func (c *Channel) Type() string    { return "CHANNEL_OBJ" }
+func (c *Channel) Inspect() string { return fmt.Sprintf("channel(%d)", c.capacity) }
+
+// WaitGroup espera a que terminen un número de tareas, como sync.WaitGroup.
+type WaitGroup struct {
+	mu    sync.Mutex
+	count int
+	done  chan struct{} // Se cierra cuando count vuelve a cero
+}
+
+// NewWaitGroup crea un wait group con el contador a cero.
+func NewWaitGroup() *WaitGroup {
+	done := make(chan struct{})
+	close(done)
+	return &WaitGroup{done: done}
+}
+
+func (wg *WaitGroup) Type() string { return "WAITGROUP_OBJ" }
+func (wg *WaitGroup) Inspect() string {
+	wg.mu.Lock()
+	defer wg.mu.Unlock()
+	return fmt.Sprintf("waitgroup(%d)", wg.count)
+}
+
+// add suma delta al contador y despierta a los que esperan al llegar a cero.
+func (wg *WaitGroup) add(delta int) error {
+	wg.mu.Lock()
+	defer wg.mu.Unlock()
+	if wg.count+delta < 0 {
+		return fmt.Errorf("negative waitgroup counter")
+	}
+	if wg.count == 0 && delta > 0 {
+		wg.done = make(chan struct{})
+	}
+	wg.count += delta
+	if wg.count == 0 && delta < 0 {
+		close(wg.done)
+	}
+	return nil
+}
+
+// waitChannel devuelve el canal que se cierra cuando el contador llega a cero.
+func (wg *WaitGroup) waitChannel() chan struct{} {
+	wg.mu.Lock()
+	defer wg.mu.Unlock()
+	return wg.done
+}
+
+// taskGroup coordina la tarea principal de una ejecución y las que lanza con
+// spawn: comparten un contexto que se cancela con el primer error y detectan
+// cuándo todas quedan bloqueadas.
+type taskGroup struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu       sync.Mutex
+	err      error         // Primer error de cualquier tarea
+	running  int           // Tareas vivas, incluida la principal
+	blocked  int           // Tareas esperando en un canal o wait group
+	progress uint64        // Operaciones bloqueantes completadas
+	deadlock chan struct{} // Se cierra al detectar un deadlock
+	dead     bool
+}
+
+func newTaskGroup(parent context.Context) *taskGroup {
+	ctx, cancel := context.WithCancel(parent)
+	return &taskGroup{
+		ctx:      ctx,
+		cancel:   cancel,
+		running:  1,
+		deadlock: make(chan struct{}),
+	}
+}
+
+// fail registra el primer error de la ejecución y cancela el resto de tareas.
+func (g *taskGroup) fail(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.err == nil {
+		g.err = err
+		g.cancel()
+	}
+}
+
+// firstError devuelve el primer error registrado.
+func (g *taskGroup) firstError() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.err
+}
+
+// start ejecuta fn en una goroutine nueva contada como tarea viva.
+func (g *taskGroup) start(fn func()) {
+	g.mu.Lock()
+	g.running++
+	g.mu.Unlock()
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		defer func() {
+			g.mu.Lock()
+			g.running--
+			g.checkBlocked()
+			g.mu.Unlock()
+		}()
+		fn()
+	}()
+}
+
+func (g *taskGroup) enterBlocked() {
+	g.mu.Lock()
+	g.blocked++
+	g.checkBlocked()
+	g.mu.Unlock()
+}
+
+func (g *taskGroup) leaveBlocked() {
+	g.mu.Lock()
+	g.blocked--
+	g.progress++
+	g.mu.Unlock()
+}
+
+// checkBlocked programa la detección de deadlock si todas las tareas vivas
+// están bloqueadas. Se invoca con g.mu tomado.
+func (g *taskGroup) checkBlocked() {
+	if g.running == 0 || g.blocked < g.running {
+		return
+	}
+	progress := g.progress
+	time.AfterFunc(deadlockGrace, func() {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		// Solo es un deadlock si nadie avanzó durante el periodo de gracia
+		if !g.dead && g.running > 0 && g.blocked >= g.running && g.progress == progress {
+			g.dead = true
+			close(g.deadlock)
+		}
+	})
+}
+
+// runMain ejecuta fn como tarea principal. Al terminar espera a las tareas
+// lanzadas con spawn y devuelve el primer error de cualquiera de ellas. Las
+// llamadas anidadas, desde una ejecución en curso, ejecutan fn sin más.
+func (e *Evaluator) runMain(fn func() error) error {
+	if e.tasks != nil {
+		return fn()
+	}
+
+	tasks := newTaskGroup(e.ctx)
+	ctx := e.ctx
+	e.tasks, e.ctx = tasks, tasks.ctx
+	defer func() {
+		tasks.cancel()
+		e.tasks, e.ctx = nil, ctx
+	}()
+
+	if err := fn(); err != nil {
+		tasks.fail(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		tasks.wg.Wait()
+		close(done)
+	}()
+	if _, _, _, err := e.block([]reflect.SelectCase{recvCase(done)}); err != nil {
+		tasks.fail(err)
+		<-done // Las tareas terminan al ver el contexto cancelado
+	}
+	return tasks.firstError()
+}
+
+// fork crea el evaluador de una tarea: comparte los globales, la entrada y
+// salida, los límites y el grupo de tareas, pero tiene su propia pila de
+// llamadas, que empieza con la de la tarea que la lanza.
+func (e *Evaluator) fork() *Evaluator {
+	child := *e
+	child.callStack = append([]callFrame(nil), e.callStack...)
+	return &child
+}
+
+// newTask crea el evaluador de una tarea nueva. Las tareas no llaman a los
+// hooks: un depurador sigue solo a la tarea principal.
+func (e *Evaluator) newTask() *Evaluator {
+	task := e.fork()
+	task.hooks = Hooks{}
+	return task
+}
+
+// evaluateSpawnExpression evalúa la llamada en la tarea actual y la ejecuta
+// en una goroutine nueva con su propio evaluador.
+func (e *Evaluator) evaluateSpawnExpression(exp *ast.SpawnExpression) (Value, error) {
+	if e.tasks == nil {
+		return nil, fmt.Errorf("spawn is not available outside a program run")
+	}
+
+	fn, err := e.evaluateExpression(exp.Call.Function)
+	if err != nil {
+		return nil, err
+	}
+	args := make([]Value, len(exp.Call.Arguments))
+	for i, arg := range exp.Call.Arguments {
+		args[i], err = e.evaluateExpression(arg)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := fn.(*ZyloClass); !ok && !isCallable(fn) {
+		return nil, fmt.Errorf("cannot spawn %T", fn)
+	}
+
+	task := e.newTask()
+	task.callSite = ast.SpanOf(exp)
+	e.tasks.start(func() {
+		var err error
+		if class, ok := fn.(*ZyloClass); ok {
+			_, err = task.instantiateClass(class, args)
+		} else {
+			_, err = task.callFunction(fn, args)
+		}
+		if err != nil {
+			e.tasks.fail(task.wrapError(exp, err))
+		}
+	})
+	return &Null{}, nil
+}
+
+// poll intenta una de cases sin esperar. Devuelve chosen -1 si ninguna está
+// lista.
+func poll(cases []reflect.SelectCase) (chosen int, recv reflect.Value, recvOK bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			chosen, err = -1, fmt.Errorf("%v", r) // Enviar a un canal cerrado
+		}
+	}()
+	n := len(cases)
+	chosen, recv, recvOK = reflect.Select(append(cases[:n:n], reflect.SelectCase{Dir: reflect.SelectDefault}))
+	if chosen == n {
+		return -1, reflect.Value{}, false, nil
+	}
+	return chosen, recv, recvOK, nil
+}
+
+// block espera a que una de cases esté lista, como reflect.Select. Cuenta la
+// tarea como bloqueada para detectar deadlocks y se interrumpe si se cancela
+// la ejecución.
+func (e *Evaluator) block(cases []reflect.SelectCase) (chosen int, recv reflect.Value, recvOK bool, err error) {
+	if chosen, recv, recvOK, err = poll(cases); chosen >= 0 || err != nil {
+		return chosen, recv, recvOK, err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			chosen, err = -1, fmt.Errorf("%v", r)
+		}
+	}()
+	n := len(cases)
+	all := append(cases[:n:n], recvCase(e.ctx.Done()))
+	if e.tasks != nil {
+		all = append(all, recvCase(e.tasks.deadlock))
+		e.tasks.enterBlocked()
+		defer e.tasks.leaveBlocked()
+	}
+	chosen, recv, recvOK = reflect.Select(all)
+	switch {
+	case chosen < n:
+		return chosen, recv, recvOK, nil
+	case chosen == n:
+		return -1, reflect.Value{}, false, fmt.Errorf("%w: %w", ErrCanceled, e.ctx.Err())
+	default:
+		return -1, reflect.Value{}, false, ErrDeadlock
+	}
+}
+
+func recvCase[T any](ch <-chan T) reflect.SelectCase {
+	return reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ch)}
+}
+
+// send envía value por el canal, esperando si no hay receptor o espacio.
+func (e *Evaluator) send(c *Channel, value Value) error {
+	_, _, _, err := e.block([]reflect.SelectCase{{Dir: reflect.SelectSend, Chan: reflect.ValueOf(c.ch), Send: reflect.ValueOf(&value).Elem()}})
+	return err
+}
+
+// receive recibe un valor del canal. Un canal cerrado y vacío devuelve null.
+func (e *Evaluator) receive(c *Channel) (Value, bool, error) {
+	_, recv, ok, err := e.block([]reflect.SelectCase{recvCase(c.ch)})
+	if err != nil {
+		return nil, false, err
+	}
+	if !ok {
+		return &Null{}, false, nil
+	}
+	return recv.Interface(), true, nil
+}
+
+// closeChannel cierra el canal; cerrarlo dos veces es un error.
+func closeChannel(c *Channel) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	close(c.ch)
+	return nil
+}
+
+// channelMethod devuelve el método propName de un canal. Los métodos usan el
+// evaluador de la tarea que los llama, como los built-ins bloqueantes.
+func channelMethod(c *Channel, propName string) (Value, error) {
+	var fn func(e *Evaluator, args []Value) (Value, error)
+	switch propName {
+	case "send":
+		fn = func(e *Evaluator, args []Value) (Value, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("send() expects exactly 1 argument")
+			}
+			return &Null{}, e.send(c, args[0])
+		}
+	case "receive":
+		fn = func(e *Evaluator, args []Value) (Value, error) {
+			value, _, err := e.receive(c)
+			return value, err
+		}
+	case "close":
+		fn = func(e *Evaluator, args []Value) (Value, error) {
+			return &Null{}, closeChannel(c)
+		}
+	case "len":
+		fn = func(e *Evaluator, args []Value) (Value, error) {
+			return &Integer{Value: int64(len(c.ch))}, nil
+		}
+	case "cap":
+		fn = func(e *Evaluator, args []Value) (Value, error) {
+			return &Integer{Value: int64(c.capacity)}, nil
+		}
+	default:
+		return nil, fmt.Errorf("method '%s' not found on channel", propName)
+	}
+	return &BuiltinFunction{Name: "channel." + propName, EvalFn: fn}, nil
+}
+
+// waitGroupMethod devuelve el método propName de un wait group.
+func waitGroupMethod(wg *WaitGroup, propName string) (Value, error) {
+	var fn func(e *Evaluator, args []Value) (Value, error)
+	switch propName {
+	case "add":
+		fn = func(e *Evaluator, args []Value) (Value, error) {
+			delta := int64(1)
+			if len(args) > 0 {
+				n, ok := args[0].(*Integer)
+				if !ok {
+					return nil, fmt.Errorf("add() expects an integer")
+				}
+				delta = n.Value
+			}
+			return &Null{}, wg.add(int(delta))
+		}
+	case "done":
+		fn = func(e *Evaluator, args []Value) (Value, error) {
+			return &Null{}, wg.add(-1)
+		}
+	case "wait":
+		fn = func(e *Evaluator, args []Value) (Value, error) {
+			_, _, _, err := e.block([]reflect.SelectCase{recvCase(wg.waitChannel())})
+			return &Null{}, err
+		}
+	default:
+		return nil, fmt.Errorf("method '%s' not found on waitgroup", propName)
+	}
+	return &BuiltinFunction{Name: "waitgroup." + propName, EvalFn: fn}, nil
+}
+
+// selectChannels implementa el built-in select(cases, wait). Cada caso es un
+// canal, para recibir, o una lista [canal, valor], para enviar. Devuelve
+// [índice, valor recibido] del primer caso listo; con wait en false y ningún
+// caso listo devuelve [-1, null].
+func (e *Evaluator) selectChannels(args []Value) (Value, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, fmt.Errorf("select() expects 1 or 2 arguments")
+	}
+	list, ok := args[0].(*List)
+	if !ok {
+		return nil, fmt.Errorf("first argument to select() must be a list of cases")
+	}
+	wait := true
+	if len(args) == 2 {
+		wait = e.isTruthy(args[1])
+	}
+
+	items := list.Elements()
+	cases := make([]reflect.SelectCase, len(items))
+	for i, item := range items {
+		switch c := item.(type) {
+		case *Channel:
+			cases[i] = recvCase(c.ch)
+		case *List:
+			pair := c.Elements()
+			if len(pair) != 2 {
+				return nil, fmt.Errorf("select() send case must be [channel, value]")
+			}
+			ch, ok := pair[0].(*Channel)
+			if !ok {
+				return nil, fmt.Errorf("select() send case must be [channel, value]")
+			}
+			value := pair[1]
+			cases[i] = reflect.SelectCase{Dir: reflect.SelectSend, Chan: reflect.ValueOf(ch.ch), Send: reflect.ValueOf(&value).Elem()}
+		default:
+			return nil, fmt.Errorf("invalid select() case: %T", item)
+		}
+	}
+
+	var chosen int
+	var recv reflect.Value
+	var recvOK bool
+	var err error
+	if wait {
+		chosen, recv, recvOK, err = e.block(cases)
+	} else {
+		chosen, recv, recvOK, err = poll(cases)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if chosen < 0 { // Ningún caso listo
+		return &List{Items: []Value{&Integer{Value: -1}, &Null{}}}, nil
+	}
+	var value Value = &Null{}
+	if recvOK {
+		value = recv.Interface()
+	}
+	return &List{Items: []Value{&Integer{Value: int64(chosen)}, value}}, nil
+}
+
+// sleep pausa la tarea actual ms milisegundos o hasta que se cancele la ejecución.
+func (e *Evaluator) sleep(ms int64) error {
+	timer := time.NewTimer(time.Duration(ms) * time.Millisecond)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-e.ctx.Done():
+		return fmt.Errorf("%w: %w", ErrCanceled, e.ctx.Err())
+	}
+}
+
+// initConcurrencyBuiltins registra los built-ins que acompañan a spawn:
+// channel, waitgroup, select y sleep.
+func (e *Evaluator) initConcurrencyBuiltins() {
+	e.env.Set("channel", &BuiltinFunction{
+		Name: "channel",
+		Fn: func(args []Value) (Value, error) {
+			if len(args) == 0 {
+				return NewChannel(0), nil
+			}
+			size, ok := args[0].(*Integer)
+			if len(args) > 1 || !ok || size.Value < 0 {
+				return nil, fmt.Errorf("channel() expects an optional non-negative buffer size")
+			}
+			return NewChannel(int(size.Value)), nil
+		},
+	})
+
+	e.env.Set("waitgroup", &BuiltinFunction{
+		Name: "waitgroup",
+		Fn: func(args []Value) (Value, error) {
+			return NewWaitGroup(), nil
+		},
+	})
+
+	e.env.Set("select", &BuiltinFunction{
+		Name: "select",
+		EvalFn: func(e *Evaluator, args []Value) (Value, error) {
+			return e.selectChannels(args)
+		},
+	})
+
+	e.env.Set("sleep", &BuiltinFunction{
+		Name: "sleep",
+		EvalFn: func(e *Evaluator, args []Value) (Value, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("sleep() expects exactly 1 argument")
+			}
+			ms, ok := args[0].(*Integer)
+			if !ok {
+				return nil, fmt.Errorf("sleep() expects milliseconds as an integer")
+			}
+			return &Null{}, e.sleep(ms.Value)
+		},
+	})
+}
+
+// syncWriter serializa las escrituras de varias tareas. stdout y stderr
+// comparten el mutex porque pueden ser el mismo destino.
+type syncWriter struct {
+	mu *sync.Mutex
+	w  io.Writer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
@@ -0,0 +1,281 @@
+package evaluator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChannels(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "unbuffered channel between tasks",
+			input: `
+var ch = channel()
+func producer(n) {
+    for i in [1, 2, 3] {
+        ch.send(i * n)
+    }
+    ch.close()
+}
+spawn producer(10)
+var result = ""
+for v in ch {
+    result = result + string(v) + " "
+}
+`,
+			expected: "10 20 30 ",
+		},
+		{
+			name: "buffered channel does not block until full",
+			input: `
+var ch = channel(2)
+ch.send("a")
+ch.send("b")
+var result = [ch.len(), ch.cap(), ch.receive(), ch.receive()]
+`,
+			expected: "[2, 2, a, b]",
+		},
+		{
+			name: "receive on closed channel returns null",
+			input: `
+var ch = channel(1)
+ch.send(1)
+ch.close()
+var result = [ch.receive(), ch.receive()]
+`,
+			expected: "[1, null]",
+		},
+		{
+			name: "waitgroup waits for every task",
+			input: `
+var wg = waitgroup()
+var results = channel(5)
+func square(n) {
+    results.send(n * n)
+    wg.done()
+}
+for i in [1, 2, 3, 4, 5] {
+    wg.add(1)
+    spawn square(i)
+}
+wg.wait()
+results.close()
+var result = 0
+for v in results {
+    result = result + v
+}
+`,
+			expected: "55",
+		},
+		{
+			name: "select receives from the ready channel",
+			input: `
+var a = channel()
+var b = channel()
+func send(ch, v) {
+    ch.send(v)
+}
+spawn send(b, "from b")
+var result = select([a, b])
+`,
+			expected: "[1, from b]",
+		},
+		{
+			name: "select sends and falls through without wait",
+			input: `
+var full = channel(1)
+full.send(0)
+var free = channel(1)
+var sent = select([[full, 1], [free, 2]])
+var result = [sent, select([full, channel()], false), select([channel()], false)]
+`,
+			expected: "[[1, null], [0, 0], [-1, null]]",
+		},
+		{
+			name: "spawned closures share the environment",
+			input: `
+var counter = channel(1)
+counter.send(0)
+var wg = waitgroup()
+for i in [1, 2, 3, 4, 5, 6, 7, 8] {
+    wg.add(1)
+    spawn (() => {
+        var n = counter.receive()
+        counter.send(n + 1)
+        wg.done()
+    })()
+}
+wg.wait()
+var result = counter.receive()
+`,
+			expected: "8",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eval, err := runProgram(t, tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expectGlobal(t, eval, "result", tt.expected)
+		})
+	}
+}
+
+func TestConcurrencyErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "send on closed channel",
+			input: "var ch = channel(1)\nch.close()\nch.send(1)",
+			want:  "send on closed channel",
+		},
+		{
+			name:  "close twice",
+			input: "var ch = channel()\nch.close()\nch.close()",
+			want:  "close of closed channel",
+		},
+		{
+			name:  "negative waitgroup",
+			input: "var wg = waitgroup()\nwg.done()",
+			want:  "negative waitgroup counter",
+		},
+		{
+			name:  "spawn of a non-callable",
+			input: "var x = 1\nspawn x()",
+			want:  "cannot spawn",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runProgram(t, tt.input)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestDeadlockDetection(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"main blocked on receive", "var ch = channel()\nch.receive()"},
+		{"tasks blocked on each other", `
+var a = channel()
+var b = channel()
+func left() {
+    a.receive()
+    b.send(1)
+}
+spawn left()
+b.receive()
+a.send(1)
+`},
+		{"task blocked after main ends", "var ch = channel()\nspawn ch.receive()"},
+		{"waitgroup never done", "var wg = waitgroup()\nwg.add(1)\nwg.wait()"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runProgram(t, tt.input)
+			if !errors.Is(err, ErrDeadlock) {
+				t.Fatalf("expected ErrDeadlock, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSpawnErrorStopsProgram(t *testing.T) {
+	input := `
+func fail(n) {
+    throw "task failed"
+}
+func main() {
+    spawn fail(1)
+    var ch = channel()
+    ch.receive()
+}
+`
+	_, err := runProgram(t, input)
+	var runtimeErr *RuntimeError
+	if !errors.As(err, &runtimeErr) {
+		t.Fatalf("expected *RuntimeError, got %T: %v", err, err)
+	}
+	if !strings.Contains(err.Error(), "task failed") {
+		t.Errorf("expected the task's error, got %v", err)
+	}
+
+	// La traza incluye la llamada de main que lanzó la tarea
+	stack := runtimeErr.Stack
+	if len(stack) != 2 || stack[0].Function != "main" || stack[0].Line != 6 || stack[1].Function != "fail" {
+		t.Errorf("unexpected stack: %+v", stack)
+	}
+}
+
+func TestSpawnHonorsCancellation(t *testing.T) {
+	input := `
+func spin() {
+    while true {
+    }
+}
+spawn spin()
+`
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := runWithLimits(t, ctx, Limits{}, input)
+	if !errors.Is(err, ErrCanceled) {
+		t.Fatalf("expected ErrCanceled, got %v", err)
+	}
+}
+
+// TestSharedCollections escribe la misma lista, el mismo hash y la misma
+// instancia desde dos tareas. Sin los bloqueos de List, Hash y ZyloInstance
+// falla con "concurrent map writes" o, con go test -race, con una carrera.
+func TestSharedCollections(t *testing.T) {
+	input := `
+class Counter {
+    var last = 0
+}
+var m = {}
+var l = [0, 0]
+var c = Counter()
+var wg = waitgroup()
+func fill(k, slot) {
+    var i = 0
+    while i < 500 {
+        m[k + i] = i
+        l[slot] = i
+        c.last = i
+        l.Append(i)
+        var seen = [len(m), m[k + i], l[slot], c.last, string(l)]
+        i = i + 1
+    }
+    wg.done()
+}
+wg.add(2)
+spawn fill("a", 0)
+spawn fill("b", 1)
+wg.wait()
+var result = [len(m), l[0], l[1], len(l)]
+`
+	eval, err := runProgram(t, input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectGlobal(t, eval, "result", "[1000, 499, 499, 1002]")
+}
@@ -0,0 +1,194 @@
+package evaluator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+	"github.com/zylo-lang/zylo/internal/modules"
+)
+
+// StackFrame es una entrada de la pila de llamadas de Zylo: la función y la
+// posición que se estaba ejecutando en ella.
+type StackFrame struct {
+	Function string
+	File     string
+	Line     int
+	Column   int
+}
+
+// RuntimeError es un error de ejecución con la región del nodo que falló y la
+// pila de llamadas de Zylo en ese momento (la llamada más reciente al final).
+type RuntimeError struct {
+	Message string
+	File    string
+	Span    ast.Span
+	Stack   []StackFrame
+	Cause   error // Error original; puede ser un *ZyloException
+}
+
+// Error devuelve el mensaje con la posición, al estilo archivo:línea:columna.
+func (re *RuntimeError) Error() string {
+	if re.Span.StartLine == 0 {
+		return re.Message
+	}
+	file := re.File
+	if file == "" {
+		file = "<entrada>"
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", file, re.Span.StartLine, re.Span.StartCol, re.Message)
+}
+
+// Códigos de RuntimeError. No cambian entre versiones. Un ciclo de imports
+// lleva el código de los módulos, modules.CodeLoad.
+const (
+	CodeRuntime   = "R001" // Un error de ejecución sin código más específico
+	CodeUncaught  = "R002" // Una excepción que nadie capturó
+	CodeAssertion = "R003" // Una aserción que falló
+	CodeLimit     = "R004" // Un límite de ejecución excedido o una cancelación
+	CodeDeadlock  = "R005" // Todas las tareas bloqueadas
+)
+
+// Code devuelve el código del error según su causa.
+func (re *RuntimeError) Code() string {
+	var exception *ZyloException
+	var cycle *modules.CycleError
+	switch {
+	case errors.As(re.Cause, &exception):
+		return CodeUncaught
+	case errors.As(re.Cause, &cycle):
+		// El mismo código que da zylo build al cargar los módulos
+		return modules.CodeLoad
+	case errors.Is(re.Cause, ErrAssertion):
+		return CodeAssertion
+	case errors.Is(re.Cause, ErrCanceled), errors.Is(re.Cause, ErrStepLimit),
+		errors.Is(re.Cause, ErrCallDepth), errors.Is(re.Cause, ErrAllocLimit):
+		return CodeLimit
+	case errors.Is(re.Cause, ErrDeadlock):
+		return CodeDeadlock
+	}
+	return CodeRuntime
+}
+
+// Unwrap permite inspeccionar el error original con errors.As / errors.Is.
+func (re *RuntimeError) Unwrap() error {
+	return re.Cause
+}
+
+// Call es una llamada de la pila: Function fue llamada desde la posición
+// Caller, Count veces seguidas.
+type Call struct {
+	Function string
+	Caller   StackFrame
+	Count    int
+}
+
+// Calls devuelve las llamadas de la pila, de la más reciente a la más
+// antigua. Las repeticiones seguidas de una llamada, como las de una
+// recursión, se juntan en una con su número en Count.
+func (re *RuntimeError) Calls() []Call {
+	var calls []Call
+	for i := len(re.Stack) - 2; i >= 0; i-- {
+		call := Call{Function: re.Stack[i+1].Function, Caller: re.Stack[i], Count: 1}
+		if n := len(calls); n > 0 && calls[n-1].Function == call.Function && calls[n-1].Caller == call.Caller {
+			calls[n-1].Count++
+			continue
+		}
+		calls = append(calls, call)
+	}
+	return calls
+}
+
+// Traceback formatea el error con la pila de llamadas, de la llamada más
+// antigua a la más reciente. Las entradas repetidas seguidas se escriben
+// una vez, con el número de repeticiones.
+func (re *RuntimeError) Traceback() string {
+	var out strings.Builder
+	if len(re.Stack) > 0 {
+		out.WriteString("Traza (llamada más reciente al final):\n")
+		for i := 0; i < len(re.Stack); {
+			frame := re.Stack[i]
+			repeated := 0
+			for i++; i < len(re.Stack) && re.Stack[i] == frame; i++ {
+				repeated++
+			}
+			file := frame.File
+			if file == "" {
+				file = "<entrada>"
+			}
+			fmt.Fprintf(&out, "  %s:%d:%d, en %s\n", file, frame.Line, frame.Column, frame.Function)
+			if repeated > 0 {
+				fmt.Fprintf(&out, "  [la línea anterior se repite %d veces más]\n", repeated)
+			}
+		}
+	}
+	out.WriteString(re.Error())
+	return out.String()
+}
+
+// callFrame es la entrada interna de la pila de llamadas del evaluador.
+type callFrame struct {
+	function   string
+	callSite   ast.Span     // Posición de la llamada dentro de la función que llama
+	file       string       // Archivo de la función que llama
+	env        *Environment // Entorno de la función que llama
+	returnType string       // Anotación del tipo de retorno de la función llamada
+}
+
+// pushFrame registra la entrada a una función llamada desde la posición actual.
+// Falla si se excede la profundidad máxima de llamadas.
+func (e *Evaluator) pushFrame(function, returnType string) error {
+	if err := e.checkCallDepth(); err != nil {
+		return err
+	}
+	e.callStack = append(e.callStack, callFrame{function: function, callSite: e.callSite, file: e.file, env: e.env, returnType: returnType})
+	return nil
+}
+
+// popFrame registra la salida de la función más reciente.
+func (e *Evaluator) popFrame() {
+	e.callStack = e.callStack[:len(e.callStack)-1]
+}
+
+// stackTrace construye la pila de llamadas de Zylo terminando en pos.
+func (e *Evaluator) stackTrace(pos ast.Span) []StackFrame {
+	frames := make([]StackFrame, 0, len(e.callStack)+1)
+	caller := "<programa>"
+	for _, frame := range e.callStack {
+		if frame.callSite.StartLine > 0 {
+			frames = append(frames, StackFrame{
+				Function: caller,
+				File:     frame.file,
+				Line:     frame.callSite.StartLine,
+				Column:   frame.callSite.StartCol,
+			})
+		}
+		caller = frame.function
+	}
+	frames = append(frames, StackFrame{
+		Function: caller,
+		File:     e.file,
+		Line:     pos.StartLine,
+		Column:   pos.StartCol,
+	})
+	return frames
+}
+
+// wrapError convierte err en un *RuntimeError ubicado en node, salvo que ya
+// lo sea. Así la posición registrada es la del nodo más interno que falló.
+func (e *Evaluator) wrapError(node ast.Node, err error) error {
+	var runtimeErr *RuntimeError
+	if errors.As(err, &runtimeErr) {
+		return err
+	}
+
+	span := ast.SpanOf(node)
+	return &RuntimeError{
+		Message: err.Error(),
+		File:    e.file,
+		Span:    span,
+		Stack:   e.stackTrace(span),
+		Cause:   err,
+	}
+}
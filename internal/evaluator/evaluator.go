@@ -2,12 +2,19 @@ package evaluator
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/zylo-lang/zylo/internal/ast"
+	"github.com/zylo-lang/zylo/internal/modules"
 )
 
 // ZyloObject representa un objeto en tiempo de ejecución de Zylo
@@ -40,15 +47,61 @@ type Float struct {
 func (f *Float) Type() string    { return "FLOAT_OBJ" }
 func (f *Float) Inspect() string { return fmt.Sprintf("%g", f.Value) }
 
-// List representa un objeto list
+// List representa un objeto list. Items solo se usa directamente al crear la
+// lista; después, como las tareas lanzadas con spawn pueden compartirla, se
+// lee y se modifica con los métodos, que la bloquean.
 type List struct {
+	mu    sync.RWMutex
 	Items []Value
 }
 
+// Len devuelve el número de elementos de la lista.
+func (l *List) Len() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.Items)
+}
+
+// Get devuelve el elemento index, o false si está fuera de la lista.
+func (l *List) Get(index int64) (Value, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if index < 0 || index >= int64(len(l.Items)) {
+		return nil, false
+	}
+	return l.Items[index], true
+}
+
+// Set reemplaza el elemento index, o devuelve false si está fuera de la lista.
+func (l *List) Set(index int64, value Value) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if index < 0 || index >= int64(len(l.Items)) {
+		return false
+	}
+	l.Items[index] = value
+	return true
+}
+
+// Append añade value al final de la lista.
+func (l *List) Append(value Value) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.Items = append(l.Items, value)
+}
+
+// Elements devuelve una copia de los elementos de la lista.
+func (l *List) Elements() []Value {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return append([]Value(nil), l.Items...)
+}
+
 func (l *List) Type() string { return "LIST_OBJ" }
 func (l *List) Inspect() string {
-	parts := make([]string, len(l.Items))
-	for i, el := range l.Items {
+	items := l.Elements()
+	parts := make([]string, len(items))
+	for i, el := range items {
 		if obj, ok := el.(ZyloObject); ok {
 			parts[i] = obj.Inspect()
 		} else {
@@ -57,15 +110,72 @@ func (l *List) Inspect() string {
 	}
 	return "[" + strings.Join(parts, ", ") + "]"
 }
-// Hash representa un objeto hash
+// Hash representa un objeto hash. Como en List, Pairs solo se usa
+// directamente al crear el hash y después con los métodos.
 type Hash struct {
+	mu    sync.RWMutex
 	Pairs map[string]Value
 }
 
+// Len devuelve el número de claves del hash.
+func (h *Hash) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.Pairs)
+}
+
+// Get devuelve el valor de key, o false si no está.
+func (h *Hash) Get(key string) (Value, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	value, ok := h.Pairs[key]
+	return value, ok
+}
+
+// Set asigna value a key y devuelve si key es una clave nueva.
+func (h *Hash) Set(key string, value Value) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, exists := h.Pairs[key]
+	h.Pairs[key] = value
+	return !exists
+}
+
+// Keys devuelve las claves del hash ordenadas.
+func (h *Hash) Keys() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	keys := make([]string, 0, len(h.Pairs))
+	for key := range h.Pairs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Entries devuelve una copia de los pares del hash.
+func (h *Hash) Entries() map[string]Value {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	pairs := make(map[string]Value, len(h.Pairs))
+	for key, value := range h.Pairs {
+		pairs[key] = value
+	}
+	return pairs
+}
+
 func (h *Hash) Type() string { return "HASH_OBJ" }
 func (h *Hash) Inspect() string {
+	entries := h.Entries()
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
 	var pairs []string
-	for key, value := range h.Pairs {
+	for _, key := range keys {
+		value := entries[key]
 		if obj, ok := value.(ZyloObject); ok {
 			pairs = append(pairs, fmt.Sprintf("%s: %s", key, obj.Inspect()))
 		} else {
@@ -96,7 +206,9 @@ type Value interface{}
 
 // Environment representa el entorno de ejecución con variables
 type Environment struct {
+	mu        sync.RWMutex // Las tareas lanzadas con spawn comparten entornos
 	variables map[string]Value
+	types     map[string]string // Anotaciones de tipo de las variables que la tienen
 	parent    *Environment
 }
 
@@ -118,51 +230,259 @@ func (e *Environment) NewChildEnvironment() *Environment {
 
 // Get obtiene el valor de una variable
 func (e *Environment) Get(name string) (Value, bool) {
-	if value, exists := e.variables[name]; exists {
-		return value, true
-	}
-	if e.parent != nil {
-		return e.parent.Get(name)
+	for env := e; env != nil; env = env.parent {
+		env.mu.RLock()
+		value, exists := env.variables[name]
+		env.mu.RUnlock()
+		if exists {
+			return value, true
+		}
 	}
 	return nil, false
 }
 
 // Set establece el valor de una variable
 func (e *Environment) Set(name string, value Value) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.variables[name] = value
+}
+
+// Declare declara name con value y con su anotación de tipo, que se
+// comprueba en las asignaciones. annotation está vacía si no tiene.
+func (e *Environment) Declare(name string, value Value, annotation string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	e.variables[name] = value
+	if annotation == "" {
+		delete(e.types, name)
+		return
+	}
+	if e.types == nil {
+		e.types = make(map[string]string)
+	}
+	e.types[name] = annotation
+}
+
+// Annotation devuelve la anotación de tipo de la variable name, buscándola
+// en el entorno donde fue declarada, o "" si no tiene.
+func (e *Environment) Annotation(name string) string {
+	for env := e; env != nil; env = env.parent {
+		env.mu.RLock()
+		_, exists := env.variables[name]
+		annotation := env.types[name]
+		env.mu.RUnlock()
+		if exists {
+			return annotation
+		}
+	}
+	return ""
+}
+
+// Assign actualiza una variable existente en el entorno donde fue declarada.
+// Devuelve false si la variable no existe en ningún entorno de la cadena.
+func (e *Environment) Assign(name string, value Value) bool {
+	for env := e; env != nil; env = env.parent {
+		env.mu.Lock()
+		_, exists := env.variables[name]
+		if exists {
+			env.variables[name] = value
+		}
+		env.mu.Unlock()
+		if exists {
+			return true
+		}
+	}
+	return false
+}
+
+// Names devuelve los nombres declarados en este entorno, sin los de sus
+// padres, en orden alfabético.
+func (e *Environment) Names() []string {
+	e.mu.RLock()
+	names := make([]string, 0, len(e.variables))
+	for name := range e.variables {
+		names = append(names, name)
+	}
+	e.mu.RUnlock()
+	sort.Strings(names)
+	return names
+}
+
+// Parent devuelve el entorno que contiene a este, o nil en el global.
+func (e *Environment) Parent() *Environment {
+	return e.parent
 }
 
 // Evaluator evalúa expresiones y sentencias de Zylo
 type Evaluator struct {
 	env    *Environment
 	reader *bufio.Reader
+	input  *sync.Mutex // Serializa las lecturas de reader entre tareas
+	stdout io.Writer
+	stderr io.Writer
+
+	file      string      // Archivo fuente, usado en los errores de ejecución
+	callStack []callFrame // Pila de llamadas de Zylo
+	callSite  ast.Span    // Posición de la llamada en curso
+	statement ast.Span    // Posición de la sentencia en curso
+	hooks     Hooks       // Funciones que sigue un depurador
+
+	ctx    context.Context // Cancela la ejecución
+	limits Limits
+	usage  *usage     // Recursos consumidos, compartidos con las tareas
+	tasks  *taskGroup // Tareas de la ejecución en curso, nil fuera de ella
+
+	registry    *moduleRegistry // Módulos cargados, compartidos con los módulos importados
+	importChain []string        // Archivos que se están importando, para detectar ciclos
 }
 
-// NewEvaluator crea un nuevo evaluador
+// NewEvaluator crea un nuevo evaluador conectado a la entrada y salida estándar
 func NewEvaluator() *Evaluator {
+	return NewEvaluatorWithIO(os.Stdin, os.Stdout, os.Stderr)
+}
+
+// NewEvaluatorWithIO crea un evaluador que lee de stdin y escribe la salida
+// del programa en stdout y los avisos en stderr.
+func NewEvaluatorWithIO(stdin io.Reader, stdout, stderr io.Writer) *Evaluator {
+	output := &sync.Mutex{}
 	eval := &Evaluator{
 		env:    NewEnvironment(),
-		reader: bufio.NewReader(os.Stdin),
+		reader: bufio.NewReader(stdin),
+		input:  &sync.Mutex{},
+		stdout: &syncWriter{mu: output, w: stdout},
+		stderr: &syncWriter{mu: output, w: stderr},
+		ctx:    context.Background(),
+		limits: Limits{MaxCallDepth: DefaultMaxCallDepth},
+		usage:  &usage{},
+
+		registry: newModuleRegistry(modules.SearchPath()),
 	}
 	eval.InitBuiltins()
 	return eval
 }
 
+// Define declara (o reemplaza) una variable global.
+func (e *Evaluator) Define(name string, value Value) {
+	e.globals().Set(name, value)
+}
+
+// Lookup obtiene el valor de una variable global.
+func (e *Evaluator) Lookup(name string) (Value, bool) {
+	return e.globals().Get(name)
+}
+
+// Call llama a una función de Zylo, un método ligado o una función built-in
+// desde Go y devuelve su resultado. Si es un Future, devuelve su valor una vez
+// resuelto.
+func (e *Evaluator) Call(fn Value, args []Value) (Value, error) {
+	e.callSite = ast.Span{} // La llamada viene de Go, no de código Zylo
+	if e.tasks == nil && len(e.callStack) == 0 {
+		// Una ejecución nueva, no una llamada desde Execute o un built-in
+		e.resetUsage()
+	}
+	var result Value
+	err := e.runMain(func() (err error) {
+		result, err = e.callFunction(fn, args)
+		if err != nil {
+			return err
+		}
+		// Desde Go no hay 'await': el resultado de una función async se espera aquí
+		result, err = e.await(result)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return &Null{}, nil
+	}
+	return result, nil
+}
+
+// ApplyOperator aplica un operador binario de Zylo a dos valores.
+func (e *Evaluator) ApplyOperator(operator string, left, right Value) (Value, error) {
+	return e.applyOperator(operator, left, right)
+}
+
+// IsTruthy indica si value cuenta como verdadero en una condición.
+func (e *Evaluator) IsTruthy(value Value) bool {
+	return e.isTruthy(value)
+}
+
+// Index obtiene el elemento index de una lista o de un string.
+func (e *Evaluator) Index(left, index Value) (Value, error) {
+	return e.indexValue(left, index)
+}
+
+// BuiltinNames devuelve los nombres de los built-ins que define un evaluador
+// nuevo, ordenados. Los de la forma show.log se usan como miembros.
+func BuiltinNames() []string {
+	return append([]string(nil), builtinNames()...)
+}
+
+// builtinNames calcula los nombres de BuiltinNames una sola vez: el
+// analizador semántico los pide en cada análisis, y el servidor LSP analiza
+// el documento en cada cambio.
+var builtinNames = sync.OnceValue(func() []string {
+	eval := NewEvaluatorWithIO(strings.NewReader(""), io.Discard, io.Discard)
+	globals := eval.Globals()
+	names := make([]string, 0, len(globals))
+	for name := range globals {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+})
+
+// Globals devuelve una copia de las variables globales, incluidos los
+// built-ins.
+func (e *Evaluator) Globals() map[string]Value {
+	env := e.globals()
+	env.mu.RLock()
+	defer env.mu.RUnlock()
+	globals := make(map[string]Value, len(env.variables))
+	for name, value := range env.variables {
+		globals[name] = value
+	}
+	return globals
+}
+
+// globals devuelve el entorno global del evaluador.
+func (e *Evaluator) globals() *Environment {
+	env := e.env
+	for env.parent != nil {
+		env = env.parent
+	}
+	return env
+}
+
+// SetFile indica el nombre del archivo fuente que se evalúa, para que los
+// errores de ejecución y las trazas lo incluyan.
+func (e *Evaluator) SetFile(filename string) {
+	e.file = filename
+}
+
 // InitBuiltins inicializa las funciones incorporadas
 func (e *Evaluator) InitBuiltins() {
 	// Mostrar por consola
 	e.env.Set("show.log", &BuiltinFunction{
 		Name: "show.log",
 		Fn: func(args []Value) (Value, error) {
-			for _, arg := range args {
+			// La línea se escribe de una vez para no mezclarse con otras tareas
+			var line strings.Builder
+			for i, arg := range args {
+				if i > 0 {
+					line.WriteString(" ")
+				}
 				if obj, ok := arg.(ZyloObject); ok {
-					fmt.Print(obj.Inspect(), " ")
+					line.WriteString(obj.Inspect())
 				} else {
-					fmt.Print(arg, " ")
+					fmt.Fprint(&line, arg)
 				}
 			}
-			fmt.Println()
-			os.Stdout.Sync() // Force flush after printing
+			line.WriteString("\n")
+			io.WriteString(e.stdout, line.String())
 			return &Null{}, nil
 		},
 	})
@@ -171,11 +491,9 @@ func (e *Evaluator) InitBuiltins() {
 	e.env.Set("read.line", &BuiltinFunction{
 		Name: "read.line",
 		Fn: func(args []Value) (Value, error) {
-			fmt.Print("> ")  // Mostrar prompt
-			os.Stdout.Sync() // Force flush
-			input, err := e.reader.ReadString('\n')
+			input, err := e.readLine()
 			if err != nil {
-				fmt.Println("⚠️  No se pudo leer entrada, usando valor vacío")
+				fmt.Fprintln(e.stderr, "⚠️  No se pudo leer entrada, usando valor vacío")
 				return &String{Value: ""}, nil
 			}
 			return &String{Value: strings.TrimSpace(input)}, nil
@@ -187,17 +505,15 @@ func (e *Evaluator) InitBuiltins() {
 		Name: "read.int",
 		Fn: func(args []Value) (Value, error) {
 			for { // Loop until valid input is received
-				fmt.Print("> ")  // Mostrar prompt
-				os.Stdout.Sync() // Force flush
-				input, err := e.reader.ReadString('\n')
+				input, err := e.readLine()
 				if err != nil {
-					fmt.Println("⚠️  No se pudo leer entrada, usando 0 por defecto")
+					fmt.Fprintln(e.stderr, "⚠️  No se pudo leer entrada, usando 0 por defecto")
 					return &Integer{Value: 0}, nil // Still return 0 on read error, as per original logic
 				}
 				input = strings.TrimSpace(input)
 				n, err := strconv.Atoi(input)
 				if err != nil {
-					fmt.Println("❌ Error: no es un número válido, por favor intenta de nuevo.")
+					fmt.Fprintln(e.stderr, "❌ Error: no es un número válido, por favor intenta de nuevo.")
 					// Continue the loop to re-prompt
 				} else {
 					// Valid input received, return the integer
@@ -225,7 +541,8 @@ func (e *Evaluator) InitBuiltins() {
 			case *Boolean:
 				return &String{Value: fmt.Sprintf("%t", arg.Value)}, nil
 			default:
-				return &String{Value: fmt.Sprintf("%v", arg)}, nil
+				// Inspect lee las listas y los hashes con su bloqueo
+				return &String{Value: inspectValue(arg)}, nil
 			}
 		},
 	})
@@ -239,9 +556,11 @@ func (e *Evaluator) InitBuiltins() {
 			}
 			switch arg := args[0].(type) {
 			case *List:
-				return &Integer{Value: int64(len(arg.Items))}, nil
+				return &Integer{Value: int64(arg.Len())}, nil
 			case *String:
 				return &Integer{Value: int64(len(arg.Value))}, nil
+			case *Hash:
+				return &Integer{Value: int64(arg.Len())}, nil
 			default:
 				return nil, fmt.Errorf("len() not supported for %T", arg)
 			}
@@ -251,7 +570,7 @@ func (e *Evaluator) InitBuiltins() {
 	// split function
 	e.env.Set("split", &BuiltinFunction{
 		Name: "split",
-		Fn: func(args []Value) (Value, error) {
+		EvalFn: func(e *Evaluator, args []Value) (Value, error) {
 			if len(args) != 2 {
 				return nil, fmt.Errorf("split() expects exactly 2 arguments")
 			}
@@ -264,6 +583,9 @@ func (e *Evaluator) InitBuiltins() {
 				return nil, fmt.Errorf("second argument to split() must be string")
 			}
 			parts := strings.Split(str.Value, sep.Value)
+			if err := e.allocate(int64(len(parts))*ListItemSize + int64(len(str.Value))); err != nil {
+				return nil, err
+			}
 			items := make([]Value, len(parts))
 			for i, part := range parts {
 				items[i] = &String{Value: part}
@@ -296,7 +618,7 @@ func (e *Evaluator) InitBuiltins() {
 	// zyloruntime.Split function
 	e.env.Set("zyloruntime.Split", &BuiltinFunction{
 		Name: "zyloruntime.Split",
-		Fn: func(args []Value) (Value, error) {
+		EvalFn: func(e *Evaluator, args []Value) (Value, error) {
 			if len(args) != 2 {
 				return nil, fmt.Errorf("zyloruntime.Split() expects exactly 2 arguments")
 			}
@@ -309,6 +631,9 @@ func (e *Evaluator) InitBuiltins() {
 				return nil, fmt.Errorf("second argument to zyloruntime.Split() must be string")
 			}
 			parts := strings.Split(str.Value, sep.Value)
+			if err := e.allocate(int64(len(parts))*ListItemSize + int64(len(str.Value))); err != nil {
+				return nil, err
+			}
 			items := make([]Value, len(parts))
 			for i, part := range parts {
 				items[i] = &String{Value: part}
@@ -320,30 +645,28 @@ func (e *Evaluator) InitBuiltins() {
 	// try function
 	e.env.Set("try", &BuiltinFunction{
 		Name: "try",
-		Fn: func(args []Value) (Value, error) {
+		EvalFn: func(e *Evaluator, args []Value) (Value, error) {
 			if len(args) != 2 {
 				return nil, fmt.Errorf("try() expects exactly 2 arguments")
 			}
-			funcBlock, ok := args[0].(*ZyloFunction)
-			if !ok {
+			funcBlock, catchBlock := args[0], args[1]
+			if !isCallable(funcBlock) {
 				return nil, fmt.Errorf("first argument to try() must be function")
 			}
-			catchBlock, ok := args[1].(*ZyloFunction)
-			if !ok {
+			if !isCallable(catchBlock) {
 				return nil, fmt.Errorf("second argument to try() must be function")
 			}
 			// Call funcBlock
-			result, err := e.callZyloFunction(funcBlock, []Value{})
-			if err != nil {
-				// Call catchBlock with error as string
-				errorStr := &String{Value: err.Error()}
-				_, catchErr := e.callZyloFunction(catchBlock, []Value{errorStr})
+			result, err := e.callFunction(funcBlock, []Value{})
+			if err != nil && !uncatchable(err) {
+				// Call catchBlock with the thrown value
+				_, catchErr := e.callFunction(catchBlock, []Value{ExceptionValue(err)})
 				if catchErr != nil {
 					return nil, catchErr
 				}
 				return &Null{}, nil
 			}
-			return result, nil
+			return result, err
 		},
 	})
 
@@ -354,9 +677,7 @@ func (e *Evaluator) InitBuiltins() {
 	e.env.Set("getInput", &BuiltinFunction{
 		Name: "getInput",
 		Fn: func(args []Value) (Value, error) {
-			fmt.Print("> ")
-			os.Stdout.Sync()
-			input, err := e.reader.ReadString('\n')
+			input, err := e.readLine()
 			if err != nil {
 				return &String{Value: ""}, nil
 			}
@@ -407,15 +728,70 @@ func (e *Evaluator) InitBuiltins() {
 			return e.applyOperator("/", args[0], args[1])
 		},
 	})
+
+	e.initConcurrencyBuiltins()
+	e.initFutureBuiltins()
+	e.initAssertBuiltins()
+	e.initStdlibBuiltins()
+}
+
+// readLine muestra el prompt y lee una línea de la entrada. Las tareas
+// comparten la entrada, así que solo una lee a la vez.
+func (e *Evaluator) readLine() (string, error) {
+	e.input.Lock()
+	defer e.input.Unlock()
+	fmt.Fprint(e.stdout, "> ") // Mostrar prompt
+	return e.reader.ReadString('\n')
 }
 
 // EvaluateProgram evalúa un programa completo
 func (e *Evaluator) EvaluateProgram(program *ast.Program) error {
+	e.resetUsage()
+	return e.runMain(func() error {
+		return e.evaluateProgram(program)
+	})
+}
+
+// EvaluateStatements evalúa las sentencias de program sin llamar a main y
+// devuelve el valor de la última. Las declaraciones se acumulan en el entorno
+// global entre llamadas, como en una sesión interactiva.
+func (e *Evaluator) EvaluateStatements(program *ast.Program) (Value, error) {
+	e.resetUsage()
+	var result Value = &Null{}
+	err := e.runMain(func() error {
+		for _, stmt := range program.Statements {
+			value, err := e.evaluateStatement(stmt)
+			if err != nil {
+				return err
+			}
+			if returned, ok := value.(*ReturnValue); ok {
+				result = returned.Value
+				return nil
+			}
+			result = value
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		result = &Null{}
+	}
+	return result, nil
+}
+
+// evaluateProgram evalúa las sentencias del programa y llama a main si existe.
+func (e *Evaluator) evaluateProgram(program *ast.Program) error {
 	for _, stmt := range program.Statements {
-		_, err := e.evaluateStatement(stmt)
+		value, err := e.evaluateStatement(stmt)
 		if err != nil {
 			return err
 		}
+		// Un 'return' a nivel de programa termina la ejecución
+		if _, ok := value.(*ReturnValue); ok {
+			return nil
+		}
 	}
 
 	// Execute main function if it exists
@@ -432,11 +808,33 @@ func (e *Evaluator) EvaluateProgram(program *ast.Program) error {
 	return nil
 }
 
-// evaluateStatement evalúa una sentencia
+// evaluateStatement evalúa una sentencia. Los errores se ubican en la
+// sentencia si ningún nodo más interno lo hizo antes.
 func (e *Evaluator) evaluateStatement(stmt ast.Statement) (Value, error) {
 	if stmt == nil {
 		return nil, fmt.Errorf("nil statement")
 	}
+	if err := e.step(); err != nil {
+		return nil, e.wrapError(stmt, err)
+	}
+	// Al terminar, la sentencia en curso vuelve a ser la que contiene a esta
+	outer := e.statement
+	e.statement = ast.SpanOf(stmt)
+	defer func() { e.statement = outer }()
+	if e.hooks.BeforeStatement != nil {
+		if err := e.hooks.BeforeStatement(e, stmt); err != nil {
+			return nil, e.wrapError(stmt, err)
+		}
+	}
+	value, err := e.evalStatement(stmt)
+	if err != nil {
+		return nil, e.wrapError(stmt, err)
+	}
+	return value, nil
+}
+
+// evalStatement despacha la evaluación según el tipo de sentencia.
+func (e *Evaluator) evalStatement(stmt ast.Statement) (Value, error) {
 	switch s := stmt.(type) {
 	case *ast.VarStatement:
 		if s == nil {
@@ -468,12 +866,7 @@ func (e *Evaluator) evaluateStatement(stmt ast.Statement) (Value, error) {
 		if s == nil {
 			return nil, fmt.Errorf("nil return statement")
 		}
-		// If a return statement is encountered directly, evaluate its value and return it.
-		// This assumes it's handled correctly by the caller (e.g., evaluateBlockStatement).
-		if s.ReturnValue != nil {
-			return e.evaluateExpression(s.ReturnValue)
-		}
-		return &Null{}, nil // Return Null if return statement has no value
+		return e.evaluateReturnStatement(s)
 	case *ast.IfStatement:
 		if s == nil {
 			return nil, fmt.Errorf("nil if statement")
@@ -493,13 +886,13 @@ func (e *Evaluator) evaluateStatement(stmt ast.Statement) (Value, error) {
 	case *ast.ClassStatement:
 		return e.evaluateClassStatement(s)
 	case *ast.TryStatement:
-		// TODO: Implement try-catch to return Value, error
-		return e.evaluateTryStatement(s) // Assuming this will be fixed to return Value, error
+		return e.evaluateTryStatement(s)
 	case *ast.ThrowStatement:
-		// TODO: Implement throw to return Value, error
-		return e.evaluateThrowStatement(s) // Assuming this will be fixed to return Value, error
+		return e.evaluateThrowStatement(s)
 	case *ast.ImportStatement:
 		return e.evaluateImportStatement(s)
+	case *ast.ExportStatement:
+		return e.evaluateExportStatement(s)
 	case *ast.BlockStatement:
 		return e.evaluateBlockStatement(s) // This needs to return Value, error
 	default:
@@ -507,6 +900,26 @@ func (e *Evaluator) evaluateStatement(stmt ast.Statement) (Value, error) {
 	}
 }
 
+// evaluateReturnStatement evalúa el valor de retorno y lo envuelve en una
+// señal ReturnValue que detiene la ejecución hasta el límite de la función.
+func (e *Evaluator) evaluateReturnStatement(stmt *ast.ReturnStatement) (Value, error) {
+	var value Value = &Null{}
+	if stmt.ReturnValue != nil {
+		var err error
+		value, err = e.evaluateExpression(stmt.ReturnValue)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if n := len(e.callStack); n > 0 {
+		var err error
+		if value, err = CheckType(value, e.callStack[n-1].returnType); err != nil {
+			return nil, err
+		}
+	}
+	return &ReturnValue{Value: value}, nil
+}
+
 // evaluateVarStatement evalúa una declaración de variable
 func (e *Evaluator) evaluateVarStatement(stmt *ast.VarStatement) error {
 	var value Value
@@ -519,7 +932,10 @@ func (e *Evaluator) evaluateVarStatement(stmt *ast.VarStatement) error {
 	} else {
 		value = &Null{}
 	}
-	e.env.Set(stmt.Name.Value, value)
+	if value, err = CheckType(value, stmt.Type); err != nil {
+		return err
+	}
+	e.env.Declare(stmt.Name.Value, value, stmt.Type)
 	return nil
 }
 
@@ -529,8 +945,11 @@ func (e *Evaluator) evaluateFuncStatement(stmt *ast.FuncStatement) error {
 	zyloFunc := &ZyloFunction{
 		Name:       stmt.Name.Value,
 		Parameters: stmt.Parameters,
+		ReturnType: stmt.ReturnType,
 		Body:       stmt.Body,
 		Env:        e.env,
+		Async:      stmt.Async,
+		File:       e.file,
 	}
 	e.env.Set(stmt.Name.Value, zyloFunc)
 	return nil
@@ -561,19 +980,63 @@ func (e *Evaluator) evaluateIfStatement(stmt *ast.IfStatement) (Value, error) {
 	return &Null{}, nil
 }
 
-// evaluateTryStatement evalúa una sentencia try-catch
+// evaluateTryStatement evalúa una sentencia try-catch-finally.
+// Cualquier error producido en el bloque try (un 'throw' o un error de
+// ejecución) se entrega al catch, salvo la cancelación y el deadlock; el
+// bloque finally se ejecuta siempre.
 func (e *Evaluator) evaluateTryStatement(stmt *ast.TryStatement) (Value, error) {
-	// TODO: Implementar try-catch
 	if stmt.TryBlock == nil {
 		return nil, fmt.Errorf("nil try block")
 	}
-	return e.evaluateBlockStatement(stmt.TryBlock)
+
+	result, err := e.evaluateBlockStatement(stmt.TryBlock)
+	if err != nil && stmt.CatchClause != nil && !uncatchable(err) {
+		result, err = e.evaluateCatchClause(stmt.CatchClause, err)
+	}
+
+	if stmt.FinallyBlock != nil {
+		finallyResult, finallyErr := e.evaluateBlockStatement(stmt.FinallyBlock)
+		if finallyErr != nil {
+			// Un error dentro de finally reemplaza al resultado del try/catch
+			return nil, finallyErr
+		}
+		if isControlFlow(finallyResult) {
+			return finallyResult, nil
+		}
+	}
+
+	return result, err
+}
+
+// evaluateCatchClause ejecuta el bloque catch con el parámetro ligado al valor lanzado.
+func (e *Evaluator) evaluateCatchClause(clause *ast.CatchClause, thrown error) (Value, error) {
+	if clause.CatchBlock == nil {
+		return nil, fmt.Errorf("nil catch block")
+	}
+
+	catchEnv := e.env.NewChildEnvironment()
+	if clause.Parameter != nil {
+		catchEnv.Set(clause.Parameter.Value, ExceptionValue(thrown))
+	}
+
+	oldEnv := e.env
+	e.env = catchEnv
+	defer func() { e.env = oldEnv }()
+
+	return e.evaluateBlockStatement(clause.CatchBlock)
 }
 
 // evaluateThrowStatement evalúa una sentencia throw
 func (e *Evaluator) evaluateThrowStatement(stmt *ast.ThrowStatement) (Value, error) {
-	// TODO: Implementar throw
-	return &Null{}, nil
+	var value Value = &Null{}
+	if stmt.Exception != nil {
+		var err error
+		value, err = e.evaluateExpression(stmt.Exception)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return nil, &ZyloException{Value: value}
 }
 
 // evaluateBlockStatement evalúa un bloque de sentencias
@@ -593,11 +1056,8 @@ func (e *Evaluator) evaluateBlockStatement(stmt *ast.BlockStatement) (Value, err
 			return nil, err // Propagate error
 		}
 
-		// Handle break and continue
-		if _, ok := value.(*BreakValue); ok {
-			return value, nil
-		}
-		if _, ok := value.(*ContinueValue); ok {
+		// Handle return, break and continue
+		if isControlFlow(value) {
 			return value, nil
 		}
 
@@ -628,7 +1088,10 @@ func (e *Evaluator) evaluateWhileStatement(stmt *ast.WhileStatement) (Value, err
 				return nil, err
 			}
 
-			// Handle break and continue
+			// Handle return, break and continue
+			if _, ok := value.(*ReturnValue); ok {
+				return value, nil // Propagate return to the function boundary
+			}
 			if _, ok := value.(*BreakValue); ok {
 				return &Null{}, nil // Break out of while
 			}
@@ -650,15 +1113,21 @@ func (e *Evaluator) evaluateForInStatement(stmt *ast.ForInStatement) (Value, err
 
 	switch iter := iterable.(type) {
 	case *List:
-		for _, element := range iter.Items {
-			e.env.Set(stmt.Identifier.Value, element)
-
-			result, err := e.evaluateBlockStatement(stmt.Body)
+		// Como range, recorre los elementos que había al empezar
+		for i, n := int64(0), int64(iter.Len()); i < n; i++ {
+			element, ok := iter.Get(i)
+			if !ok {
+				break
+			}
+			result, err := e.evaluateIteration(stmt, element)
 			if err != nil {
 				return nil, err
 			}
 
-			// Handle break and continue
+			// Handle return, break and continue
+			if _, ok := result.(*ReturnValue); ok {
+				return result, nil
+			}
 			if _, ok := result.(*BreakValue); ok {
 				break
 			}
@@ -668,14 +1137,15 @@ func (e *Evaluator) evaluateForInStatement(stmt *ast.ForInStatement) (Value, err
 		}
 	case *String:
 		for _, char := range iter.Value {
-			e.env.Set(stmt.Identifier.Value, &String{Value: string(char)})
-
-			result, err := e.evaluateBlockStatement(stmt.Body)
+			result, err := e.evaluateIteration(stmt, &String{Value: string(char)})
 			if err != nil {
 				return nil, err
 			}
 
-			// Handle break and continue
+			// Handle return, break and continue
+			if _, ok := result.(*ReturnValue); ok {
+				return result, nil
+			}
 			if _, ok := result.(*BreakValue); ok {
 				break
 			}
@@ -683,6 +1153,43 @@ func (e *Evaluator) evaluateForInStatement(stmt *ast.ForInStatement) (Value, err
 				continue
 			}
 		}
+	case *Hash:
+		// Recorre las claves en orden, como el código Go generado
+		for _, key := range iter.Keys() {
+			result, err := e.evaluateIteration(stmt, &String{Value: key})
+			if err != nil {
+				return nil, err
+			}
+
+			if _, ok := result.(*ReturnValue); ok {
+				return result, nil
+			}
+			if _, ok := result.(*BreakValue); ok {
+				break
+			}
+		}
+	case *Channel:
+		// Recibe valores hasta que el canal se cierre
+		for {
+			element, ok, err := e.receive(iter)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				break
+			}
+			result, err := e.evaluateIteration(stmt, element)
+			if err != nil {
+				return nil, err
+			}
+
+			if _, ok := result.(*ReturnValue); ok {
+				return result, nil
+			}
+			if _, ok := result.(*BreakValue); ok {
+				break
+			}
+		}
 	default:
 		return nil, fmt.Errorf("cannot iterate over %T", iterable)
 	}
@@ -690,28 +1197,17 @@ func (e *Evaluator) evaluateForInStatement(stmt *ast.ForInStatement) (Value, err
 	return &Null{}, nil
 }
 
-// evaluateImportStatement evalúa una declaración de import
-func (e *Evaluator) evaluateImportStatement(stmt *ast.ImportStatement) (Value, error) {
-	if stmt.ModuleName == nil {
-		return nil, fmt.Errorf("import statement has nil module name")
-	}
-
-	moduleName := stmt.ModuleName.Value
-
-	// Check if module is already imported - if so, ignore silently
-	if _, exists := e.env.Get(moduleName); exists {
-		return &Null{}, nil
-	}
-
-	// For now, we only support "zyloruntime" module
-	if moduleName == "zyloruntime" {
-		// Create a module object with available functions
-		moduleObj := &String{Value: "zyloruntime_module"}
-		e.env.Set(moduleName, moduleObj)
-		return &Null{}, nil
-	}
-
-	return nil, fmt.Errorf("Module '%s' not found", moduleName)
+// evaluateIteration ejecuta el cuerpo del for-in con la variable del bucle
+// ligada a element en un entorno propio de la iteración, como el código Go
+// generado: la variable no reemplaza a una de fuera del bucle con el mismo
+// nombre y cada closure creado en el cuerpo captura la de su iteración.
+func (e *Evaluator) evaluateIteration(stmt *ast.ForInStatement, element Value) (Value, error) {
+	iterEnv := e.env.NewChildEnvironment()
+	iterEnv.Set(stmt.Identifier.Value, element)
+	oldEnv := e.env
+	e.env = iterEnv
+	defer func() { e.env = oldEnv }()
+	return e.evaluateBlockStatement(stmt.Body)
 }
 
 // evaluateBreakStatement evalúa una sentencia break
@@ -734,6 +1230,19 @@ func (e *Evaluator) evaluateClassStatement(stmt *ast.ClassStatement) (Value, err
 		InitMethod: nil,
 	}
 
+	// Resolver la superclase
+	if stmt.SuperClass != nil {
+		parent, err := e.evaluateExpression(stmt.SuperClass)
+		if err != nil {
+			return nil, err
+		}
+		parentClass, ok := parent.(*ZyloClass)
+		if !ok {
+			return nil, fmt.Errorf("superclass '%s' is not a class", stmt.SuperClass.Value)
+		}
+		classObj.Parent = parentClass
+	}
+
 	// Set attributes
 	for _, attr := range stmt.Attributes {
 		if attr.Value != nil {
@@ -752,8 +1261,12 @@ func (e *Evaluator) evaluateClassStatement(stmt *ast.ClassStatement) (Value, err
 		zyloFunc := &ZyloFunction{
 			Name:       method.Name.Value,
 			Parameters: method.Parameters,
+			ReturnType: method.ReturnType,
 			Body:       method.Body,
 			Env:        e.env,
+			Class:      classObj,
+			Async:      method.Async,
+			File:       e.file,
 		}
 		classObj.Methods[method.Name.Value] = zyloFunc
 
@@ -767,12 +1280,24 @@ func (e *Evaluator) evaluateClassStatement(stmt *ast.ClassStatement) (Value, err
 	return &Null{}, nil
 }
 
-// evaluateExpression evalúa una expresión
+// evaluateExpression evalúa una expresión. Los errores se ubican en el nodo
+// más interno que falló.
 func (e *Evaluator) evaluateExpression(exp ast.Expression) (Value, error) {
 	if exp == nil {
 		return nil, fmt.Errorf("nil expression passed to evaluator")
 	}
+	if err := e.step(); err != nil {
+		return nil, e.wrapError(exp, err)
+	}
+	value, err := e.evalExpression(exp)
+	if err != nil {
+		return nil, e.wrapError(exp, err)
+	}
+	return value, nil
+}
 
+// evalExpression despacha la evaluación según el tipo de expresión.
+func (e *Evaluator) evalExpression(exp ast.Expression) (Value, error) {
 	switch ex := exp.(type) {
 	case *ast.Identifier:
 		if ex == nil {
@@ -826,7 +1351,23 @@ func (e *Evaluator) evaluateExpression(exp ast.Expression) (Value, error) {
 				return nil, err
 			}
 		}
+		if err := e.allocate(int64(len(elements)) * ListItemSize); err != nil {
+			return nil, err
+		}
 		return &List{Items: elements}, nil
+	case *ast.FunctionLiteral:
+		if ex == nil {
+			return nil, fmt.Errorf("nil function literal")
+		}
+		// La función captura el entorno actual: es un closure
+		return &ZyloFunction{
+			Name:       anonymousFunctionName,
+			Parameters: ex.Parameters,
+			ReturnType: ex.ReturnType,
+			Body:       ex.Body,
+			Env:        e.env,
+			File:       e.file,
+		}, nil
 	case *ast.HashLiteral:
 		if ex == nil {
 			return nil, fmt.Errorf("nil hash literal")
@@ -848,6 +1389,13 @@ func (e *Evaluator) evaluateExpression(exp ast.Expression) (Value, error) {
 			}
 			pairs[keyStr.Value] = val
 		}
+		var size int64
+		for key := range pairs {
+			size += HashEntrySize + int64(len(key))
+		}
+		if err := e.allocate(size); err != nil {
+			return nil, err
+		}
 		return &Hash{Pairs: pairs}, nil
 	case *ast.IndexExpression:
 		if ex == nil {
@@ -872,6 +1420,20 @@ func (e *Evaluator) evaluateExpression(exp ast.Expression) (Value, error) {
 			return nil, fmt.Errorf("nil prefix expression")
 		}
 		return e.evaluatePrefixExpression(ex)
+	case *ast.SpawnExpression:
+		return e.evaluateSpawnExpression(ex)
+	case *ast.AwaitExpression:
+		value, err := e.evaluateExpression(ex.Value)
+		if err != nil {
+			return nil, err
+		}
+		return e.await(value)
+	case *ast.SuperExpression:
+		value, exists := e.env.Get("super")
+		if !exists {
+			return nil, fmt.Errorf("'super' is not available in this context")
+		}
+		return value, nil
 	case *ast.ThisExpression:
 		if ex == nil {
 			return nil, fmt.Errorf("nil this expression")
@@ -922,13 +1484,27 @@ func (e *Evaluator) evaluateMemberExpression(exp *ast.MemberExpression) (Value,
 	}
 
 	// Para otros casos, evaluar el objeto primero
-		obj, err := e.evaluateExpression(exp.Object)
-		if err != nil {
-			return nil, err
-		}
-		if obj == nil {
-			return nil, fmt.Errorf("cannot access member on nil object")
-		}
+	obj, err := e.evaluateExpression(exp.Object)
+	if err != nil {
+		return nil, err
+	}
+	return e.Member(obj, propName)
+}
+
+// Member obtiene la propiedad o el método propName de obj.
+func (e *Evaluator) Member(obj Value, propName string) (Value, error) {
+	if obj == nil {
+		return nil, fmt.Errorf("cannot access member on nil object")
+	}
+
+	switch o := obj.(type) {
+	case *Channel:
+		return channelMethod(o, propName)
+	case *WaitGroup:
+		return waitGroupMethod(o, propName)
+	case *Module:
+		return o.Export(propName)
+	}
 
 	// Handle zyloruntime namespace
 	if identifier, ok := obj.(*ast.Identifier); ok && identifier.Value == "zyloruntime" {
@@ -964,7 +1540,7 @@ func (e *Evaluator) evaluateMemberExpression(exp *ast.MemberExpression) (Value,
 	// Handle List methods
 	if list, ok := obj.(*List); ok {
 		switch propName {
-		case "Get":
+		case "Get", "get":
 			return &BuiltinFunction{
 				Name: "List.Get",
 				Fn: func(args []Value) (Value, error) {
@@ -975,28 +1551,32 @@ func (e *Evaluator) evaluateMemberExpression(exp *ast.MemberExpression) (Value,
 					if !ok {
 						return nil, fmt.Errorf("List.Get() index must be integer")
 					}
-					if idx.Value < 0 || int(idx.Value) >= len(list.Items) {
+					item, ok := list.Get(idx.Value)
+					if !ok {
 						return nil, fmt.Errorf("index out of bounds")
 					}
-					return list.Items[idx.Value], nil
+					return item, nil
 				},
 			}, nil
-		case "Append":
+		case "Append", "append":
 			return &BuiltinFunction{
 				Name: "List.Append",
 				Fn: func(args []Value) (Value, error) {
 					if len(args) != 1 {
 						return nil, fmt.Errorf("List.Append() expects exactly 1 argument")
 					}
-					list.Items = append(list.Items, args[0])
+					if err := e.allocate(ListItemSize); err != nil {
+						return nil, err
+					}
+					list.Append(args[0])
 					return &Null{}, nil
 				},
 			}, nil
-		case "Len":
+		case "Len", "len":
 			return &BuiltinFunction{
 				Name: "List.Len",
 				Fn: func(args []Value) (Value, error) {
-					return &Integer{Value: int64(len(list.Items))}, nil
+					return &Integer{Value: int64(list.Len())}, nil
 				},
 			}, nil
 		default:
@@ -1004,12 +1584,61 @@ func (e *Evaluator) evaluateMemberExpression(exp *ast.MemberExpression) (Value,
 		}
 	}
 
+	// Handle Hash methods
+	if hash, ok := obj.(*Hash); ok {
+		switch propName {
+		case "Get", "get":
+			return &BuiltinFunction{
+				Name: "Hash.Get",
+				Fn: func(args []Value) (Value, error) {
+					if len(args) != 1 {
+						return nil, fmt.Errorf("Hash.Get() expects exactly 1 argument")
+					}
+					key, ok := args[0].(*String)
+					if !ok {
+						return nil, fmt.Errorf("Hash.Get() key must be string")
+					}
+					value, ok := hash.Get(key.Value)
+					if !ok {
+						return &Null{}, nil
+					}
+					return value, nil
+				},
+			}, nil
+		case "Set", "set":
+			return &BuiltinFunction{
+				Name: "Hash.Set",
+				Fn: func(args []Value) (Value, error) {
+					if len(args) != 2 {
+						return nil, fmt.Errorf("Hash.Set() expects exactly 2 arguments")
+					}
+					if _, ok := args[0].(*String); !ok {
+						return nil, fmt.Errorf("Hash.Set() key must be string")
+					}
+					if err := e.SetIndex(hash, args[0], args[1]); err != nil {
+						return nil, err
+					}
+					return &Null{}, nil
+				},
+			}, nil
+		case "Len", "len":
+			return &BuiltinFunction{
+				Name: "Hash.Len",
+				Fn: func(args []Value) (Value, error) {
+					return &Integer{Value: int64(hash.Len())}, nil
+				},
+			}, nil
+		default:
+			return nil, fmt.Errorf("method '%s' not found on Hash", propName)
+		}
+	}
+
 	// Handle instance member access
 	if instance, ok := obj.(*ZyloInstance); ok {
-		if field, exists := instance.Fields[propName]; exists {
+		if field, exists := instance.Field(propName); exists {
 			return field, nil
 		}
-		if method, exists := instance.Class.Methods[propName]; exists {
+		if method := instance.Class.FindMethod(propName); method != nil {
 			// Return a bound method
 			return &BoundMethod{
 				Instance: instance,
@@ -1019,6 +1648,17 @@ func (e *Evaluator) evaluateMemberExpression(exp *ast.MemberExpression) (Value,
 		return nil, fmt.Errorf("property '%s' not found on instance of %s", propName, instance.Class.Name)
 	}
 
+	// super.metodo: el método de la superclase ligado a la instancia actual
+	if super, ok := obj.(*SuperReference); ok {
+		if method := super.Class.FindMethod(propName); method != nil {
+			return &BoundMethod{
+				Instance: super.Instance,
+				Method:   method,
+			}, nil
+		}
+		return nil, fmt.Errorf("method '%s' not found on superclass %s", propName, super.Class.Name)
+	}
+
 	return nil, fmt.Errorf("cannot access property '%s' on %T", propName, obj)
 }
 
@@ -1034,11 +1674,6 @@ func (e *Evaluator) evaluateCallExpression(exp *ast.CallExpression) (Value, erro
 		return nil, err
 	}
 
-	// Check if it's a class instantiation
-	if class, ok := fn.(*ZyloClass); ok {
-		return e.instantiateClass(class, exp.Arguments)
-	}
-
 	// Evaluar argumentos
 	args := make([]Value, len(exp.Arguments))
 	for i, arg := range exp.Arguments {
@@ -1051,7 +1686,11 @@ func (e *Evaluator) evaluateCallExpression(exp *ast.CallExpression) (Value, erro
 		}
 	}
 
-	// Llamar a la función
+	// Llamar a la función o instanciar la clase
+	e.callSite = ast.SpanOf(exp)
+	if class, ok := fn.(*ZyloClass); ok {
+		return e.instantiateClass(class, args)
+	}
 	return e.callFunction(fn, args)
 }
 
@@ -1066,40 +1705,108 @@ func (e *Evaluator) evaluateInfixExpression(exp *ast.InfixExpression) (Value, er
 
 	// Handle assignment operator
 	if exp.Operator == "=" {
-		// Check if left is an identifier
-		leftIdent, ok := exp.Left.(*ast.Identifier)
-		if !ok {
-			return nil, fmt.Errorf("left side of assignment must be an identifier")
-		}
-
-		// Evaluate the right side
-		right, err := e.evaluateExpression(exp.Right)
-		if err != nil {
-			return nil, err
-		}
+		return e.evaluateAssignment(exp)
+	}
 
-		// Check if the variable is declared (exists in environment)
-		_, exists := e.env.Get(leftIdent.Value)
-		if !exists {
-			return nil, fmt.Errorf("variable no definida: %s", leftIdent.Value)
-		}
+	left, err := e.evaluateExpression(exp.Left)
+	if err != nil {
+		return nil, err
+	}
 
-		// Assign the value
-		e.env.Set(leftIdent.Value, right)
-		return right, nil
+	right, err := e.evaluateExpression(exp.Right)
+	if err != nil {
+		return nil, err
 	}
 
-	left, err := e.evaluateExpression(exp.Left)
+	result, err := e.applyOperator(exp.Operator, left, right)
 	if err != nil {
 		return nil, err
 	}
+	if str, ok := result.(*String); ok {
+		if err := e.allocate(int64(len(str.Value))); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
 
+// evaluateAssignment evalúa una asignación a una variable, a un miembro
+// (obj.campo = valor) o a un índice (lista[i] = valor).
+func (e *Evaluator) evaluateAssignment(exp *ast.InfixExpression) (Value, error) {
+	// Evaluate the right side
 	right, err := e.evaluateExpression(exp.Right)
 	if err != nil {
 		return nil, err
 	}
 
-	return e.applyOperator(exp.Operator, left, right)
+	switch target := exp.Left.(type) {
+	case *ast.Identifier:
+		// Assign the value where the variable was declared
+		if right, err = CheckType(right, e.env.Annotation(target.Value)); err != nil {
+			return nil, err
+		}
+		if !e.env.Assign(target.Value, right) {
+			return nil, fmt.Errorf("variable no definida: %s", target.Value)
+		}
+		return right, nil
+	case *ast.MemberExpression:
+		obj, err := e.evaluateExpression(target.Object)
+		if err != nil {
+			return nil, err
+		}
+		return right, e.SetMember(obj, target.Property.Value, right)
+	case *ast.IndexExpression:
+		left, err := e.evaluateExpression(target.Left)
+		if err != nil {
+			return nil, err
+		}
+		index, err := e.evaluateExpression(target.Index)
+		if err != nil {
+			return nil, err
+		}
+		return right, e.SetIndex(left, index, right)
+	default:
+		return nil, fmt.Errorf("invalid assignment target: %T", exp.Left)
+	}
+}
+
+// SetMember asigna value a la propiedad propName de obj.
+func (e *Evaluator) SetMember(obj Value, propName string, value Value) error {
+	switch o := obj.(type) {
+	case *ZyloInstance:
+		o.SetField(propName, value)
+	case *Hash:
+		o.Set(propName, value)
+	default:
+		return fmt.Errorf("cannot assign property '%s' on %T", propName, obj)
+	}
+	return nil
+}
+
+// SetIndex asigna value a la posición index de una lista o a la clave index
+// de un hash.
+func (e *Evaluator) SetIndex(left, index, value Value) error {
+	switch l := left.(type) {
+	case *List:
+		idx, ok := index.(*Integer)
+		if !ok {
+			return fmt.Errorf("list index must be integer")
+		}
+		if !l.Set(idx.Value, value) {
+			return fmt.Errorf("index out of bounds")
+		}
+	case *Hash:
+		key, ok := index.(*String)
+		if !ok {
+			return fmt.Errorf("hash key must be string")
+		}
+		if l.Set(key.Value, value) {
+			return e.allocate(HashEntrySize + int64(len(key.Value)))
+		}
+	default:
+		return fmt.Errorf("cannot index-assign %T", left)
+	}
+	return nil
 }
 
 // evaluatePrefixExpression evalúa una expresión prefija
@@ -1120,6 +1827,9 @@ func (e *Evaluator) evaluatePrefixExpression(exp *ast.PrefixExpression) (Value,
 		if num, ok := right.(*Integer); ok {
 			return &Integer{Value: -num.Value}, nil
 		}
+		if num, ok := right.(*Float); ok {
+			return &Float{Value: -num.Value}, nil
+		}
 		return nil, fmt.Errorf("operador '-' no soportado para tipo %T", right)
 	default:
 		return nil, fmt.Errorf("operador prefijo no soportado: %s", exp.Operator)
@@ -1133,10 +1843,20 @@ func (e *Evaluator) callFunction(fn Value, args []Value) (Value, error) {
 	}
 	switch f := fn.(type) {
 	case *ZyloFunction:
+		if f.Async {
+			return e.async(func(task *Evaluator) (Value, error) {
+				return task.callZyloFunction(f, args)
+			})
+		}
 		return e.callZyloFunction(f, args)
 	case *BuiltinFunction:
-		return f.Fn(args)
+		return f.call(e, args)
 	case *BoundMethod:
+		if f.Method.Async {
+			return e.async(func(task *Evaluator) (Value, error) {
+				return task.callBoundMethod(f, args)
+			})
+		}
 		return e.callBoundMethod(f, args)
 	default:
 		// Intentar funciones built-in
@@ -1148,47 +1868,28 @@ func (e *Evaluator) callFunction(fn Value, args []Value) (Value, error) {
 }
 
 // instantiateClass crea una instancia de una clase
-func (e *Evaluator) instantiateClass(class *ZyloClass, args []ast.Expression) (Value, error) {
+func (e *Evaluator) instantiateClass(class *ZyloClass, args []Value) (Value, error) {
 	instance := &ZyloInstance{
 		Class:  class,
 		Fields: make(map[string]Value),
 	}
 
-	// Copy class attributes to instance
-	for name, value := range class.Attributes {
-		instance.Fields[name] = value
+	// Copy class attributes to instance, from the root class down so that
+	// subclasses override inherited defaults
+	var chain []*ZyloClass
+	for c := class; c != nil; c = c.Parent {
+		chain = append(chain, c)
 	}
-
-	// Call init method if it exists
-	if class.InitMethod != nil {
-		// Evaluate arguments
-		evalArgs := make([]Value, len(args))
-		for i, arg := range args {
-			var err error
-			evalArgs[i], err = e.evaluateExpression(arg)
-			if err != nil {
-				return nil, err
-			}
+	for i := len(chain) - 1; i >= 0; i-- {
+		for name, value := range chain[i].Attributes {
+			instance.Fields[name] = value
 		}
+	}
 
-		// Create instance environment
-		funcEnv := class.InitMethod.Env.NewChildEnvironment()
-		funcEnv.Set("this", instance)
-
-		// Set parameters
-		for i, param := range class.InitMethod.Parameters {
-			if i < len(evalArgs) {
-				funcEnv.Set(param.Value, evalArgs[i])
-			}
-		}
-
-		// Execute init method
-		oldEnv := e.env
-		e.env = funcEnv
-		defer func() { e.env = oldEnv }()
-
-		_, err := e.evaluateBlockStatement(class.InitMethod.Body)
-		if err != nil {
+	// Call init method if it exists, own or inherited
+	if init := class.FindMethod("init"); init != nil {
+		// El valor de retorno de init se descarta: la instanciación devuelve la instancia
+		if _, err := e.callBoundMethod(&BoundMethod{Instance: instance, Method: init}, args); err != nil {
 			return nil, err
 		}
 	}
@@ -1202,47 +1903,100 @@ func (e *Evaluator) callZyloFunction(fn *ZyloFunction, args []Value) (Value, err
 	funcEnv := fn.Env.NewChildEnvironment()
 
 	// Establecer parámetros
-	for i, param := range fn.Parameters {
-		if i < len(args) {
-			funcEnv.Set(param.Value, args[i]) // Usar param.Value
-		}
+	if err := bindParameters(funcEnv, fn.Parameters, args); err != nil {
+		return nil, err
 	}
 
 	// Ejecutar cuerpo de la función
+	if err := e.pushFrame(fn.Name, fn.ReturnType); err != nil {
+		return nil, err
+	}
+	defer e.popFrame()
+	defer e.enterFile(fn.File)()
 	oldEnv := e.env
 	e.env = funcEnv
 	defer func() { e.env = oldEnv }()
 
-	result, err := e.evaluateBlockStatement(fn.Body) // Capture the result
-	if err != nil {
-		return nil, err // Propagate error
+	return e.evaluateBody(fn.Name, fn.Body)
+}
+
+// bindParameters declara en env los parámetros con los argumentos args,
+// comprobando sus anotaciones de tipo. Los parámetros sin argumento no se
+// declaran.
+func bindParameters(env *Environment, params []*ast.Identifier, args []Value) error {
+	for i, param := range params {
+		if i >= len(args) {
+			break
+		}
+		value, err := CheckType(args[i], param.Type)
+		if err != nil {
+			return err
+		}
+		env.Declare(param.Value, value, param.Type)
 	}
-	return result, nil // Return the captured result
+	return nil
 }
 
 // callBoundMethod llama a un método ligado
 func (e *Evaluator) callBoundMethod(boundMethod *BoundMethod, args []Value) (Value, error) {
 	// Crear entorno de método
-	funcEnv := boundMethod.Method.Env.NewChildEnvironment()
+	method := boundMethod.Method
+	funcEnv := method.Env.NewChildEnvironment()
 	funcEnv.Set("this", boundMethod.Instance)
+	if method.Class != nil && method.Class.Parent != nil {
+		funcEnv.Set("super", &SuperReference{Instance: boundMethod.Instance, Class: method.Class.Parent})
+	}
 
 	// Establecer parámetros
-	for i, param := range boundMethod.Method.Parameters {
-		if i < len(args) {
-			funcEnv.Set(param.Value, args[i])
-		}
+	if err := bindParameters(funcEnv, method.Parameters, args); err != nil {
+		return nil, err
 	}
 
-	// Ejecutar cuerpo del método
+	// Ejecutar cuerpo del método. La traza nombra la clase que lo define,
+	// que puede ser una superclase de la instancia.
+	className := boundMethod.Instance.Class.Name
+	if method.Class != nil {
+		className = method.Class.Name
+	}
+	if err := e.pushFrame(className+"."+method.Name, method.ReturnType); err != nil {
+		return nil, err
+	}
+	defer e.popFrame()
+	defer e.enterFile(method.File)()
 	oldEnv := e.env
 	e.env = funcEnv
 	defer func() { e.env = oldEnv }()
 
-	result, err := e.evaluateBlockStatement(boundMethod.Method.Body)
+	return e.evaluateBody(className+"."+method.Name, method.Body)
+}
+
+// evaluateBody evalúa el cuerpo de la función llamada function, con su marco
+// y su entorno ya preparados, y avisa a los hooks de la entrada y la salida.
+func (e *Evaluator) evaluateBody(function string, body *ast.BlockStatement) (Value, error) {
+	if e.hooks.OnCall != nil {
+		if err := e.hooks.OnCall(e, function, e.callStack[len(e.callStack)-1].callSite); err != nil {
+			return nil, err
+		}
+	}
+	result, err := e.evaluateBlockStatement(body)
 	if err != nil {
-		return nil, err
+		result = nil
+	} else {
+		result = unwrapReturnValue(result)
 	}
-	return result, nil
+	if e.hooks.OnReturn != nil {
+		e.hooks.OnReturn(e, function, result, err)
+	}
+	return result, err
+}
+
+// unwrapReturnValue extrae el valor de una señal de retorno al llegar al
+// límite de una función. Si el cuerpo terminó sin 'return', el resultado es null.
+func unwrapReturnValue(result Value) Value {
+	if ret, ok := result.(*ReturnValue); ok {
+		return ret.Value
+	}
+	return &Null{}
 }
 
 // evaluateThisExpression evalúa una expresión 'this'
@@ -1260,13 +2014,24 @@ func (e *Evaluator) callBuiltinFunction(name string, args []Value) (Value, error
 	// Buscar la función en el entorno
 	if builtin, exists := e.env.Get(name); exists {
 		if fn, ok := builtin.(*BuiltinFunction); ok {
-			return fn.Fn(args)
+			return fn.call(e, args)
 		}
 	}
 	return nil, fmt.Errorf("función no definida: %s", name)
 }
 
 // applyOperator aplica un operador binario
+// numberValue devuelve el valor de un Integer o un Float como float64.
+func numberValue(value Value) (float64, bool) {
+	switch v := value.(type) {
+	case *Integer:
+		return float64(v.Value), true
+	case *Float:
+		return v.Value, true
+	}
+	return 0, false
+}
+
 func (e *Evaluator) applyOperator(operator string, left, right Value) (Value, error) {
 	// Check for nil operands
 	if left == nil || right == nil {
@@ -1377,6 +2142,21 @@ func (e *Evaluator) applyOperator(operator string, left, right Value) (Value, er
 				return &Float{Value: leftFloat.Value / rightFloat.Value}, nil
 			}
 		}
+	case "%":
+		if leftNum, ok := left.(*Integer); ok {
+			if rightNum, ok := right.(*Integer); ok {
+				if rightNum.Value == 0 {
+					return nil, fmt.Errorf("división por cero")
+				}
+				return &Integer{Value: leftNum.Value % rightNum.Value}, nil
+			}
+		}
+		// Con algún float, el resto es el de math.Mod
+		leftValue, leftOk := numberValue(left)
+		rightValue, rightOk := numberValue(right)
+		if leftOk && rightOk {
+			return &Float{Value: math.Mod(leftValue, rightValue)}, nil
+		}
 	case "==":
 		// Handle string comparison specifically
 		if leftStr, ok := left.(*String); ok {
@@ -1407,6 +2187,11 @@ func (e *Evaluator) applyOperator(operator string, left, right Value) (Value, er
 				return &Boolean{Value: leftBool.Value == rightBool.Value}, nil
 			}
 		}
+		// null solo es igual a null
+		if _, ok := left.(*Null); ok {
+			_, rightNull := right.(*Null)
+			return &Boolean{Value: rightNull}, nil
+		}
 		return &Boolean{Value: false}, nil
 	case "!=":
 		// Handle string comparison specifically
@@ -1438,6 +2223,11 @@ func (e *Evaluator) applyOperator(operator string, left, right Value) (Value, er
 				return &Boolean{Value: leftBool.Value != rightBool.Value}, nil
 			}
 		}
+		// null solo es igual a null
+		if _, ok := left.(*Null); ok {
+			_, rightNull := right.(*Null)
+			return &Boolean{Value: !rightNull}, nil
+		}
 		return &Boolean{Value: true}, nil
 	case "<":
 		if leftNum, ok := left.(*Integer); ok {
@@ -1507,6 +2297,32 @@ func (e *Evaluator) applyOperator(operator string, left, right Value) (Value, er
 				return &Boolean{Value: leftFloat.Value >= rightFloat.Value}, nil
 			}
 		}
+	case "..":
+		// start..end son los enteros de start a end, sin incluir end
+		start, ok := left.(*Integer)
+		if !ok {
+			break
+		}
+		end, ok := right.(*Integer)
+		if !ok {
+			break
+		}
+		n := max(end.Value-start.Value, 0)
+		if err := e.allocate(n * ListItemSize); err != nil {
+			return nil, err
+		}
+		items := make([]Value, n)
+		for i := range items {
+			items[i] = &Integer{Value: start.Value + int64(i)}
+		}
+		return &List{Items: items}, nil
+	case "instanceof":
+		class, ok := right.(*ZyloClass)
+		if !ok {
+			return nil, fmt.Errorf("right operand of 'instanceof' must be a class, got %T", right)
+		}
+		instance, ok := left.(*ZyloInstance)
+		return &Boolean{Value: ok && instance.Class.IsSubclassOf(class)}, nil
 	case "&&":
 		leftBool := e.isTruthy(left)
 		if !leftBool {
@@ -1554,10 +2370,11 @@ func (e *Evaluator) indexValue(left, index Value) (Value, error) {
 		if !ok {
 			return nil, fmt.Errorf("list index must be integer")
 		}
-		if idx.Value < 0 || int(idx.Value) >= len(l.Items) {
+		item, ok := l.Get(idx.Value)
+		if !ok {
 			return nil, fmt.Errorf("index out of bounds")
 		}
-		return l.Items[idx.Value], nil
+		return item, nil
 	case *String:
 		idx, ok := index.(*Integer)
 		if !ok {
@@ -1567,29 +2384,85 @@ func (e *Evaluator) indexValue(left, index Value) (Value, error) {
 			return nil, fmt.Errorf("index out of bounds")
 		}
 		return &String{Value: string(l.Value[idx.Value])}, nil
+	case *Hash:
+		key, ok := index.(*String)
+		if !ok {
+			return nil, fmt.Errorf("hash key must be string")
+		}
+		value, ok := l.Get(key.Value)
+		if !ok {
+			return &Null{}, nil
+		}
+		return value, nil
 	default:
 		return nil, fmt.Errorf("cannot index %T", left)
 	}
 }
 
+// anonymousFunctionName es el nombre de las funciones anónimas en las trazas.
+const anonymousFunctionName = "<anónima>"
+
 // ZyloFunction representa una función definida en Zylo
 type ZyloFunction struct {
 	Name       string
 	Parameters []*ast.Identifier // Cambiado de []*ast.Variable a []*ast.Identifier
 	ReturnType string          // Nuevo campo para el tipo de retorno
 	Body       *ast.BlockStatement
-	Env        *Environment
+	Env        *Environment // Entorno donde se definió la función
+	Class      *ZyloClass   // Clase que declara el método, nil para funciones
+	Async      bool         // Las llamadas devuelven un Future en lugar del resultado
+	File       string       // Archivo donde se declaró, para los errores de su cuerpo
+}
+
+func (f *ZyloFunction) Type() string { return "FUNCTION_OBJ" }
+func (f *ZyloFunction) Inspect() string {
+	params := make([]string, len(f.Parameters))
+	for i, param := range f.Parameters {
+		params[i] = param.Value
+	}
+	if f.Name == anonymousFunctionName {
+		return fmt.Sprintf("func(%s)", strings.Join(params, ", "))
+	}
+	if f.Async {
+		return fmt.Sprintf("async func %s(%s)", f.Name, strings.Join(params, ", "))
+	}
+	return fmt.Sprintf("func %s(%s)", f.Name, strings.Join(params, ", "))
 }
 
 // BuiltinFunction representa una función built-in
 type BuiltinFunction struct {
 	Name string
 	Fn   func([]Value) (Value, error)
+	// EvalFn, si no es nil, reemplaza a Fn y recibe el evaluador de la tarea
+	// que llama. Lo usan los built-ins que llaman a funciones de Zylo o se
+	// bloquean, para no compartir la pila de llamadas entre tareas.
+	EvalFn func(e *Evaluator, args []Value) (Value, error)
+}
+
+// call ejecuta el built-in desde el evaluador e.
+func (b *BuiltinFunction) call(e *Evaluator, args []Value) (Value, error) {
+	if b.EvalFn != nil {
+		return b.EvalFn(e, args)
+	}
+	return b.Fn(args)
+}
+
+func (b *BuiltinFunction) Type() string    { return "BUILTIN_OBJ" }
+func (b *BuiltinFunction) Inspect() string { return fmt.Sprintf("builtin %s", b.Name) }
+
+// isCallable indica si value puede llamarse como función.
+func isCallable(value Value) bool {
+	switch value.(type) {
+	case *ZyloFunction, *BuiltinFunction, *BoundMethod:
+		return true
+	}
+	return false
 }
 
 // ZyloClass representa una clase definida en Zylo
 type ZyloClass struct {
 	Name       string
+	Parent     *ZyloClass // Superclase, nil si la clase no hereda
 	Attributes map[string]Value
 	Methods    map[string]*ZyloFunction
 	InitMethod *ZyloFunction
@@ -1600,12 +2473,62 @@ func (c *ZyloClass) Inspect() string {
 	return fmt.Sprintf("class %s", c.Name)
 }
 
-// ZyloInstance representa una instancia de una clase Zylo
+// FindMethod busca un método en la clase y después en sus superclases, de
+// modo que los métodos redefinidos ocultan a los heredados.
+func (c *ZyloClass) FindMethod(name string) *ZyloFunction {
+	for class := c; class != nil; class = class.Parent {
+		if method, ok := class.Methods[name]; ok {
+			return method
+		}
+	}
+	return nil
+}
+
+// IsSubclassOf indica si c es other o hereda de ella.
+func (c *ZyloClass) IsSubclassOf(other *ZyloClass) bool {
+	for class := c; class != nil; class = class.Parent {
+		if class == other {
+			return true
+		}
+	}
+	return false
+}
+
+// ZyloInstance representa una instancia de una clase Zylo. Como en List,
+// Fields solo se usa directamente al crear la instancia y después con los
+// métodos.
 type ZyloInstance struct {
+	mu     sync.RWMutex
 	Class  *ZyloClass
 	Fields map[string]Value
 }
 
+// Field devuelve el atributo name, o false si la instancia no lo tiene.
+func (i *ZyloInstance) Field(name string) (Value, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	value, ok := i.Fields[name]
+	return value, ok
+}
+
+// SetField asigna value al atributo name.
+func (i *ZyloInstance) SetField(name string, value Value) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.Fields[name] = value
+}
+
+// FieldEntries devuelve una copia de los atributos de la instancia.
+func (i *ZyloInstance) FieldEntries() map[string]Value {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	fields := make(map[string]Value, len(i.Fields))
+	for name, value := range i.Fields {
+		fields[name] = value
+	}
+	return fields
+}
+
 func (i *ZyloInstance) Type() string { return "INSTANCE_OBJ" }
 func (i *ZyloInstance) Inspect() string {
 	return fmt.Sprintf("instance of %s", i.Class.Name)
@@ -1622,6 +2545,60 @@ func (b *BoundMethod) Inspect() string {
 	return fmt.Sprintf("bound method %s", b.Method.Name)
 }
 
+// SuperReference es el valor de 'super' dentro de un método: busca los
+// métodos a partir de la superclase de la clase que declara el método.
+type SuperReference struct {
+	Instance *ZyloInstance
+	Class    *ZyloClass
+}
+
+func (s *SuperReference) Type() string { return "SUPER_OBJ" }
+func (s *SuperReference) Inspect() string {
+	return fmt.Sprintf("super of %s", s.Class.Name)
+}
+
+// ZyloException es el error que transporta un valor lanzado con 'throw'.
+// Se propaga como un error normal de Go a través de bloques y llamadas hasta
+// que un catch lo intercepta.
+type ZyloException struct {
+	Value Value
+}
+
+func (ex *ZyloException) Error() string {
+	return "excepción no capturada: " + inspectValue(ex.Value)
+}
+
+// ExceptionValue convierte un error en el valor que recibe un catch. Los
+// errores de ejecución que no provienen de un 'throw' se entregan como String.
+func ExceptionValue(err error) Value {
+	var ex *ZyloException
+	if errors.As(err, &ex) {
+		return ex.Value
+	}
+	var runtimeErr *RuntimeError
+	if errors.As(err, &runtimeErr) {
+		return &String{Value: runtimeErr.Message}
+	}
+	return &String{Value: err.Error()}
+}
+
+// inspectValue devuelve la representación legible de un valor.
+func inspectValue(value Value) string {
+	if obj, ok := value.(ZyloObject); ok {
+		return obj.Inspect()
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// isControlFlow indica si un valor es una señal de return, break o continue.
+func isControlFlow(value Value) bool {
+	switch value.(type) {
+	case *ReturnValue, *BreakValue, *ContinueValue:
+		return true
+	}
+	return false
+}
+
 // Control flow types for break and continue
 type BreakValue struct{}
 type ContinueValue struct{}
@@ -1631,3 +2608,12 @@ func (b *BreakValue) Inspect() string { return "break" }
 
 func (c *ContinueValue) Type() string    { return "CONTINUE_OBJ" }
 func (c *ContinueValue) Inspect() string { return "continue" }
+
+// ReturnValue es la señal que produce una sentencia 'return'. Se propaga a
+// través de bloques, bucles y try/finally hasta el límite de la función.
+type ReturnValue struct {
+	Value Value
+}
+
+func (r *ReturnValue) Type() string    { return "RETURN_VALUE_OBJ" }
+func (r *ReturnValue) Inspect() string { return inspectValue(r.Value) }
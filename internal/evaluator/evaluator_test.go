@@ -0,0 +1,547 @@
+package evaluator
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/parser"
+)
+
+// runProgram parsea y evalúa input, devolviendo el evaluador y el error de ejecución.
+func runProgram(t *testing.T, input string) (*Evaluator, error) {
+	t.Helper()
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+
+	eval := NewEvaluator()
+	return eval, eval.EvaluateProgram(program)
+}
+
+// expectGlobal verifica el valor (vía Inspect) de una variable global.
+func expectGlobal(t *testing.T, eval *Evaluator, name, expected string) {
+	t.Helper()
+	value, ok := eval.env.Get(name)
+	if !ok {
+		t.Fatalf("variable %q not defined", name)
+	}
+	if got := inspectValue(value); got != expected {
+		t.Errorf("%s: expected %q, got %q", name, expected, got)
+	}
+}
+
+func TestTryCatchFinally(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "throw unwinds through nested calls",
+			input: `
+var log = ""
+func validate(n) {
+    if n < 0 {
+        throw "negative"
+    }
+    log = log + "valid "
+}
+func outer(n) {
+    validate(n)
+    log = log + "unreachable "
+}
+try {
+    outer(-1)
+    log = log + "unreachable "
+} catch (e) {
+    log = log + "caught " + e
+}
+`,
+			expected: "caught negative",
+		},
+		{
+			name: "finally runs after catch",
+			input: `
+var log = ""
+try {
+    throw 42
+} catch e {
+    log = log + e
+} finally {
+    log = log + " finally"
+}
+`,
+			expected: "42 finally",
+		},
+		{
+			name: "runtime errors are catchable",
+			input: `
+var log = ""
+try {
+    var z = 10 / 0
+} catch (err) {
+    log = err
+}
+`,
+			expected: "división por cero",
+		},
+		{
+			name: "nearest catch wins",
+			input: `
+var log = ""
+try {
+    try {
+        throw "inner"
+    } catch (e) {
+        log = "first " + e
+        throw "rethrown"
+    }
+} catch (e) {
+    log = log + ", second " + e
+}
+`,
+			expected: "first inner, second rethrown",
+		},
+		{
+			name: "finally runs on break",
+			input: `
+var log = ""
+var i = 0
+while i < 5 {
+    i = i + 1
+    try {
+        if i == 2 {
+            break
+        }
+    } finally {
+        log = log + i
+    }
+}
+`,
+			expected: "12",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eval, err := runProgram(t, tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expectGlobal(t, eval, "log", tt.expected)
+		})
+	}
+}
+
+func TestUncaughtException(t *testing.T) {
+	_, err := runProgram(t, `
+try {
+    throw "first"
+} finally {
+    var x = 1
+}
+`)
+	if err == nil {
+		t.Fatal("expected uncaught exception")
+	}
+	var ex *ZyloException
+	if !errors.As(err, &ex) {
+		t.Fatalf("expected *ZyloException, got %T", err)
+	}
+	if inspectValue(ex.Value) != "first" {
+		t.Errorf("unexpected thrown value: %s", inspectValue(ex.Value))
+	}
+	if !strings.Contains(err.Error(), "first") {
+		t.Errorf("error message should mention thrown value: %v", err)
+	}
+}
+
+func TestReturnUnwinding(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "early return from if",
+			input: `
+func fib(n) {
+    if n < 2 {
+        return n
+    }
+    return fib(n - 1) + fib(n - 2)
+}
+var result = fib(10)
+`,
+			expected: "55",
+		},
+		{
+			name: "return inside while",
+			input: `
+func firstOver(limit) {
+    var i = 0
+    while true {
+        i = i + 1
+        if i * i > limit {
+            return i
+        }
+    }
+}
+var result = firstOver(50)
+`,
+			expected: "8",
+		},
+		{
+			name: "return inside for in",
+			input: `
+func find(items, wanted) {
+    for x in items {
+        if x == wanted {
+            return "found " + x
+        }
+    }
+    return "missing"
+}
+var result = find([1, 2, 3], 2) + ", " + find([1], 5)
+`,
+			expected: "found 2, missing",
+		},
+		{
+			name: "function without return yields null",
+			input: `
+func noop() {
+    var x = 1
+}
+var result = noop()
+`,
+			expected: "null",
+		},
+		{
+			name: "finally runs on return",
+			input: `
+var log = ""
+func guarded() {
+    try {
+        return "from try"
+    } finally {
+        log = "finally ran"
+    }
+    return "unreachable"
+}
+var result = guarded() + " / " + log
+`,
+			expected: "from try / finally ran",
+		},
+		{
+			name: "method return",
+			input: `
+class Counter {
+    var count = 0
+    func init(start) {
+        this.count = start
+        return 99
+    }
+    func next() {
+        if true {
+            return this.count + 1
+        }
+        return -1
+    }
+}
+var c = Counter(4)
+var result = c.next()
+`,
+			expected: "5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eval, err := runProgram(t, tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expectGlobal(t, eval, "result", tt.expected)
+		})
+	}
+}
+
+func TestRuntimeErrorPosition(t *testing.T) {
+	_, err := runProgram(t, `func divide(a, b) {
+    return a / b
+}
+func compute() {
+    var x = 1
+    return divide(x, 0)
+}
+compute()
+`)
+	var runtimeErr *RuntimeError
+	if !errors.As(err, &runtimeErr) {
+		t.Fatalf("expected *RuntimeError, got %T (%v)", err, err)
+	}
+	if runtimeErr.Message != "división por cero" {
+		t.Errorf("unexpected message: %q", runtimeErr.Message)
+	}
+	if runtimeErr.Code() != CodeRuntime {
+		t.Errorf("expected code %s, got %s", CodeRuntime, runtimeErr.Code())
+	}
+	if runtimeErr.Span.StartLine != 2 || runtimeErr.Span.StartCol != 12 {
+		t.Errorf("expected error at 2:12, got %d:%d", runtimeErr.Span.StartLine, runtimeErr.Span.StartCol)
+	}
+
+	expected := []StackFrame{
+		{Function: "<programa>", Line: 8, Column: 1},
+		{Function: "compute", Line: 6, Column: 12},
+		{Function: "divide", Line: 2, Column: 12},
+	}
+	if len(runtimeErr.Stack) != len(expected) {
+		t.Fatalf("expected %d frames, got %d: %+v", len(expected), len(runtimeErr.Stack), runtimeErr.Stack)
+	}
+	for i, frame := range expected {
+		if runtimeErr.Stack[i] != frame {
+			t.Errorf("frame %d: expected %+v, got %+v", i, frame, runtimeErr.Stack[i])
+		}
+	}
+	if !strings.Contains(runtimeErr.Traceback(), "en compute") {
+		t.Errorf("traceback should mention compute:\n%s", runtimeErr.Traceback())
+	}
+}
+
+func TestThrowKeepsStack(t *testing.T) {
+	_, err := runProgram(t, `func fail() {
+    throw "boom"
+}
+fail()
+`)
+	var runtimeErr *RuntimeError
+	if !errors.As(err, &runtimeErr) {
+		t.Fatalf("expected *RuntimeError, got %T (%v)", err, err)
+	}
+	var ex *ZyloException
+	if !errors.As(err, &ex) || inspectValue(ex.Value) != "boom" {
+		t.Fatalf("expected wrapped ZyloException with value boom, got %v", err)
+	}
+	if runtimeErr.Span.StartLine != 2 {
+		t.Errorf("expected throw on line 2, got %d", runtimeErr.Span.StartLine)
+	}
+	if runtimeErr.Code() != CodeUncaught {
+		t.Errorf("expected code %s, got %s", CodeUncaught, runtimeErr.Code())
+	}
+	if n := len(runtimeErr.Stack); n != 2 || runtimeErr.Stack[n-1].Function != "fail" {
+		t.Errorf("unexpected stack: %+v", runtimeErr.Stack)
+	}
+}
+
+func TestClosures(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "counter keeps its own state",
+			input: `
+func makeCounter() {
+    var count = 0
+    return func() {
+        count = count + 1
+        return count
+    }
+}
+var a = makeCounter()
+var b = makeCounter()
+a()
+a()
+b()
+var result = a()
+`,
+			expected: "3",
+		},
+		{
+			name: "arrow lambdas",
+			input: `
+var add = (x, y) => x + y
+var double = x => x * 2
+var result = double(add(1, 2))
+`,
+			expected: "6",
+		},
+		{
+			name: "curried arrows",
+			input: `
+var adder = n => m => n + m
+var result = adder(10)(5)
+`,
+			expected: "15",
+		},
+		{
+			name: "functions stored in lists",
+			input: `
+var ops = [x => x + 1, func(x) { return x * 10 }]
+var result = 0
+for op in ops {
+    result = op(result + 1)
+}
+`,
+			expected: "30",
+		},
+		{
+			name: "try builtin with lambdas",
+			input: `
+var result = "sin error"
+try(() => {
+    throw "boom"
+}, e => {
+    result = e
+})
+`,
+			expected: "boom",
+		},
+		{
+			name: "closure sees later assignments",
+			input: `
+var base = 1
+var get = () => base
+base = 5
+var result = get()
+`,
+			expected: "5",
+		},
+		{
+			name: "immediately invoked",
+			input: `
+var result = func(x) { return x + 1 }(41)
+`,
+			expected: "42",
+		},
+		{
+			name:     "inspect",
+			input:    `var result = [func(a, b) { return a }, () => 1]`,
+			expected: "[func(a, b), func()]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eval, err := runProgram(t, tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expectGlobal(t, eval, "result", tt.expected)
+		})
+	}
+}
+
+func TestAnonymousFunctionInTrace(t *testing.T) {
+	_, err := runProgram(t, `
+var fail = () => missing
+fail()
+`)
+	var runtimeErr *RuntimeError
+	if !errors.As(err, &runtimeErr) {
+		t.Fatalf("expected *RuntimeError, got %T: %v", err, err)
+	}
+	if !strings.Contains(runtimeErr.Traceback(), "en <anónima>") {
+		t.Errorf("expected anonymous frame in traceback, got:\n%s", runtimeErr.Traceback())
+	}
+}
+
+func TestInheritance(t *testing.T) {
+	classes := `
+class Animal {
+    var name = "?"
+    var sound = "..."
+    func init(name) {
+        this.name = name
+    }
+    func speak() {
+        return this.name + " dice " + this.sound
+    }
+    func describe() {
+        return "animal"
+    }
+}
+class Dog extends Animal {
+    var sound = "guau"
+    var tricks = 0
+    func init(name, tricks) {
+        super.init(name)
+        this.tricks = tricks
+    }
+    func describe() {
+        return "perro, " + super.describe()
+    }
+}
+class Puppy extends Dog {
+    func describe() {
+        return "cachorro, " + super.describe()
+    }
+}
+class Cat extends Animal {
+}
+`
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"inherited method and overridden attribute", `var result = Dog("Rex", 2).speak()`, "Rex dice guau"},
+		{"super.init", `var result = Dog("Rex", 2).tricks`, "2"},
+		{"inherited init", `var result = Cat("Tom").speak()`, "Tom dice ..."},
+		{"super chain", `var result = Puppy("Bo", 1).describe()`, "cachorro, perro, animal"},
+		{"instanceof", `
+var p = Puppy("Bo", 1)
+var result = [p instanceof Animal, p instanceof Dog, p instanceof Cat, 3 instanceof Animal]
+`, "[true, true, false, false]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eval, err := runProgram(t, classes+tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expectGlobal(t, eval, "result", tt.expected)
+		})
+	}
+}
+
+func TestInheritanceErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"var NotAClass = 1\nclass A extends NotAClass {\n}", "superclass 'NotAClass' is not a class"},
+		{"class A {\n    func f() {\n        return super.f()\n    }\n}\nA().f()", "'super' is not available in this context"},
+		{"class A {\n}\nclass B extends A {\n    func f() {\n        return super.g()\n    }\n}\nB().f()", "method 'g' not found on superclass A"},
+		{"var x = 1 instanceof 2", "right operand of 'instanceof' must be a class"},
+	}
+
+	for _, tt := range tests {
+		_, err := runProgram(t, tt.input)
+		if err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("%q: expected error containing %q, got %v", tt.input, tt.expected, err)
+		}
+	}
+}
+
+func TestBuiltinNamesAreComputedOnce(t *testing.T) {
+	names := BuiltinNames()
+	if len(names) == 0 || !sort.StringsAreSorted(names) {
+		t.Fatalf("expected sorted built-in names, got %v", names)
+	}
+	names[0] = "changed"
+	if again := BuiltinNames(); again[0] == "changed" {
+		t.Errorf("expected BuiltinNames to return a copy of the cached names")
+	}
+	if allocs := testing.AllocsPerRun(10, func() { BuiltinNames() }); allocs > 1 {
+		t.Errorf("expected BuiltinNames to reuse the names, got %v allocations per call", allocs)
+	}
+}
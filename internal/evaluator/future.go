@@ -0,0 +1,203 @@
+package evaluator
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Future es el resultado pendiente de una función async. Se resuelve con un
+// valor o se rechaza con un error una sola vez; 'await' espera a que ocurra.
+type Future struct {
+	done  chan struct{} // Se cierra al resolverse o rechazarse
+	value Value
+	err   error
+}
+
+func newFuture() *Future {
+	return &Future{done: make(chan struct{})}
+}
+
+func (f *Future) Type() string { return "FUTURE_OBJ" }
+func (f *Future) Inspect() string {
+	select {
+	case <-f.done:
+	default:
+		return "future(pending)"
+	}
+	if f.err != nil {
+		return fmt.Sprintf("future(rejected: %s)", inspectValue(ExceptionValue(f.err)))
+	}
+	return fmt.Sprintf("future(%s)", inspectValue(f.value))
+}
+
+// settle resuelve el future con value o lo rechaza con err.
+func (f *Future) settle(value Value, err error) {
+	if err == nil && value == nil {
+		value = &Null{}
+	}
+	f.value, f.err = value, err
+	close(f.done)
+}
+
+// async ejecuta run en una tarea nueva y devuelve enseguida el Future que
+// recibirá su resultado. A diferencia de spawn, un error de la tarea rechaza
+// el future en lugar de detener la ejecución.
+func (e *Evaluator) async(run func(task *Evaluator) (Value, error)) (Value, error) {
+	if e.tasks == nil {
+		return nil, fmt.Errorf("async calls are not available outside a program run")
+	}
+	future := newFuture()
+	task := e.newTask()
+	e.tasks.start(func() {
+		future.settle(run(task))
+	})
+	return future, nil
+}
+
+// await espera a que value se resuelva si es un Future y devuelve su valor;
+// un future rechazado devuelve su error, que se puede capturar con try/catch.
+// Cualquier otro valor se devuelve tal cual.
+func (e *Evaluator) await(value Value) (Value, error) {
+	future, ok := value.(*Future)
+	if !ok {
+		return value, nil
+	}
+	if _, _, _, err := e.block([]reflect.SelectCase{recvCase(future.done)}); err != nil {
+		return nil, err
+	}
+	return future.value, future.err
+}
+
+// futureList extrae los elementos del único argumento de all() y race().
+func futureList(name string, args []Value) ([]Value, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("%s() expects exactly 1 argument", name)
+	}
+	list, ok := args[0].(*List)
+	if !ok {
+		return nil, fmt.Errorf("argument to %s() must be a list", name)
+	}
+	return list.Elements(), nil
+}
+
+// awaitAll espera a todos los futures de items y devuelve sus valores en
+// orden. Falla con el primer rechazo, sin esperar al resto.
+func (e *Evaluator) awaitAll(items []Value) (Value, error) {
+	results := make([]Value, len(items))
+	var pending []int // Índices de items que siguen pendientes
+	for i, item := range items {
+		if _, ok := item.(*Future); ok {
+			pending = append(pending, i)
+		} else {
+			results[i] = item
+		}
+	}
+
+	for len(pending) > 0 {
+		cases := make([]reflect.SelectCase, len(pending))
+		for j, i := range pending {
+			cases[j] = recvCase(items[i].(*Future).done)
+		}
+		chosen, _, _, err := e.block(cases)
+		if err != nil {
+			return nil, err
+		}
+		i := pending[chosen]
+		future := items[i].(*Future)
+		if future.err != nil {
+			return nil, future.err
+		}
+		results[i] = future.value
+		pending = append(pending[:chosen], pending[chosen+1:]...)
+	}
+	return &List{Items: results}, nil
+}
+
+// awaitFirst espera al primer future de items que se resuelva o rechace. Un
+// elemento que no es un future cuenta como ya resuelto.
+func (e *Evaluator) awaitFirst(items []Value) (Value, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("race() expects at least one future")
+	}
+	cases := make([]reflect.SelectCase, len(items))
+	for i, item := range items {
+		future, ok := item.(*Future)
+		if !ok {
+			return item, nil
+		}
+		cases[i] = recvCase(future.done)
+	}
+	chosen, _, _, err := e.block(cases)
+	if err != nil {
+		return nil, err
+	}
+	future := items[chosen].(*Future)
+	return future.value, future.err
+}
+
+// awaitTimeout espera a value como await, pero lo rechaza si no se resuelve
+// en ms milisegundos. La espera no cuenta como bloqueo para la detección de
+// deadlocks, porque el temporizador siempre termina.
+func (e *Evaluator) awaitTimeout(value Value, ms int64) (Value, error) {
+	future, ok := value.(*Future)
+	if !ok {
+		return value, nil
+	}
+	timer := time.NewTimer(time.Duration(ms) * time.Millisecond)
+	defer timer.Stop()
+	select {
+	case <-future.done:
+		return future.value, future.err
+	case <-timer.C:
+		return nil, fmt.Errorf("future timed out after %d ms", ms)
+	case <-e.ctx.Done():
+		return nil, fmt.Errorf("%w: %w", ErrCanceled, e.ctx.Err())
+	}
+}
+
+// initFutureBuiltins registra los built-ins que combinan futures: all, race y
+// timeout. Todos devuelven un Future nuevo.
+func (e *Evaluator) initFutureBuiltins() {
+	e.env.Set("all", &BuiltinFunction{
+		Name: "all",
+		EvalFn: func(e *Evaluator, args []Value) (Value, error) {
+			items, err := futureList("all", args)
+			if err != nil {
+				return nil, err
+			}
+			return e.async(func(task *Evaluator) (Value, error) {
+				return task.awaitAll(items)
+			})
+		},
+	})
+
+	e.env.Set("race", &BuiltinFunction{
+		Name: "race",
+		EvalFn: func(e *Evaluator, args []Value) (Value, error) {
+			items, err := futureList("race", args)
+			if err != nil {
+				return nil, err
+			}
+			return e.async(func(task *Evaluator) (Value, error) {
+				return task.awaitFirst(items)
+			})
+		},
+	})
+
+	e.env.Set("timeout", &BuiltinFunction{
+		Name: "timeout",
+		EvalFn: func(e *Evaluator, args []Value) (Value, error) {
+			if len(args) != 2 {
+				return nil, fmt.Errorf("timeout() expects exactly 2 arguments")
+			}
+			ms, ok := args[1].(*Integer)
+			if !ok || ms.Value < 0 {
+				return nil, fmt.Errorf("second argument to timeout() must be a non-negative integer")
+			}
+			return e.async(func(task *Evaluator) (Value, error) {
+				return task.awaitTimeout(args[0], ms.Value)
+			})
+		},
+	})
+}
@@ -0,0 +1,168 @@
+package evaluator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAsyncAwait(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "async function returns a future",
+			input: `
+async func double(x) {
+    return x * 2
+}
+var f = double(21)
+var result = [await f, f]
+`,
+			expected: "[42, future(42)]",
+		},
+		{
+			name: "await of a plain value returns it",
+			input: `
+var result = await 7
+`,
+			expected: "7",
+		},
+		{
+			name: "rejection is catchable",
+			input: `
+async func fail() {
+    throw "boom"
+}
+var result = ""
+try {
+    await fail()
+} catch (e) {
+    result = "caught " + e
+}
+`,
+			expected: "caught boom",
+		},
+		{
+			name: "async methods",
+			input: `
+class Counter {
+    var n = 1
+    async func next() {
+        return this.n + 1
+    }
+}
+var c = Counter()
+var result = await c.next()
+`,
+			expected: "2",
+		},
+		{
+			name: "all keeps the order of the futures",
+			input: `
+async func after(ms, v) {
+    sleep(ms)
+    return v
+}
+var result = await all([after(30, "a"), after(1, "b"), "c"])
+`,
+			expected: "[a, b, c]",
+		},
+		{
+			name: "all rejects with the first failure",
+			input: `
+async func fail() {
+    throw "first"
+}
+async func never() {
+    sleep(300)
+}
+var result = ""
+try {
+    await all([never(), fail()])
+} catch (e) {
+    result = e
+}
+`,
+			expected: "first",
+		},
+		{
+			name: "race settles with the fastest future",
+			input: `
+async func after(ms, v) {
+    sleep(ms)
+    return v
+}
+var result = await race([after(200, "slow"), after(1, "fast")])
+`,
+			expected: "fast",
+		},
+		{
+			name: "timeout rejects a slow future",
+			input: `
+async func slow() {
+    sleep(200)
+    return 1
+}
+var result = ""
+try {
+    await timeout(slow(), 10)
+} catch (e) {
+    result = e
+}
+`,
+			expected: "future timed out after 10 ms",
+		},
+		{
+			name: "timeout resolves a fast future",
+			input: `
+async func fast() {
+    return "ok"
+}
+var result = await timeout(fast(), 1000)
+`,
+			expected: "ok",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eval, err := runProgram(t, tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expectGlobal(t, eval, "result", tt.expected)
+		})
+	}
+}
+
+func TestAwaitErrors(t *testing.T) {
+	// Un rechazo sin capturar conserva la traza de la función async
+	_, err := runProgram(t, `
+async func fail() {
+    throw "boom"
+}
+await fail()
+`)
+	var runtimeErr *RuntimeError
+	if !errors.As(err, &runtimeErr) {
+		t.Fatalf("expected *RuntimeError, got %T: %v", err, err)
+	}
+	if !strings.Contains(err.Error(), "boom") || runtimeErr.Span.StartLine != 3 {
+		t.Errorf("expected the error at the throw, got %v", err)
+	}
+
+	// Esperar a un future que nunca se resuelve es un deadlock
+	_, err = runProgram(t, `
+var ch = channel()
+async func wait() {
+    return ch.receive()
+}
+await wait()
+`)
+	if !errors.Is(err, ErrDeadlock) {
+		t.Fatalf("expected ErrDeadlock, got %v", err)
+	}
+}
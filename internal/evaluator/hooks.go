@@ -0,0 +1,94 @@
+package evaluator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/parser"
+)
+
+// Hooks son funciones que el evaluador llama durante la ejecución para que un
+// depurador pueda seguirla. Cada una recibe el evaluador que ejecuta el
+// código, que es distinto del original dentro de un módulo importado. Los
+// campos nil se ignoran.
+type Hooks struct {
+	// BeforeStatement se llama antes de evaluar cada sentencia. Un error
+	// detiene la ejecución en la sentencia.
+	BeforeStatement func(e *Evaluator, stmt ast.Statement) error
+	// OnCall se llama al entrar en una función o un método de Zylo, con la
+	// posición de la llamada. Un error detiene la llamada.
+	OnCall func(e *Evaluator, function string, callSite ast.Span) error
+	// OnReturn se llama al salir de una función o un método de Zylo, con su
+	// resultado o el error con el que terminó.
+	OnReturn func(e *Evaluator, function string, result Value, err error)
+}
+
+// SetHooks reemplaza los hooks del evaluador. Las tareas lanzadas con spawn
+// o con funciones async no los llaman: un depurador sigue solo a la tarea
+// principal.
+func (e *Evaluator) SetHooks(hooks Hooks) {
+	e.hooks = hooks
+}
+
+// Frame es una entrada de la pila de llamadas tal como la ve un depurador.
+type Frame struct {
+	Function string
+	File     string
+	Span     ast.Span     // Sentencia en curso, o la llamada pendiente en los marcos anteriores
+	Env      *Environment // Variables visibles en el marco
+}
+
+// Frames devuelve la pila de llamadas de Zylo en curso, con el marco más
+// reciente al final. Tiene sentido dentro de un hook.
+func (e *Evaluator) Frames() []Frame {
+	frames := make([]Frame, 0, len(e.callStack)+1)
+	caller := "<programa>"
+	for _, frame := range e.callStack {
+		frames = append(frames, Frame{
+			Function: caller,
+			File:     frame.file,
+			Span:     frame.callSite,
+			Env:      frame.env,
+		})
+		caller = frame.function
+	}
+	return append(frames, Frame{
+		Function: caller,
+		File:     e.file,
+		Span:     e.statement,
+		Env:      e.env,
+	})
+}
+
+// EvaluateIn evalúa la expresión source con las variables de env, como si
+// apareciera en el marco que las contiene, y devuelve su valor. Los hooks no
+// se llaman mientras tanto, así que una expresión que llama a funciones no
+// se detiene en sus breakpoints.
+func (e *Evaluator) EvaluateIn(env *Environment, source string) (Value, error) {
+	p := parser.New(lexer.New(source))
+	program := p.ParseProgram()
+	if errs := p.Errors(); len(errs) > 0 {
+		return nil, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	if len(program.Statements) != 1 {
+		return nil, fmt.Errorf("expected a single expression")
+	}
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok || stmt.Expression == nil {
+		return nil, fmt.Errorf("expected a single expression")
+	}
+
+	hooks, oldEnv, callSite := e.hooks, e.env, e.callSite
+	e.hooks, e.env = Hooks{}, env
+	defer func() { e.hooks, e.env, e.callSite = hooks, oldEnv, callSite }()
+	value, err := e.evaluateExpression(stmt.Expression)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return &Null{}, nil
+	}
+	return value, nil
+}
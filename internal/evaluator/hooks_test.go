@@ -0,0 +1,107 @@
+package evaluator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/parser"
+)
+
+func TestHooks(t *testing.T) {
+	input := `func double(n) {
+    var result = n * 2
+    return result
+}
+var x = double(21)
+spawn double(1)
+`
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+
+	var events []string
+	var inside []Frame
+	eval := NewEvaluatorWithIO(strings.NewReader(""), io.Discard, io.Discard)
+	eval.SetFile("hooks.zylo")
+	eval.SetHooks(Hooks{
+		BeforeStatement: func(e *Evaluator, stmt ast.Statement) error {
+			span := ast.SpanOf(stmt)
+			events = append(events, fmt.Sprintf("stmt %d", span.StartLine))
+			if span.StartLine == 3 {
+				inside = e.Frames()
+			}
+			return nil
+		},
+		OnCall: func(e *Evaluator, function string, callSite ast.Span) error {
+			events = append(events, fmt.Sprintf("call %s from %d", function, callSite.StartLine))
+			return nil
+		},
+		OnReturn: func(e *Evaluator, function string, result Value, err error) {
+			events = append(events, fmt.Sprintf("return %s %s", function, inspectValue(result)))
+		},
+	})
+	if err := eval.EvaluateProgram(program); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// La tarea lanzada con spawn no llama a los hooks
+	want := []string{"stmt 1", "stmt 5", "call double from 5", "stmt 2", "stmt 3", "return double 42", "stmt 6"}
+	if strings.Join(events, ", ") != strings.Join(want, ", ") {
+		t.Errorf("expected events %v, got %v", want, events)
+	}
+
+	if len(inside) != 2 {
+		t.Fatalf("expected 2 frames inside double, got %+v", inside)
+	}
+	if inside[0].Function != "<programa>" || inside[0].Span.StartLine != 5 || inside[1].Function != "double" || inside[1].Span.StartLine != 3 {
+		t.Errorf("unexpected frames %+v", inside)
+	}
+	if inside[1].File != "hooks.zylo" {
+		t.Errorf("expected frames in hooks.zylo, got %q", inside[1].File)
+	}
+
+	value, err := eval.EvaluateIn(inside[1].Env, "result + n")
+	if err != nil || inspectValue(value) != "63" {
+		t.Errorf("expected result + n to be 63 in double, got %v (%v)", value, err)
+	}
+	if _, err := eval.EvaluateIn(inside[0].Env, "result"); err == nil {
+		t.Errorf("expected result to be undefined in the caller")
+	}
+	if names := inside[1].Env.Parent().Names(); len(names) != 1 || names[0] != "n" {
+		t.Errorf("expected the parameters in the function environment, got %v", names)
+	}
+}
+
+func TestHookErrorStopsExecution(t *testing.T) {
+	p := parser.New(lexer.New("var a = 1\nvar b = 2\n"))
+	program := p.ParseProgram()
+
+	stop := errors.New("stop")
+	eval := NewEvaluatorWithIO(strings.NewReader(""), io.Discard, io.Discard)
+	eval.SetHooks(Hooks{
+		BeforeStatement: func(e *Evaluator, stmt ast.Statement) error {
+			if ast.SpanOf(stmt).StartLine == 2 {
+				return stop
+			}
+			return nil
+		},
+	})
+	err := eval.EvaluateProgram(program)
+	if !errors.Is(err, stop) {
+		t.Fatalf("expected the hook error, got %v", err)
+	}
+	var runtimeErr *RuntimeError
+	if !errors.As(err, &runtimeErr) || runtimeErr.Span.StartLine != 2 {
+		t.Errorf("expected the error at line 2, got %v", err)
+	}
+	if _, ok := eval.Lookup("b"); ok {
+		t.Errorf("expected b not to be declared")
+	}
+}
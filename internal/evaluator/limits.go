@@ -0,0 +1,141 @@
+package evaluator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// Errores de los límites de ejecución. Los errores devueltos los envuelven,
+// así que se distinguen con errors.Is; desde Zylo se capturan con try/catch
+// como cualquier otro error de ejecución, salvo ErrCanceled, que atraviesa
+// los catch y el built-in try() (los finally sí se ejecutan).
+var (
+	ErrCanceled   = errors.New("ejecución cancelada")
+	ErrStepLimit  = errors.New("límite de pasos excedido")
+	ErrCallDepth  = errors.New("profundidad máxima de llamadas excedida")
+	ErrAllocLimit = errors.New("límite de memoria excedido")
+)
+
+// DefaultMaxCallDepth es la profundidad de llamadas que admite un evaluador
+// nuevo, suficiente para programas normales y lejos del límite de la pila de Go.
+const DefaultMaxCallDepth = 10000
+
+// Limits acota los recursos que puede consumir una ejecución. Un valor cero
+// desactiva el límite correspondiente.
+type Limits struct {
+	MaxSteps      int64 // Sentencias y expresiones evaluadas, o instrucciones de la vm
+	MaxCallDepth  int   // Llamadas de Zylo anidadas
+	MaxAllocBytes int64 // Estimación acumulada de memoria para strings, listas y hashes
+}
+
+// Tamaño estimado de cada elemento de una lista (una interfaz de Go).
+const ListItemSize = 16
+
+// Tamaño estimado de cada par de un hash, sin los bytes de la clave: la
+// cabecera del string de la clave y la interfaz del valor.
+const HashEntrySize = 32
+
+// Cada cuántos pasos se consulta el contexto.
+const contextCheckInterval = 256
+
+// usage acumula los recursos consumidos en una ejecución. Es compartido por
+// todas las tareas, así que los límites cubren la ejecución completa.
+type usage struct {
+	steps     atomic.Int64 // Nodos evaluados en la ejecución actual
+	allocated atomic.Int64 // Memoria estimada reservada en la ejecución actual
+}
+
+// SetLimits reemplaza los límites de ejecución del evaluador.
+func (e *Evaluator) SetLimits(limits Limits) {
+	e.limits = limits
+}
+
+// SetContext establece el contexto que cancela la ejecución. Un nil equivale
+// a context.Background().
+func (e *Evaluator) SetContext(ctx context.Context) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	e.ctx = ctx
+}
+
+// resetUsage reinicia los contadores de pasos y memoria al empezar una
+// ejecución desde Go.
+func (e *Evaluator) resetUsage() {
+	e.usage.steps.Store(0)
+	e.usage.allocated.Store(0)
+}
+
+// step contabiliza la evaluación de un nodo y comprueba la cancelación y el
+// límite de pasos.
+func (e *Evaluator) step() error {
+	steps := e.usage.steps.Add(1)
+	if e.limits.MaxSteps > 0 && steps > e.limits.MaxSteps {
+		return fmt.Errorf("%w (%d)", ErrStepLimit, e.limits.MaxSteps)
+	}
+	if steps%contextCheckInterval == 1 {
+		if err := e.ctx.Err(); err != nil {
+			return fmt.Errorf("%w: %w", ErrCanceled, err)
+		}
+	}
+	return nil
+}
+
+// Limits devuelve los límites de ejecución del evaluador.
+func (e *Evaluator) Limits() Limits {
+	return e.limits
+}
+
+// Execute ejecuta fn como una ejecución desde Go, igual que EvaluateProgram:
+// reinicia los contadores de los límites y espera a las tareas que se lancen.
+// Las llamadas a Call dentro de fn cuentan para la misma ejecución. Así
+// ejecuta la vm sus programas con este evaluador como anfitrión.
+func (e *Evaluator) Execute(fn func() error) error {
+	e.resetUsage()
+	return e.runMain(fn)
+}
+
+// Steps contabiliza n pasos dados fuera del evaluador, como las
+// instrucciones de la vm, y comprueba la cancelación y el límite de pasos.
+func (e *Evaluator) Steps(n int64) error {
+	steps := e.usage.steps.Add(n)
+	if e.limits.MaxSteps > 0 && steps > e.limits.MaxSteps {
+		return fmt.Errorf("%w (%d)", ErrStepLimit, e.limits.MaxSteps)
+	}
+	if err := e.ctx.Err(); err != nil {
+		return fmt.Errorf("%w: %w", ErrCanceled, err)
+	}
+	return nil
+}
+
+// Allocate registra bytes reservados fuera del evaluador, como las listas
+// que construye la vm, y comprueba el límite de memoria.
+func (e *Evaluator) Allocate(bytes int64) error {
+	return e.allocate(bytes)
+}
+
+// uncatchable indica si err atraviesa los catch: la cancelación y el
+// deadlock. Un catch que los tragara podría mantener viva la ejecución para
+// siempre.
+func uncatchable(err error) bool {
+	return errors.Is(err, ErrCanceled) || errors.Is(err, ErrDeadlock)
+}
+
+// checkCallDepth comprueba que se pueda entrar en una llamada más.
+func (e *Evaluator) checkCallDepth() error {
+	if e.limits.MaxCallDepth > 0 && len(e.callStack) >= e.limits.MaxCallDepth {
+		return fmt.Errorf("%w (%d)", ErrCallDepth, e.limits.MaxCallDepth)
+	}
+	return nil
+}
+
+// allocate registra bytes nuevos reservados para strings, listas o hashes.
+func (e *Evaluator) allocate(bytes int64) error {
+	allocated := e.usage.allocated.Add(bytes)
+	if e.limits.MaxAllocBytes > 0 && allocated > e.limits.MaxAllocBytes {
+		return fmt.Errorf("%w (%d bytes)", ErrAllocLimit, e.limits.MaxAllocBytes)
+	}
+	return nil
+}
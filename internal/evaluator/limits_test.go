@@ -0,0 +1,204 @@
+package evaluator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/parser"
+)
+
+// runWithLimits evalúa input con los límites y el contexto dados.
+func runWithLimits(t *testing.T, ctx context.Context, limits Limits, input string) (*Evaluator, error) {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+
+	eval := NewEvaluator()
+	eval.SetContext(ctx)
+	eval.SetLimits(limits)
+	return eval, eval.EvaluateProgram(program)
+}
+
+func TestExecutionLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		limits   Limits
+		input    string
+		expected error
+	}{
+		{
+			name:     "infinite loop hits step limit",
+			limits:   Limits{MaxSteps: 1000},
+			input:    "while true {\n}",
+			expected: ErrStepLimit,
+		},
+		{
+			name:     "runaway recursion hits call depth",
+			limits:   Limits{MaxCallDepth: 50},
+			input:    "func f(n) {\n    return f(n + 1)\n}\nf(0)",
+			expected: ErrCallDepth,
+		},
+		{
+			name:     "string growth hits allocation limit",
+			limits:   Limits{MaxAllocBytes: 1024},
+			input:    "var s = \"x\"\nwhile true {\n    s = s + s\n}",
+			expected: ErrAllocLimit,
+		},
+		{
+			name:     "list growth hits allocation limit",
+			limits:   Limits{MaxAllocBytes: 1024},
+			input:    "var l = []\nwhile true {\n    l.Append(1)\n}",
+			expected: ErrAllocLimit,
+		},
+		{
+			name:     "split results hit allocation limit",
+			limits:   Limits{MaxAllocBytes: 1024},
+			input:    "var parts = []\nwhile true {\n    parts = split(\"a,b,c,d\", \",\")\n}",
+			expected: ErrAllocLimit,
+		},
+		{
+			name:     "hash literals hit allocation limit",
+			limits:   Limits{MaxAllocBytes: 1024},
+			input:    "var h = {}\nwhile true {\n    h = {\"a\": 1, \"b\": 2}\n}",
+			expected: ErrAllocLimit,
+		},
+		{
+			name:     "hash growth hits allocation limit",
+			limits:   Limits{MaxAllocBytes: 1024},
+			input:    "var h = {}\nvar i = 0\nwhile true {\n    h[string(i)] = i\n    i = i + 1\n}",
+			expected: ErrAllocLimit,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runWithLimits(t, context.Background(), tt.limits, tt.input)
+			if !errors.Is(err, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, err)
+			}
+			var runtimeErr *RuntimeError
+			if !errors.As(err, &runtimeErr) || runtimeErr.Span.StartLine == 0 {
+				t.Fatalf("expected a positioned *RuntimeError, got %T (%v)", err, err)
+			}
+			if runtimeErr.Code() != CodeLimit {
+				t.Errorf("expected code %s, got %s", CodeLimit, runtimeErr.Code())
+			}
+		})
+	}
+}
+
+func TestContextCancellation(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := runWithLimits(t, ctx, Limits{}, "while true {\n}")
+	if !errors.Is(err, ErrCanceled) {
+		t.Fatalf("expected ErrCanceled, got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the context error to be wrapped, got %v", err)
+	}
+}
+
+func TestCancellationIsNotCatchable(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty catch", "while true { try { while true { } } catch (e) { } }"},
+		{"catch doing work", "var n = 0\nwhile true {\n    try {\n        while true { }\n    } catch (e) {\n        n = n + 1\n    }\n}"},
+		{"try with finally", "var n = 0\ntry {\n    while true { }\n} finally {\n    n = 1\n}"},
+		{"try builtin", "while true { try(func() { while true { } }, func(e) { }) }"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			p := parser.New(lexer.New(tt.input))
+			program := p.ParseProgram()
+			if len(p.Errors()) > 0 {
+				t.Fatalf("Parser errors: %v", p.Errors())
+			}
+			eval := NewEvaluator()
+			eval.SetContext(ctx)
+
+			done := make(chan error, 1)
+			go func() { done <- eval.EvaluateProgram(program) }()
+			select {
+			case err := <-done:
+				if !errors.Is(err, ErrCanceled) {
+					t.Fatalf("expected ErrCanceled, got %v", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("execution was still running 5s after the deadline")
+			}
+		})
+	}
+}
+
+func TestCallDepthIsCatchable(t *testing.T) {
+	eval, err := runWithLimits(t, context.Background(), Limits{MaxCallDepth: 20}, `
+func f(n) {
+    return f(n + 1)
+}
+var log = ""
+try {
+    f(0)
+} catch (e) {
+    log = e
+}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectGlobal(t, eval, "log", "profundidad máxima de llamadas excedida (20)")
+}
+
+func TestCallDepthStackIsCollapsed(t *testing.T) {
+	_, err := runWithLimits(t, context.Background(), Limits{MaxCallDepth: 500}, "func r(n) {\n    return r(n + 1)\n}\nr(0)")
+	var runtimeErr *RuntimeError
+	if !errors.As(err, &runtimeErr) {
+		t.Fatalf("expected a runtime error, got %v", err)
+	}
+
+	calls := runtimeErr.Calls()
+	want := []Call{
+		{Function: "r", Caller: StackFrame{Function: "r", Line: 2, Column: 12}, Count: 499},
+		{Function: "r", Caller: StackFrame{Function: "<programa>", Line: 4, Column: 1}, Count: 1},
+	}
+	if len(calls) != len(want) || calls[0] != want[0] || calls[1] != want[1] {
+		t.Errorf("expected calls %+v, got %+v", want, calls)
+	}
+
+	traceback := runtimeErr.Traceback()
+	if lines := strings.Count(traceback, "\n"); lines > 5 {
+		t.Errorf("expected a collapsed traceback, got %d lines:\n%s", lines, traceback)
+	}
+	if !strings.Contains(traceback, "[la línea anterior se repite 499 veces más]") {
+		t.Errorf("traceback does not mention the repeated frames:\n%s", traceback)
+	}
+}
+
+func TestDefaultLimitsAllowNormalPrograms(t *testing.T) {
+	eval, err := runProgram(t, `
+func depth(n) {
+    if n == 0 {
+        return 0
+    }
+    return 1 + depth(n - 1)
+}
+var result = depth(500)
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectGlobal(t, eval, "result", "500")
+}
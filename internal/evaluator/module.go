@@ -0,0 +1,184 @@
+package evaluator
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+	"github.com/zylo-lang/zylo/internal/modules"
+)
+
+// Module es un archivo .zylo importado. Se evalúa en su propio entorno global
+// y solo se puede acceder a los nombres que declara con 'export'.
+type Module struct {
+	Name    string // Nombre con el que se importó (e.g. utils)
+	Path    string // Ruta absoluta del archivo
+	env     *Environment
+	exports map[string]bool
+}
+
+func (m *Module) Type() string    { return "MODULE_OBJ" }
+func (m *Module) Inspect() string { return fmt.Sprintf("module(%s)", m.Name) }
+
+// Export devuelve el valor exportado name.
+func (m *Module) Export(name string) (Value, error) {
+	if !m.exports[name] {
+		return nil, fmt.Errorf("module %s does not export '%s'", m.Name, name)
+	}
+	value, _ := m.env.Get(name)
+	return value, nil
+}
+
+// moduleRegistry guarda los módulos ya cargados de una ejecución, para que
+// cada uno se evalúe una sola vez aunque se importe desde varios archivos.
+type moduleRegistry struct {
+	mu         sync.Mutex
+	searchPath []string
+	loaded     map[string]*moduleEntry // Ruta absoluta -> módulo
+}
+
+// moduleEntry es un módulo cargado o en carga; ready se cierra al terminar.
+type moduleEntry struct {
+	ready  chan struct{}
+	module *Module
+	err    error
+}
+
+func newModuleRegistry(searchPath []string) *moduleRegistry {
+	return &moduleRegistry{searchPath: searchPath, loaded: make(map[string]*moduleEntry)}
+}
+
+// load devuelve el módulo de path, evaluándolo con evaluate si es la primera
+// vez que se importa. Un error de evaluación también queda guardado.
+func (r *moduleRegistry) load(path string, evaluate func() (*Module, error)) (*Module, error) {
+	r.mu.Lock()
+	entry, ok := r.loaded[path]
+	if !ok {
+		entry = &moduleEntry{ready: make(chan struct{})}
+		r.loaded[path] = entry
+	}
+	r.mu.Unlock()
+
+	if ok {
+		<-entry.ready
+		return entry.module, entry.err
+	}
+	entry.module, entry.err = evaluate()
+	close(entry.ready)
+	return entry.module, entry.err
+}
+
+// SetModulePath indica los directorios donde se buscan los módulos que no
+// están junto al archivo que los importa. Por defecto son los de ZYLOPATH.
+func (e *Evaluator) SetModulePath(dirs []string) {
+	e.registry = newModuleRegistry(dirs)
+}
+
+// enterFile cambia el archivo actual a file y devuelve la función que
+// restaura el anterior. Se usa al llamar a funciones de otro módulo.
+func (e *Evaluator) enterFile(file string) func() {
+	old := e.file
+	e.file = file
+	return func() { e.file = old }
+}
+
+// evaluateImportStatement evalúa 'import utils', 'import "lib/utils"' o
+// 'from utils import a, b'.
+func (e *Evaluator) evaluateImportStatement(stmt *ast.ImportStatement) (Value, error) {
+	if stmt.ModuleName == nil {
+		return nil, fmt.Errorf("import statement has nil module name")
+	}
+
+	moduleName := stmt.ModuleName.Value
+	if modules.IsBuiltin(stmt.Module()) {
+		// zyloruntime no tiene archivo: sus funciones son built-ins
+		if _, exists := e.env.Get(moduleName); !exists {
+			e.env.Set(moduleName, &String{Value: "zyloruntime_module"})
+		}
+		return &Null{}, nil
+	}
+
+	module, err := e.importModule(stmt.Module())
+	if err != nil {
+		return nil, err
+	}
+	if stmt.Names == nil {
+		e.env.Set(moduleName, module)
+		return &Null{}, nil
+	}
+	for _, name := range stmt.Names {
+		value, err := module.Export(name.Value)
+		if err != nil {
+			return nil, e.wrapError(name, err)
+		}
+		e.env.Set(name.Value, value)
+	}
+	return &Null{}, nil
+}
+
+// importModule resuelve spec desde el archivo actual y devuelve su módulo.
+func (e *Evaluator) importModule(spec string) (*Module, error) {
+	dir := "."
+	if e.file != "" {
+		dir = filepath.Dir(e.file)
+	}
+	path, err := modules.Resolve(spec, dir, e.registry.searchPath)
+	if err != nil {
+		return nil, err
+	}
+
+	chain := e.importChain
+	if len(chain) == 0 && e.file != "" {
+		if file, err := filepath.Abs(e.file); err == nil {
+			chain = []string{file}
+		}
+	}
+	if err := modules.CheckCycle(chain, path); err != nil {
+		return nil, err
+	}
+	chain = append(append([]string(nil), chain...), path)
+
+	return e.registry.load(path, func() (*Module, error) {
+		return e.evaluateModule(path, chain)
+	})
+}
+
+// evaluateModule evalúa el archivo path en un entorno global nuevo, con sus
+// propios built-ins, y devuelve el módulo con sus exportaciones.
+func (e *Evaluator) evaluateModule(path string, chain []string) (*Module, error) {
+	program, err := modules.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	m := e.fork()
+	m.env = NewEnvironment()
+	m.InitBuiltins()
+	m.file = path
+	m.callStack = nil
+	m.importChain = chain
+	for _, stmt := range program.Statements {
+		if _, err := m.evaluateStatement(stmt); err != nil {
+			return nil, err
+		}
+	}
+
+	module := &Module{
+		Name:    strings.TrimSuffix(filepath.Base(path), modules.Extension),
+		Path:    path,
+		env:     m.env,
+		exports: make(map[string]bool),
+	}
+	for _, name := range modules.Exports(program) {
+		module.exports[name] = true
+	}
+	return module, nil
+}
+
+// evaluateExportStatement evalúa la declaración exportada. Qué nombres
+// exporta un módulo se decide al cargarlo, a partir del AST.
+func (e *Evaluator) evaluateExportStatement(stmt *ast.ExportStatement) (Value, error) {
+	return e.evaluateStatement(stmt.Statement)
+}
@@ -0,0 +1,144 @@
+package evaluator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/modules"
+	"github.com/zylo-lang/zylo/internal/parser"
+)
+
+// writeFiles crea los archivos de files (ruta relativa -> contenido) en un
+// directorio temporal y devuelve el directorio.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// runFile ejecuta el archivo path y devuelve el evaluador, la salida y el
+// error de la ejecución.
+func runFile(t *testing.T, path string, searchPath ...string) (*Evaluator, string, error) {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := parser.New(lexer.New(string(content)))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+
+	var output strings.Builder
+	eval := NewEvaluatorWithIO(strings.NewReader(""), &output, &output)
+	eval.SetFile(path)
+	eval.SetModulePath(searchPath)
+	err = eval.EvaluateProgram(program)
+	return eval, output.String(), err
+}
+
+func TestImportModules(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"lib/utils.zylo": `
+show.log("loading utils")
+func twice(x) {
+    return x * 2
+}
+export func double(x) {
+    return twice(x)
+}
+export var VERSION = "1.0"
+export class Counter {
+    func init(n) {
+        this.n = n
+    }
+}
+`,
+		"lib/strings.zylo": `
+import utils
+export func shout(s) {
+    return s + "!" + utils.VERSION
+}
+`,
+		"main.zylo": `
+import "lib/utils"
+import "lib/strings.zylo"
+from "lib/utils" import double, Counter
+var a = utils.double(2)
+var b = double(5)
+var c = Counter(3).n
+var d = strings.shout("hi")
+`,
+	})
+
+	eval, output, err := runFile(t, filepath.Join(dir, "main.zylo"), filepath.Join(dir, "lib"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectGlobal(t, eval, "a", "4")
+	expectGlobal(t, eval, "b", "10")
+	expectGlobal(t, eval, "c", "3")
+	expectGlobal(t, eval, "d", "hi!1.0")
+
+	// lib/strings.zylo importa utils por el search path: es el mismo módulo
+	if got := strings.Count(output, "loading utils"); got != 1 {
+		t.Errorf("expected utils to be evaluated once, got %d times", got)
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"utils.zylo": "func hidden() {\n    return 1\n}\nexport var shown = 2\n",
+		"a.zylo":     "import b\nexport var A = 1\n",
+		"b.zylo":     "import a\nexport var B = 2\n",
+		"fails.zylo": "show.log(\"x\")\nthrow \"broken\"\n",
+	})
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"missing module", "import nope", `module "nope" not found`},
+		{"not exported", "import utils\nvar x = utils.hidden", "main.zylo:2:9: module utils does not export 'hidden'"},
+		{"not exported in from", "from utils import shown, hidden", "main.zylo:1:26: module utils does not export 'hidden'"},
+		{"cycle", "import a", "b.zylo:1:1: import cycle: a.zylo -> b.zylo -> a.zylo"},
+		{"error in module", "import fails", "fails.zylo:2:1: excepción no capturada: broken"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			main := filepath.Join(dir, "main.zylo")
+			if err := os.WriteFile(main, []byte(tt.input), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, _, err := runFile(t, main)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	_, _, err := runFile(t, filepath.Join(dir, "a.zylo"))
+	var cycle *modules.CycleError
+	if !errors.As(err, &cycle) {
+		t.Fatalf("expected a *modules.CycleError, got %v", err)
+	}
+	var runtimeErr *RuntimeError
+	if !errors.As(err, &runtimeErr) || runtimeErr.Code() != modules.CodeLoad {
+		t.Errorf("expected an import cycle to have code %s, got %v", modules.CodeLoad, err)
+	}
+}
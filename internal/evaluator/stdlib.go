@@ -0,0 +1,206 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// initStdlibBuiltins registra las funciones de la biblioteca estándar:
+// newList y newMap crean colecciones vacías, readFile y writeFile leen y
+// escriben archivos de texto, y toJSON y fromJSON convierten valores a JSON
+// y de JSON. El código Go generado usa las funciones equivalentes de
+// zyloruntime.
+func (e *Evaluator) initStdlibBuiltins() {
+	e.env.Set("newList", &BuiltinFunction{
+		Name: "newList",
+		Fn: func(args []Value) (Value, error) {
+			if len(args) != 0 {
+				return nil, fmt.Errorf("newList() expects no arguments")
+			}
+			return &List{Items: []Value{}}, nil
+		},
+	})
+
+	e.env.Set("newMap", &BuiltinFunction{
+		Name: "newMap",
+		Fn: func(args []Value) (Value, error) {
+			if len(args) != 0 {
+				return nil, fmt.Errorf("newMap() expects no arguments")
+			}
+			return &Hash{Pairs: make(map[string]Value)}, nil
+		},
+	})
+
+	e.env.Set("readFile", &BuiltinFunction{
+		Name: "readFile",
+		EvalFn: func(e *Evaluator, args []Value) (Value, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("readFile() expects exactly 1 argument")
+			}
+			path, ok := args[0].(*String)
+			if !ok {
+				return nil, fmt.Errorf("argument to readFile() must be string")
+			}
+			content, err := os.ReadFile(path.Value)
+			if err != nil {
+				return nil, fmt.Errorf("cannot read file: %v", err)
+			}
+			if err := e.allocate(int64(len(content))); err != nil {
+				return nil, err
+			}
+			return &String{Value: string(content)}, nil
+		},
+	})
+
+	e.env.Set("writeFile", &BuiltinFunction{
+		Name: "writeFile",
+		Fn: func(args []Value) (Value, error) {
+			if len(args) != 2 {
+				return nil, fmt.Errorf("writeFile() expects exactly 2 arguments")
+			}
+			path, ok := args[0].(*String)
+			if !ok {
+				return nil, fmt.Errorf("first argument to writeFile() must be string")
+			}
+			content, ok := args[1].(*String)
+			if !ok {
+				return nil, fmt.Errorf("second argument to writeFile() must be string")
+			}
+			if err := os.WriteFile(path.Value, []byte(content.Value), 0644); err != nil {
+				return nil, fmt.Errorf("cannot write file: %v", err)
+			}
+			return &Null{}, nil
+		},
+	})
+
+	e.env.Set("toJSON", &BuiltinFunction{
+		Name: "toJSON",
+		EvalFn: func(e *Evaluator, args []Value) (Value, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("toJSON() expects exactly 1 argument")
+			}
+			data, err := toJSONValue(args[0])
+			if err != nil {
+				return nil, err
+			}
+			encoded, err := json.Marshal(data)
+			if err != nil {
+				return nil, fmt.Errorf("cannot convert to JSON: %v", err)
+			}
+			if err := e.allocate(int64(len(encoded))); err != nil {
+				return nil, err
+			}
+			return &String{Value: string(encoded)}, nil
+		},
+	})
+
+	e.env.Set("fromJSON", &BuiltinFunction{
+		Name: "fromJSON",
+		EvalFn: func(e *Evaluator, args []Value) (Value, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("fromJSON() expects exactly 1 argument")
+			}
+			str, ok := args[0].(*String)
+			if !ok {
+				return nil, fmt.Errorf("argument to fromJSON() must be string")
+			}
+			data, err := decodeJSON(str.Value)
+			if err != nil {
+				return nil, err
+			}
+			// Los valores decodificados no ocupan más que el texto
+			if err := e.allocate(int64(len(str.Value))); err != nil {
+				return nil, err
+			}
+			return fromJSONValue(data), nil
+		},
+	})
+}
+
+// toJSONValue convierte value a los tipos de Go que codifica encoding/json.
+// Solo se convierten null, booleanos, números, strings, listas y hashes.
+func toJSONValue(value Value) (interface{}, error) {
+	switch v := value.(type) {
+	case *Null:
+		return nil, nil
+	case *Boolean:
+		return v.Value, nil
+	case *Integer:
+		return v.Value, nil
+	case *Float:
+		return v.Value, nil
+	case *String:
+		return v.Value, nil
+	case *List:
+		items := v.Elements()
+		data := make([]interface{}, len(items))
+		for i, item := range items {
+			converted, err := toJSONValue(item)
+			if err != nil {
+				return nil, err
+			}
+			data[i] = converted
+		}
+		return data, nil
+	case *Hash:
+		data := make(map[string]interface{})
+		for key, item := range v.Entries() {
+			converted, err := toJSONValue(item)
+			if err != nil {
+				return nil, err
+			}
+			data[key] = converted
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("cannot convert %s to JSON", TypeName(value))
+}
+
+// decodeJSON decodifica un documento JSON. Los números quedan como
+// json.Number para distinguir los enteros de los decimales.
+func decodeJSON(text string) (interface{}, error) {
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	var data interface{}
+	if err := decoder.Decode(&data); err != nil {
+		return nil, fmt.Errorf("cannot parse JSON: %v", err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("cannot parse JSON: unexpected data after the value")
+	}
+	return data, nil
+}
+
+// fromJSONValue convierte un valor decodificado por decodeJSON en un valor
+// de Zylo. Los números sin decimales ni exponente son enteros.
+func fromJSONValue(data interface{}) Value {
+	switch v := data.(type) {
+	case bool:
+		return &Boolean{Value: v}
+	case json.Number:
+		if n, err := strconv.ParseInt(v.String(), 10, 64); err == nil {
+			return &Integer{Value: n}
+		}
+		f, _ := v.Float64()
+		return &Float{Value: f}
+	case string:
+		return &String{Value: v}
+	case []interface{}:
+		items := make([]Value, len(v))
+		for i, item := range v {
+			items[i] = fromJSONValue(item)
+		}
+		return &List{Items: items}
+	case map[string]interface{}:
+		pairs := make(map[string]Value, len(v))
+		for key, item := range v {
+			pairs[key] = fromJSONValue(item)
+		}
+		return &Hash{Pairs: pairs}
+	}
+	return &Null{}
+}
@@ -0,0 +1,79 @@
+package evaluator
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestFileBuiltins(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.txt")
+	input := `
+writeFile(` + strconv.Quote(path) + `, "hola\nmundo")
+var content = readFile(` + strconv.Quote(path) + `)
+var message = ""
+try {
+    readFile(` + strconv.Quote(path+".missing") + `)
+} catch (e) {
+    message = e
+}
+`
+	eval, err := runProgram(t, input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectGlobal(t, eval, "content", "hola\nmundo")
+	message, _ := eval.env.Get("message")
+	if got := inspectValue(message); !strings.HasPrefix(got, "cannot read file: ") {
+		t.Errorf("expected a read error, got %q", got)
+	}
+	if written, err := os.ReadFile(path); err != nil || string(written) != "hola\nmundo" {
+		t.Errorf("unexpected file content %q (%v)", written, err)
+	}
+}
+
+func TestJSONBuiltins(t *testing.T) {
+	input := `
+var data = newMap()
+data.set("name", "Zylo")
+data.set("items", [1, 2.5, null, true])
+var text = toJSON(data)
+var parsed = fromJSON(text)
+var name = parsed.get("name")
+var numbers = fromJSON("[1, 2.0, 1e3]")
+`
+	eval, err := runProgram(t, input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectGlobal(t, eval, "text", `{"items":[1,2.5,null,true],"name":"Zylo"}`)
+	expectGlobal(t, eval, "name", "Zylo")
+	value, _ := eval.env.Get("numbers")
+	items := value.(*List).Elements()
+	if _, ok := items[0].(*Integer); !ok {
+		t.Errorf("expected 1 to be an integer, got %T", items[0])
+	}
+	for _, item := range items[1:] {
+		if _, ok := item.(*Float); !ok {
+			t.Errorf("expected %s to be a float, got %T", inspectValue(item), item)
+		}
+	}
+
+	for _, input := range []string{`toJSON(newList)`, `fromJSON("{")`, `fromJSON("1 2")`} {
+		if _, err := runProgram(t, input); err == nil {
+			t.Errorf("%s: expected an error", input)
+		}
+	}
+}
+
+func TestRange(t *testing.T) {
+	eval, err := runProgram(t, "var a = 0..3\nvar b = 3..1\nvar c = len(2..2 + 3)")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectGlobal(t, eval, "a", "[0, 1, 2]")
+	expectGlobal(t, eval, "b", "[]")
+	expectGlobal(t, eval, "c", "3")
+}
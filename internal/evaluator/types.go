@@ -0,0 +1,150 @@
+package evaluator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CheckType comprueba que value cumple la anotación de tipo annotation de
+// una variable, un parámetro o un return. El comprobador de tipos
+// (internal/sema) no conoce el tipo de los valores de tipo any, como el
+// resultado de una función sin anotar, así que el evaluador los comprueba
+// al ejecutar, igual que el ejecutable de zylo build al convertirlos.
+// Devuelve value con los int convertidos a float donde la anotación pide
+// float. null cumple cualquier anotación y una anotación vacía, cualquier
+// valor.
+func CheckType(value Value, annotation string) (Value, error) {
+	if annotation == "" {
+		return value, nil
+	}
+	converted, ok := conform(value, annotation)
+	if !ok {
+		return nil, fmt.Errorf("cannot use %s as %s", TypeName(value), annotation)
+	}
+	return converted, nil
+}
+
+// conform devuelve value convertido a la anotación, o false si no la cumple.
+// Entiende las mismas anotaciones que el comprobador de tipos: los tipos
+// básicos sin distinguir mayúsculas, list<T>, map<K, V>, future<T> y los
+// nombres de clase.
+func conform(value Value, annotation string) (Value, bool) {
+	if _, ok := value.(*Null); ok || value == nil {
+		return value, true
+	}
+	name, args, generic := strings.Cut(annotation, "<")
+	name = strings.TrimSpace(name)
+	var params []string
+	if generic {
+		for _, arg := range strings.Split(strings.TrimSuffix(strings.TrimSpace(args), ">"), ",") {
+			params = append(params, strings.TrimSpace(arg))
+		}
+	}
+
+	switch strings.ToLower(name) {
+	case "any", "dynamic":
+		return value, true
+	case "null", "void":
+		return value, false
+	case "bool", "boolean":
+		_, ok := value.(*Boolean)
+		return value, ok
+	case "int", "integer":
+		_, ok := value.(*Integer)
+		return value, ok
+	case "float", "number":
+		switch v := value.(type) {
+		case *Integer:
+			return &Float{Value: float64(v.Value)}, true
+		case *Float:
+			return value, true
+		}
+		return value, false
+	case "string":
+		_, ok := value.(*String)
+		return value, ok
+	case "future":
+		_, ok := value.(*Future)
+		return value, ok
+	case "list", "array":
+		list, ok := value.(*List)
+		if !ok || len(params) != 1 {
+			return value, ok
+		}
+		items := list.Elements()
+		changed := false
+		for i, item := range items {
+			converted, ok := conform(item, params[0])
+			if !ok {
+				return value, false
+			}
+			changed = changed || converted != item
+			items[i] = converted
+		}
+		if changed {
+			return &List{Items: items}, true
+		}
+		return value, true
+	case "map", "hash":
+		hash, ok := value.(*Hash)
+		if !ok || len(params) == 0 {
+			return value, ok
+		}
+		// Las claves de un hash son siempre strings
+		pairs := hash.Entries()
+		changed := false
+		for key, item := range pairs {
+			converted, ok := conform(item, params[len(params)-1])
+			if !ok {
+				return value, false
+			}
+			changed = changed || converted != item
+			pairs[key] = converted
+		}
+		if changed {
+			return &Hash{Pairs: pairs}, true
+		}
+		return value, true
+	}
+
+	instance, ok := value.(*ZyloInstance)
+	if !ok {
+		return value, false
+	}
+	for class := instance.Class; class != nil; class = class.Parent {
+		if class.Name == name {
+			return value, true
+		}
+	}
+	return value, false
+}
+
+// TypeName devuelve el nombre del tipo de Zylo de value, el que usan las
+// anotaciones, para los mensajes de error.
+func TypeName(value Value) string {
+	switch v := value.(type) {
+	case nil, *Null:
+		return "null"
+	case *Boolean:
+		return "bool"
+	case *Integer:
+		return "int"
+	case *Float:
+		return "float"
+	case *String:
+		return "string"
+	case *List:
+		return "list"
+	case *Hash:
+		return "map"
+	case *Future:
+		return "future"
+	case *ZyloInstance:
+		return v.Class.Name
+	case *ZyloClass:
+		return "class " + v.Name
+	case *ZyloFunction, *BuiltinFunction, *BoundMethod:
+		return "function"
+	}
+	return fmt.Sprintf("%T", value)
+}
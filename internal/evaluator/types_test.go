@@ -0,0 +1,62 @@
+package evaluator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTypeAnnotationsAtRunTime(t *testing.T) {
+	// id devuelve any, así que el comprobador de tipos no ve los errores
+	const id = "func id(x) { return x }\n"
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string // Valor de result, o el error si empieza por "error: "
+	}{
+		{"var", `var a: int = id("str")`, "error: 2:1: cannot use string as int"},
+		{"assignment", "var a: int = 1\na = id(\"str\")", "error: 3:1: cannot use string as int"},
+		{"parameter", "func half(x: float) {\n    return x / 2\n}\nvar result = half(id(true))", "error: 5:14: cannot use bool as float"},
+		{"return", "func name(): string {\n    return id(3)\n}\nvar result = name()", "error: 3:5: cannot use int as string"},
+		{"class", "class A {\n}\nvar a: A = id(1)", "error: 4:1: cannot use int as A"},
+		{"list elements", `var a: list<int> = id([1, "dos"])`, "error: 2:1: cannot use list as list<int>"},
+		{"int as float", "var f: float = id(1)\nvar result = f / 2", "0.5"},
+		{"float parameter", "func half(x: float) {\n    return x / 2\n}\nvar result = half(id(3))", "1.5"},
+		{"list of floats", "var xs: list<float> = id([1, 2])\nvar result = xs[0] / 2", "0.5"},
+		{"subclass", "class A {\n}\nclass B extends A {\n}\nvar a: A = id(B())\nvar result = a instanceof B", "true"},
+		{"null", "var s: string = id(null)\nvar result = s", "null"},
+		{"any", "var x: any = id(\"a\")\nx = 1\nvar result = x", "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eval, err := runProgram(t, id+tt.input)
+			if want, ok := strings.CutPrefix(tt.expected, "error: "); ok {
+				if err == nil || !strings.Contains(err.Error(), want) {
+					t.Fatalf("expected error containing %q, got %v", want, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expectGlobal(t, eval, "result", tt.expected)
+		})
+	}
+}
+
+func TestTypeErrorIsCatchable(t *testing.T) {
+	eval, err := runProgram(t, `func id(x) {
+    return x
+}
+var result = ""
+try {
+    var a: int = id("str")
+} catch (e) {
+    result = e
+}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectGlobal(t, eval, "result", "cannot use string as int")
+}
@@ -0,0 +1,753 @@
+// Package format implementa 'zylo fmt': reescribe el código fuente con un
+// formato canónico conservando los comentarios.
+//
+// El formato se obtiene del AST, que fija la estructura, y del flujo de
+// tokens y comentarios, que aporta lo que el AST no guarda: la posición de
+// los comentarios, las líneas en blanco y los literales multilínea.
+package format
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/parser"
+)
+
+// indentUnit es la indentación de cada nivel de bloque.
+const indentUnit = "    "
+
+// Source devuelve src con el formato canónico. Formatear el resultado otra
+// vez no lo cambia. Devuelve un error si src tiene errores de sintaxis.
+func Source(src string) (string, error) {
+	l := lexer.New(src)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if errs := p.Errors(); len(errs) > 0 {
+		return "", fmt.Errorf("errores de parsing:\n  %s", strings.Join(errs, "\n  "))
+	}
+
+	pr := newPrinter(src, l.Comments())
+	pr.program(program)
+	return pr.String(), nil
+}
+
+// Program devuelve program, parseado de src, con el formato canónico y sin
+// comentarios. program puede haber perdido sentencias después de parsearse:
+// sirve para escribir un programa reducido.
+func Program(src string, program *ast.Program) string {
+	pr := newPrinter(src, nil)
+	pr.program(program)
+	return pr.String()
+}
+
+// position es una posición línea:columna del código fuente.
+type position struct {
+	line, col int
+}
+
+func (a position) before(b position) bool {
+	return a.line < b.line || (a.line == b.line && a.col < b.col)
+}
+
+func startOf(tok lexer.Token) position {
+	return position{tok.StartLine, tok.StartCol}
+}
+
+// printer escribe el código formateado.
+type printer struct {
+	out       strings.Builder
+	depth     int  // Nivel de indentación actual
+	lineStart bool // La próxima escritura empieza una línea nueva
+
+	tokens   []lexer.Token // Tokens de código del fuente, sin saltos de línea
+	comments []lexer.Token // Comentarios del fuente, en orden
+	emitted  []bool        // Comentarios ya escritos
+	covered  map[int]bool  // Líneas del fuente con algún token o comentario
+	prevLine int           // Última línea del fuente escrita en el bloque actual
+}
+
+func newPrinter(src string, comments []lexer.Token) *printer {
+	pr := &printer{
+		lineStart: true,
+		comments:  comments,
+		emitted:   make([]bool, len(comments)),
+		covered:   make(map[int]bool),
+	}
+
+	l := lexer.New(src)
+	for {
+		tok := l.NextToken()
+		if tok.Type == lexer.EOF {
+			break
+		}
+		if tok.Type == lexer.NEWLINE {
+			continue
+		}
+		pr.tokens = append(pr.tokens, tok)
+		pr.cover(tok)
+	}
+	for _, c := range comments {
+		pr.cover(c)
+	}
+	return pr
+}
+
+// cover marca las líneas que ocupa tok.
+func (pr *printer) cover(tok lexer.Token) {
+	end := tok.EndLine
+	if end < tok.StartLine {
+		end = tok.StartLine
+	}
+	for line := tok.StartLine; line <= end; line++ {
+		pr.covered[line] = true
+	}
+}
+
+// String devuelve el resultado, terminado en un único salto de línea.
+func (pr *printer) String() string {
+	out := strings.TrimRight(pr.out.String(), "\n")
+	if out == "" {
+		return ""
+	}
+	return out + "\n"
+}
+
+// write escribe s, indentándolo si empieza una línea. s no se parte: los
+// strings y comentarios multilínea se escriben tal como están en el fuente.
+func (pr *printer) write(s string) {
+	if s == "" {
+		return
+	}
+	if pr.lineStart {
+		pr.out.WriteString(strings.Repeat(indentUnit, pr.depth))
+		pr.lineStart = false
+	}
+	pr.out.WriteString(s)
+}
+
+func (pr *printer) newline() {
+	pr.out.WriteString("\n")
+	pr.lineStart = true
+}
+
+// blankBefore indica si hay que dejar una línea en blanco antes de lo que
+// empieza en line: la hay en el fuente y no es lo primero del bloque.
+func (pr *printer) blankBefore(line int) bool {
+	return pr.prevLine > 0 && pr.prevLine < line-1 && !pr.covered[line-1]
+}
+
+// tokenIndex devuelve el índice del primer token que empieza en pos o
+// después.
+func (pr *printer) tokenIndex(pos position) int {
+	return sort.Search(len(pr.tokens), func(i int) bool {
+		return !startOf(pr.tokens[i]).before(pos)
+	})
+}
+
+// closing devuelve la posición del token que cierra el '{', '(' o '[' open.
+func (pr *printer) closing(open lexer.Token) position {
+	closer := map[lexer.TokenType]lexer.TokenType{
+		lexer.LEFT_BRACE:   lexer.RIGHT_BRACE,
+		lexer.LEFT_PAREN:   lexer.RIGHT_PAREN,
+		lexer.LEFT_BRACKET: lexer.RIGHT_BRACKET,
+	}[open.Type]
+
+	depth := 0
+	for i := pr.tokenIndex(startOf(open)); i < len(pr.tokens); i++ {
+		switch pr.tokens[i].Type {
+		case open.Type:
+			depth++
+		case closer:
+			depth--
+			if depth == 0 {
+				return startOf(pr.tokens[i])
+			}
+		}
+	}
+	return position{line: int(^uint(0) >> 1)}
+}
+
+// nextToken devuelve el primer token de tipo typ que empieza después de pos.
+func (pr *printer) nextToken(pos position, typ lexer.TokenType) lexer.Token {
+	for i := pr.tokenIndex(pos); i < len(pr.tokens); i++ {
+		if pr.tokens[i].Type == typ && pos.before(startOf(pr.tokens[i])) {
+			return pr.tokens[i]
+		}
+	}
+	return lexer.Token{}
+}
+
+// lastToken devuelve el último token que termina un elemento antes de pos.
+// Los separadores y los paréntesis de apertura no terminan ningún elemento.
+func (pr *printer) lastToken(pos position) lexer.Token {
+	for i := pr.tokenIndex(pos) - 1; i >= 0; i-- {
+		switch pr.tokens[i].Type {
+		case lexer.SEMICOLON, lexer.COMMA, lexer.LEFT_PAREN, lexer.ASYNC:
+			continue
+		}
+		return pr.tokens[i]
+	}
+	return lexer.Token{}
+}
+
+// start devuelve la posición donde empieza node en el fuente.
+func (pr *printer) start(node ast.Node) position {
+	switch n := node.(type) {
+	case *ast.FuncStatement:
+		if n.Async {
+			if i := pr.tokenIndex(startOf(n.Token)); i > 0 {
+				return startOf(pr.tokens[i-1])
+			}
+		}
+	case *ast.ExpressionStatement:
+		if n.Expression != nil {
+			return pr.start(n.Expression)
+		}
+	case *ast.FunctionLiteral:
+		if n.IsArrow() && len(n.Parameters) > 0 {
+			return startOf(n.Parameters[0].Token)
+		}
+	case *ast.InfixExpression:
+		if n.Left != nil {
+			return pr.start(n.Left)
+		}
+	case *ast.CallExpression:
+		return pr.start(n.Function)
+	case *ast.IndexExpression:
+		return pr.start(n.Left)
+	case *ast.MemberExpression:
+		return pr.start(n.Object)
+	}
+	span := ast.SpanOf(node)
+	return position{span.StartLine, span.StartCol}
+}
+
+// leadingComments escribe, cada uno en su línea, los comentarios pendientes
+// que empiezan antes de pos.
+func (pr *printer) leadingComments(pos position) {
+	for i, c := range pr.comments {
+		if pr.emitted[i] || !startOf(c).before(pos) {
+			continue
+		}
+		if pr.blankBefore(c.StartLine) {
+			pr.newline()
+		}
+		pr.write(c.Lexeme)
+		pr.newline()
+		pr.emitted[i] = true
+		pr.prevLine = max(c.EndLine, c.StartLine)
+	}
+}
+
+// trailingComments añade al final de la línea actual los comentarios
+// pendientes que siguen a end en su misma línea del fuente.
+func (pr *printer) trailingComments(end lexer.Token) {
+	if end.StartLine == 0 {
+		return
+	}
+	line := end.EndLine
+	if line < end.StartLine {
+		line = end.StartLine
+	}
+	for i, c := range pr.comments {
+		if !pr.emitted[i] && c.StartLine == line && startOf(end).before(startOf(c)) {
+			pr.write(" " + c.Lexeme)
+			pr.emitted[i] = true
+		}
+	}
+}
+
+// hasComments indica si quedan comentarios pendientes antes de pos.
+func (pr *printer) hasComments(pos position) bool {
+	for i, c := range pr.comments {
+		if !pr.emitted[i] && startOf(c).before(pos) {
+			return true
+		}
+	}
+	return false
+}
+
+// lines escribe nodes uno por línea, seguidos de suffix, con sus comentarios.
+// Entre dos elementos se conserva como mucho una línea en blanco. end es la
+// posición del token que cierra la secuencia.
+func (pr *printer) lines(nodes []ast.Node, end position, suffix string, print func(ast.Node)) {
+	pr.prevLine = 0
+	for i, node := range nodes {
+		start := pr.start(node)
+		pr.leadingComments(start)
+		if pr.blankBefore(start.line) {
+			pr.newline()
+		}
+		print(node)
+		pr.write(suffix)
+
+		boundary := end
+		if i+1 < len(nodes) {
+			boundary = pr.start(nodes[i+1])
+		}
+		last := pr.lastToken(boundary)
+		pr.trailingComments(last)
+		pr.newline()
+		pr.prevLine = max(last.EndLine, last.StartLine, start.line)
+	}
+	pr.leadingComments(end)
+}
+
+func (pr *printer) program(program *ast.Program) {
+	nodes := make([]ast.Node, 0, len(program.Statements))
+	for _, stmt := range program.Statements {
+		if stmt != nil {
+			nodes = append(nodes, stmt)
+		}
+	}
+	pr.lines(nodes, position{line: int(^uint(0) >> 1)}, "", func(n ast.Node) {
+		pr.statement(n.(ast.Statement))
+	})
+}
+
+// block escribe un bloque entre llaves. Un bloque vacío y sin comentarios se
+// escribe como '{}'.
+func (pr *printer) block(open lexer.Token, statements []ast.Statement) {
+	end := pr.closing(open)
+	if len(statements) == 0 && !pr.hasComments(end) {
+		pr.write("{}")
+		return
+	}
+
+	nodes := make([]ast.Node, 0, len(statements))
+	for _, stmt := range statements {
+		if stmt != nil {
+			nodes = append(nodes, stmt)
+		}
+	}
+	pr.write("{")
+	pr.trailingComments(open)
+	pr.newline()
+	pr.depth++
+	pr.lines(nodes, end, "", func(n ast.Node) {
+		pr.statement(n.(ast.Statement))
+	})
+	pr.depth--
+	pr.write("}")
+}
+
+func (pr *printer) statement(stmt ast.Statement) {
+	switch s := stmt.(type) {
+	case *ast.ExpressionStatement:
+		if s.Expression != nil {
+			pr.expression(s.Expression, lowest)
+		}
+	case *ast.VarStatement:
+		pr.write(s.Token.Lexeme + " " + s.Name.Value)
+		if s.Type != "" {
+			pr.write(": " + s.Type)
+		}
+		if s.Value != nil {
+			pr.write(" = ")
+			pr.expression(s.Value, lowest)
+		}
+	case *ast.FuncStatement:
+		if s.Async {
+			pr.write("async ")
+		}
+		pr.write("func " + s.Name.Value)
+		pr.signature(s.Parameters, s.ReturnType)
+		pr.write(" ")
+		pr.block(s.Body.Token, s.Body.Statements)
+	case *ast.ReturnStatement:
+		pr.write("return")
+		if s.ReturnValue != nil {
+			pr.write(" ")
+			pr.expression(s.ReturnValue, lowest)
+		}
+	case *ast.IfStatement:
+		pr.write("if ")
+		pr.ifStatement(s)
+	case *ast.WhileStatement:
+		pr.write("while ")
+		pr.expression(s.Condition, lowest)
+		pr.write(" ")
+		pr.block(s.Body.Token, s.Body.Statements)
+	case *ast.ForInStatement:
+		pr.write("for " + s.Identifier.Value + " in ")
+		pr.expression(s.Iterable, lowest)
+		pr.write(" ")
+		pr.block(s.Body.Token, s.Body.Statements)
+	case *ast.TryStatement:
+		pr.write("try ")
+		pr.block(s.TryBlock.Token, s.TryBlock.Statements)
+		if c := s.CatchClause; c != nil {
+			pr.write(" catch ")
+			if c.Parameter != nil {
+				pr.write("(" + c.Parameter.Value + ") ")
+			}
+			pr.block(c.CatchBlock.Token, c.CatchBlock.Statements)
+		}
+		if s.FinallyBlock != nil {
+			pr.write(" finally ")
+			pr.block(s.FinallyBlock.Token, s.FinallyBlock.Statements)
+		}
+	case *ast.ThrowStatement:
+		pr.write("throw ")
+		pr.expression(s.Exception, lowest)
+	case *ast.BreakStatement:
+		pr.write("break")
+	case *ast.ContinueStatement:
+		pr.write("continue")
+	case *ast.ImportStatement:
+		pr.write(importStatement(s))
+	case *ast.ExportStatement:
+		pr.write("export ")
+		pr.statement(s.Statement)
+	case *ast.ClassStatement:
+		pr.class(s)
+	default:
+		pr.write(stmt.String())
+	}
+}
+
+// importStatement devuelve un import en su forma canónica. El módulo se
+// escribe como ruta entre comillas solo si así se escribió.
+func importStatement(s *ast.ImportStatement) string {
+	module := s.ModuleName.Value
+	if s.Path != "" {
+		module = strconv.Quote(s.Path)
+	}
+	if s.Names == nil {
+		return "import " + module
+	}
+	names := make([]string, len(s.Names))
+	for i, name := range s.Names {
+		names[i] = name.Value
+	}
+	return "from " + module + " import " + strings.Join(names, ", ")
+}
+
+// ifStatement escribe la condición, el bloque y las ramas elif/else de s; el
+// 'if', 'elif' o 'else if' inicial ya está escrito.
+func (pr *printer) ifStatement(s *ast.IfStatement) {
+	pr.expression(s.Condition, lowest)
+	pr.write(" ")
+	pr.block(s.Consequence.Token, s.Consequence.Statements)
+
+	alt := s.Alternative
+	if alt == nil {
+		return
+	}
+	// Un elif o un 'else if' se guarda como un bloque con el token del if
+	// interior y ese if como única sentencia.
+	if len(alt.Statements) == 1 {
+		if inner, ok := alt.Statements[0].(*ast.IfStatement); ok && startOf(inner.Token) == startOf(alt.Token) {
+			if inner.Token.Type == lexer.ELIF {
+				pr.write(" elif ")
+			} else {
+				pr.write(" else if ")
+			}
+			pr.ifStatement(inner)
+			return
+		}
+	}
+	pr.write(" else ")
+	pr.block(alt.Token, alt.Statements)
+}
+
+// class escribe una clase con sus atributos y métodos en el orden del fuente.
+func (pr *printer) class(s *ast.ClassStatement) {
+	pr.write("class " + s.Name.Value)
+	header := s.Name.Token
+	if s.SuperClass != nil {
+		pr.write(" extends " + s.SuperClass.Value)
+		header = s.SuperClass.Token
+	}
+	pr.write(" ")
+
+	var members []ast.Node
+	for _, attr := range s.Attributes {
+		members = append(members, attr)
+	}
+	for _, method := range s.Methods {
+		members = append(members, method)
+	}
+	sort.SliceStable(members, func(i, j int) bool {
+		return pr.start(members[i]).before(pr.start(members[j]))
+	})
+
+	open := pr.nextToken(startOf(header), lexer.LEFT_BRACE)
+	end := pr.closing(open)
+	if len(members) == 0 && !pr.hasComments(end) {
+		pr.write("{}")
+		return
+	}
+	pr.write("{")
+	pr.trailingComments(open)
+	pr.newline()
+	pr.depth++
+	pr.lines(members, end, "", func(n ast.Node) {
+		pr.statement(n.(ast.Statement))
+	})
+	pr.depth--
+	pr.write("}")
+}
+
+// signature escribe '(a: T, b)' y el tipo de retorno, si lo hay.
+func (pr *printer) signature(params []*ast.Identifier, returnType string) {
+	pr.write("(" + parameters(params) + ")")
+	if returnType != "" {
+		pr.write(": " + returnType)
+	}
+}
+
+func parameters(params []*ast.Identifier) string {
+	parts := make([]string, len(params))
+	for i, param := range params {
+		parts[i] = param.Value
+		if param.Type != "" {
+			parts[i] += ": " + param.Type
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Precedencias de las expresiones, de menor a mayor, para decidir dónde hacen
+// falta paréntesis.
+const (
+	lowest = iota
+	assign
+	logicalOr
+	logicalAnd
+	equals
+	compares
+	ranges
+	sum
+	product
+	prefix
+	call
+	primary
+)
+
+var infixPrecedence = map[lexer.TokenType]int{
+	lexer.EQUAL:         assign,
+	lexer.OR:            logicalOr,
+	lexer.AND:           logicalAnd,
+	lexer.EQUAL_EQUAL:   equals,
+	lexer.BANG_EQUAL:    equals,
+	lexer.LESS:          compares,
+	lexer.LESS_EQUAL:    compares,
+	lexer.GREATER:       compares,
+	lexer.GREATER_EQUAL: compares,
+	lexer.INSTANCEOF:    compares,
+	lexer.DOT_DOT:       ranges,
+	lexer.PLUS:          sum,
+	lexer.MINUS:         sum,
+	lexer.STAR:          product,
+	lexer.SLASH:         product,
+	lexer.PERCENT:       product,
+}
+
+func precedence(exp ast.Expression) int {
+	switch e := exp.(type) {
+	case *ast.InfixExpression:
+		if p, ok := infixPrecedence[e.Token.Type]; ok {
+			return p
+		}
+		return lowest
+	case *ast.FunctionLiteral:
+		if e.IsArrow() {
+			return assign
+		}
+	case *ast.PrefixExpression, *ast.AwaitExpression, *ast.SpawnExpression:
+		return prefix
+	}
+	return primary
+}
+
+// expression escribe exp, entre paréntesis si su precedencia es menor que
+// min.
+func (pr *printer) expression(exp ast.Expression, min int) {
+	if exp == nil {
+		return
+	}
+	if precedence(exp) < min {
+		pr.write("(")
+		defer pr.write(")")
+	}
+
+	switch e := exp.(type) {
+	case *ast.Identifier:
+		pr.write(e.Value)
+	case *ast.NumberLiteral:
+		pr.write(e.Token.Lexeme)
+	case *ast.StringLiteral:
+		if e.Token.Lexeme != "" {
+			pr.write(e.Token.Lexeme)
+		} else {
+			pr.write(fmt.Sprintf("%q", e.Value))
+		}
+	case *ast.BooleanLiteral:
+		pr.write(e.Token.Lexeme)
+	case *ast.NullLiteral:
+		pr.write(e.Token.Lexeme)
+	case *ast.ThisExpression:
+		pr.write("this")
+	case *ast.SuperExpression:
+		pr.write("super")
+	case *ast.PrefixExpression:
+		pr.write(e.Operator)
+		pr.expression(e.Right, prefix)
+	case *ast.InfixExpression:
+		p := precedence(e)
+		left, right := p, p+1
+		if e.Token.Type == lexer.EQUAL {
+			left, right = p+1, p // La asignación asocia por la derecha
+		}
+		pr.expression(e.Left, left)
+		if e.Token.Type == lexer.DOT_DOT {
+			pr.write(e.Operator) // Los rangos se escriben sin espacios: 0..10
+		} else {
+			pr.write(" " + e.Operator + " ")
+		}
+		pr.expression(e.Right, right)
+	case *ast.CallExpression:
+		pr.expression(e.Function, call)
+		pr.list("(", e.Token, e.Arguments, ")")
+	case *ast.IndexExpression:
+		pr.expression(e.Left, call)
+		pr.write("[")
+		pr.expression(e.Index, lowest)
+		pr.write("]")
+	case *ast.MemberExpression:
+		pr.expression(e.Object, call)
+		pr.write("." + e.Property.Value)
+	case *ast.ListLiteral:
+		pr.list("[", e.Token, e.Elements, "]")
+	case *ast.HashLiteral:
+		pr.hash(e)
+	case *ast.FunctionLiteral:
+		pr.function(e)
+	case *ast.SpawnExpression:
+		pr.write("spawn ")
+		pr.expression(e.Call, call)
+	case *ast.AwaitExpression:
+		pr.write("await ")
+		pr.expression(e.Value, prefix)
+	case *ast.ImportStatement:
+		pr.write(importStatement(e))
+	default:
+		pr.write(exp.String())
+	}
+}
+
+// multiline indica si una lista que se abre con open debe escribirse con un
+// elemento por línea: así se escribió en el fuente si el primer elemento
+// empieza en una línea posterior al delimitador.
+func (pr *printer) multiline(open lexer.Token, first ast.Node) bool {
+	return pr.start(first).line > open.StartLine
+}
+
+// list escribe los elementos de una lista o los argumentos de una llamada.
+func (pr *printer) list(left string, open lexer.Token, elements []ast.Expression, right string) {
+	if len(elements) == 0 || !pr.multiline(open, elements[0]) {
+		pr.write(left)
+		for i, element := range elements {
+			if i > 0 {
+				pr.write(", ")
+			}
+			pr.expression(element, lowest)
+		}
+		pr.write(right)
+		return
+	}
+
+	nodes := make([]ast.Node, len(elements))
+	for i, element := range elements {
+		nodes[i] = element
+	}
+	pr.write(left)
+	pr.trailingComments(open)
+	pr.newline()
+	pr.depth++
+	pr.lines(nodes, pr.closing(open), ",", func(n ast.Node) {
+		pr.expression(n.(ast.Expression), lowest)
+	})
+	pr.depth--
+	pr.write(right)
+}
+
+// hashPair es un par clave-valor de un literal hash; el AST los guarda en un
+// map, así que el orden del fuente se recupera por la posición de la clave.
+type hashPair struct {
+	key, value ast.Expression
+}
+
+func (hp *hashPair) TokenLiteral() string { return hp.key.TokenLiteral() }
+func (hp *hashPair) String() string       { return hp.key.String() + ": " + hp.value.String() }
+func (hp *hashPair) Pos() lexer.Token     { return hp.key.Pos() }
+
+func (pr *printer) hash(h *ast.HashLiteral) {
+	pairs := make([]ast.Node, 0, len(h.Pairs))
+	for key, value := range h.Pairs {
+		pairs = append(pairs, &hashPair{key, value})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return pr.start(pairs[i]).before(pr.start(pairs[j]))
+	})
+
+	printPair := func(n ast.Node) {
+		pair := n.(*hashPair)
+		pr.expression(pair.key, lowest)
+		pr.write(": ")
+		pr.expression(pair.value, lowest)
+	}
+
+	if len(pairs) == 0 || !pr.multiline(h.Token, pairs[0]) {
+		pr.write("{")
+		for i, pair := range pairs {
+			if i > 0 {
+				pr.write(", ")
+			}
+			printPair(pair)
+		}
+		pr.write("}")
+		return
+	}
+
+	pr.write("{")
+	pr.trailingComments(h.Token)
+	pr.newline()
+	pr.depth++
+	pr.lines(pairs, pr.closing(h.Token), ",", printPair)
+	pr.depth--
+	pr.write("}")
+}
+
+// function escribe una función anónima o una lambda flecha.
+func (pr *printer) function(f *ast.FunctionLiteral) {
+	if !f.IsArrow() {
+		pr.write("func")
+		pr.signature(f.Parameters, f.ReturnType)
+		pr.write(" ")
+		pr.block(f.Body.Token, f.Body.Statements)
+		return
+	}
+
+	if len(f.Parameters) == 1 && f.Parameters[0].Type == "" {
+		pr.write(f.Parameters[0].Value)
+	} else {
+		pr.write("(" + parameters(f.Parameters) + ")")
+	}
+	pr.write(" => ")
+
+	// El cuerpo de una lambda con expresión es un bloque sintético con el
+	// token '=>' y un único return.
+	if f.Body.Token.Type == lexer.ARROW && len(f.Body.Statements) == 1 {
+		if ret, ok := f.Body.Statements[0].(*ast.ReturnStatement); ok {
+			pr.expression(ret.ReturnValue, assign)
+			return
+		}
+	}
+	pr.block(f.Body.Token, f.Body.Statements)
+}
@@ -0,0 +1,236 @@
+package format
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/parser"
+)
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "spacing, semicolons and return type",
+			input:    "func main() Int{\n  var a:Int=1+2*3;\n  show.log( a );return 0;\n}",
+			expected: "func main(): Int {\n    var a: Int = 1 + 2 * 3\n    show.log(a)\n    return 0\n}\n",
+		},
+		{
+			name:     "parentheses are kept only where needed",
+			input:    "var x = (a + b) * c - (d - e) - (f)\nx = y = -(1 + 2)\nvar ok = !done && (a || b)\n",
+			expected: "var x = (a + b) * c - (d - e) - f\nx = y = -(1 + 2)\nvar ok = !done && (a || b)\n",
+		},
+		{
+			name:     "if, elif and else",
+			input:    "if (a>1){x=1}elif b{  } else if c { y = 2 } else { z = 3 }",
+			expected: "if a > 1 {\n    x = 1\n} elif b {} else if c {\n    y = 2\n} else {\n    z = 3\n}\n",
+		},
+		{
+			name:     "blank lines collapse to one and are trimmed at block edges",
+			input:    "func f() {\n\n    var a = 1\n\n\n\n    var b = 2\n\n}\n\n\n\nf()\n\n",
+			expected: "func f() {\n    var a = 1\n\n    var b = 2\n}\n\nf()\n",
+		},
+		{
+			name:     "imports and exports",
+			input:    "import utils;import \"lib/strings\"\nfrom  \"lib/utils\"  import Split ,Len\nexport   var x=1\nexport func f(){return x}\n",
+			expected: "import utils\nimport \"lib/strings\"\nfrom \"lib/utils\" import Split, Len\nexport var x = 1\nexport func f() {\n    return x\n}\n",
+		},
+		{
+			name:     "one statement per line",
+			input:    "var x = 5; show.log(x)",
+			expected: "var x = 5\nshow.log(x)\n",
+		},
+		{
+			name:     "loops, try and throw",
+			input:    "for i in range(3) { if i == 1 { continue }; break }\nwhile true {}\ntry { throw \"x\" } catch e { show.log(e) } finally {}",
+			expected: "for i in range(3) {\n    if i == 1 {\n        continue\n    }\n    break\n}\nwhile true {}\ntry {\n    throw \"x\"\n} catch (e) {\n    show.log(e)\n} finally {}\n",
+		},
+		{
+			name:     "classes keep member order",
+			input:    "class Dog   extends Animal{\n  func speak(){return this.name}\n  var name: String = \"rex\"\n  async func later(ms:Int):Int{return await sleep(ms)}\n}",
+			expected: "class Dog extends Animal {\n    func speak() {\n        return this.name\n    }\n    var name: String = \"rex\"\n    async func later(ms: Int): Int {\n        return await sleep(ms)\n    }\n}\n",
+		},
+		{
+			name:     "functions and lambdas",
+			input:    "var f = (x) => x*2\nvar g = (a: Int, b) => { return a+b }\nvar h = func(n){return n}\nspawn worker( 1 )",
+			expected: "var f = x => x * 2\nvar g = (a: Int, b) => {\n    return a + b\n}\nvar h = func(n) {\n    return n\n}\nspawn worker(1)\n",
+		},
+		{
+			name:     "literals keep their source spelling",
+			input:    "var s = 'hola'\nvar n = 1.50\nvar m = \"\"\"uno\n  dos\"\"\"\nvar z = nil",
+			expected: "var s = 'hola'\nvar n = 1.50\nvar m = \"\"\"uno\n  dos\"\"\"\nvar z = nil\n",
+		},
+		{
+			name:     "hashes keep source order",
+			input:    "var h={b:1,a:2,}\nvar l=[1,2,3,]",
+			expected: "var h = {b: 1, a: 2}\nvar l = [1, 2, 3]\n",
+		},
+		{
+			name:     "ranges are written without spaces",
+			input:    "for i in 0 .. n+1 {}\nvar r = (0..3) == [0, 1, 2]",
+			expected: "for i in 0..n + 1 {}\nvar r = 0..3 == [0, 1, 2]\n",
+		},
+		{
+			name:     "multi-line lists get one element per line",
+			input:    "var l = [\n  1,\n  2]\nf(\n a, b)\nvar h = {\n  a: 1, b: 2}",
+			expected: "var l = [\n    1,\n    2,\n]\nf(\n    a,\n    b,\n)\nvar h = {\n    a: 1,\n    b: 2,\n}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Source(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("unexpected output:\n%s\nexpected:\n%s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := `// cabecera
+
+
+import math
+func main() { // entrada
+  # antes
+  var x = 1    // uno
+
+
+  /* bloque
+     largo */
+  var l = [
+    1, // primero
+    2,
+  ]
+  if x {
+    // solo un comentario
+  }
+  // al final del bloque
+}
+// fin
+`
+	expected := `// cabecera
+
+import math
+func main() { // entrada
+    # antes
+    var x = 1 // uno
+
+    /* bloque
+     largo */
+    var l = [
+        1, // primero
+        2,
+    ]
+    if x {
+        // solo un comentario
+    }
+    // al final del bloque
+}
+// fin
+`
+	got, err := Source(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestIdempotent(t *testing.T) {
+	files, err := filepath.Glob("../../examples/*.zylo")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no examples found: %v", err)
+	}
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			once, err := Source(string(content))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			twice, err := Source(once)
+			if err != nil {
+				t.Fatalf("formatted output does not parse: %v\n%s", err, once)
+			}
+			if once != twice {
+				t.Errorf("formatting is not idempotent:\n%s", Diff(file, once, twice))
+			}
+		})
+	}
+}
+
+func TestProgram(t *testing.T) {
+	src := `// saludo
+var a = 1
+func f(x) {
+    show.log(x)  // muestra x
+    return x * 2
+}
+show.log(f(a))
+`
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+	// Quitar el show.log de f y la última sentencia
+	fn := program.Statements[1].(*ast.FuncStatement)
+	fn.Body.Statements = fn.Body.Statements[1:]
+	program.Statements = program.Statements[:2]
+
+	expected := "var a = 1\nfunc f(x) {\n    return x * 2\n}\n"
+	if got := Program(src, program); got != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, got)
+	}
+}
+
+func TestSyntaxError(t *testing.T) {
+	if _, err := Source("var = 1"); err == nil || !strings.Contains(err.Error(), "errores de parsing") {
+		t.Errorf("expected a parse error, got %v", err)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	if got := Diff("a.zylo", "x\n", "x\n"); got != "" {
+		t.Errorf("expected no diff for equal inputs, got:\n%s", got)
+	}
+
+	before := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	after := "1\n2\n3\n4\nCINCO\n6\n7\n8\n9\n10\n11\n12\nTRECE\n"
+	expected := `--- a.zylo
++++ a.zylo
+@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++CINCO
+ 6
+ 7
+ 8
+@@ -10,3 +10,4 @@
+ 10
+ 11
+ 12
++TRECE
+`
+	if got := Diff("a.zylo", before, after); got != expected {
+		t.Errorf("unexpected diff:\n%s\nexpected:\n%s", got, expected)
+	}
+}
@@ -8,13 +8,14 @@ import (
 
 // Lexer se encarga de convertir el código fuente en una secuencia de tokens.
 type Lexer struct {
-	source      []rune // El código fuente como un slice de runas para soportar Unicode.
-	start       int    // Posición de inicio del token actual.
-	current     int    // Posición actual en el slice de runas.
-	line        int    // Línea actual.
-	column      int    // Columna actual en la línea.
-	startLine   int    // Línea de inicio del token actual.
-	startColumn int    // Columna de inicio del token actual.
+	source      []rune  // El código fuente como un slice de runas para soportar Unicode.
+	start       int     // Posición de inicio del token actual.
+	current     int     // Posición actual en el slice de runas.
+	line        int     // Línea actual.
+	column      int     // Columna actual en la línea.
+	startLine   int     // Línea de inicio del token actual.
+	startColumn int     // Columna de inicio del token actual.
+	comments    []Token // Comentarios saltados, en orden de aparición.
 }
 
 // New crea un nuevo Lexer para el código fuente proporcionado.
@@ -115,7 +116,7 @@ func (l *Lexer) makeToken(tokenType TokenType, literal interface{}) Token {
 // errorToken crea un token de error.
 func (l *Lexer) errorToken(message string) Token {
 	return Token{
-		Type:      "ERROR",
+		Type:      ERROR,
 		Lexeme:    message,
 		StartLine: l.line,
 		StartCol:  l.column,
@@ -134,6 +135,7 @@ func (l *Lexer) skipWhitespace() {
 			// No consumir newlines aquí, dejarlos para que sean tokens
 			return
 		case '/':
+			start, line, column := l.current, l.line, l.column
 			if l.peekNext() == '/' { // Comentario de una línea
 				for l.peek() != '\n' && !l.isAtEnd() {
 					l.advance()
@@ -146,17 +148,39 @@ func (l *Lexer) skipWhitespace() {
 			} else {
 				return
 			}
+			l.addComment(start, line, column)
 		case '#': // Soporte para comentarios de una línea con #
+			start, line, column := l.current, l.line, l.column
 			for l.peek() != '\n' && !l.isAtEnd() {
 				l.advance()
 			}
 			// No consumir el newline aquí, dejarlo para que sea un token
+			l.addComment(start, line, column)
 		default:
 			return
 		}
 	}
 }
 
+// addComment registra el comentario que empieza en start y termina en la
+// posición actual.
+func (l *Lexer) addComment(start, line, column int) {
+	l.comments = append(l.comments, Token{
+		Type:      COMMENT,
+		Lexeme:    string(l.source[start:l.current]),
+		StartLine: line,
+		StartCol:  column,
+		EndLine:   l.line,
+		EndCol:    l.column - 1,
+	})
+}
+
+// Comments devuelve los comentarios leídos hasta ahora, en orden. El parser
+// no los recibe como tokens; los usa el formateador para conservarlos.
+func (l *Lexer) Comments() []Token {
+	return l.comments
+}
+
 // skipMultiLineComment consume un comentario multilínea, incluyendo anidamiento.
 func (l *Lexer) skipMultiLineComment() {
 	nestingLevel := 1
@@ -370,6 +394,9 @@ func (l *Lexer) NextToken() Token {
 	case ',':
 		return l.makeToken(COMMA, nil)
 	case '.':
+		if l.match('.') {
+			return l.makeToken(DOT_DOT, nil)
+		}
 		return l.makeToken(DOT, nil)
 	case '-':
 		return l.makeToken(MINUS, nil)
@@ -453,6 +480,7 @@ var keywords = map[string]TokenType{
 	// "show" and "log" are treated as regular identifiers for member access
 	"import":   IMPORT,
 	"from":     FROM,
+	"export":   EXPORT,
 	"try":      TRY,
 	"catch":    CATCH,
 	"throw":    THROW,
@@ -461,4 +489,8 @@ var keywords = map[string]TokenType{
 	"await":    AWAIT,
 	"spawn":    SPAWN,
 	"in":       IN,
+
+	// Herencia
+	"extends":    EXTENDS,
+	"instanceof": INSTANCEOF,
 }
@@ -119,6 +119,29 @@ line
 		})
 	}
 }
+
+func TestComments(t *testing.T) {
+	input := "var x = 1 // uno\n# dos\n/* tres\n /* anidado */ */ x"
+	l := New(input)
+	for l.NextToken().Type != EOF {
+	}
+
+	expected := []Token{
+		{Type: COMMENT, Lexeme: "// uno", StartLine: 1, StartCol: 11, EndLine: 1, EndCol: 16},
+		{Type: COMMENT, Lexeme: "# dos", StartLine: 2, StartCol: 1, EndLine: 2, EndCol: 5},
+		{Type: COMMENT, Lexeme: "/* tres\n /* anidado */ */", StartLine: 3, StartCol: 1, EndLine: 4, EndCol: 17},
+	}
+	comments := l.Comments()
+	if len(comments) != len(expected) {
+		t.Fatalf("expected %d comments, got %d: %v", len(expected), len(comments), comments)
+	}
+	for i, want := range expected {
+		if comments[i] != want {
+			t.Errorf("comment %d: expected %v, got %v", i, want, comments[i])
+		}
+	}
+}
+
 func BenchmarkLex(b *testing.B) {
 	input := `var five = 5;
 const ten = 10.5;
@@ -51,6 +51,7 @@ const (
 	LESS          TokenType = "LESS"
 	LESS_EQUAL    TokenType = "LESS_EQUAL"
 	ARROW         TokenType = "ARROW" // =>
+	DOT_DOT       TokenType = "DOT_DOT" // ..
 
 	// Literales
 	IDENTIFIER TokenType = "IDENTIFIER"
@@ -81,6 +82,7 @@ const (
 	LOG      TokenType = "LOG"
 	IMPORT   TokenType = "IMPORT"
 	FROM     TokenType = "FROM"
+	EXPORT   TokenType = "EXPORT"
 	TRY      TokenType = "TRY"
 	CATCH    TokenType = "CATCH"
 	THROW    TokenType = "THROW"
@@ -90,7 +92,15 @@ const (
 	SPAWN    TokenType = "SPAWN"
 	IN       TokenType = "IN"
 
+	// Herencia
+	EXTENDS    TokenType = "EXTENDS"
+	INSTANCEOF TokenType = "INSTANCEOF"
+
 	// Control
 	NEWLINE TokenType = "NEWLINE"
 	EOF     TokenType = "EOF"
+	ERROR   TokenType = "ERROR" // Texto no reconocido; Lexeme lleva el mensaje
+
+	// Comentarios, que el lexer guarda aparte (ver Lexer.Comments)
+	COMMENT TokenType = "COMMENT"
 )
@@ -0,0 +1,295 @@
+package lsp
+
+import (
+	"strings"
+	"unicode/utf16"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/parser"
+	"github.com/zylo-lang/zylo/internal/sema"
+)
+
+// document es un documento abierto en el editor y el resultado de
+// analizarlo. Se vuelve a analizar entero con cada cambio.
+type document struct {
+	uri         string
+	lines       [][]rune
+	program     *ast.Program
+	analyzer    *sema.SemanticAnalyzer
+	checker     *sema.Checker
+	decls       map[*ast.Identifier]*declaration
+	diagnostics []Diagnostic
+}
+
+// declaration es un identificador que declara algo en el documento.
+type declaration struct {
+	kind  string   // Lo que declara: "variable", "función", "clase"...
+	scope ast.Node // Función, bloque o clase que la contiene; nil en el nivel superior
+}
+
+// newDocument analiza text con el lexer, el parser y sema. Con errores de
+// parsing el análisis sigue sobre el AST parcial, para que el resto de las
+// funciones del editor sigan funcionando, y de sus errores solo se informan
+// los de las sentencias sin errores de parsing.
+func newDocument(uri, text string) *document {
+	d := &document{uri: uri, decls: make(map[*ast.Identifier]*declaration)}
+	for _, line := range strings.Split(text, "\n") {
+		d.lines = append(d.lines, []rune(line))
+	}
+
+	p := parser.New(lexer.New(text))
+	d.program = p.ParseProgram()
+	var parseErrors []ast.Span
+	for _, diag := range p.Diagnostics() {
+		d.addDiagnostic(diag.Span, diag.Code, diag.Message)
+		parseErrors = append(parseErrors, diag.Span)
+	}
+
+	d.analyzer = sema.NewSemanticAnalyzer()
+	d.checker = sema.NewChecker()
+	if !safely(func() { d.analyzer.Analyze(d.program) }) || !safely(func() { d.checker.Check(d.program) }) {
+		return d
+	}
+	d.collectDeclarations(d.program, nil, "")
+
+	for _, err := range d.analyzer.NameErrors() {
+		if d.parsedCleanly(err.Span, parseErrors) {
+			d.addDiagnostic(err.Span, err.Code, err.Message)
+		}
+	}
+	for _, err := range d.checker.Errors() {
+		if d.parsedCleanly(err.Span, parseErrors) {
+			d.addDiagnostic(err.Span, err.Code, err.Message)
+		}
+	}
+	return d
+}
+
+// parsedCleanly indica si la sentencia más interna que contiene span no
+// tiene ninguno de los errores de parsing parseErrors. Los errores de sema
+// en las sentencias con errores de parsing suelen ser consecuencia de ellos.
+func (d *document) parsedCleanly(span ast.Span, parseErrors []ast.Span) bool {
+	if len(parseErrors) == 0 {
+		return true
+	}
+	var innermost ast.Span
+	ast.Inspect(d.program, func(n ast.Node) bool {
+		if n == nil {
+			return false
+		}
+		s := extent(n)
+		if !contains(s, span.StartLine, span.StartCol) {
+			return false
+		}
+		if _, ok := n.(ast.Statement); ok {
+			innermost = s
+		}
+		return true
+	})
+	if innermost.StartLine == 0 {
+		return true
+	}
+	for _, err := range parseErrors {
+		if contains(innermost, err.StartLine, err.StartCol) {
+			return false
+		}
+	}
+	return true
+}
+
+// safely ejecuta f y devuelve false si entra en pánico. El análisis de un
+// AST parcial puede encontrar nodos incompletos.
+func safely(f func()) (ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	f()
+	return true
+}
+
+func (d *document) addDiagnostic(span ast.Span, code, msg string) {
+	d.diagnostics = append(d.diagnostics, Diagnostic{
+		Range:    d.rangeOf(span),
+		Severity: SeverityError,
+		Code:     code,
+		Source:   "zylo",
+		Message:  msg,
+	})
+}
+
+// collectDeclarations registra los identificadores que declaran algo dentro
+// de node. scope es la función, bloque o clase que contiene a node y class,
+// la clase cuyos miembros se declaran.
+func (d *document) collectDeclarations(node ast.Node, scope ast.Node, class string) {
+	declare := func(ident *ast.Identifier, kind string, scope ast.Node) {
+		if ident != nil {
+			d.decls[ident] = &declaration{kind: kind, scope: scope}
+		}
+	}
+	switch n := node.(type) {
+	case *ast.VarStatement:
+		kind := "variable"
+		if class != "" {
+			kind = "atributo"
+		}
+		declare(n.Name, kind, scope)
+		if n.Value != nil {
+			d.collectDeclarations(n.Value, scope, "")
+		}
+		return
+	case *ast.FuncStatement:
+		kind := "función"
+		if class != "" {
+			kind = "método"
+		}
+		declare(n.Name, kind, scope)
+		for _, param := range n.Parameters {
+			declare(param, "parámetro", n)
+		}
+		if n.Body != nil {
+			d.collectDeclarations(n.Body, n, "")
+		}
+		return
+	case *ast.FunctionLiteral:
+		for _, param := range n.Parameters {
+			declare(param, "parámetro", n)
+		}
+		if n.Body != nil {
+			d.collectDeclarations(n.Body, n, "")
+		}
+		return
+	case *ast.ClassStatement:
+		declare(n.Name, "clase", scope)
+		for _, child := range ast.Children(n) {
+			if child != ast.Node(n.Name) && child != ast.Node(n.SuperClass) {
+				d.collectDeclarations(child, n, n.Name.Value)
+			}
+		}
+		return
+	case *ast.ImportStatement:
+		if n.Names == nil {
+			declare(n.ModuleName, "módulo", scope)
+		}
+		for _, name := range n.Names {
+			declare(name, "importado", scope)
+		}
+		return
+	case *ast.ForInStatement:
+		declare(n.Identifier, "variable", n)
+	case *ast.CatchClause:
+		declare(n.Parameter, "variable", n)
+	case *ast.BlockStatement:
+		scope = n
+	}
+	for _, child := range ast.Children(node) {
+		d.collectDeclarations(child, scope, "")
+	}
+}
+
+// identifierAt devuelve el identificador que ocupa la línea y la columna
+// dadas (desde 1, en runas), o el que termina justo antes, y el nodo que lo
+// contiene.
+func (d *document) identifierAt(line, col int) (*ast.Identifier, ast.Node) {
+	var found *ast.Identifier
+	var parent ast.Node
+	var visit func(node, from ast.Node)
+	visit = func(node, from ast.Node) {
+		if ident, ok := node.(*ast.Identifier); ok {
+			tok := ident.Token
+			if tok.StartLine == line && tok.StartCol <= col && col <= tok.EndCol+1 {
+				// Un identificador bajo el cursor gana al que termina antes
+				if found == nil || col <= tok.EndCol {
+					found, parent = ident, from
+				}
+			}
+		}
+		for _, child := range ast.Children(node) {
+			visit(child, node)
+		}
+	}
+	visit(d.program, nil)
+	return found, parent
+}
+
+// extent devuelve la región que ocupan node y todos sus subnodos, hasta la
+// '}' que cierra los bloques y las clases.
+func extent(node ast.Node) ast.Span {
+	span := ast.SpanOf(node)
+	grow := func(s ast.Span) {
+		if s.StartLine == 0 {
+			return
+		}
+		if span.StartLine == 0 || before(s.StartLine, s.StartCol, span.StartLine, span.StartCol) {
+			span.StartLine, span.StartCol = s.StartLine, s.StartCol
+		}
+		if before(span.EndLine, span.EndCol, s.EndLine, s.EndCol) {
+			span.EndLine, span.EndCol = s.EndLine, s.EndCol
+		}
+	}
+	ast.Inspect(node, func(n ast.Node) bool {
+		grow(ast.SpanOf(n))
+		switch n := n.(type) {
+		case *ast.BlockStatement:
+			grow(ast.TokenSpan(n.RBrace))
+		case *ast.ClassStatement:
+			grow(ast.TokenSpan(n.RBrace))
+		}
+		return true
+	})
+	return span
+}
+
+// contains indica si la posición (line, col) está dentro de span.
+func contains(span ast.Span, line, col int) bool {
+	return !before(line, col, span.StartLine, span.StartCol) && !before(span.EndLine, span.EndCol+1, line, col)
+}
+
+// before indica si la posición (l1, c1) va antes que (l2, c2).
+func before(l1, c1, l2, c2 int) bool {
+	return l1 < l2 || (l1 == l2 && c1 < c2)
+}
+
+// rangeOf convierte una región de Zylo (desde 1, en runas, con el final
+// incluido) en un Range de LSP.
+func (d *document) rangeOf(span ast.Span) Range {
+	if span.StartLine == 0 {
+		return Range{}
+	}
+	return Range{
+		Start: d.position(span.StartLine, span.StartCol),
+		End:   d.position(span.EndLine, span.EndCol+1),
+	}
+}
+
+// position convierte una línea y una columna de Zylo en una Position.
+func (d *document) position(line, col int) Position {
+	pos := Position{Line: line - 1}
+	if line < 1 || line > len(d.lines) {
+		return pos
+	}
+	runes := d.lines[line-1]
+	if col-1 > len(runes) {
+		col = len(runes) + 1
+	}
+	pos.Character = len(utf16.Encode(runes[:max(col-1, 0)]))
+	return pos
+}
+
+// location convierte una Position en la línea y la columna de Zylo.
+func (d *document) location(pos Position) (line, col int) {
+	line = pos.Line + 1
+	if pos.Line < 0 || pos.Line >= len(d.lines) {
+		return line, pos.Character + 1
+	}
+	units := 0
+	for i, r := range d.lines[pos.Line] {
+		if units >= pos.Character {
+			return line, i + 1
+		}
+		units += len(utf16.Encode([]rune{r}))
+	}
+	return line, len(d.lines[pos.Line]) + 1
+}
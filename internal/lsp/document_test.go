@@ -0,0 +1,72 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+)
+
+func TestPositionsUseUTF16(t *testing.T) {
+	// ñ ocupa una unidad UTF-16 y 😀 dos, pero los dos son una runa
+	d := newDocument(uri, "var año = \"😀\"\nshow.log(año)\n")
+	if len(d.diagnostics) != 0 {
+		t.Fatalf("unexpected diagnostics %+v", d.diagnostics)
+	}
+
+	got := d.rangeOf(ast.Span{StartLine: 1, StartCol: 11, EndLine: 1, EndCol: 13})
+	want := Range{Start: Position{Line: 0, Character: 10}, End: Position{Line: 0, Character: 14}}
+	if got != want {
+		t.Errorf("expected range %+v, got %+v", want, got)
+	}
+	if line, col := d.location(Position{Line: 0, Character: 14}); line != 1 || col != 14 {
+		t.Errorf("expected 1:14, got %d:%d", line, col)
+	}
+
+	ident, _ := d.identifierAt(d.location(Position{Line: 1, Character: 11}))
+	if ident == nil || ident.Value != "año" {
+		t.Errorf("expected año, got %v", ident)
+	}
+}
+
+func TestExtentReachesClosingBrace(t *testing.T) {
+	d := newDocument(uri, "func f(a) {\n    var local = 1\n    show.log(local)\n    \n}\nclass C {\n    var x = 1\n\n}\n")
+
+	// Después de la última sentencia de f, pero antes de su '}'
+	labels := make(map[string]bool)
+	for _, item := range d.completion(Position{Line: 3, Character: 4}).Items {
+		labels[item.Label] = true
+	}
+	if !labels["local"] || !labels["a"] {
+		t.Errorf("expected local and a in completion, got %v", labels)
+	}
+
+	symbols := d.symbols()
+	if len(symbols) != 2 {
+		t.Fatalf("expected 2 symbols, got %+v", symbols)
+	}
+	if end := symbols[0].Range.End; end != (Position{Line: 4, Character: 1}) {
+		t.Errorf("function range should end after its '}', got %+v", end)
+	}
+	if end := symbols[1].Range.End; end != (Position{Line: 8, Character: 1}) {
+		t.Errorf("class range should end after its '}', got %+v", end)
+	}
+}
+
+func TestSemaDiagnosticsWithParseErrors(t *testing.T) {
+	d := newDocument(uri, "show.log(missing)\nvar = 3\nvar n: int = \"a\"\n")
+
+	codes := make(map[string]bool)
+	for _, diag := range d.diagnostics {
+		codes[diag.Code] = true
+	}
+	for _, code := range []string{"N001", "T002"} {
+		if !codes[code] {
+			t.Errorf("expected a %s diagnostic next to the parse error, got %+v", code, d.diagnostics)
+		}
+	}
+	for _, diag := range d.diagnostics {
+		if diag.Range.Start.Line == 1 && diag.Code[0] != 'P' {
+			t.Errorf("unexpected sema diagnostic in the statement that failed to parse: %+v", diag)
+		}
+	}
+}
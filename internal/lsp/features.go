@@ -0,0 +1,413 @@
+package lsp
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+	"github.com/zylo-lang/zylo/internal/evaluator"
+	"github.com/zylo-lang/zylo/internal/sema"
+)
+
+// builtinNames son los built-ins que registra InitBuiltins, sin el espacio de
+// nombres zyloruntime, que es interno.
+var builtinNames = sync.OnceValue(func() []string {
+	var names []string
+	for _, name := range evaluator.BuiltinNames() {
+		if !strings.HasPrefix(name, "zyloruntime") {
+			names = append(names, name)
+		}
+	}
+	return names
+})
+
+// hover describe el símbolo en pos: qué es y su tipo inferido.
+func (d *document) hover(pos Position) *Hover {
+	ident, parent := d.identifierAt(d.location(pos))
+	if ident == nil {
+		return nil
+	}
+	text := d.describe(ident, parent)
+	if text == "" {
+		return nil
+	}
+	r := d.rangeOf(ast.TokenSpan(ident.Token))
+	return &Hover{Contents: MarkupContent{Kind: "markdown", Value: "```zylo\n" + text + "\n```"}, Range: &r}
+}
+
+// describe devuelve la descripción de ident para el hover, como
+// "(función) suma: func(int, int) int", o "" si no se conoce.
+func (d *document) describe(ident *ast.Identifier, parent ast.Node) string {
+	if member, ok := parent.(*ast.MemberExpression); ok {
+		// show y log describen los dos el built-in show.log
+		if name, ok := builtinMember(member); ok {
+			return "(built-in) " + name + ": " + sema.BuiltinType(name).String()
+		}
+	}
+	if member, ok := parent.(*ast.MemberExpression); ok && member.Property == ident {
+		if decl := d.memberDeclaration(member); decl != nil {
+			return d.describe(decl, nil)
+		}
+		return ""
+	}
+
+	sym, ok := d.analyzer.SymbolOf(ident)
+	if !ok {
+		return ""
+	}
+	if sym.Node == nil {
+		return "(built-in) " + ident.Value + ": " + sema.BuiltinType(ident.Value).String()
+	}
+	decl := d.decls[sym.Node]
+	if decl == nil {
+		return "(" + sym.Type + ") " + ident.Value
+	}
+
+	switch decl.kind {
+	case "clase":
+		text := "(clase) " + sym.Node.Value
+		if class := d.classOf(sym.Node); class != nil && class.SuperClass != nil {
+			text += " extends " + class.SuperClass.Value
+		}
+		return text
+	case "atributo", "método":
+		class, _ := decl.scope.(*ast.ClassStatement)
+		if class == nil {
+			return ""
+		}
+		return "(" + decl.kind + ") " + class.Name.Value + "." + sym.Node.Value + ": " + d.memberType(class.Name.Value, sym.Node.Value).String()
+	}
+
+	t := d.checker.TypeOf(ident)
+	if sym.Node == ident || t.Kind == sema.KindAny {
+		t = d.checker.TypeOfName(sym.Node)
+	}
+	return "(" + decl.kind + ") " + sym.Node.Value + ": " + t.String()
+}
+
+// builtinMember devuelve el nombre del built-in de member si es uno con
+// punto en el nombre, como show.log.
+func builtinMember(member *ast.MemberExpression) (string, bool) {
+	object, ok := member.Object.(*ast.Identifier)
+	if !ok {
+		return "", false
+	}
+	name := object.Value + "." + member.Property.Value
+	for _, builtin := range builtinNames() {
+		if builtin == name {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// memberType devuelve el tipo del atributo o método name de la clase
+// className, incluido init.
+func (d *document) memberType(className, name string) *sema.Type {
+	class, ok := d.checker.Class(className)
+	if !ok {
+		return sema.Any
+	}
+	if name == "init" {
+		if class.Init != nil {
+			return class.Init
+		}
+		return sema.Any
+	}
+	if t, ok := class.Member(name); ok {
+		return t
+	}
+	return sema.Any
+}
+
+// classOf devuelve la clase que declara el identificador de su nombre.
+func (d *document) classOf(name *ast.Identifier) *ast.ClassStatement {
+	for _, class := range d.classes() {
+		if class.Name == name {
+			return class
+		}
+	}
+	return nil
+}
+
+// classes devuelve las clases del documento.
+func (d *document) classes() []*ast.ClassStatement {
+	var classes []*ast.ClassStatement
+	ast.Inspect(d.program, func(node ast.Node) bool {
+		if class, ok := node.(*ast.ClassStatement); ok && class.Name != nil {
+			classes = append(classes, class)
+		}
+		return true
+	})
+	return classes
+}
+
+// memberDeclaration devuelve el identificador que declara la propiedad de
+// member, buscándola en la clase del objeto y en sus superclases, o nil si
+// el tipo del objeto no se conoce.
+func (d *document) memberDeclaration(member *ast.MemberExpression) *ast.Identifier {
+	t := d.checker.TypeOf(member.Object)
+	if t.Kind != sema.KindInstance && t.Kind != sema.KindClass {
+		return nil
+	}
+	classes := make(map[string]*ast.ClassStatement)
+	for _, class := range d.classes() {
+		classes[class.Name.Value] = class
+	}
+	name := member.Property.Value
+	for class := t.Class; class != nil; class = class.Super {
+		stmt, ok := classes[class.Name]
+		if !ok {
+			return nil
+		}
+		for _, attr := range stmt.Attributes {
+			if attr.Name != nil && attr.Name.Value == name {
+				return attr.Name
+			}
+		}
+		for _, method := range stmt.Methods {
+			if method.Name != nil && method.Name.Value == name {
+				return method.Name
+			}
+		}
+	}
+	return nil
+}
+
+// declarationAt devuelve el identificador que declara el símbolo en pos.
+func (d *document) declarationAt(pos Position) *ast.Identifier {
+	ident, parent := d.identifierAt(d.location(pos))
+	if ident == nil {
+		return nil
+	}
+	if member, ok := parent.(*ast.MemberExpression); ok && member.Property == ident {
+		return d.memberDeclaration(member)
+	}
+	if sym, ok := d.analyzer.SymbolOf(ident); ok {
+		return sym.Node
+	}
+	return nil
+}
+
+// definition devuelve dónde se declara el símbolo en pos.
+func (d *document) definition(pos Position) []Location {
+	decl := d.declarationAt(pos)
+	if decl == nil {
+		return nil
+	}
+	return []Location{d.locationOf(decl)}
+}
+
+// references devuelve los usos del símbolo en pos y, si includeDeclaration,
+// su declaración. Los atributos y métodos se buscan en los accesos a
+// miembros de las instancias de su clase.
+func (d *document) references(pos Position, includeDeclaration bool) []Location {
+	var refs []*ast.Identifier
+	decl := d.declarationAt(pos)
+	if decl != nil && d.isMember(decl) {
+		refs = append(refs, decl)
+		ast.Inspect(d.program, func(node ast.Node) bool {
+			if member, ok := node.(*ast.MemberExpression); ok && member.Property != nil && member.Property.Value == decl.Value {
+				if d.memberDeclaration(member) == decl {
+					refs = append(refs, member.Property)
+				}
+			}
+			return true
+		})
+	} else {
+		ident, _ := d.identifierAt(d.location(pos))
+		if ident == nil {
+			return nil
+		}
+		sym, ok := d.analyzer.SymbolOf(ident)
+		if !ok {
+			return nil
+		}
+		refs = d.analyzer.References(sym)
+	}
+
+	locations := []Location{}
+	for _, ref := range refs {
+		if ref == decl && !includeDeclaration {
+			continue
+		}
+		locations = append(locations, d.locationOf(ref))
+	}
+	return locations
+}
+
+// isMember indica si decl declara un atributo o un método.
+func (d *document) isMember(decl *ast.Identifier) bool {
+	info, ok := d.decls[decl]
+	return ok && (info.kind == "atributo" || info.kind == "método")
+}
+
+func (d *document) locationOf(ident *ast.Identifier) Location {
+	return Location{URI: d.uri, Range: d.rangeOf(ast.TokenSpan(ident.Token))}
+}
+
+// symbols devuelve las funciones y las clases del nivel superior, con los
+// atributos y los métodos de cada clase.
+func (d *document) symbols() []DocumentSymbol {
+	symbols := []DocumentSymbol{}
+	for _, stmt := range d.program.Statements {
+		if export, ok := stmt.(*ast.ExportStatement); ok {
+			stmt = export.Statement
+		}
+		switch s := stmt.(type) {
+		case *ast.FuncStatement:
+			if s.Name != nil {
+				symbols = append(symbols, d.symbol(s, s.Name, SymbolKindFunction, d.checker.TypeOfName(s.Name).String()))
+			}
+		case *ast.ClassStatement:
+			if s.Name == nil {
+				continue
+			}
+			class := d.symbol(s, s.Name, SymbolKindClass, "")
+			for _, attr := range s.Attributes {
+				if attr.Name != nil {
+					class.Children = append(class.Children, d.symbol(attr, attr.Name, SymbolKindField, d.memberType(s.Name.Value, attr.Name.Value).String()))
+				}
+			}
+			for _, method := range s.Methods {
+				if method.Name != nil {
+					class.Children = append(class.Children, d.symbol(method, method.Name, SymbolKindMethod, d.memberType(s.Name.Value, method.Name.Value).String()))
+				}
+			}
+			symbols = append(symbols, class)
+		}
+	}
+	return symbols
+}
+
+func (d *document) symbol(node ast.Node, name *ast.Identifier, kind int, detail string) DocumentSymbol {
+	return DocumentSymbol{
+		Name:           name.Value,
+		Detail:         detail,
+		Kind:           kind,
+		Range:          d.rangeOf(extent(node)),
+		SelectionRange: d.rangeOf(ast.TokenSpan(name.Token)),
+	}
+}
+
+// memberAccess reconoce un acceso a miembro a medio escribir antes del
+// cursor, como "perro.ha".
+var memberAccess = regexp.MustCompile(`([A-Za-z_][A-Za-z0-9_]*)\.([A-Za-z0-9_]*)$`)
+
+// completion propone los nombres que se pueden escribir en pos: los
+// miembros del objeto tras un punto o, si no, los built-ins y los nombres
+// declarados visibles.
+func (d *document) completion(pos Position) completionList {
+	line, col := d.location(pos)
+	prefix := ""
+	if line-1 < len(d.lines) {
+		runes := d.lines[line-1]
+		prefix = string(runes[:min(col-1, len(runes))])
+	}
+
+	items := []CompletionItem{}
+	seen := make(map[string]bool)
+	add := func(item CompletionItem) {
+		if !seen[item.Label] {
+			seen[item.Label] = true
+			items = append(items, item)
+		}
+	}
+
+	if m := memberAccess.FindStringSubmatch(prefix); m != nil {
+		receiver := m[1]
+		for _, name := range builtinNames() {
+			if method, ok := strings.CutPrefix(name, receiver+"."); ok {
+				add(CompletionItem{Label: method, Kind: CompletionKindFunction, Detail: sema.BuiltinType(name).String()})
+			}
+		}
+		if class := d.receiverClass(receiver, line, col); class != nil {
+			for c := class; c != nil; c = c.Super {
+				for _, name := range sortedKeys(c.Fields) {
+					add(CompletionItem{Label: name, Kind: CompletionKindField, Detail: c.Fields[name].String()})
+				}
+				for _, name := range sortedKeys(c.Methods) {
+					add(CompletionItem{Label: name, Kind: CompletionKindMethod, Detail: c.Methods[name].String()})
+				}
+			}
+		}
+		return completionList{Items: items}
+	}
+
+	var names []*ast.Identifier
+	for ident, decl := range d.decls {
+		if decl.kind == "atributo" || decl.kind == "método" {
+			continue
+		}
+		if decl.scope == nil || contains(extent(decl.scope), line, col) {
+			names = append(names, ident)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i].Value < names[j].Value })
+	for _, ident := range names {
+		kind := CompletionKindVariable
+		switch d.decls[ident].kind {
+		case "función":
+			kind = CompletionKindFunction
+		case "clase":
+			kind = CompletionKindClass
+		case "módulo":
+			kind = CompletionKindModule
+		}
+		add(CompletionItem{Label: ident.Value, Kind: kind, Detail: d.checker.TypeOfName(ident).String()})
+	}
+	for _, name := range builtinNames() {
+		add(CompletionItem{Label: name, Kind: CompletionKindFunction, Detail: sema.BuiltinType(name).String()})
+	}
+	return completionList{Items: items}
+}
+
+// receiverClass devuelve la clase de receiver, el objeto de un acceso a
+// miembro en (line, col): la clase que contiene la posición para 'this' o,
+// para una variable, la de su tipo en el último uso o declaración anterior.
+func (d *document) receiverClass(receiver string, line, col int) *sema.Class {
+	if receiver == "this" {
+		for _, class := range d.classes() {
+			if contains(extent(class), line, col) {
+				c, _ := d.checker.Class(class.Name.Value)
+				return c
+			}
+		}
+		return nil
+	}
+
+	var last *ast.Identifier
+	ast.Inspect(d.program, func(node ast.Node) bool {
+		ident, ok := node.(*ast.Identifier)
+		if ok && ident.Value == receiver && before(ident.Token.StartLine, ident.Token.StartCol, line, col) {
+			if _, resolved := d.analyzer.SymbolOf(ident); resolved {
+				last = ident
+			}
+		}
+		return true
+	})
+	if last == nil {
+		return nil
+	}
+	t := d.checker.TypeOf(last)
+	if t.Kind == sema.KindAny {
+		if sym, ok := d.analyzer.SymbolOf(last); ok && sym.Node != nil {
+			t = d.checker.TypeOfName(sym.Node)
+		}
+	}
+	if t.Kind != sema.KindInstance {
+		return nil
+	}
+	return t.Class
+}
+
+func sortedKeys(m map[string]*sema.Type) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,193 @@
+// Package lsp implementa un servidor del Language Server Protocol para
+// Zylo. Habla JSON-RPC por la entrada y la salida estándar y usa el lexer,
+// el parser y sema para los diagnósticos, el hover, la navegación, los
+// símbolos del documento y el completado.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Server es un servidor LSP conectado a un cliente por in y out.
+type Server struct {
+	in       *bufio.Reader
+	out      io.Writer
+	docs     map[string]*document
+	shutdown bool // Se recibió 'shutdown': solo se acepta 'exit'
+}
+
+// errExitWithoutShutdown es el error de Run cuando el cliente envía 'exit'
+// sin haber enviado 'shutdown'.
+var errExitWithoutShutdown = errors.New("exit without shutdown")
+
+// NewServer crea un servidor que lee los mensajes de in y escribe las
+// respuestas en out.
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return &Server{
+		in:   bufio.NewReader(in),
+		out:  out,
+		docs: make(map[string]*document),
+	}
+}
+
+// Run atiende los mensajes hasta que el cliente envía 'exit' o cierra la
+// entrada. Devuelve un error si la conexión falla o si el cliente sale sin
+// pedir 'shutdown'.
+func (s *Server) Run() error {
+	for {
+		body, err := readMessage(s.in)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			if err := s.replyError(nil, codeParseError, err.Error()); err != nil {
+				return err
+			}
+			continue
+		}
+		if req.Method == "exit" {
+			if !s.shutdown {
+				return errExitWithoutShutdown
+			}
+			return nil
+		}
+		if err := s.handle(&req); err != nil {
+			return err
+		}
+	}
+}
+
+// handle atiende una petición o una notificación.
+func (s *Server) handle(req *request) error {
+	if req.ID == nil {
+		return s.notify(req)
+	}
+	if s.shutdown {
+		return s.replyError(req.ID, codeInvalidRequest, "server is shutting down")
+	}
+
+	var result interface{}
+	var err error
+	switch req.Method {
+	case "initialize":
+		result = s.initialize()
+	case "shutdown":
+		s.shutdown = true
+	case "textDocument/hover":
+		result, err = withPosition(s, req.Params, (*document).hover)
+	case "textDocument/definition":
+		result, err = withPosition(s, req.Params, (*document).definition)
+	case "textDocument/references":
+		var params referenceParams
+		if err = json.Unmarshal(req.Params, &params); err == nil {
+			if doc, ok := s.docs[params.TextDocument.URI]; ok {
+				result = doc.references(params.Position, params.Context.IncludeDeclaration)
+			}
+		}
+	case "textDocument/documentSymbol":
+		var params documentSymbolParams
+		if err = json.Unmarshal(req.Params, &params); err == nil {
+			if doc, ok := s.docs[params.TextDocument.URI]; ok {
+				result = doc.symbols()
+			}
+		}
+	case "textDocument/completion":
+		result, err = withPosition(s, req.Params, (*document).completion)
+	default:
+		return s.replyError(req.ID, codeMethodNotFound, fmt.Sprintf("method not found: %s", req.Method))
+	}
+	if err != nil {
+		return s.replyError(req.ID, codeInvalidParams, err.Error())
+	}
+	return writeMessage(s.out, response{JSONRPC: "2.0", ID: req.ID, Result: result})
+}
+
+// withPosition decodifica los parámetros de una petición sobre una posición
+// de un documento y llama a f con ellos. El resultado es null si el
+// documento no está abierto.
+func withPosition[T any](s *Server, raw json.RawMessage, f func(*document, Position) T) (interface{}, error) {
+	var params textDocumentPositionParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, err
+	}
+	doc, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return nil, nil
+	}
+	return f(doc, params.Position), nil
+}
+
+// notify atiende una notificación del cliente. Las desconocidas se ignoran.
+func (s *Server) notify(req *request) error {
+	switch req.Method {
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil
+		}
+		return s.open(params.TextDocument.URI, params.TextDocument.Text)
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err := json.Unmarshal(req.Params, &params); err != nil || len(params.ContentChanges) == 0 {
+			return nil
+		}
+		// Con sincronización completa el último cambio es el texto entero
+		text := params.ContentChanges[len(params.ContentChanges)-1].Text
+		return s.open(params.TextDocument.URI, text)
+	case "textDocument/didClose":
+		var params didCloseParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil
+		}
+		delete(s.docs, params.TextDocument.URI)
+		// Al cerrar se borran los diagnósticos del documento
+		return s.publish(params.TextDocument.URI, []Diagnostic{})
+	}
+	return nil
+}
+
+// open analiza el texto de un documento y publica sus diagnósticos.
+func (s *Server) open(uri, text string) error {
+	doc := newDocument(uri, text)
+	s.docs[uri] = doc
+	diagnostics := doc.diagnostics
+	if diagnostics == nil {
+		diagnostics = []Diagnostic{}
+	}
+	return s.publish(uri, diagnostics)
+}
+
+func (s *Server) publish(uri string, diagnostics []Diagnostic) error {
+	return writeMessage(s.out, notification{
+		JSONRPC: "2.0",
+		Method:  "textDocument/publishDiagnostics",
+		Params:  publishDiagnosticsParams{URI: uri, Diagnostics: diagnostics},
+	})
+}
+
+func (s *Server) initialize() initializeResult {
+	var result initializeResult
+	result.Capabilities = serverCapabilities{
+		TextDocumentSync:       1, // Sincronización completa
+		HoverProvider:          true,
+		DefinitionProvider:     true,
+		ReferencesProvider:     true,
+		DocumentSymbolProvider: true,
+		CompletionProvider:     completionOptions{TriggerCharacters: []string{"."}},
+	}
+	result.ServerInfo.Name = "zylo"
+	return result
+}
+
+func (s *Server) replyError(id *json.RawMessage, code int, msg string) error {
+	return writeMessage(s.out, errorResponse{JSONRPC: "2.0", ID: id, Error: responseError{Code: code, Message: msg}})
+}
@@ -0,0 +1,330 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+// client es un cliente LSP con guion: envía mensajes al servidor y lee sus
+// respuestas como lo haría un editor.
+type client struct {
+	t       *testing.T
+	in      *io.PipeWriter
+	out     *bufio.Reader
+	nextID  int
+	pending []json.RawMessage // Notificaciones leídas mientras se esperaba una respuesta
+	done    chan error
+}
+
+func startServer(t *testing.T) *client {
+	t.Helper()
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	c := &client{t: t, in: clientOut, out: bufio.NewReader(clientIn), done: make(chan error, 1)}
+	go func() {
+		err := NewServer(serverIn, serverOut).Run()
+		serverOut.Close()
+		c.done <- err
+	}()
+	t.Cleanup(func() { clientOut.Close() })
+	c.request("initialize", map[string]interface{}{"capabilities": map[string]interface{}{}}, nil)
+	c.notify("initialized", map[string]interface{}{})
+	return c
+}
+
+func (c *client) send(msg interface{}) {
+	c.t.Helper()
+	if err := writeMessage(c.in, msg); err != nil {
+		c.t.Fatalf("write: %v", err)
+	}
+}
+
+func (c *client) read() map[string]json.RawMessage {
+	c.t.Helper()
+	body, err := readMessage(c.out)
+	if err != nil {
+		c.t.Fatalf("read: %v", err)
+	}
+	var msg map[string]json.RawMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		c.t.Fatalf("invalid message %s: %v", body, err)
+	}
+	return msg
+}
+
+func (c *client) notify(method string, params interface{}) {
+	c.t.Helper()
+	c.send(map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+// request envía una petición y decodifica el resultado de su respuesta en
+// result. Las notificaciones que lleguen antes se guardan.
+func (c *client) request(method string, params interface{}, result interface{}) {
+	c.t.Helper()
+	c.nextID++
+	id := c.nextID
+	c.send(map[string]interface{}{"jsonrpc": "2.0", "id": id, "method": method, "params": params})
+	for {
+		msg := c.read()
+		if _, ok := msg["id"]; !ok {
+			c.pending = append(c.pending, msg["params"])
+			continue
+		}
+		var got int
+		json.Unmarshal(msg["id"], &got)
+		if got != id {
+			c.t.Fatalf("expected response %d, got %d", id, got)
+		}
+		if e, ok := msg["error"]; ok {
+			c.t.Fatalf("%s failed: %s", method, e)
+		}
+		if result != nil {
+			if err := json.Unmarshal(msg["result"], result); err != nil {
+				c.t.Fatalf("invalid %s result %s: %v", method, msg["result"], err)
+			}
+		}
+		return
+	}
+}
+
+// diagnostics devuelve los diagnósticos de la siguiente publicación.
+func (c *client) diagnostics() []Diagnostic {
+	c.t.Helper()
+	var raw json.RawMessage
+	if len(c.pending) > 0 {
+		raw, c.pending = c.pending[0], c.pending[1:]
+	} else {
+		msg := c.read()
+		var method string
+		json.Unmarshal(msg["method"], &method)
+		if method != "textDocument/publishDiagnostics" {
+			c.t.Fatalf("expected diagnostics, got %s", method)
+		}
+		raw = msg["params"]
+	}
+	var params publishDiagnosticsParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		c.t.Fatal(err)
+	}
+	return params.Diagnostics
+}
+
+func (c *client) open(uri, text string) []Diagnostic {
+	c.t.Helper()
+	c.notify("textDocument/didOpen", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri, "languageId": "zylo", "version": 1, "text": text},
+	})
+	return c.diagnostics()
+}
+
+func at(uri string, line, character int) map[string]interface{} {
+	return map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+		"position":     Position{Line: line, Character: character},
+	}
+}
+
+const uri = "file:///tmp/animales.zylo"
+
+const source = `class Animal {
+    var name: string
+    func init(name: string) {
+        this.name = name
+    }
+    func speak() {
+        return this.name + " hace ruido"
+    }
+}
+func greet(a: Animal) {
+    return "hola " + a.name
+}
+var perro = Animal("rex")
+show.log(greet(perro), perro.speak())
+`
+
+func TestDiagnostics(t *testing.T) {
+	c := startServer(t)
+	if diags := c.open(uri, source); len(diags) != 0 {
+		t.Fatalf("expected no diagnostics, got %+v", diags)
+	}
+
+	// Al escribir se publican los errores con su posición
+	c.notify("textDocument/didChange", map[string]interface{}{
+		"textDocument":   map[string]interface{}{"uri": uri, "version": 2},
+		"contentChanges": []map[string]string{{"text": "var total = 1\nshow.log(totl)\nvar n: int = \"x\"\n"}},
+	})
+	diags := c.diagnostics()
+	if len(diags) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %+v", diags)
+	}
+	want := Range{Start: Position{Line: 1, Character: 9}, End: Position{Line: 1, Character: 13}}
+	if diags[0].Range != want || diags[0].Message != "identifier not found: totl" || diags[0].Code != "N001" {
+		t.Errorf("unexpected diagnostic %+v", diags[0])
+	}
+	if diags[1].Range.Start.Line != 2 || !strings.Contains(diags[1].Message, "string") || diags[1].Code != "T002" {
+		t.Errorf("unexpected type diagnostic %+v", diags[1])
+	}
+
+	c.notify("textDocument/didChange", map[string]interface{}{
+		"textDocument":   map[string]interface{}{"uri": uri, "version": 3},
+		"contentChanges": []map[string]string{{"text": "var x = (1 + \n"}},
+	})
+	if diags := c.diagnostics(); len(diags) == 0 {
+		t.Errorf("expected parse errors")
+	}
+
+	c.notify("textDocument/didClose", map[string]interface{}{"textDocument": map[string]interface{}{"uri": uri}})
+	if diags := c.diagnostics(); len(diags) != 0 {
+		t.Errorf("expected closing to clear diagnostics, got %+v", diags)
+	}
+}
+
+func TestHover(t *testing.T) {
+	c := startServer(t)
+	c.open(uri, source)
+
+	tests := []struct {
+		line, character int
+		want            string
+	}{
+		{13, 10, "(función) greet: func(Animal) string"},
+		{13, 16, "(variable) perro: Animal"},
+		{13, 30, "(método) Animal.speak: func() string"},
+		{13, 2, "(built-in) show.log: func(...) null"},
+		{12, 13, "(clase) Animal"},
+		{9, 11, "(parámetro) a: Animal"},
+		{10, 25, "(atributo) Animal.name: string"},
+	}
+	for _, tt := range tests {
+		var hover *Hover
+		c.request("textDocument/hover", at(uri, tt.line, tt.character), &hover)
+		if hover == nil {
+			t.Errorf("%d:%d: expected hover %q, got none", tt.line, tt.character, tt.want)
+			continue
+		}
+		if want := "```zylo\n" + tt.want + "\n```"; hover.Contents.Value != want {
+			t.Errorf("%d:%d: expected hover %q, got %q", tt.line, tt.character, want, hover.Contents.Value)
+		}
+	}
+
+	var hover *Hover
+	c.request("textDocument/hover", at(uri, 6, 30), &hover)
+	if hover != nil {
+		t.Errorf("expected no hover on a string, got %+v", hover)
+	}
+}
+
+func TestDefinitionAndReferences(t *testing.T) {
+	c := startServer(t)
+	c.open(uri, source)
+
+	var locations []Location
+	c.request("textDocument/definition", at(uri, 13, 17), &locations)
+	want := Location{URI: uri, Range: Range{Start: Position{Line: 12, Character: 4}, End: Position{Line: 12, Character: 9}}}
+	if len(locations) != 1 || locations[0] != want {
+		t.Errorf("expected definition %+v, got %+v", want, locations)
+	}
+
+	// Un método se declara en su clase
+	c.request("textDocument/definition", at(uri, 13, 30), &locations)
+	if len(locations) != 1 || locations[0].Range.Start != (Position{Line: 5, Character: 9}) {
+		t.Errorf("expected the definition of speak, got %+v", locations)
+	}
+
+	params := at(uri, 12, 6)
+	params["context"] = map[string]bool{"includeDeclaration": true}
+	c.request("textDocument/references", params, &locations)
+	var lines []int
+	for _, loc := range locations {
+		lines = append(lines, loc.Range.Start.Line)
+	}
+	if len(lines) != 3 || lines[0] != 12 || lines[1] != 13 || lines[2] != 13 {
+		t.Errorf("expected perro on lines 12, 13 and 13, got %v", lines)
+	}
+
+	// Los usos de un atributo son los accesos a miembros, incluido this.name
+	params = at(uri, 1, 9)
+	params["context"] = map[string]bool{"includeDeclaration": false}
+	c.request("textDocument/references", params, &locations)
+	lines = nil
+	for _, loc := range locations {
+		lines = append(lines, loc.Range.Start.Line)
+	}
+	if len(lines) != 3 || lines[0] != 3 || lines[1] != 6 || lines[2] != 10 {
+		t.Errorf("expected name on lines 3, 6 and 10, got %v", lines)
+	}
+}
+
+func TestDocumentSymbols(t *testing.T) {
+	c := startServer(t)
+	c.open(uri, source)
+
+	var symbols []DocumentSymbol
+	c.request("textDocument/documentSymbol", map[string]interface{}{"textDocument": map[string]string{"uri": uri}}, &symbols)
+	if len(symbols) != 2 {
+		t.Fatalf("expected 2 symbols, got %+v", symbols)
+	}
+	class, fn := symbols[0], symbols[1]
+	if class.Name != "Animal" || class.Kind != SymbolKindClass || len(class.Children) != 3 {
+		t.Errorf("unexpected class symbol %+v", class)
+	}
+	if class.Range.Start.Line != 0 || class.Range.End.Line != 8 {
+		t.Errorf("expected the class to span lines 0-8, got %+v", class.Range)
+	}
+	if fn.Name != "greet" || fn.Kind != SymbolKindFunction || fn.Detail != "func(Animal) string" {
+		t.Errorf("unexpected function symbol %+v", fn)
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	c := startServer(t)
+	c.open(uri, source+"perro.\nshow.\n")
+
+	labels := func(line, character int) map[string]int {
+		var list completionList
+		c.request("textDocument/completion", at(uri, line, character), &list)
+		got := make(map[string]int)
+		for _, item := range list.Items {
+			got[item.Label] = item.Kind
+		}
+		return got
+	}
+
+	members := labels(14, 6)
+	if members["name"] != CompletionKindField || members["speak"] != CompletionKindMethod || len(members) != 2 {
+		t.Errorf("expected the members of Animal, got %v", members)
+	}
+	if got := labels(15, 5); got["log"] != CompletionKindFunction {
+		t.Errorf("expected show.log, got %v", got)
+	}
+
+	global := labels(13, 0)
+	for _, name := range []string{"len", "show.log", "greet", "perro", "Animal"} {
+		if _, ok := global[name]; !ok {
+			t.Errorf("expected %s in the completion, got %v", name, global)
+		}
+	}
+	if _, ok := global["a"]; ok {
+		t.Errorf("parameter a completed outside of greet")
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	c := startServer(t)
+	c.send(map[string]interface{}{"jsonrpc": "2.0", "id": 99, "method": "unknown/method"})
+	msg := c.read()
+	var e responseError
+	json.Unmarshal(msg["error"], &e)
+	if e.Code != codeMethodNotFound {
+		t.Errorf("expected method not found, got %s", msg["error"])
+	}
+
+	c.request("shutdown", nil, nil)
+	c.notify("exit", nil)
+	if err := <-c.done; err != nil {
+		t.Errorf("expected a clean exit, got %v", err)
+	}
+}
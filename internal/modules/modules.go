@@ -0,0 +1,198 @@
+// Package modules resuelve los módulos de Zylo: archivos .zylo que otro
+// archivo importa con 'import' o 'from ... import'. Lo comparten el
+// evaluador, que los ejecuta, y 'zylo build', que los traduce a paquetes Go.
+package modules
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/parser"
+)
+
+// Extension es la extensión de los archivos de módulo.
+const Extension = ".zylo"
+
+// PathEnv es la variable de entorno con los directorios donde se buscan los
+// módulos que no están junto al archivo que los importa.
+const PathEnv = "ZYLOPATH"
+
+// SearchPath devuelve los directorios de ZYLOPATH.
+func SearchPath() []string {
+	return filepath.SplitList(os.Getenv(PathEnv))
+}
+
+// IsBuiltin indica si spec es un módulo incorporado, que no tiene archivo.
+func IsBuiltin(spec string) bool {
+	return spec == "zyloruntime"
+}
+
+// Resolve devuelve la ruta absoluta del archivo del módulo spec. spec es un
+// nombre o una ruta con '/', con o sin la extensión .zylo. Se busca primero
+// relativo a fromDir, el directorio del archivo que importa, y después en
+// cada directorio de searchPath.
+func Resolve(spec, fromDir string, searchPath []string) (string, error) {
+	name := filepath.FromSlash(spec)
+	if !strings.HasSuffix(name, Extension) {
+		name += Extension
+	}
+
+	dirs := append([]string{fromDir}, searchPath...)
+	if filepath.IsAbs(name) {
+		dirs = []string{""}
+	}
+	for _, dir := range dirs {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return filepath.Abs(path)
+		}
+	}
+	return "", fmt.Errorf("module %q not found (searched in %s)", spec, strings.Join(dirs, ", "))
+}
+
+// CodeLoad es el código de los errores al cargar un módulo que no son de
+// sintaxis, como un módulo que no existe o un ciclo de imports, en los
+// diagnósticos. No cambia entre versiones.
+const CodeLoad = "M001"
+
+// ParseError es el error de un módulo con errores de sintaxis.
+type ParseError struct {
+	Path        string
+	Diagnostics []parser.Diagnostic
+}
+
+func (e *ParseError) Error() string {
+	messages := make([]string, len(e.Diagnostics))
+	for i, d := range e.Diagnostics {
+		messages[i] = d.Message
+	}
+	return fmt.Sprintf("%s: errores de parsing:\n  %s", e.Path, strings.Join(messages, "\n  "))
+}
+
+// Parse lee y analiza el archivo de un módulo. Los errores de sintaxis se
+// devuelven como un *ParseError.
+func Parse(path string) (*ast.Program, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p := parser.New(lexer.New(string(content)))
+	program := p.ParseProgram()
+	if len(p.Diagnostics()) > 0 {
+		return nil, &ParseError{Path: path, Diagnostics: p.Diagnostics()}
+	}
+	return program, nil
+}
+
+// Exports devuelve los nombres que program declara con 'export', en orden.
+func Exports(program *ast.Program) []string {
+	var names []string
+	for _, stmt := range program.Statements {
+		if export, ok := stmt.(*ast.ExportStatement); ok && export.Name() != "" {
+			names = append(names, export.Name())
+		}
+	}
+	return names
+}
+
+// Imports devuelve los imports de nivel superior de program que se refieren
+// a archivos, es decir, sin los módulos incorporados.
+func Imports(program *ast.Program) []*ast.ImportStatement {
+	var imports []*ast.ImportStatement
+	for _, stmt := range program.Statements {
+		if imp, ok := stmt.(*ast.ImportStatement); ok && imp.ModuleName != nil && !IsBuiltin(imp.Module()) {
+			imports = append(imports, imp)
+		}
+	}
+	return imports
+}
+
+// CycleError es el error de un import que vuelve a un módulo que todavía se
+// está cargando.
+type CycleError struct {
+	Chain []string // Archivos desde el primero que importa hasta el repetido
+}
+
+func (e *CycleError) Error() string {
+	names := make([]string, len(e.Chain))
+	for i, path := range e.Chain {
+		names[i] = filepath.Base(path)
+	}
+	return "import cycle: " + strings.Join(names, " -> ")
+}
+
+// CheckCycle devuelve un *CycleError si path ya está en chain, la cadena de
+// archivos que se están cargando.
+func CheckCycle(chain []string, path string) error {
+	for i, loading := range chain {
+		if loading == path {
+			return &CycleError{Chain: append(append([]string(nil), chain[i:]...), path)}
+		}
+	}
+	return nil
+}
+
+// File es un módulo cargado de su archivo.
+type File struct {
+	Name    string // Nombre del módulo: el del archivo sin la extensión
+	Path    string // Ruta absoluta
+	Program *ast.Program
+}
+
+// Load carga los módulos que importa program, el contenido del archivo
+// path, directa o indirectamente. Cada módulo aparece una vez, después de
+// los módulos que importa.
+func Load(path string, program *ast.Program, searchPath []string) ([]*File, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	l := &loader{searchPath: searchPath, loaded: make(map[string]bool)}
+	if err := l.imports(program, []string{path}); err != nil {
+		return nil, err
+	}
+	return l.files, nil
+}
+
+type loader struct {
+	searchPath []string
+	loaded     map[string]bool
+	files      []*File
+}
+
+// imports carga los módulos que importa program; chain son los archivos que
+// se están cargando, el último el de program.
+func (l *loader) imports(program *ast.Program, chain []string) error {
+	dir := filepath.Dir(chain[len(chain)-1])
+	for _, imp := range Imports(program) {
+		path, err := Resolve(imp.Module(), dir, l.searchPath)
+		if err != nil {
+			return err
+		}
+		if err := CheckCycle(chain, path); err != nil {
+			return err
+		}
+		if l.loaded[path] {
+			continue
+		}
+		l.loaded[path] = true
+
+		module, err := Parse(path)
+		if err != nil {
+			return err
+		}
+		if err := l.imports(module, append(append([]string(nil), chain...), path)); err != nil {
+			return err
+		}
+		l.files = append(l.files, &File{
+			Name:    strings.TrimSuffix(filepath.Base(path), Extension),
+			Path:    path,
+			Program: module,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package modules
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestResolve(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"app/utils.zylo":    "",
+		"app/lib/str.zylo":  "",
+		"shared/utils.zylo": "",
+		"shared/json.zylo":  "",
+	})
+	app, shared := filepath.Join(dir, "app"), filepath.Join(dir, "shared")
+
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"utils", filepath.Join(app, "utils.zylo")}, // El directorio del archivo va antes que el search path
+		{"lib/str", filepath.Join(app, "lib", "str.zylo")},
+		{"lib/str.zylo", filepath.Join(app, "lib", "str.zylo")},
+		{"json", filepath.Join(shared, "json.zylo")},
+	}
+	for _, tt := range tests {
+		got, err := Resolve(tt.spec, app, []string{shared})
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.spec, err)
+		} else if got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.spec, tt.want, got)
+		}
+	}
+
+	if _, err := Resolve("missing", app, []string{shared}); err == nil || !strings.Contains(err.Error(), `module "missing" not found`) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"main.zylo":  "import a\nfrom b import x\nimport zyloruntime\n",
+		"a.zylo":     "import b\nexport var y = 1\n",
+		"b.zylo":     "export var x = 1\n",
+		"cycle.zylo": "import c\n",
+		"c.zylo":     "import d\n",
+		"d.zylo":     "import c\n",
+	})
+
+	load := func(name string) ([]*File, error) {
+		path := filepath.Join(dir, name)
+		program, err := Parse(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return Load(path, program, nil)
+	}
+
+	files, err := load("main.zylo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var names []string
+	for _, file := range files {
+		names = append(names, file.Name)
+	}
+	if got := strings.Join(names, ","); got != "b,a" {
+		t.Errorf("expected modules b,a in dependency order, got %s", got)
+	}
+
+	_, err = load("cycle.zylo")
+	var cycle *CycleError
+	if !errors.As(err, &cycle) {
+		t.Fatalf("expected a *CycleError, got %v", err)
+	}
+	if got := err.Error(); got != "import cycle: c.zylo -> d.zylo -> c.zylo" {
+		t.Errorf("unexpected error %q", got)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"bad.zylo": "var = 1\nvar ok = 2\nvar = 3\n"})
+	path := filepath.Join(dir, "bad.zylo")
+	_, err := Parse(path)
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("expected a *ParseError, got %v", err)
+	}
+	if parseErr.Path != path || len(parseErr.Diagnostics) != 2 || parseErr.Diagnostics[1].Span.StartLine != 3 {
+		t.Errorf("unexpected error %+v", parseErr)
+	}
+	if !strings.HasPrefix(err.Error(), path+": errores de parsing:\n  ") {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+}
@@ -0,0 +1,114 @@
+package parser
+
+import (
+	"github.com/zylo-lang/zylo/internal/ast"
+	"github.com/zylo-lang/zylo/internal/lexer"
+)
+
+// Diagnostic es un error de parsing: la región del token donde se detectó,
+// un código estable que identifica el tipo de error y el mensaje.
+type Diagnostic struct {
+	Span    ast.Span
+	Code    string
+	Message string
+}
+
+// Códigos de Diagnostic. No cambian entre versiones, así que las
+// herramientas pueden filtrar por ellos.
+const (
+	CodeInvalidToken    = "P001" // El lexer no reconoció el texto
+	CodeUnexpectedToken = "P002" // Un token que no puede aparecer ahí
+	CodeExpectedToken   = "P003" // Falta el token que la sintaxis exige
+	CodeUnclosed        = "P004" // Un bloque o una clase sin '}' de cierre
+	CodeInvalidSyntax   = "P005" // Una construcción mal formada
+	CodeTooDeep         = "P006" // Expresiones anidadas más allá del límite
+	CodeTimeout         = "P007" // El parsing excedió el tiempo permitido
+)
+
+// Errors devuelve los mensajes de los errores encontrados durante el
+// parsing, en el orden de Diagnostics.
+func (p *Parser) Errors() []string {
+	messages := make([]string, len(p.diagnostics))
+	for i, d := range p.diagnostics {
+		messages[i] = d.Message
+	}
+	return messages
+}
+
+// Diagnostics devuelve los errores encontrados durante el parsing, en el
+// orden del código fuente.
+func (p *Parser) Diagnostics() []Diagnostic {
+	return p.diagnostics
+}
+
+// addError añade un error en el token actual con protección contra overflow
+func (p *Parser) addError(code, msg string) {
+	p.addErrorAt(p.curToken, code, msg)
+}
+
+// addErrorAt añade un error en la posición de tok.
+func (p *Parser) addErrorAt(tok lexer.Token, code, msg string) {
+	if len(p.diagnostics) < p.maxErrors {
+		p.diagnostics = append(p.diagnostics, Diagnostic{Span: ast.TokenSpan(tok), Code: code, Message: msg})
+	}
+}
+
+// statementKeywords son las palabras clave que empiezan una sentencia. Al
+// principio de una línea son un punto de sincronización.
+var statementKeywords = map[lexer.TokenType]bool{
+	lexer.VAR:      true,
+	lexer.CONST:    true,
+	lexer.FUNC:     true,
+	lexer.ASYNC:    true,
+	lexer.CLASS:    true,
+	lexer.IF:       true,
+	lexer.WHILE:    true,
+	lexer.FOR:      true,
+	lexer.RETURN:   true,
+	lexer.IMPORT:   true,
+	lexer.FROM:     true,
+	lexer.EXPORT:   true,
+	lexer.TRY:      true,
+	lexer.THROW:    true,
+	lexer.BREAK:    true,
+	lexer.CONTINUE: true,
+}
+
+// recover sincroniza después de una sentencia o un miembro de clase que
+// empezó en start, con braces llaves abiertas, si tuvo errores que no
+// sincronizó ya una sentencia anidada. Devuelve true si lo hizo; entonces
+// curToken queda sobre el punto de sincronización en lugar de sobre el
+// último token de la sentencia.
+func (p *Parser) recover(start lexer.Token, braces, errors int) bool {
+	if len(p.diagnostics) == errors || p.synced == len(p.diagnostics) {
+		return false
+	}
+	p.synchronize(start, braces)
+	p.synced = len(p.diagnostics)
+	return true
+}
+
+// synchronize descarta el resto de una sentencia con errores, que empezó en
+// start con braces llaves abiertas, para que un error no provoque otros en
+// cascada. Termina con curToken sobre un punto de sincronización al mismo
+// nivel de llaves: un salto de línea, ';', el '}' que cierra el bloque, una
+// palabra clave de sentencia al principio de una línea o EOF. Siempre
+// consume al menos un token desde start.
+func (p *Parser) synchronize(start lexer.Token, braces int) {
+	if p.curToken.Type == start.Type && p.curToken.StartLine == start.StartLine && p.curToken.StartCol == start.StartCol {
+		p.nextToken()
+	}
+	for !p.curTokenIs(lexer.EOF) {
+		switch {
+		case p.braces < braces:
+			return // El '}' del bloque que contiene a la sentencia
+		case p.braces > braces:
+			// Dentro de un bloque de la sentencia descartada
+		case p.curTokenIs(lexer.NEWLINE), p.curTokenIs(lexer.SEMICOLON):
+			return
+		case statementKeywords[p.curToken.Type] && p.prevToken.Type == lexer.NEWLINE:
+			return
+		}
+		p.nextToken()
+	}
+}
@@ -1,466 +1,591 @@
-	package parser
+package parser
+
+import (
+	"context"
+	"fmt"
+	pathpkg "path"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+	"github.com/zylo-lang/zylo/internal/lexer"
+)
+
+// Parser toma una secuencia de tokens y construye un AST.
+//
+// Convención: cada función de parsing se invoca con curToken sobre el primer
+// token de su construcción y termina con curToken sobre el último token de la
+// misma. El llamador es quien avanza al siguiente token.
+type Parser struct {
+	l           *lexer.Lexer
+	diagnostics []Diagnostic
+
+	prevToken lexer.Token
+	curToken  lexer.Token
+	peekToken lexer.Token
+	braces    int // Llaves abiertas hasta curToken, incluido
+	synced    int // Errores ya seguidos de una sincronización
+
+	// Protecciones contra memory leak
+	recursionDepth    int
+	maxRecursionDepth int
+	maxErrors         int
+
+	// Funciones de parsing para prefijos y sufijos.
+	prefixParseFns map[lexer.TokenType]prefixParseFn
+	infixParseFns  map[lexer.TokenType]infixParseFn
+}
+
+// prefixParseFn es el tipo para funciones que parsean expresiones prefijo.
+type prefixParseFn func() ast.Expression
+
+// infixParseFn es el tipo para funciones que parsean expresiones infijo.
+type infixParseFn func(ast.Expression) ast.Expression
+
+// New crea un nuevo Parser.
+func New(l *lexer.Lexer) *Parser {
+	p := &Parser{
+		l:                 l,
+		maxRecursionDepth: 1000,
+		maxErrors:         100,
+	}
+
+	p.prefixParseFns = make(map[lexer.TokenType]prefixParseFn)
+	p.infixParseFns = make(map[lexer.TokenType]infixParseFn)
+
+	// LITERALES Y IDENTIFICADORES
+	p.registerPrefix(lexer.IDENTIFIER, p.parseIdentifier)
+	p.registerPrefix(lexer.NUMBER, p.parseNumberLiteral)
+	p.registerPrefix(lexer.STRING, p.parseStringLiteral)
+	p.registerPrefix(lexer.TRUE, p.parseBooleanLiteral)
+	p.registerPrefix(lexer.FALSE, p.parseBooleanLiteral)
+	p.registerPrefix(lexer.NIL, p.parseNullLiteral)
+
+	// OPERADORES PREFIJO
+	p.registerPrefix(lexer.BANG, p.parsePrefixExpression)
+	p.registerPrefix(lexer.MINUS, p.parsePrefixExpression)
+	p.registerPrefix(lexer.PLUS, p.parsePrefixExpression) // +num
+
+	// AGRUPACIÓN Y ESTRUCTURAS
+	p.registerPrefix(lexer.LEFT_PAREN, p.parseGroupedExpression)
+	p.registerPrefix(lexer.LEFT_BRACE, p.parseHashLiteral)   // Para objetos {}
+	p.registerPrefix(lexer.LEFT_BRACKET, p.parseListLiteral) // Para arrays []
+
+	// PALABRAS CLAVE QUE PUEDEN SER EXPRESIONES
+	p.registerPrefix(lexer.THIS, p.parseThisExpression)
+	p.registerPrefix(lexer.SUPER, p.parseSuperExpression)
+	p.registerPrefix(lexer.FUNC, p.parseFunctionLiteral)    // func(a, b) { ... }
+	p.registerPrefix(lexer.IMPORT, p.parseImportExpression) // Import como expresión
+	p.registerPrefix(lexer.TRY, p.parseTryBuiltin)          // try(fn, catchFn)
+	p.registerPrefix(lexer.SPAWN, p.parseSpawnExpression)   // spawn f(x)
+	p.registerPrefix(lexer.AWAIT, p.parseAwaitExpression)   // await future
+	p.registerPrefix(lexer.ELIF, func() ast.Expression {
+		// ELIF no debería ser una expresión, devolver error controlado
+		p.addError(CodeUnexpectedToken, "elif must be used after if statement")
+		return nil
+	})
 
-	import (
-		"context"
-		"fmt"
-		"time"
+	// Dummy prefix parsers for tokens that should not appear in expressions
+	p.registerPrefix(lexer.RETURN, func() ast.Expression {
+		p.addError(CodeUnexpectedToken, "return statement not allowed in expression")
+		return nil
+	})
+	p.registerPrefix(lexer.VAR, func() ast.Expression {
+		p.addError(CodeUnexpectedToken, "var statement not allowed in expression")
+		return nil
+	})
+	p.registerPrefix(lexer.IF, func() ast.Expression {
+		p.addError(CodeUnexpectedToken, "if statement not allowed in expression")
+		return nil
+	})
 
-		"github.com/zylo-lang/zylo/internal/ast"
-		"github.com/zylo-lang/zylo/internal/lexer"
-	)
+	// Una expresión puede continuar en la línea siguiente (e.g. después de un operador)
+	p.registerPrefix(lexer.NEWLINE, func() ast.Expression {
+		p.skipNewlines()
+		return p.parseExpression(LOWEST)
+	})
 
-	// Parser toma una secuencia de tokens y construye un AST.
-	type Parser struct {
-		l      *lexer.Lexer
-		errors []string
+	// Dummy prefix parsers for tokens that should not appear in expressions
+	p.registerPrefix(lexer.COMMA, func() ast.Expression {
+		p.addError(CodeUnexpectedToken, "comma not allowed in expression")
+		return nil
+	})
+	p.registerPrefix(lexer.DOT, func() ast.Expression {
+		p.addError(CodeUnexpectedToken, "dot not allowed in expression")
+		return nil
+	})
+	p.registerPrefix(lexer.EQUAL, func() ast.Expression {
+		p.addError(CodeUnexpectedToken, "= not allowed in expression")
+		return nil
+	})
+	p.registerPrefix(lexer.LESS, func() ast.Expression {
+		p.addError(CodeUnexpectedToken, "< not allowed in expression")
+		return nil
+	})
+	p.registerPrefix(lexer.BANG_EQUAL, func() ast.Expression {
+		p.addError(CodeUnexpectedToken, "!= not allowed in expression")
+		return nil
+	})
+	p.registerPrefix(lexer.AND, func() ast.Expression {
+		p.addError(CodeUnexpectedToken, "&& not allowed in expression")
+		return nil
+	})
+	p.registerPrefix(lexer.RIGHT_PAREN, func() ast.Expression {
+		p.addError(CodeUnexpectedToken, ") not allowed in expression")
+		return nil
+	})
+	p.registerPrefix(lexer.EOF, func() ast.Expression {
+		p.addError(CodeUnexpectedToken, "EOF not allowed in expression")
+		return nil
+	})
+
+	// OPERADORES INFIJO
+	p.registerInfix(lexer.PLUS, p.parseInfixExpression)
+	p.registerInfix(lexer.MINUS, p.parseInfixExpression)
+	p.registerInfix(lexer.STAR, p.parseInfixExpression)
+	p.registerInfix(lexer.SLASH, p.parseInfixExpression)
+	p.registerInfix(lexer.PERCENT, p.parseInfixExpression)
+	p.registerInfix(lexer.DOT_DOT, p.parseInfixExpression) // 0..10
+
+	// COMPARACIÓN
+	p.registerInfix(lexer.EQUAL_EQUAL, p.parseInfixExpression)
+	p.registerInfix(lexer.BANG_EQUAL, p.parseInfixExpression)
+	p.registerInfix(lexer.LESS, p.parseInfixExpression)
+	p.registerInfix(lexer.LESS_EQUAL, p.parseInfixExpression)
+	p.registerInfix(lexer.GREATER, p.parseInfixExpression)
+	p.registerInfix(lexer.GREATER_EQUAL, p.parseInfixExpression)
+	p.registerInfix(lexer.INSTANCEOF, p.parseInfixExpression)
+
+	// LÓGICOS
+	p.registerInfix(lexer.AND, p.parseInfixExpression)
+	p.registerInfix(lexer.OR, p.parseInfixExpression)
+
+	// ASIGNACIÓN
+	p.registerInfix(lexer.EQUAL, p.parseInfixExpression)
+
+	// ACCESO
+	p.registerInfix(lexer.LEFT_PAREN, p.parseCallExpression)
+	p.registerInfix(lexer.LEFT_BRACKET, p.parseIndexExpression)
+	p.registerInfix(lexer.DOT, p.parseDotExpression)
+
+	// LAMBDAS
+	p.registerInfix(lexer.ARROW, p.parseArrowFunction) // x => x * 2
+
+	p.nextToken()
+	p.nextToken()
+
+	return p
+}
 
-		curToken  lexer.Token
-		peekToken lexer.Token
+// registerPrefix registra una función de parsing prefijo.
+func (p *Parser) registerPrefix(tokenType lexer.TokenType, fn prefixParseFn) {
+	p.prefixParseFns[tokenType] = fn
+}
 
-		// Protecciones contra memory leak
-		recursionDepth    int
-		maxRecursionDepth int
-		maxErrors         int
+// registerInfix registra una función de parsing infijo.
+func (p *Parser) registerInfix(tokenType lexer.TokenType, fn infixParseFn) {
+	p.infixParseFns[tokenType] = fn
+}
 
-		// Funciones de parsing para prefijos y sufijos.
-		prefixParseFns map[lexer.TokenType]prefixParseFn
-		infixParseFns  map[lexer.TokenType]infixParseFn
-	}
+// ParseProgram es el punto de entrada para el parsing.
+func (p *Parser) ParseProgram() *ast.Program {
+	return p.ParseProgramWithTimeout(30 * time.Second)
+}
 
-	// prefixParseFn es el tipo para funciones que parsean expresiones prefijo.
-	type prefixParseFn func() ast.Expression
+// ParseProgramWithTimeout analiza el programa completo y se detiene con un
+// error si tarda más que timeout. Cada sentencia consume al menos un token,
+// así que el parsing siempre termina; el plazo protege de entradas enormes.
+func (p *Parser) ParseProgramWithTimeout(timeout time.Duration) *ast.Program {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
-	// infixParseFn es el tipo para funciones que parsean expresiones infijo.
-	type infixParseFn func(ast.Expression) ast.Expression
+	program := &ast.Program{}
+	program.Statements = p.parseStatements(ctx, lexer.EOF)
+	return program
+}
 
-	// New crea un nuevo Parser.
-	func New(l *lexer.Lexer) *Parser {
-		p := &Parser{
-			l:                 l,
-			errors:            []string{},
-			maxRecursionDepth: 1000,
-			maxErrors:         100,
-		}
-	
-		p.prefixParseFns = make(map[lexer.TokenType]prefixParseFn)
-		p.infixParseFns = make(map[lexer.TokenType]infixParseFn)
-	
-		// LITERALES Y IDENTIFICADORES
-		p.registerPrefix(lexer.IDENTIFIER, p.parseIdentifier)
-		p.registerPrefix(lexer.NUMBER, p.parseNumberLiteral)
-		p.registerPrefix(lexer.STRING, p.parseStringLiteral)
-		p.registerPrefix(lexer.TRUE, p.parseBooleanLiteral)
-		p.registerPrefix(lexer.FALSE, p.parseBooleanLiteral)
-		p.registerPrefix(lexer.NIL, p.parseNullLiteral)
-	
-		// OPERADORES PREFIJO
-		p.registerPrefix(lexer.BANG, p.parsePrefixExpression)
-		p.registerPrefix(lexer.MINUS, p.parsePrefixExpression)
-		p.registerPrefix(lexer.PLUS, p.parsePrefixExpression) // +num
-	
-		// AGRUPACIÓN Y ESTRUCTURAS
-		p.registerPrefix(lexer.LEFT_PAREN, p.parseGroupedExpression)
-		p.registerPrefix(lexer.LEFT_BRACE, p.parseHashLiteral) // Para objetos {}
-		p.registerPrefix(lexer.LEFT_BRACKET, p.parseListLiteral) // Para arrays []
-	
-		// PALABRAS CLAVE QUE PUEDEN SER EXPRESIONES
-		p.registerPrefix(lexer.THIS, p.parseThisExpression)
-		p.registerPrefix(lexer.SUPER, p.parseSuperExpression)
-		// FUNC is handled as statement, not expression
-		p.registerPrefix(lexer.IMPORT, p.parseImportExpression) // Import como expresión
-		p.registerPrefix(lexer.ELIF, func() ast.Expression {
-			// ELIF no debería ser una expresión, devolver error controlado
-			p.addError("elif must be used after if statement")
-			return &ast.Identifier{Token: p.curToken, Value: "ERROR"}
-		})
-
-		// Dummy prefix parsers for tokens that should not appear in expressions
-		p.registerPrefix(lexer.RETURN, func() ast.Expression {
-			p.addError("return statement not allowed in expression")
-			return &ast.Identifier{Token: p.curToken, Value: "ERROR"}
-		})
-		p.registerPrefix(lexer.VAR, func() ast.Expression {
-			p.addError("var statement not allowed in expression")
-			return &ast.Identifier{Token: p.curToken, Value: "ERROR"}
-		})
-		p.registerPrefix(lexer.IF, func() ast.Expression {
-			p.addError("if statement not allowed in expression")
-			return &ast.Identifier{Token: p.curToken, Value: "ERROR"}
-		})
-		p.registerPrefix(lexer.FUNC, func() ast.Expression {
-			p.addError("func statement not allowed in expression")
-			return &ast.Identifier{Token: p.curToken, Value: "ERROR"}
-		})
-
-		// Registrar NEWLINE como token que se puede saltar
-		p.registerPrefix(lexer.NEWLINE, func() ast.Expression {
-			// Saltar el NEWLINE y continuar
+// parseStatements analiza sentencias hasta end ('}' en un bloque, EOF en el
+// programa) y termina con curToken sobre él. Después de una sentencia con
+// errores se sincroniza en el siguiente punto seguro, y una sentencia
+// correcta tiene que terminar la línea.
+func (p *Parser) parseStatements(ctx context.Context, end lexer.TokenType) []ast.Statement {
+	statements := []ast.Statement{}
+	for !p.curTokenIs(end) && !p.curTokenIs(lexer.EOF) {
+		if p.curTokenIs(lexer.NEWLINE) || p.curTokenIs(lexer.SEMICOLON) {
 			p.nextToken()
-			return p.parseExpression(LOWEST)
-		})
+			continue
+		}
+		if ctx != nil && ctx.Err() != nil {
+			p.addError(CodeTimeout, "parsing timeout")
+			return statements
+		}
 
-		// Dummy prefix parsers for tokens that should not appear in expressions
-		p.registerPrefix(lexer.COMMA, func() ast.Expression {
-			p.addError("comma not allowed in expression")
-			p.nextToken()
-			return &ast.Identifier{Token: p.curToken, Value: "ERROR"}
-		})
-		p.registerPrefix(lexer.DOT, func() ast.Expression {
-			p.addError("dot not allowed in expression")
-			p.nextToken()
-			return &ast.Identifier{Token: p.curToken, Value: "ERROR"}
-		})
-		p.registerPrefix(lexer.EQUAL, func() ast.Expression {
-			p.addError("= not allowed in expression")
-			p.nextToken()
-			return &ast.Identifier{Token: p.curToken, Value: "ERROR"}
-		})
-		p.registerPrefix(lexer.LESS, func() ast.Expression {
-			p.addError("< not allowed in expression")
-			p.nextToken()
-			return &ast.Identifier{Token: p.curToken, Value: "ERROR"}
-		})
-		p.registerPrefix(lexer.BANG_EQUAL, func() ast.Expression {
-			p.addError("!= not allowed in expression")
-			p.nextToken()
-			return &ast.Identifier{Token: p.curToken, Value: "ERROR"}
-		})
-		p.registerPrefix(lexer.AND, func() ast.Expression {
-			p.addError("&& not allowed in expression")
-			p.nextToken()
-			return &ast.Identifier{Token: p.curToken, Value: "ERROR"}
-		})
-		p.registerPrefix(lexer.RIGHT_PAREN, func() ast.Expression {
-			p.addError(") not allowed in expression")
+		start, braces, errors := p.curToken, p.braces, len(p.diagnostics)
+		stmt := p.parseStatement()
+		if stmt != nil {
+			statements = append(statements, stmt)
+		}
+		if len(p.diagnostics) == errors && !p.atStatementEnd() {
+			p.addErrorAt(p.peekToken, CodeExpectedToken,
+				fmt.Sprintf("expected newline or ';' after statement, got %s", p.peekToken.Type))
 			p.nextToken()
-			return &ast.Identifier{Token: p.curToken, Value: "ERROR"}
-		})
-		p.registerPrefix(lexer.EOF, func() ast.Expression {
-			p.addError("EOF not allowed in expression")
+		}
+		if !p.recover(start, braces, errors) {
 			p.nextToken()
-			return &ast.Identifier{Token: p.curToken, Value: "ERROR"}
-		})
-	
-		// OPERADORES INFIJO
-		p.registerInfix(lexer.PLUS, p.parseInfixExpression)
-		p.registerInfix(lexer.MINUS, p.parseInfixExpression)
-		p.registerInfix(lexer.STAR, p.parseInfixExpression)
-		p.registerInfix(lexer.SLASH, p.parseInfixExpression)
-		p.registerInfix(lexer.PERCENT, p.parseInfixExpression)
-	
-		// COMPARACIÓN
-		p.registerInfix(lexer.EQUAL_EQUAL, p.parseInfixExpression)
-		p.registerInfix(lexer.BANG_EQUAL, p.parseInfixExpression)
-		p.registerInfix(lexer.LESS, p.parseInfixExpression)
-		p.registerInfix(lexer.LESS_EQUAL, p.parseInfixExpression)
-		p.registerInfix(lexer.GREATER, p.parseInfixExpression)
-		p.registerInfix(lexer.GREATER_EQUAL, p.parseInfixExpression)
-	
-		// LÓGICOS
-		p.registerInfix(lexer.AND, p.parseInfixExpression)
-		p.registerInfix(lexer.OR, p.parseInfixExpression)
-	
-		// ASIGNACIÓN
-		p.registerInfix(lexer.EQUAL, p.parseInfixExpression)
-	
-		// ACCESO
-		p.registerInfix(lexer.LEFT_PAREN, func(left ast.Expression) ast.Expression {
-			return p.parseCallExpression(left)
-		})
-		p.registerInfix(lexer.LEFT_BRACKET, func(left ast.Expression) ast.Expression {
-			return p.parseIndexExpression(left)
-		})
-		p.registerInfix(lexer.DOT, p.parseDotExpression)
-	
-		p.nextToken()
-		p.nextToken()
-	
-		return p
+		}
 	}
+	return statements
+}
 
-	// registerPrefix registra una función de parsing prefijo.
-	func (p *Parser) registerPrefix(tokenType lexer.TokenType, fn prefixParseFn) {
-		p.prefixParseFns[tokenType] = fn
+// atStatementEnd indica si la sentencia que termina en curToken termina
+// también la línea.
+func (p *Parser) atStatementEnd() bool {
+	switch p.curToken.Type {
+	case lexer.NEWLINE, lexer.SEMICOLON, lexer.EOF:
+		return true
 	}
-
-	// registerInfix registra una función de parsing infijo.
-	func (p *Parser) registerInfix(tokenType lexer.TokenType, fn infixParseFn) {
-		p.infixParseFns[tokenType] = fn
+	switch p.peekToken.Type {
+	case lexer.NEWLINE, lexer.SEMICOLON, lexer.RIGHT_BRACE, lexer.EOF:
+		return true
 	}
+	return false
+}
 
-	// ParseProgram es el punto de entrada para el parsing.
-	func (p *Parser) ParseProgram() *ast.Program {
-		return p.ParseProgramWithTimeout(30 * time.Second)
+// Funciones helper para control de recursión
+func (p *Parser) enterRecursion() error {
+	p.recursionDepth++
+	if p.recursionDepth > p.maxRecursionDepth {
+		return fmt.Errorf("máxima profundidad de recursión alcanzada (%d)", p.maxRecursionDepth)
 	}
+	return nil
+}
 
-	// ParseProgramWithTimeout - con debugging y protección extra
-	func (p *Parser) ParseProgramWithTimeout(timeout time.Duration) *ast.Program {
-		ctx, cancel := context.WithTimeout(context.Background(), timeout)
-		defer cancel()
-
-		program := &ast.Program{}
-		program.Statements = []ast.Statement{}
+func (p *Parser) exitRecursion() {
+	p.recursionDepth--
+}
 
-		iterations := 0 // Contador de seguridad
+// skipNewlines salta tokens NEWLINE consecutivos
+func (p *Parser) skipNewlines() {
+	for p.curToken.Type == lexer.NEWLINE {
+		p.nextToken()
+	}
+}
 
-		// Saltar tokens NEWLINE iniciales
-		for p.curToken.Type == lexer.NEWLINE && p.curToken.Type != lexer.EOF {
-			p.nextToken()
-		}
+// skipPeekNewlines avanza mientras el siguiente token sea NEWLINE, dejando
+// curToken sobre el último NEWLINE consumido.
+func (p *Parser) skipPeekNewlines() {
+	for p.peekToken.Type == lexer.NEWLINE {
+		p.nextToken()
+	}
+}
 
-		for p.curToken.Type != lexer.EOF {
-			iterations++
-			if iterations > 1000 { // Límite de seguridad
-				p.addError("too many iterations - possible infinite loop")
-				break
-			}
+// noPrefixParseFnError añade un error cuando no hay función de parsing
+// prefijo. Los tokens de error del lexer llevan su propio mensaje.
+func (p *Parser) noPrefixParseFnError(t lexer.TokenType) {
+	if t == lexer.ERROR {
+		p.addError(CodeInvalidToken, p.curToken.Lexeme)
+		return
+	}
+	p.addError(CodeUnexpectedToken, fmt.Sprintf("no prefix parse function for %s found", t))
+}
 
-			select {
-			case <-ctx.Done():
-				p.addError("parsing timeout")
-				return program
-			default:
-			}
+// parseImportStatement analiza 'import utils' o 'import "lib/utils"'.
+func (p *Parser) parseImportStatement() *ast.ImportStatement {
+	stmt := &ast.ImportStatement{Token: p.curToken}
+	if !p.parseModuleSpec(stmt) {
+		return nil
+	}
 
-			// Guardar token actual para detectar si avanzamos
-			currentTokenType := p.curToken.Type
+	if p.peekTokenIs(lexer.SEMICOLON) {
+		p.nextToken()
+	}
 
-			stmt := p.parseStatement()
-			if stmt != nil {
-				program.Statements = append(program.Statements, stmt)
-			}
+	return stmt
+}
 
-			// CRÍTICO: Siempre avanzar si no cambió el token
-			if p.curToken.Type == currentTokenType {
-				p.nextToken()
-			}
+// parseFromImportStatement analiza 'from utils import Split, Len'.
+func (p *Parser) parseFromImportStatement() *ast.ImportStatement {
+	stmt := &ast.ImportStatement{Token: p.curToken}
+	if !p.parseModuleSpec(stmt) {
+		return nil
+	}
+	if !p.expectPeek(lexer.IMPORT) {
+		return nil
+	}
 
-			if len(program.Statements) > 1000 {
-				p.addError("too many statements")
-				break
-			}
+	stmt.Names = []*ast.Identifier{}
+	for {
+		if !p.expectPeek(lexer.IDENTIFIER) {
+			return nil
+		}
+		stmt.Names = append(stmt.Names, &ast.Identifier{Token: p.curToken, Value: p.curToken.Lexeme})
+		if !p.peekTokenIs(lexer.COMMA) {
+			break
 		}
+		p.nextToken() // ,
+	}
 
-		return program
+	if p.peekTokenIs(lexer.SEMICOLON) {
+		p.nextToken()
 	}
 
-	// Errors devuelve la lista de errores encontrados durante el parsing.
-	func (p *Parser) Errors() []string {
-		return p.errors
+	return stmt
+}
+
+// parseModuleSpec analiza el módulo de un import, que es un identificador o
+// una ruta entre comillas. Una ruta liga el módulo a su último componente
+// sin la extensión .zylo.
+func (p *Parser) parseModuleSpec(stmt *ast.ImportStatement) bool {
+	p.nextToken()
+	switch p.curToken.Type {
+	case lexer.IDENTIFIER:
+		stmt.ModuleName = &ast.Identifier{Token: p.curToken, Value: p.curToken.Lexeme}
+	case lexer.STRING:
+		path, _ := p.curToken.Literal.(string)
+		name := strings.TrimSuffix(pathpkg.Base(path), ".zylo")
+		if !isIdentifier(name) {
+			p.addError(CodeInvalidSyntax, fmt.Sprintf("invalid module path %q: %q is not a valid module name", path, name))
+			return false
+		}
+		stmt.Path = path
+		stmt.ModuleName = &ast.Identifier{Token: p.curToken, Value: name}
+	default:
+		p.addError(CodeExpectedToken, fmt.Sprintf("expected module name or path after %s, got %s", stmt.Token.Lexeme, p.curToken.Type))
+		return false
 	}
+	return true
+}
 
-	// Funciones helper para control de recursión
-	func (p *Parser) enterRecursion() error {
-		p.recursionDepth++
-		if p.recursionDepth > p.maxRecursionDepth {
-			return fmt.Errorf("máxima profundidad de recursión alcanzada (%d)", p.maxRecursionDepth)
+// isIdentifier indica si name es un identificador válido de Zylo.
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if !(r == '_' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r))) {
+			return false
 		}
-		return nil
 	}
+	return true
+}
 
-	func (p *Parser) exitRecursion() {
-		p.recursionDepth--
+// parseImportExpression analiza import como expresión (para casos donde aparece en contexto de expresión)
+func (p *Parser) parseImportExpression() ast.Expression {
+	stmt := &ast.ImportStatement{Token: p.curToken}
+	if !p.parseModuleSpec(stmt) {
+		return nil
 	}
+	return stmt
+}
 
-	// addError añade un error con protección contra overflow
-	func (p *Parser) addError(msg string) {
-		if len(p.errors) < p.maxErrors {
-			p.errors = append(p.errors, msg)
-		}
+// parseExportStatement analiza 'export' seguido de una declaración de
+// función, variable o clase.
+func (p *Parser) parseExportStatement() *ast.ExportStatement {
+	stmt := &ast.ExportStatement{Token: p.curToken}
+	p.nextToken()
+	switch p.curToken.Type {
+	case lexer.FUNC:
+		stmt.Statement = nilIfEmpty(p.parseFuncStatement())
+	case lexer.ASYNC:
+		stmt.Statement = nilIfEmpty(p.parseAsyncFuncStatement())
+	case lexer.VAR, lexer.CONST:
+		stmt.Statement = nilIfEmpty(p.parseVarStatement())
+	case lexer.CLASS:
+		stmt.Statement = nilIfEmpty(p.parseClassStatement())
+	default:
+		p.addError(CodeInvalidSyntax, fmt.Sprintf("export expects a function, variable or class declaration, got %s", p.curToken.Type))
+		return nil
+	}
+	if stmt.Statement == nil {
+		return nil
 	}
+	return stmt
+}
 
-	// skipNewlines salta tokens NEWLINE consecutivos
-	func (p *Parser) skipNewlines() {
-		for p.curToken.Type == lexer.NEWLINE {
-			p.nextToken()
-		}
+// parseBreakStatement analiza una sentencia 'break'.
+func (p *Parser) parseBreakStatement() *ast.BreakStatement {
+	stmt := &ast.BreakStatement{Token: p.curToken}
+	if p.peekTokenIs(lexer.SEMICOLON) {
+		p.nextToken()
 	}
+	return stmt
+}
 
-	// noPrefixParseFnError añade un error cuando no hay función de parsing prefijo
-	func (p *Parser) noPrefixParseFnError(t lexer.TokenType) {
-		msg := fmt.Sprintf("no prefix parse function for %s found", t)
-		p.addError(msg)
+// parseContinueStatement analiza una sentencia 'continue'.
+func (p *Parser) parseContinueStatement() *ast.ContinueStatement {
+	stmt := &ast.ContinueStatement{Token: p.curToken}
+	if p.peekTokenIs(lexer.SEMICOLON) {
+		p.nextToken()
 	}
+	return stmt
+}
 
-	// parseImportStatement analiza una declaración de import.
-	func (p *Parser) parseImportStatement() *ast.ImportStatement {
-		stmt := &ast.ImportStatement{Token: p.curToken}
+// parseStatement analiza una sentencia a partir del token actual.
+func (p *Parser) parseStatement() ast.Statement {
+	// Saltar NEWLINES al inicio
+	p.skipNewlines()
 
-		if !p.expectPeek(lexer.IDENTIFIER) {
-			return nil
-		}
+	switch p.curToken.Type {
+	case lexer.IMPORT:
+		return nilIfEmpty(p.parseImportStatement())
+	case lexer.FROM:
+		return nilIfEmpty(p.parseFromImportStatement())
+	case lexer.EXPORT:
+		return nilIfEmpty(p.parseExportStatement())
+	case lexer.VAR, lexer.CONST:
+		return nilIfEmpty(p.parseVarStatement())
+	case lexer.FUNC:
+		if p.peekTokenIs(lexer.LEFT_PAREN) {
+			// Función anónima usada como expresión, e.g. func() { ... }()
+			return nilIfEmpty(p.parseExpressionStatement())
+		}
+		return nilIfEmpty(p.parseFuncStatement())
+	case lexer.ASYNC:
+		return nilIfEmpty(p.parseAsyncFuncStatement())
+	case lexer.RETURN:
+		return p.parseReturnStatement()
+	case lexer.IF:
+		return nilIfEmpty(p.parseIfStatement())
+	case lexer.ELIF:
+		// ELIF solo es válido después de IF, tratar como error
+		p.addError(CodeUnexpectedToken, "elif without preceding if statement")
+		return nil
+	case lexer.WHILE:
+		return nilIfEmpty(p.parseWhileStatement())
+	case lexer.FOR:
+		return p.parseForStatement()
+	case lexer.TRY:
+		if p.peekTokenIs(lexer.LEFT_PAREN) {
+			// Llamada al built-in try(fn, catchFn)
+			return nilIfEmpty(p.parseExpressionStatement())
+		}
+		return nilIfEmpty(p.parseTryStatement())
+	case lexer.CLASS:
+		return nilIfEmpty(p.parseClassStatement())
+	case lexer.BREAK:
+		return p.parseBreakStatement()
+	case lexer.CONTINUE:
+		return p.parseContinueStatement()
+	case lexer.THROW:
+		return p.parseThrowStatement()
+	case lexer.SEMICOLON, lexer.NEWLINE, lexer.EOF:
+		return nil
+	case lexer.RIGHT_BRACE:
+		p.addError(CodeUnexpectedToken, "unexpected '}'")
+		return nil
+	default:
+		return nilIfEmpty(p.parseExpressionStatement())
+	}
+}
 
-		stmt.ModuleName = &ast.Identifier{Token: p.curToken, Value: p.curToken.Lexeme}
+// nilIfEmpty evita devolver un puntero nil tipado dentro de la interfaz ast.Statement.
+func nilIfEmpty[T ast.Statement](stmt T) ast.Statement {
+	var zero T
+	if any(stmt) == any(zero) {
+		return nil
+	}
+	return stmt
+}
 
-		// Skip newlines after import statement
-		for p.peekTokenIs(lexer.NEWLINE) {
-			p.nextToken()
-		}
+func (p *Parser) parseVarStatement() *ast.VarStatement {
+	stmt := &ast.VarStatement{Token: p.curToken}
 
-		return stmt
+	if !p.expectPeek(lexer.IDENTIFIER) {
+		return nil
 	}
 
-	// parseImportExpression analiza import como expresión (para casos donde aparece en contexto de expresión)
-	func (p *Parser) parseImportExpression() ast.Expression {
-		stmt := &ast.ImportStatement{Token: p.curToken}
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Lexeme}
 
-		if !p.expectPeek(lexer.IDENTIFIER) {
+	// Tipo opcional ": Float" o ": Array<String>"
+	if p.peekTokenIs(lexer.COLON) {
+		p.nextToken() // ':'
+		typeName, ok := p.parseTypeAnnotation()
+		if !ok {
 			return nil
 		}
-
-		stmt.ModuleName = &ast.Identifier{Token: p.curToken, Value: p.curToken.Lexeme}
-
-		return stmt
-	}
-
-	// parseBreakStatement analiza una sentencia 'break'.
-	func (p *Parser) parseBreakStatement() *ast.BreakStatement {
-		stmt := &ast.BreakStatement{Token: p.curToken}
-		return stmt
+		stmt.Type = typeName
 	}
 
-	// parseContinueStatement analiza una sentencia 'continue'.
-	func (p *Parser) parseContinueStatement() *ast.ContinueStatement {
-		stmt := &ast.ContinueStatement{Token: p.curToken}
-		return stmt
+	if p.peekTokenIs(lexer.EQUAL) {
+		p.nextToken() // =
+		p.nextToken() // valor
+		stmt.Value = p.parseExpression(LOWEST)
 	}
 
-	// parseElifStatement analiza una sentencia 'elif' - esto se trata como un if anidado
-	func (p *Parser) parseElifStatement() ast.Statement {
-		// 'elif' se convierte en 'if' para el parser
-		return p.parseIfStatement()
+	if p.peekTokenIs(lexer.SEMICOLON) {
+		p.nextToken()
 	}
 
-	// AGREGAR parseStatement mejorado que maneje ELIF correctamente
-	func (p *Parser) parseStatement() ast.Statement {
-		// Saltar NEWLINES al inicio
-		for p.curToken.Type == lexer.NEWLINE {
-			p.nextToken()
-		}
+	return stmt
+}
 
-		switch p.curToken.Type {
-		case lexer.IMPORT:
-			return p.parseImportStatement()
-		case lexer.VAR:
-			return p.parseVarStatement()
-		case lexer.FUNC:
-			return p.parseFuncStatement()
-		case lexer.RETURN:
-			return p.parseReturnStatement()
-		case lexer.IF:
-			return p.parseIfStatement()
-		case lexer.ELIF:
-			// ELIF solo es válido después de IF, tratar como error
-			p.addError("elif without preceding if statement")
-			return nil
-		case lexer.WHILE:
-			return p.parseWhileStatement()
-		case lexer.FOR:
-			return p.parseForStatement()
-		case lexer.TRY:
-			return p.parseTryStatement()
-		case lexer.CLASS:
-			return p.parseClassStatement()
-		case lexer.BREAK:
-			return p.parseBreakStatement()
-		case lexer.CONTINUE:
-			return p.parseContinueStatement()
-		case lexer.THROW:
-			return p.parseThrowStatement()
-		case lexer.SEMICOLON, lexer.NEWLINE:
-			return nil
-		case lexer.RIGHT_BRACE:
-			return nil
-		default:
-			return p.parseExpressionStatement()
-		}
+// parseTypeAnnotation analiza una anotación de tipo que sigue a ':'.
+// Se invoca con curToken sobre ':' y termina sobre el último token del tipo.
+func (p *Parser) parseTypeAnnotation() (string, bool) {
+	if !p.expectPeek(lexer.IDENTIFIER) {
+		return "", false
 	}
+	typeName := p.curToken.Lexeme
 
-	func (p *Parser) parseVarStatement() *ast.VarStatement {
-		stmt := &ast.VarStatement{Token: p.curToken}
-
-		p.skipNewlines()
-		if !p.expectPeek(lexer.IDENTIFIER) {
-			return nil
-		}
-
-		stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Lexeme}
-	
-		p.skipNewlines()
-	
-		// CRÍTICO: Manejar tipo opcional ": Float" o ": Array<String>"
-		if p.peekTokenIs(lexer.COLON) {
-			p.nextToken() // consumir ':'
+	// Tipos genéricos como Array<String> o Map<String, Int>
+	if p.peekTokenIs(lexer.LESS) {
+		p.nextToken() // <
+		typeName += "<"
+		for {
 			if !p.expectPeek(lexer.IDENTIFIER) {
-				return nil
+				return "", false
 			}
-			// Opcional: guardar tipo de variable
-			// stmt.Type = p.curToken.Lexeme
-
-			// Manejar tipos genéricos como Array<String>
-			if p.peekTokenIs(lexer.LESS) {
-				p.nextToken() // consume <
-				if p.expectPeek(lexer.IDENTIFIER) {
-					// Tipo parámetro, ignorar por ahora
-				}
-				p.expectPeek(lexer.GREATER) // consume >
+			typeName += p.curToken.Lexeme
+			if !p.peekTokenIs(lexer.COMMA) {
+				break
 			}
+			p.nextToken() // ,
+			typeName += ", "
 		}
-
-		if p.peekTokenIs(lexer.EQUAL) {
-			p.nextToken() // consume =
-			p.nextToken() // move to value
-			stmt.Value = p.parseExpression(LOWEST)
-		}
-
-		if p.peekTokenIs(lexer.SEMICOLON) {
-			p.nextToken()
+		if !p.expectPeek(lexer.GREATER) {
+			return "", false
 		}
-
-		return stmt
+		typeName += ">"
 	}
 
-	func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
-		stmt := &ast.ReturnStatement{Token: p.curToken}
-
-		p.nextToken()
-		if !p.curTokenIs(lexer.SEMICOLON) && !p.curTokenIs(lexer.NEWLINE) && !p.curTokenIs(lexer.RIGHT_BRACE) {
-			stmt.ReturnValue = p.parseExpression(LOWEST)
-		}
+	return typeName, true
+}
 
-		if p.peekTokenIs(lexer.SEMICOLON) {
-			p.nextToken()
-		}
+func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
+	stmt := &ast.ReturnStatement{Token: p.curToken}
 
-		return stmt
+	if !p.peekTokenIs(lexer.SEMICOLON) && !p.peekTokenIs(lexer.NEWLINE) &&
+		!p.peekTokenIs(lexer.RIGHT_BRACE) && !p.peekTokenIs(lexer.EOF) {
+		p.nextToken()
+		stmt.ReturnValue = p.parseExpression(LOWEST)
 	}
 
-	func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
-		// Verificar que el token actual puede ser una expresión
-		if p.curToken.Type == lexer.RIGHT_PAREN ||
-		   p.curToken.Type == lexer.ELSE ||
-		   p.curToken.Type == lexer.CATCH {
-			// Estos no son expresiones válidas, devolver nil
-			return nil
-		}
+	if p.peekTokenIs(lexer.SEMICOLON) {
+		p.nextToken()
+	}
 
-		stmt := &ast.ExpressionStatement{Token: p.curToken}
-		stmt.Expression = p.parseExpression(LOWEST)
+	return stmt
+}
 
-		// CRÍTICO: Si la expresión es nil, devolver nil en lugar del statement
-		if stmt.Expression == nil {
-			return nil
-		}
+func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
+	stmt := &ast.ExpressionStatement{Token: p.curToken}
+	stmt.Expression = p.parseExpression(LOWEST)
 
-		// Consumir ; si está presente
-		if p.peekTokenIs(lexer.SEMICOLON) {
-			p.nextToken()
-		}
+	// Si la expresión es nil, devolver nil en lugar del statement
+	if stmt.Expression == nil {
+		return nil
+	}
 
-		return stmt
+	// Consumir ; si está presente
+	if p.peekTokenIs(lexer.SEMICOLON) {
+		p.nextToken()
 	}
 
-// ARREGLAR parseFuncStatement para el error "expected LEFT_BRACE, got NUMBER"
+	return stmt
+}
+
+// parseFuncStatement analiza una declaración de función (también usada para métodos de clase).
 func (p *Parser) parseFuncStatement() *ast.FuncStatement {
 	stmt := &ast.FuncStatement{Token: p.curToken}
 
@@ -475,779 +600,878 @@ func (p *Parser) parseFuncStatement() *ast.FuncStatement {
 	}
 
 	stmt.Parameters = p.parseFunctionParameters()
+	if stmt.Parameters == nil {
+		return nil
+	}
+
+	returnType, body := p.parseFunctionBody()
+	if body == nil {
+		return nil
+	}
+	stmt.ReturnType = returnType
+	stmt.Body = body
+	return stmt
+}
+
+// parseAsyncFuncStatement analiza 'async func nombre(...) { ... }'. Se
+// invoca con curToken sobre 'async'.
+func (p *Parser) parseAsyncFuncStatement() *ast.FuncStatement {
+	if !p.expectPeek(lexer.FUNC) {
+		return nil
+	}
+	stmt := p.parseFuncStatement()
+	if stmt == nil {
+		return nil
+	}
+	stmt.Async = true
+	return stmt
+}
 
-	// CRÍTICO: Manejar tipo de retorno opcional sin consumir tokens incorrectos
+// parseFunctionBody analiza el tipo de retorno opcional y el cuerpo de una
+// función. Se invoca con curToken sobre el ')' de los parámetros y termina
+// sobre el '}' del cuerpo.
+func (p *Parser) parseFunctionBody() (string, *ast.BlockStatement) {
+	returnType := ""
+
+	// Tipo de retorno opcional, con o sin dos puntos
 	if p.peekTokenIs(lexer.COLON) {
-		p.nextToken() // consume :
-		if p.expectPeek(lexer.IDENTIFIER) {
-			// stmt.ReturnType = p.curToken.Lexeme
-		} else {
-			return nil
+		p.nextToken() // :
+		annotation, ok := p.parseTypeAnnotation()
+		if !ok {
+			return "", nil
 		}
+		returnType = annotation
 	} else if p.peekTokenIs(lexer.IDENTIFIER) {
-		// Tipo sin dos puntos
 		p.nextToken()
-		// stmt.ReturnType = p.curToken.Lexeme
+		returnType = p.curToken.Lexeme
 	}
 
-	// Skip newlines antes de {
-	p.skipNewlines()
+	// Permitir newlines antes de {
+	p.skipPeekNewlines()
 
-	if !p.curTokenIs(lexer.LEFT_BRACE) {
-		p.addError("expected '{' to start function body")
-		return nil
+	if !p.peekTokenIs(lexer.LEFT_BRACE) {
+		p.addErrorAt(p.peekToken, CodeExpectedToken, "expected '{' to start function body")
+		return "", nil
 	}
+	p.nextToken()
 
-	p.nextToken() // consume {
-	stmt.Body = p.parseBlockStatement()
-	return stmt
+	return returnType, p.parseBlockStatement()
 }
 
-	func (p *Parser) parseFunctionParameters() []*ast.Identifier {
-		identifiers := []*ast.Identifier{}
-
-		if p.peekTokenIs(lexer.RIGHT_PAREN) {
-			p.nextToken() // consume )
-			p.nextToken() // advance past )
-			return identifiers
-		}
+// parseFunctionParameters analiza la lista de parámetros. Se invoca con
+// curToken sobre '(' y termina sobre ')'.
+func (p *Parser) parseFunctionParameters() []*ast.Identifier {
+	identifiers := []*ast.Identifier{}
 
-		p.nextToken() // consume primer identificador
+	p.skipPeekNewlines()
+	if p.peekTokenIs(lexer.RIGHT_PAREN) {
+		p.nextToken() // )
+		return identifiers
+	}
 
+	for {
+		p.skipPeekNewlines()
+		if !p.expectPeek(lexer.IDENTIFIER) {
+			return nil
+		}
 		ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Lexeme}
 
 		// Revisar si hay : Tipo
 		if p.peekTokenIs(lexer.COLON) {
 			p.nextToken() // :
-			p.nextToken() // tipo
-			// Por ahora ignorar el tipo
+			typeName, ok := p.parseTypeAnnotation()
+			if !ok {
+				return nil
+			}
+			ident.Type = typeName
 		}
 
 		identifiers = append(identifiers, ident)
 
-		for p.peekTokenIs(lexer.COMMA) {
-			p.nextToken() // ,
-			p.nextToken() // siguiente ident
+		p.skipPeekNewlines()
+		if !p.peekTokenIs(lexer.COMMA) {
+			break
+		}
+		p.nextToken() // ,
+	}
 
-			ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Lexeme}
+	if !p.expectPeek(lexer.RIGHT_PAREN) {
+		return nil
+	}
 
-			if p.peekTokenIs(lexer.COLON) {
-				p.nextToken() // :
-				p.nextToken() // tipo
-				// Ignorar tipo
-			}
+	return identifiers
+}
 
-			identifiers = append(identifiers, ident)
-		}
+// Helper functions
+func (p *Parser) nextToken() {
+	p.prevToken = p.curToken
+	p.curToken = p.peekToken
+	p.peekToken = p.l.NextToken()
+	switch p.curToken.Type {
+	case lexer.LEFT_BRACE:
+		p.braces++
+	case lexer.RIGHT_BRACE:
+		p.braces--
+	}
+}
 
-		p.skipNewlines()
-		if !p.expectPeek(lexer.RIGHT_PAREN) {
-			return nil
-		}
-		p.nextToken() // consume )
+func (p *Parser) curTokenIs(t lexer.TokenType) bool {
+	return p.curToken.Type == t
+}
 
-		return identifiers
+func (p *Parser) peekTokenIs(t lexer.TokenType) bool {
+	return p.peekToken.Type == t
+}
+
+func (p *Parser) expectPeek(t lexer.TokenType) bool {
+	if p.peekTokenIs(t) {
+		p.nextToken()
+		return true
 	}
+	p.peekError(t)
+	return false
+}
 
+func (p *Parser) peekError(t lexer.TokenType) {
+	msg := fmt.Sprintf("expected next token to be %s, got %s instead",
+		t, p.peekToken.Type)
+	p.addErrorAt(p.peekToken, CodeExpectedToken, msg)
+}
 
+// Parsing functions
+func (p *Parser) parseIdentifier() ast.Expression {
+	return &ast.Identifier{Token: p.curToken, Value: p.curToken.Lexeme}
+}
 
-	// Helper functions
-	func (p *Parser) nextToken() {
-		p.curToken = p.peekToken
-		p.peekToken = p.l.NextToken()
+func (p *Parser) parseNumberLiteral() ast.Expression {
+	lit := &ast.NumberLiteral{Token: p.curToken}
+	if p.curToken.Literal != nil {
+		lit.Value = p.curToken.Literal
+	} else {
+		lit.Value = int64(0)
 	}
+	return lit
+}
 
-	func (p *Parser) curTokenIs(t lexer.TokenType) bool {
-		return p.curToken.Type == t
+func (p *Parser) parseStringLiteral() ast.Expression {
+	lit := &ast.StringLiteral{Token: p.curToken}
+	if val, ok := p.curToken.Literal.(string); ok {
+		lit.Value = val
 	}
+	return lit
+}
 
-	func (p *Parser) peekTokenIs(t lexer.TokenType) bool {
-		return p.peekToken.Type == t
+func (p *Parser) parseBooleanLiteral() ast.Expression {
+	return &ast.BooleanLiteral{
+		Token: p.curToken,
+		Value: p.curToken.Type == lexer.TRUE,
 	}
+}
 
-	func (p *Parser) expectPeek(t lexer.TokenType) bool {
-		if p.peekTokenIs(t) {
-			p.nextToken()
-			return true
-		}
-		p.peekError(t)
-		return false
-	}
+func (p *Parser) parseNullLiteral() ast.Expression {
+	return &ast.NullLiteral{Token: p.curToken}
+}
 
-	func (p *Parser) peekError(t lexer.TokenType) {
-		msg := fmt.Sprintf("expected next token to be %s, got %s instead",
-			t, p.peekToken.Type)
-		p.addError(msg)
+func (p *Parser) parsePrefixExpression() ast.Expression {
+	expression := &ast.PrefixExpression{
+		Token:    p.curToken,
+		Operator: p.curToken.Lexeme,
+	}
+	p.nextToken() // consume the operator
+	expression.Right = p.parseExpression(PREFIX)
+	if expression.Right == nil {
+		return nil
 	}
+	return expression
+}
 
-	// Parsing functions (minimal implementations)
-	func (p *Parser) parseIdentifier() ast.Expression {
-		expr := &ast.Identifier{Token: p.curToken, Value: p.curToken.Lexeme}
-		p.nextToken() // consume the IDENTIFIER token
-		return expr
+func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
+	exp := &ast.InfixExpression{
+		Token:    p.curToken,
+		Left:     left,
+		Operator: p.curToken.Lexeme,
 	}
 
-	func (p *Parser) parseNumberLiteral() ast.Expression {
-		lit := &ast.NumberLiteral{Token: p.curToken}
-		if p.curToken.Literal != nil {
-			lit.Value = p.curToken.Literal
-		} else {
-			lit.Value = int64(0)
-		}
-		p.nextToken() // consume the NUMBER token
-		return lit
+	// 'and'/'or' se normalizan a sus equivalentes simbólicos
+	switch p.curToken.Type {
+	case lexer.AND:
+		exp.Operator = "&&"
+	case lexer.OR:
+		exp.Operator = "||"
 	}
 
-	func (p *Parser) parseStringLiteral() ast.Expression {
-		lit := &ast.StringLiteral{Token: p.curToken}
-		if p.curToken.Literal != nil {
-			if val, ok := p.curToken.Literal.(string); ok {
-				lit.Value = val
-			} else {
-				lit.Value = ""
-			}
-		} else {
-			lit.Value = ""
-		}
-		p.nextToken() // consume the STRING token
-		return lit
+	precedence := p.currentPrecedence()
+	if p.curToken.Type == lexer.EQUAL {
+		precedence = LOWEST // la asignación es asociativa por la derecha
 	}
-
-	func (p *Parser) parseBooleanLiteral() ast.Expression {
-		expr := &ast.BooleanLiteral{
-			Token: p.curToken,
-			Value: p.curToken.Type == lexer.TRUE,
-		}
-		p.nextToken() // consume the TRUE/FALSE token
-		return expr
+	p.nextToken() // consume operator
+	exp.Right = p.parseExpression(precedence)
+	if exp.Right == nil {
+		return nil
 	}
 
-	func (p *Parser) parseNullLiteral() ast.Expression {
-		expr := &ast.NullLiteral{Token: p.curToken}
-		p.nextToken() // consume the NIL token
-		return expr
-	}
+	return exp
+}
 
-	func (p *Parser) parsePrefixExpression() ast.Expression {
-		token := p.curToken
-		operator := p.curToken.Lexeme
-		p.nextToken() // consume the operator
-		right := p.parseExpression(PREFIX)
-		return &ast.PrefixExpression{
-			Token:    token,
-			Operator: operator,
-			Right:    right,
-		}
+// parseGroupedExpression analiza (expr). También reconoce la lista de
+// parámetros de una lambda: () => ..., (a, b) => ... y (a: Int) => ...; el
+// caso (a) => ... lo resuelve parseArrowFunction.
+func (p *Parser) parseGroupedExpression() ast.Expression {
+	p.skipPeekNewlines()
+	if p.peekTokenIs(lexer.RIGHT_PAREN) {
+		p.nextToken() // )
+		return p.parseArrowParameters(nil)
 	}
 
-	func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
-		exp := &ast.InfixExpression{
-			Token:    p.curToken,
-			Left:     left,
-			Operator: p.curToken.Lexeme,
-		}
-
-		precedence := p.currentPrecedence()
-		p.nextToken() // consume operator
-		exp.Right = p.parseExpression(precedence)
-
-		return exp
+	p.nextToken()
+	exp := p.parseExpression(LOWEST)
+	p.skipPeekNewlines()
+	if ident, ok := exp.(*ast.Identifier); ok && (p.peekTokenIs(lexer.COMMA) || p.peekTokenIs(lexer.COLON)) {
+		return p.parseArrowParameters(ident)
 	}
-
-	func (p *Parser) parseGroupedExpression() ast.Expression {
-		p.nextToken()
-		exp := p.parseExpression(LOWEST)
-		p.skipNewlines()
-		p.expectPeek(lexer.RIGHT_PAREN)
-		p.nextToken() // consume )
-		return exp
+	if !p.expectPeek(lexer.RIGHT_PAREN) {
+		return nil
 	}
+	return exp
+}
 
-	func (p *Parser) parseCallExpression(left ast.Expression) ast.Expression {
-		exp := &ast.CallExpression{
-			Token:     p.curToken,
-			Function:  left,
-			Arguments: []ast.Expression{},
-		}
-
-		// Parse arguments
-		if !p.peekTokenIs(lexer.RIGHT_PAREN) {
-			p.nextToken() // move to first argument
-			exp.Arguments = append(exp.Arguments, p.parseExpression(LOWEST))
-
-			for p.peekTokenIs(lexer.COMMA) {
-				p.nextToken() // consume ,
-				p.nextToken() // move to next argument
-				exp.Arguments = append(exp.Arguments, p.parseExpression(LOWEST))
+// parseArrowParameters termina de analizar la lista de parámetros de una
+// lambda cuyo primer parámetro, si lo hay, es first, y después la lambda
+// completa. Se invoca con curToken sobre first o sobre el ')' de una lista
+// vacía.
+func (p *Parser) parseArrowParameters(first *ast.Identifier) ast.Expression {
+	params := []*ast.Identifier{}
+	if first != nil {
+		params = append(params, first)
+		for {
+			if p.peekTokenIs(lexer.COLON) {
+				p.nextToken() // :
+				typeName, ok := p.parseTypeAnnotation()
+				if !ok {
+					return nil
+				}
+				params[len(params)-1].Type = typeName
 			}
+			p.skipPeekNewlines()
+			if !p.peekTokenIs(lexer.COMMA) {
+				break
+			}
+			p.nextToken() // ,
+			p.skipPeekNewlines()
+			if !p.expectPeek(lexer.IDENTIFIER) {
+				return nil
+			}
+			params = append(params, &ast.Identifier{Token: p.curToken, Value: p.curToken.Lexeme})
 		}
-
-		p.skipNewlines()
 		if !p.expectPeek(lexer.RIGHT_PAREN) {
 			return nil
 		}
-		p.nextToken() // consume )
-
-		return exp
 	}
 
-	func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
-		exp := &ast.IndexExpression{
-			Token: p.curToken,
-			Left:  left,
-		}
-		p.nextToken() // consume [
-		exp.Index = p.parseExpression(LOWEST)
-		p.skipNewlines()
-		if !p.expectPeek(lexer.RIGHT_BRACKET) {
-			return nil
-		}
-		p.nextToken() // consume ]
-		return exp
+	if !p.peekTokenIs(lexer.ARROW) {
+		p.addError(CodeExpectedToken, "expected '=>' after lambda parameters")
+		return nil
 	}
+	p.nextToken() // =>
+	return p.parseArrowBody(params)
+}
 
-	func (p *Parser) parseDotExpression(left ast.Expression) ast.Expression {
-		if !p.expectPeek(lexer.IDENTIFIER) {
-			return nil
-		}
+// parseArrowFunction analiza x => ... cuando el parámetro ya se parseó como
+// expresión. Se invoca con curToken sobre '=>'.
+func (p *Parser) parseArrowFunction(left ast.Expression) ast.Expression {
+	param, ok := left.(*ast.Identifier)
+	if !ok {
+		p.addError(CodeInvalidSyntax, fmt.Sprintf("invalid lambda parameter: %s", left.String()))
+		return nil
+	}
+	return p.parseArrowBody([]*ast.Identifier{param})
+}
 
-		prop := &ast.Identifier{Token: p.curToken, Value: p.curToken.Lexeme}
+// parseArrowBody analiza el cuerpo de una lambda: un bloque entre llaves o
+// una expresión, que se convierte en el valor de retorno. Se invoca con
+// curToken sobre '=>'.
+func (p *Parser) parseArrowBody(params []*ast.Identifier) ast.Expression {
+	lit := &ast.FunctionLiteral{Token: p.curToken, Parameters: params}
 
-		memberExp := &ast.MemberExpression{
-			Token:    p.curToken,
-			Object:   left,
-			Property: prop,
+	if p.peekTokenIs(lexer.LEFT_BRACE) {
+		p.nextToken()
+		lit.Body = p.parseBlockStatement()
+		if lit.Body == nil {
+			return nil
 		}
+		return lit
+	}
 
-		// Si hay paréntesis, es una llamada a método como show.log()
-		if p.peekTokenIs(lexer.LEFT_PAREN) {
-			p.nextToken() // consumir '('
-			return p.parseCallExpression(memberExp)
-		}
+	p.nextToken()
+	value := p.parseExpression(LOWEST)
+	if value == nil {
+		return nil
+	}
+	// El 'return' implícito se ubica al inicio de la expresión
+	span := ast.SpanOf(value)
+	ret := &ast.ReturnStatement{
+		Token: lexer.Token{
+			Type:      lexer.RETURN,
+			Lexeme:    "return",
+			StartLine: span.StartLine,
+			StartCol:  span.StartCol,
+			EndLine:   span.StartLine,
+			EndCol:    span.StartCol,
+		},
+		ReturnValue: value,
+	}
+	lit.Body = &ast.BlockStatement{Token: lit.Token, Statements: []ast.Statement{ret}}
+	return lit
+}
 
-		return memberExp
+func (p *Parser) parseCallExpression(left ast.Expression) ast.Expression {
+	exp := &ast.CallExpression{
+		Token:    p.curToken,
+		Function: left,
 	}
 
-	func (p *Parser) parseExpression(precedence int) ast.Expression {
-		// MEJORAR: Saltar múltiples NEWLINES
-		for p.curToken.Type == lexer.NEWLINE {
-			p.nextToken()
-		}
+	exp.Arguments = p.parseExpressionList(lexer.RIGHT_PAREN)
+	if exp.Arguments == nil {
+		return nil
+	}
+	exp.RParen = p.curToken
 
-		prefix := p.prefixParseFns[p.curToken.Type]
-		if prefix == nil {
-			// No tratar NEWLINE como error
-			if p.curToken.Type == lexer.NEWLINE {
-				return nil
-			}
-			if p.curToken.Type == lexer.RIGHT_BRACKET {
-				return nil
-			}
-			p.noPrefixParseFnError(p.curToken.Type)
-			return nil
-		}
+	return exp
+}
 
-		leftExp := prefix()
-		if leftExp == nil {
-			return nil
-		}
+func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
+	exp := &ast.IndexExpression{
+		Token: p.curToken,
+		Left:  left,
+	}
+	p.nextToken() // consume [
+	exp.Index = p.parseExpression(LOWEST)
+	p.skipPeekNewlines()
+	if !p.expectPeek(lexer.RIGHT_BRACKET) {
+		return nil
+	}
+	return exp
+}
 
-		iterations := 0
-		for precedence < p.peekPrecedence() && iterations < 50 {
-			iterations++
+func (p *Parser) parseDotExpression(left ast.Expression) ast.Expression {
+	if !p.expectPeek(lexer.IDENTIFIER) {
+		return nil
+	}
 
-			infix := p.infixParseFns[p.peekToken.Type]
-			if infix == nil {
-				return leftExp
-			}
+	prop := &ast.Identifier{Token: p.curToken, Value: p.curToken.Lexeme}
 
-			p.nextToken()
-			newLeftExp := infix(leftExp)
+	return &ast.MemberExpression{
+		Token:    p.curToken,
+		Object:   left,
+		Property: prop,
+	}
+}
 
-			if newLeftExp == nil {
-				return leftExp
-			}
+func (p *Parser) parseExpression(precedence int) ast.Expression {
+	if err := p.enterRecursion(); err != nil {
+		p.addError(CodeTooDeep, err.Error())
+		p.exitRecursion()
+		return nil
+	}
+	defer p.exitRecursion()
 
-			leftExp = newLeftExp
-		}
+	prefix := p.prefixParseFns[p.curToken.Type]
+	if prefix == nil {
+		p.noPrefixParseFnError(p.curToken.Type)
+		return nil
+	}
 
-		return leftExp
+	leftExp := prefix()
+	if leftExp == nil {
+		return nil
 	}
 
-	func (p *Parser) currentPrecedence() int {
-		if p, ok := precedences[p.curToken.Type]; ok {
-			return p
+	for !p.peekTokenIs(lexer.SEMICOLON) && precedence < p.peekPrecedence() {
+		infix := p.infixParseFns[p.peekToken.Type]
+		if infix == nil {
+			return leftExp
 		}
-		return LOWEST
-	}
 
-	func (p *Parser) peekPrecedence() int {
-		if p, ok := precedences[p.peekToken.Type]; ok {
-			return p
+		p.nextToken()
+		leftExp = infix(leftExp)
+		if leftExp == nil {
+			return nil
 		}
-		return LOWEST
 	}
 
-	func (p *Parser) parseBlockExpression() ast.Expression {
-		return &ast.BlockExpression{Token: p.curToken}
+	return leftExp
+}
+
+func (p *Parser) currentPrecedence() int {
+	if p, ok := precedences[p.curToken.Type]; ok {
+		return p
 	}
+	return LOWEST
+}
 
-	func (p *Parser) parseBlockStatement() *ast.BlockStatement {
-		block := &ast.BlockStatement{Token: p.curToken, Statements: []ast.Statement{}}
+func (p *Parser) peekPrecedence() int {
+	if p, ok := precedences[p.peekToken.Type]; ok {
+		return p
+	}
+	return LOWEST
+}
 
-		if !p.curTokenIs(lexer.LEFT_BRACE) {
-			p.addError("expected '{' to start block")
-			return nil
-		}
-		p.nextToken() // consume {
+func (p *Parser) parseBlockExpression() ast.Expression {
+	return &ast.BlockExpression{Token: p.curToken, Block: p.parseBlockStatement()}
+}
 
-		iterations := 0
-		for !p.curTokenIs(lexer.RIGHT_BRACE) && !p.curTokenIs(lexer.EOF) {
-			iterations++
-			if iterations > 100 {
-				p.addError("too many iterations in parseBlockStatement")
-				break
-			}
+// parseBlockStatement analiza un bloque. Se invoca con curToken sobre '{' y
+// termina sobre el '}' de cierre.
+func (p *Parser) parseBlockStatement() *ast.BlockStatement {
+	block := &ast.BlockStatement{Token: p.curToken, Statements: []ast.Statement{}}
 
-			// Skip newlines
-			if p.curTokenIs(lexer.NEWLINE) {
-				p.nextToken()
-				continue
-			}
+	if !p.curTokenIs(lexer.LEFT_BRACE) {
+		p.addError(CodeExpectedToken, "expected '{' to start block")
+		return nil
+	}
+	p.nextToken() // consume {
 
-			// CRÍTICO: Detener si encontramos tokens que no deberían estar aquí
-			if p.curToken.Type == lexer.RIGHT_PAREN ||
-			   p.curToken.Type == lexer.ELSE ||
-			   p.curToken.Type == lexer.CATCH {
-				// Estos tokens indican fin de bloque o tokens mal posicionados
-				break
-			}
+	block.Statements = p.parseStatements(nil, lexer.RIGHT_BRACE)
+	if p.curTokenIs(lexer.RIGHT_BRACE) {
+		block.RBrace = p.curToken
+	} else {
+		p.addError(CodeUnclosed, "expected '}' to close block")
+	}
 
-			currentToken := p.curToken.Type
-			stmt := p.parseStatement()
+	return block
+}
 
-			if stmt != nil {
-				block.Statements = append(block.Statements, stmt)
-			}
+// parseExpressionList analiza expresiones separadas por comas hasta el token
+// de cierre. Se invoca con curToken sobre el token de apertura y termina sobre
+// el de cierre. Devuelve nil si hubo un error.
+func (p *Parser) parseExpressionList(end lexer.TokenType) []ast.Expression {
+	args := []ast.Expression{}
 
-			// Protección contra bucles infinitos
-			if p.curToken.Type == currentToken {
-				p.nextToken()
-			}
+	p.skipPeekNewlines()
+	if p.peekTokenIs(end) {
+		p.nextToken() // consume closing token
+		return args
+	}
+
+	for {
+		p.nextToken()
+		p.skipNewlines()
+		expr := p.parseExpression(LOWEST)
+		if expr == nil {
+			return nil
 		}
+		args = append(args, expr)
 
-		if p.curTokenIs(lexer.RIGHT_BRACE) {
-			p.nextToken() // consume }
+		p.skipPeekNewlines()
+		if !p.peekTokenIs(lexer.COMMA) {
+			break
+		}
+		p.nextToken() // consume comma
+		p.skipPeekNewlines()
+		if p.peekTokenIs(end) {
+			// Coma trailing
+			break
 		}
+	}
 
-		return block
+	if !p.expectPeek(end) {
+		return nil
 	}
 
-	// ARREGLAR parseExpressionList completamente para COMMA y RIGHT_BRACKET
-		func (p *Parser) parseExpressionList(end lexer.TokenType) []ast.Expression {
-			args := []ast.Expression{}
-	
-			// Lista vacía - si el siguiente token es el de cierre
-			if p.peekTokenIs(end) {
-				p.nextToken() // consume closing token
-				return args
-			}
-	
-			// Avanzar al primer elemento
-			p.nextToken()
-	
-			// Parsear primer elemento si no es el token de cierre
-			if !p.curTokenIs(end) {
-				expr := p.parseExpression(LOWEST)
-				if expr != nil {
-					args = append(args, expr)
-				}
-	
-				// Parsear elementos adicionales separados por comas
-				for p.peekTokenIs(lexer.COMMA) {
-					p.nextToken() // consume comma
-					p.nextToken() // move to next expression
-	
-					if p.curTokenIs(end) {
-						// Coma trailing, terminar
-						break
-					}
-	
-					expr := p.parseExpression(LOWEST)
-					if expr != nil {
-						args = append(args, expr)
-					}
-				}
-			}
-	
-			if !p.expectPeek(end) {
-				return nil
-			}
-			p.nextToken() // consume end
-	
-			return args
-		}
+	return args
+}
 
-// MEJORAR parseListLiteral para manejar arrays correctamente
+// parseListLiteral analiza un literal de lista [a, b, c].
 func (p *Parser) parseListLiteral() ast.Expression {
-	lit := &ast.ListLiteral{Token: p.curToken, Elements: []ast.Expression{}}
+	lit := &ast.ListLiteral{Token: p.curToken}
 
-	// Array vacío []
-	if p.peekTokenIs(lexer.RIGHT_BRACKET) {
-		p.nextToken() // consume ]
-		return lit
-	}
-
-	// Usar parseExpressionList mejorado
 	lit.Elements = p.parseExpressionList(lexer.RIGHT_BRACKET)
-	p.nextToken() // consume ]
+	if lit.Elements == nil {
+		return nil
+	}
 	return lit
 }
 
-// parseIfStatement con manejo de paréntesis mejorado:
+// expectBlockStart admite newlines antes de '{' y deja curToken sobre la llave.
+func (p *Parser) expectBlockStart(context string) bool {
+	p.skipPeekNewlines()
+	if !p.peekTokenIs(lexer.LEFT_BRACE) {
+		p.addErrorAt(p.peekToken, CodeExpectedToken, fmt.Sprintf("expected '{' after %s", context))
+		return false
+	}
+	p.nextToken()
+	return true
+}
+
+// parseIfStatement analiza if/elif/else. También se usa para 'elif' y 'else if'.
 func (p *Parser) parseIfStatement() *ast.IfStatement {
 	stmt := &ast.IfStatement{Token: p.curToken}
 
-	p.nextToken() // consume IF
-
-	// Detectar si hay paréntesis
-	hasParens := p.curTokenIs(lexer.LEFT_PAREN)
-	if hasParens {
-		p.nextToken() // consume (
-	}
+	p.nextToken() // consume IF / ELIF
 
+	// Los paréntesis son opcionales: (cond) se parsea como expresión agrupada
 	stmt.Condition = p.parseExpression(LOWEST)
-
-	if hasParens {
-		p.skipNewlines()
-		if !p.expectPeek(lexer.RIGHT_PAREN) {
-			return nil
-		}
+	if stmt.Condition == nil {
+		return nil
 	}
 
-	// Skip newlines
-	p.skipNewlines()
-
-	if !p.curTokenIs(lexer.LEFT_BRACE) {
-		p.addError("expected '{' after if condition")
+	if !p.expectBlockStart("if condition") {
 		return nil
 	}
-
-	p.nextToken() // consume {
 	stmt.Consequence = p.parseBlockStatement()
 
-	// Manejar else o elif
-	if p.peekTokenIs(lexer.ELSE) || p.peekTokenIs(lexer.ELIF) {
-		p.nextToken() // consume current token (likely })
-		p.nextToken() // consume ELSE/ELIF
-		if p.curTokenIs(lexer.IF) {
-			p.nextToken() // consume IF si es else if
+	// Buscar else / elif, posiblemente en la línea siguiente
+	p.skipPeekNewlines()
+
+	switch {
+	case p.peekTokenIs(lexer.ELIF):
+		p.nextToken()
+		elifStmt := p.parseIfStatement()
+		if elifStmt == nil {
+			return nil
 		}
-		if p.peekTokenIs(lexer.LEFT_BRACE) {
-			// else normal
-			if !p.expectPeek(lexer.LEFT_BRACE) {
-				return nil
-			}
-			stmt.Alternative = p.parseBlockStatement()
-		} else {
-			// else if o elif - crear nuevo if statement
-			// Crear un nuevo if statement para elif
-			elifStmt := &ast.IfStatement{Token: p.curToken}
-			elifStmt.Condition = p.parseExpression(LOWEST)
-			// Skip newlines
-			p.skipNewlines()
-			if !p.curTokenIs(lexer.LEFT_BRACE) {
-				p.addError("expected '{' after elif condition")
+		stmt.Alternative = &ast.BlockStatement{
+			Token:      elifStmt.Token,
+			Statements: []ast.Statement{elifStmt},
+		}
+	case p.peekTokenIs(lexer.ELSE):
+		p.nextToken()
+		if p.peekTokenIs(lexer.IF) {
+			p.nextToken()
+			elifStmt := p.parseIfStatement()
+			if elifStmt == nil {
 				return nil
 			}
-			p.nextToken() // consume {
-			elifStmt.Consequence = p.parseBlockStatement()
 			stmt.Alternative = &ast.BlockStatement{
-				Token: p.curToken,
+				Token:      elifStmt.Token,
 				Statements: []ast.Statement{elifStmt},
 			}
+		} else {
+			if !p.expectBlockStart("else") {
+				return nil
+			}
+			stmt.Alternative = p.parseBlockStatement()
 		}
 	}
 
 	return stmt
 }
 
-	// parseForStatement analiza una sentencia 'for in'
-	func (p *Parser) parseForStatement() ast.Statement {
-		token := p.curToken // FOR token
-	
-		// Determinar tipo de for loop
-		if p.peekTokenIs(lexer.IDENTIFIER) {
-			// Podría ser for-in: for x in array
-			p.nextToken() // consume FOR
-			identifier := p.curToken
-	
-			if p.peekTokenIs(lexer.IN) {
-				return p.parseForInStatement(token, identifier)
-			} else {
-				// for tradicional: for i = 0; i < 10; i++
-				return p.parseTraditionalForStatement(token)
-			}
-		}
-	
-		// for con paréntesis: for (i = 0; i < 10; i++)
-		return p.parseTraditionalForStatement(token)
-	}
-	
-	func (p *Parser) parseForInStatement(forToken lexer.Token, identifier lexer.Token) *ast.ForInStatement {
-		stmt := &ast.ForInStatement{Token: forToken}
-		stmt.Identifier = &ast.Identifier{Token: identifier, Value: identifier.Lexeme}
-	
-		if !p.expectPeek(lexer.IN) {
-			return nil
-		}
-	
+// parseForStatement analiza una sentencia 'for in'
+func (p *Parser) parseForStatement() ast.Statement {
+	token := p.curToken // FOR token
+
+	if p.peekTokenIs(lexer.IDENTIFIER) {
 		p.nextToken()
-		stmt.Iterable = p.parseExpression(LOWEST)
-	
-		// Skip newlines
-		p.skipNewlines()
+		identifier := p.curToken
 
-		if !p.curTokenIs(lexer.LEFT_BRACE) {
-			p.addError("expected '{' after for in")
-			return nil
+		if p.peekTokenIs(lexer.IN) {
+			return nilIfEmpty(p.parseForInStatement(token, identifier))
 		}
+	}
 
-		p.nextToken() // consume {
-		stmt.Body = p.parseBlockStatement()
-		return stmt
-	}
-	
-	func (p *Parser) parseTraditionalForStatement(forToken lexer.Token) ast.Statement {
-		// Por ahora, devolver un statement vacío
-		// TODO: Implementar for tradicional
-		return &ast.ExpressionStatement{
-			Token: forToken,
-			Expression: &ast.Identifier{Token: forToken, Value: "FOR_LOOP"},
-		}
+	return p.parseTraditionalForStatement(token)
+}
+
+func (p *Parser) parseForInStatement(forToken lexer.Token, identifier lexer.Token) *ast.ForInStatement {
+	stmt := &ast.ForInStatement{Token: forToken}
+	stmt.Identifier = &ast.Identifier{Token: identifier, Value: identifier.Lexeme}
+
+	if !p.expectPeek(lexer.IN) {
+		return nil
+	}
+
+	p.nextToken()
+	stmt.Iterable = p.parseExpression(LOWEST)
+	if stmt.Iterable == nil {
+		return nil
+	}
+
+	if !p.expectBlockStart("for in") {
+		return nil
+	}
+	stmt.Body = p.parseBlockStatement()
+	return stmt
+}
+
+func (p *Parser) parseTraditionalForStatement(forToken lexer.Token) ast.Statement {
+	// TODO: Implementar for tradicional
+	p.addError(CodeInvalidSyntax, "only 'for x in iterable' loops are supported")
+	return nil
+}
+
+// parseClassStatement analiza una declaración de clase
+func (p *Parser) parseClassStatement() *ast.ClassStatement {
+	stmt := &ast.ClassStatement{Token: p.curToken}
+
+	if !p.expectPeek(lexer.IDENTIFIER) {
+		return nil
 	}
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Lexeme}
 
-	// parseClassStatement analiza una declaración de clase
-	func (p *Parser) parseClassStatement() *ast.ClassStatement {
-		stmt := &ast.ClassStatement{Token: p.curToken}
-	
+	// Herencia opcional: class Dog extends Animal
+	if p.peekTokenIs(lexer.EXTENDS) {
+		p.nextToken() // extends
 		if !p.expectPeek(lexer.IDENTIFIER) {
 			return nil
 		}
-		stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Lexeme}
-	
-		// CRÍTICO: Avanzar después del nombre de la clase
-		p.nextToken()
-	
-		// Skip newlines antes de {
-		for p.curTokenIs(lexer.NEWLINE) {
-			p.nextToken()
-		}
-	
-		// ARREGLO: Verificar que estamos en LEFT_BRACE antes de continuar
-		if !p.curTokenIs(lexer.LEFT_BRACE) {
-			p.addError(fmt.Sprintf("expected '{' after class name, got %s", p.curToken.Type))
-			return nil
-		}
+		stmt.SuperClass = &ast.Identifier{Token: p.curToken, Value: p.curToken.Lexeme}
+	}
+
+	if !p.expectBlockStart("class name") {
+		return nil
+	}
+	p.nextToken() // consume {
 
-		// Parse class body
-		p.nextToken() // consume {
-	
-		for p.curToken.Type != lexer.RIGHT_BRACE && p.curToken.Type != lexer.EOF {
-			if p.curTokenIs(lexer.NEWLINE) {
-				p.nextToken()
-				continue
+	for !p.curTokenIs(lexer.RIGHT_BRACE) && !p.curTokenIs(lexer.EOF) {
+		start, braces, errors := p.curToken, p.braces, len(p.diagnostics)
+		switch p.curToken.Type {
+		case lexer.NEWLINE, lexer.SEMICOLON:
+			// separadores entre miembros
+		case lexer.VAR:
+			attr := p.parseVarStatement()
+			if attr != nil {
+				stmt.Attributes = append(stmt.Attributes, attr)
 			}
-	
-			switch p.curToken.Type {
-			case lexer.VAR:
-				attr := p.parseVarStatement()
-				if attr != nil {
-					if stmt.Attributes == nil {
-						stmt.Attributes = []*ast.VarStatement{}
-					}
-					stmt.Attributes = append(stmt.Attributes, attr)
-				}
-			case lexer.FUNC:
-				method := p.parseFunctionStatement()
-				if method != nil {
-					if stmt.Methods == nil {
-						stmt.Methods = []*ast.FuncStatement{}
-					}
-					stmt.Methods = append(stmt.Methods, method)
-					if method.Name != nil && method.Name.Value == "init" {
-						stmt.InitMethod = method
-					}
+		case lexer.FUNC, lexer.ASYNC:
+			var method *ast.FuncStatement
+			if p.curTokenIs(lexer.ASYNC) {
+				method = p.parseAsyncFuncStatement()
+			} else {
+				method = p.parseFuncStatement()
+			}
+			if method != nil {
+				stmt.Methods = append(stmt.Methods, method)
+				if method.Name.Value == "init" {
+					stmt.InitMethod = method
 				}
-			default:
-				// Skip tokens desconocidos sin error
-				p.nextToken()
 			}
+		default:
+			p.addError(CodeUnexpectedToken, fmt.Sprintf("unexpected %s in class body", p.curToken.Type))
 		}
-	
-		if p.curTokenIs(lexer.RIGHT_BRACE) {
-			p.nextToken() // consume }
+		if !p.recover(start, braces, errors) {
+			p.nextToken()
 		}
-	
-		return stmt
 	}
 
-	func (p *Parser) parseTryStatement() *ast.TryStatement {
-		stmt := &ast.TryStatement{Token: p.curToken}
+	if !p.curTokenIs(lexer.RIGHT_BRACE) {
+		p.addError(CodeUnclosed, "expected '}' to close class body")
+		return nil
+	}
+	stmt.RBrace = p.curToken
 
-		// Skip newlines after try
-		p.skipNewlines()
-	
-		if !p.curTokenIs(lexer.LEFT_BRACE) {
-			p.addError("expected '{' after try")
-			return nil
-		}
-	
-		p.nextToken() // consume {
-		stmt.TryBlock = p.parseBlockStatement()
+	return stmt
+}
 
-		// Skip newlines before catch
-		for p.curTokenIs(lexer.NEWLINE) {
-			p.nextToken()
-		}
+// parseTryStatement analiza try { } catch (e) { } finally { }.
+// Tanto catch como finally son opcionales, pero al menos uno debe estar presente.
+func (p *Parser) parseTryStatement() *ast.TryStatement {
+	stmt := &ast.TryStatement{Token: p.curToken}
 
-		// CRÍTICO: Verificar que realmente es CATCH
-		if p.curTokenIs(lexer.CATCH) {
-			stmt.CatchClause = &ast.CatchClause{Token: p.curToken}
-
-			// Manejar parámetro opcional
-			if p.peekTokenIs(lexer.LEFT_PAREN) {
-				p.nextToken() // consume CATCH
-				p.nextToken() // consume (
-				if p.curTokenIs(lexer.IDENTIFIER) {
-					stmt.CatchClause.Parameter = &ast.Identifier{
-						Token: p.curToken,
-						Value: p.curToken.Lexeme,
-					}
-				}
-				if !p.expectPeek(lexer.RIGHT_PAREN) {
-					return nil
-				}
-			} else if p.peekTokenIs(lexer.IDENTIFIER) {
-				p.nextToken() // consume CATCH
-				stmt.CatchClause.Parameter = &ast.Identifier{
-					Token: p.curToken,
-					Value: p.curToken.Lexeme,
-				}
-			} else {
-				p.nextToken() // solo consume CATCH
-			}
+	if !p.expectBlockStart("try") {
+		return nil
+	}
+	stmt.TryBlock = p.parseBlockStatement()
 
-			// Skip newlines before {
-			p.skipNewlines()
+	p.skipPeekNewlines()
+	if p.peekTokenIs(lexer.CATCH) {
+		p.nextToken()
+		stmt.CatchClause = &ast.CatchClause{Token: p.curToken}
 
-			if !p.curTokenIs(lexer.LEFT_BRACE) {
-				p.addError("expected '{' after catch")
+		// Parámetro opcional: catch (e) o catch e
+		if p.peekTokenIs(lexer.LEFT_PAREN) {
+			p.nextToken() // (
+			if !p.expectPeek(lexer.IDENTIFIER) {
 				return nil
 			}
+			stmt.CatchClause.Parameter = &ast.Identifier{Token: p.curToken, Value: p.curToken.Lexeme}
+			if !p.expectPeek(lexer.RIGHT_PAREN) {
+				return nil
+			}
+		} else if p.peekTokenIs(lexer.IDENTIFIER) {
+			p.nextToken()
+			stmt.CatchClause.Parameter = &ast.Identifier{Token: p.curToken, Value: p.curToken.Lexeme}
+		}
 
-			p.nextToken() // consume {
+		if !p.expectBlockStart("catch") {
+			return nil
+		}
+		stmt.CatchClause.CatchBlock = p.parseBlockStatement()
+		p.skipPeekNewlines()
+	}
 
-			stmt.CatchClause.CatchBlock = p.parseBlockStatement()
+	if p.peekTokenIs(lexer.FINALLY) {
+		p.nextToken()
+		if !p.expectBlockStart("finally") {
+			return nil
 		}
+		stmt.FinallyBlock = p.parseBlockStatement()
+	}
 
-		return stmt
+	if stmt.CatchClause == nil && stmt.FinallyBlock == nil {
+		p.addError(CodeExpectedToken, "expected 'catch' or 'finally' after try block")
+		return nil
 	}
 
-	// parseThrowStatement analiza una sentencia 'throw'
-	func (p *Parser) parseThrowStatement() *ast.ThrowStatement {
-		stmt := &ast.ThrowStatement{Token: p.curToken}
+	return stmt
+}
 
-		p.nextToken() // consume THROW
-		stmt.Exception = p.parseExpression(LOWEST)
+// parseThrowStatement analiza una sentencia 'throw'
+func (p *Parser) parseThrowStatement() *ast.ThrowStatement {
+	stmt := &ast.ThrowStatement{Token: p.curToken}
 
-		return stmt
+	p.nextToken() // consume THROW
+	stmt.Exception = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(lexer.SEMICOLON) {
+		p.nextToken()
 	}
 
-	// parseThisExpression analiza una expresión 'this'
-	func (p *Parser) parseThisExpression() ast.Expression {
-		return &ast.ThisExpression{Token: p.curToken}
+	return stmt
+}
+
+// parseThisExpression analiza una expresión 'this'
+func (p *Parser) parseThisExpression() ast.Expression {
+	return &ast.ThisExpression{Token: p.curToken}
+}
+
+// parseSuperExpression analiza una expresión 'super'
+func (p *Parser) parseSuperExpression() ast.Expression {
+	return &ast.SuperExpression{Token: p.curToken}
+}
+
+// parseSpawnExpression analiza 'spawn f(x)'. El operando tiene que ser una
+// llamada, que se ejecutará en una tarea concurrente.
+func (p *Parser) parseSpawnExpression() ast.Expression {
+	exp := &ast.SpawnExpression{Token: p.curToken}
+	p.nextToken()
+
+	call, ok := p.parseExpression(PREFIX).(*ast.CallExpression)
+	if !ok {
+		p.addError(CodeInvalidSyntax, "spawn expects a function call")
+		return nil
 	}
-	
-	func (p *Parser) parseSuperExpression() ast.Expression {
-		return &ast.Identifier{Token: p.curToken, Value: "super"}
+	exp.Call = call
+	return exp
+}
+
+// parseAwaitExpression analiza 'await expr'.
+func (p *Parser) parseAwaitExpression() ast.Expression {
+	exp := &ast.AwaitExpression{Token: p.curToken}
+	p.nextToken()
+
+	exp.Value = p.parseExpression(PREFIX)
+	if exp.Value == nil {
+		return nil
 	}
-	
-	func (p *Parser) parseFunctionLiteral() ast.Expression {
-		// Para funciones anónimas: func(a, b) { return a + b }
-		return &ast.Identifier{Token: p.curToken, Value: "FUNCTION_LITERAL"}
+	return exp
+}
+
+// parseTryBuiltin analiza el nombre del built-in try(fn, catchFn) en una
+// expresión; la sentencia try { } catch { } la analiza parseTryStatement.
+func (p *Parser) parseTryBuiltin() ast.Expression {
+	if !p.peekTokenIs(lexer.LEFT_PAREN) {
+		p.addError(CodeUnexpectedToken, "try statement not allowed in expression")
+		return nil
 	}
-	
-	func (p *Parser) parseHashLiteral() ast.Expression {
-		hash := &ast.HashLiteral{Token: p.curToken, Pairs: make(map[ast.Expression]ast.Expression)}
+	return &ast.Identifier{Token: p.curToken, Value: p.curToken.Lexeme}
+}
 
-		// Hash vacío {}
-		if p.peekTokenIs(lexer.RIGHT_BRACE) {
-			p.nextToken() // consume }
-			return hash
-		}
+// parseFunctionLiteral analiza una función anónima: func(a, b) { return a + b }.
+func (p *Parser) parseFunctionLiteral() ast.Expression {
+	lit := &ast.FunctionLiteral{Token: p.curToken}
 
-		p.nextToken() // consume {
+	if !p.expectPeek(lexer.LEFT_PAREN) {
+		return nil
+	}
 
-		for !p.curTokenIs(lexer.RIGHT_BRACE) {
-			key := p.parseExpression(LOWEST)
-			if key == nil {
-				return nil
-			}
+	lit.Parameters = p.parseFunctionParameters()
+	if lit.Parameters == nil {
+		return nil
+	}
 
-			if !p.expectPeek(lexer.COLON) {
-				return nil
-			}
+	returnType, body := p.parseFunctionBody()
+	if body == nil {
+		return nil
+	}
+	lit.ReturnType = returnType
+	lit.Body = body
+	return lit
+}
 
-			p.nextToken() // consume :
-			value := p.parseExpression(LOWEST)
-			if value == nil {
-				return nil
-			}
+// parseHashLiteral analiza un literal {clave: valor, ...}.
+func (p *Parser) parseHashLiteral() ast.Expression {
+	hash := &ast.HashLiteral{Token: p.curToken, Pairs: make(map[ast.Expression]ast.Expression)}
 
-			hash.Pairs[key] = value
+	for {
+		p.skipPeekNewlines()
+		if p.peekTokenIs(lexer.RIGHT_BRACE) {
+			break
+		}
+		p.nextToken()
 
-			if p.peekTokenIs(lexer.COMMA) {
-				p.nextToken() // consume ,
-			} else if !p.peekTokenIs(lexer.RIGHT_BRACE) {
-				p.addError("expected ',' or '}' in hash literal")
-				return nil
-			}
+		key := p.parseExpression(LOWEST)
+		if key == nil {
+			return nil
 		}
 
-		if !p.expectPeek(lexer.RIGHT_BRACE) {
+		if !p.expectPeek(lexer.COLON) {
+			return nil
+		}
+
+		p.nextToken() // consume :
+		value := p.parseExpression(LOWEST)
+		if value == nil {
 			return nil
 		}
-		p.nextToken() // consume }
 
-		return hash
+		hash.Pairs[key] = value
+
+		p.skipPeekNewlines()
+		if !p.peekTokenIs(lexer.COMMA) {
+			break
+		}
+		p.nextToken() // consume ,
+	}
+
+	if !p.peekTokenIs(lexer.RIGHT_BRACE) {
+		p.addErrorAt(p.peekToken, CodeExpectedToken, fmt.Sprintf("expected ',' or '}' in hash literal, got %s", p.peekToken.Type))
+		return nil
 	}
+	p.nextToken()
 
+	return hash
+}
 
-	// Precedence constants
-	const (
-		LOWEST int = iota
-		ASSIGN
-		EQUALS
-		COMPARES
-		SUM
-		PRODUCT
-		PREFIX
-		CALL
-		INDEX
-	)
+// Precedence constants
+const (
+	LOWEST int = iota
+	ASSIGN
+	LOGICAL_OR
+	LOGICAL_AND
+	EQUALS
+	COMPARES
+	RANGE
+	SUM
+	PRODUCT
+	PREFIX
+	CALL
+	INDEX
+)
 
-// AGREGAR manejo de precedencias para nuevos operadores:
 var precedences = map[lexer.TokenType]int{
 	lexer.EQUAL:         ASSIGN,
-	lexer.OR:            EQUALS,
-	lexer.AND:           COMPARES,
+	lexer.ARROW:         ASSIGN,
+	lexer.OR:            LOGICAL_OR,
+	lexer.AND:           LOGICAL_AND,
 	lexer.EQUAL_EQUAL:   EQUALS,
 	lexer.BANG_EQUAL:    EQUALS,
 	lexer.LESS:          COMPARES,
 	lexer.LESS_EQUAL:    COMPARES,
 	lexer.GREATER:       COMPARES,
 	lexer.GREATER_EQUAL: COMPARES,
+	lexer.INSTANCEOF:    COMPARES,
+	lexer.DOT_DOT:       RANGE,
 	lexer.PLUS:          SUM,
 	lexer.MINUS:         SUM,
 	lexer.STAR:          PRODUCT,
@@ -1258,116 +1482,21 @@ var precedences = map[lexer.TokenType]int{
 	lexer.DOT:           CALL,
 }
 
-	// parseFunctionStatement analiza una declaración de función
-	func (p *Parser) parseFunctionStatement() *ast.FuncStatement {
-		stmt := &ast.FuncStatement{Token: p.curToken}
+func (p *Parser) parseWhileStatement() *ast.WhileStatement {
+	stmt := &ast.WhileStatement{Token: p.curToken}
 
-		p.nextToken() // consume FUNC
+	p.nextToken() // consume WHILE
 
-		// Parse function name
-		if !p.curTokenIs(lexer.IDENTIFIER) {
-			p.addError("expected function name after 'func'")
-			return nil
-		}
-		stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Lexeme}
-		p.nextToken() // consume function name
-
-		// Parse parameters
-		if !p.curTokenIs(lexer.LEFT_PAREN) {
-			p.addError("expected '(' after function name")
-			return nil
-		}
-		p.nextToken() // consume (
-		stmt.Parameters = []*ast.Identifier{}
-
-		for !p.curTokenIs(lexer.RIGHT_PAREN) && !p.curTokenIs(lexer.EOF) {
-			if p.curTokenIs(lexer.IDENTIFIER) {
-				param := &ast.Identifier{
-					Token: p.curToken,
-					Value: p.curToken.Lexeme,
-				}
-				stmt.Parameters = append(stmt.Parameters, param)
-				p.nextToken()
-
-				// Skip comma if present
-				if p.curTokenIs(lexer.COMMA) {
-					p.nextToken()
-				}
-			} else if p.curTokenIs(lexer.RIGHT_PAREN) {
-				// Empty parameter list, just break
-				break
-			} else {
-				p.addError("expected parameter name")
-				break
-			}
-		}
-
-		if !p.curTokenIs(lexer.RIGHT_PAREN) {
-			p.addError("expected ')' after function parameters")
-			return nil
-		}
-		p.nextToken() // consume )
-
-		// Tipo de retorno opcional
-		if p.peekTokenIs(lexer.COLON) {
-			p.nextToken() // consume :
-			if !p.curTokenIs(lexer.IDENTIFIER) {
-				p.addError("expected return type identifier, got " + string(p.curToken.Type))
-				return nil
-			} else {
-				stmt.ReturnType = p.curToken.Lexeme
-				p.nextToken()
-			}
-		}
-
-		// Ignorar NEWLINE antes de '{'
-		for p.curTokenIs(lexer.NEWLINE) {
-			p.nextToken()
-		}
-
-		// Cuerpo de la función
-		if !p.curTokenIs(lexer.LEFT_BRACE) {
-			p.addError("expected '{' to start function body")
-			return nil
-		}
-
-		stmt.Body = p.parseBlockStatement()
-		return stmt
+	// Los paréntesis son opcionales: (cond) se parsea como expresión agrupada
+	stmt.Condition = p.parseExpression(LOWEST)
+	if stmt.Condition == nil {
+		return nil
 	}
 
-	func (p *Parser) parseWhileStatement() *ast.WhileStatement {
-		stmt := &ast.WhileStatement{Token: p.curToken}
-
-		p.nextToken() // consume WHILE
-
-		// Manejar paréntesis opcionales explícitamente
-		hasParens := false
-		if p.curTokenIs(lexer.LEFT_PAREN) {
-			hasParens = true
-			p.nextToken() // consume '('
-		}
-
-		stmt.Condition = p.parseExpression(LOWEST)
-	
-		// Si había paréntesis, consumir el ')'
-		if hasParens {
-			p.skipNewlines()
-			if !p.expectPeek(lexer.RIGHT_PAREN) {
-				return nil
-			}
-		}
-
-		// Skip newlines before {
-		p.skipNewlines()
-	
-		if !p.curTokenIs(lexer.LEFT_BRACE) {
-			p.addError("expected '{' after while condition")
-			return nil
-		}
-	
-		p.nextToken() // consume {
-		stmt.Body = p.parseBlockStatement()
-
-		return stmt
+	if !p.expectBlockStart("while condition") {
+		return nil
 	}
+	stmt.Body = p.parseBlockStatement()
 
+	return stmt
+}
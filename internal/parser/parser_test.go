@@ -1,7 +1,9 @@
 package parser
 
 import (
+	"strings"
 	"testing"
+
 	"github.com/zylo-lang/zylo/internal/ast"
 	"github.com/zylo-lang/zylo/internal/lexer"
 )
@@ -226,3 +228,300 @@ func TestExpressionStatement(t *testing.T) {
 
 	testLiteralExpression(t, callExp.Arguments[0], "Hola")
 }
+
+func TestTryCatchFinallyStatement(t *testing.T) {
+	input := `
+try {
+    risky()
+} catch (e) {
+    show.log(e)
+}
+finally {
+    cleanup()
+}
+`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d",
+			len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.TryStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.TryStatement. got=%T",
+			program.Statements[0])
+	}
+
+	if stmt.CatchClause == nil || stmt.CatchClause.Parameter == nil {
+		t.Fatalf("catch clause or parameter missing")
+	}
+	if stmt.CatchClause.Parameter.Value != "e" {
+		t.Errorf("catch parameter is not 'e'. got=%s", stmt.CatchClause.Parameter.Value)
+	}
+	if stmt.FinallyBlock == nil || len(stmt.FinallyBlock.Statements) != 1 {
+		t.Errorf("finally block not parsed correctly. got=%+v", stmt.FinallyBlock)
+	}
+}
+
+func TestFunctionLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		params   []string
+		expected string
+	}{
+		{"var f = func(a, b) { return a + b }", []string{"a", "b"}, "func(a, b) return (a + b);"},
+		{"var f = func(): Int { return 1 }", []string{}, "func(): Int return 1;"},
+		{"var f = x => x * 2", []string{"x"}, "(x) => return (x * 2);"},
+		{"var f = (x) => x", []string{"x"}, "(x) => return x;"},
+		{"var f = (a, b) => a - b", []string{"a", "b"}, "(a, b) => return (a - b);"},
+		{"var f = (a: Int, b: Int) => { return a }", []string{"a", "b"}, "(a, b) => return a;"},
+		{"var f = () => 42", []string{}, "() => return 42;"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.VarStatement)
+		if !ok {
+			t.Fatalf("%q: statement is not ast.VarStatement. got=%T", tt.input, program.Statements[0])
+		}
+		lit, ok := stmt.Value.(*ast.FunctionLiteral)
+		if !ok {
+			t.Fatalf("%q: value is not ast.FunctionLiteral. got=%T", tt.input, stmt.Value)
+		}
+		if len(lit.Parameters) != len(tt.params) {
+			t.Fatalf("%q: expected %d parameters, got %d", tt.input, len(tt.params), len(lit.Parameters))
+		}
+		for i, name := range tt.params {
+			if lit.Parameters[i].Value != name {
+				t.Errorf("%q: parameter %d is not %q. got=%q", tt.input, i, name, lit.Parameters[i].Value)
+			}
+		}
+		if got := lit.String(); got != tt.expected {
+			t.Errorf("%q: expected %q, got %q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestFunctionLiteralErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"var f = (a, b)", "expected '=>' after lambda parameters"},
+		{"var f = 1 + 2 => 3", "invalid lambda parameter"},
+		{"var f = func { }", "expected next token to be LEFT_PAREN"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		errors := strings.Join(p.Errors(), "\n")
+		if !strings.Contains(errors, tt.expected) {
+			t.Errorf("%q: expected error containing %q, got %q", tt.input, tt.expected, errors)
+		}
+	}
+}
+
+func TestClassInheritance(t *testing.T) {
+	input := `
+class Dog extends Animal {
+    func speak() {
+        return super.speak()
+    }
+}
+var ok = d instanceof Dog
+`
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	class, ok := program.Statements[0].(*ast.ClassStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ClassStatement. got=%T", program.Statements[0])
+	}
+	if class.SuperClass == nil || class.SuperClass.Value != "Animal" {
+		t.Fatalf("superclass is not 'Animal'. got=%v", class.SuperClass)
+	}
+
+	ret := class.Methods[0].Body.Statements[0].(*ast.ReturnStatement)
+	call := ret.ReturnValue.(*ast.CallExpression)
+	member, ok := call.Function.(*ast.MemberExpression)
+	if !ok {
+		t.Fatalf("call.Function is not ast.MemberExpression. got=%T", call.Function)
+	}
+	if _, ok := member.Object.(*ast.SuperExpression); !ok {
+		t.Errorf("member.Object is not ast.SuperExpression. got=%T", member.Object)
+	}
+
+	stmt := program.Statements[1].(*ast.VarStatement)
+	if got := stmt.Value.String(); got != "(d instanceof Dog)" {
+		t.Errorf("expected %q, got %q", "(d instanceof Dog)", got)
+	}
+}
+
+func TestSpawnExpression(t *testing.T) {
+	p := New(lexer.New("spawn worker(1, ch)\nspawn wg.wait()"))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	expected := []string{"spawn worker(1, ch)", "spawn (wg.wait)()"}
+	for i, want := range expected {
+		stmt, ok := program.Statements[i].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("program.Statements[%d] is not ast.ExpressionStatement. got=%T", i, program.Statements[i])
+		}
+		spawn, ok := stmt.Expression.(*ast.SpawnExpression)
+		if !ok {
+			t.Fatalf("expression is not ast.SpawnExpression. got=%T", stmt.Expression)
+		}
+		if got := spawn.String(); got != want {
+			t.Errorf("expected %q, got %q", want, got)
+		}
+	}
+
+	p = New(lexer.New("spawn worker"))
+	p.ParseProgram()
+	if len(p.Errors()) == 0 || !strings.Contains(p.Errors()[0], "spawn expects a function call") {
+		t.Errorf("expected spawn error, got %v", p.Errors())
+	}
+}
+
+func TestAsyncAwait(t *testing.T) {
+	input := `
+async func load(x) {
+    return await fetch(x)
+}
+class Repo {
+    async func get() {
+        return 1
+    }
+}
+`
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	fn, ok := program.Statements[0].(*ast.FuncStatement)
+	if !ok || !fn.Async {
+		t.Fatalf("program.Statements[0] is not an async ast.FuncStatement. got=%#v", program.Statements[0])
+	}
+	ret := fn.Body.Statements[0].(*ast.ReturnStatement)
+	await, ok := ret.ReturnValue.(*ast.AwaitExpression)
+	if !ok {
+		t.Fatalf("return value is not ast.AwaitExpression. got=%T", ret.ReturnValue)
+	}
+	if got := await.String(); got != "await fetch(x)" {
+		t.Errorf("expected %q, got %q", "await fetch(x)", got)
+	}
+
+	class := program.Statements[1].(*ast.ClassStatement)
+	if len(class.Methods) != 1 || !class.Methods[0].Async {
+		t.Errorf("expected an async method, got %v", class.Methods)
+	}
+
+	p = New(lexer.New("async var x = 1"))
+	p.ParseProgram()
+	if len(p.Errors()) == 0 {
+		t.Errorf("expected an error for async without func")
+	}
+}
+
+func TestImportExport(t *testing.T) {
+	input := `
+import utils
+import "lib/strings.zylo"
+from "lib/utils" import Split, Len
+export func Split(s, sep) {
+    return s
+}
+export const VERSION = "1.0"
+export class Counter {
+}
+`
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	imports := []struct {
+		module, name string
+		names        []string
+		str          string
+	}{
+		{"utils", "utils", nil, "import utils;"},
+		{"lib/strings.zylo", "strings", nil, `import "lib/strings.zylo";`},
+		{"lib/utils", "utils", []string{"Split", "Len"}, `from "lib/utils" import Split, Len;`},
+	}
+	for i, want := range imports {
+		imp, ok := program.Statements[i].(*ast.ImportStatement)
+		if !ok {
+			t.Fatalf("program.Statements[%d] is not ast.ImportStatement. got=%T", i, program.Statements[i])
+		}
+		if imp.Module() != want.module || imp.ModuleName.Value != want.name {
+			t.Errorf("import %d: expected module %q bound to %q, got %q bound to %q", i, want.module, want.name, imp.Module(), imp.ModuleName.Value)
+		}
+		var names []string
+		for _, name := range imp.Names {
+			names = append(names, name.Value)
+		}
+		if strings.Join(names, ",") != strings.Join(want.names, ",") {
+			t.Errorf("import %d: expected names %v, got %v", i, want.names, names)
+		}
+		if got := imp.String(); got != want.str {
+			t.Errorf("import %d: expected %q, got %q", i, want.str, got)
+		}
+	}
+
+	for i, name := range []string{"Split", "VERSION", "Counter"} {
+		export, ok := program.Statements[3+i].(*ast.ExportStatement)
+		if !ok {
+			t.Fatalf("program.Statements[%d] is not ast.ExportStatement. got=%T", 3+i, program.Statements[3+i])
+		}
+		if export.Name() != name {
+			t.Errorf("expected export %q, got %q", name, export.Name())
+		}
+	}
+
+	errorTests := []struct {
+		input string
+		want  string
+	}{
+		{`import "lib/my-utils"`, "is not a valid module name"},
+		{"from utils import", "expected next token to be IDENTIFIER"},
+		{"export x = 1", "export expects a function, variable or class declaration"},
+	}
+	for _, tt := range errorTests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 || !strings.Contains(p.Errors()[0], tt.want) {
+			t.Errorf("%q: expected error containing %q, got %v", tt.input, tt.want, p.Errors())
+		}
+	}
+}
+
+func TestCallExpressionSpan(t *testing.T) {
+	tests := []struct {
+		input string
+		want  ast.Span
+	}{
+		{"len()", ast.Span{StartLine: 1, StartCol: 1, EndLine: 1, EndCol: 5}},
+		{"r(n+1)", ast.Span{StartLine: 1, StartCol: 1, EndLine: 1, EndCol: 6}},
+		{"f(a,\n  b\n)", ast.Span{StartLine: 1, StartCol: 1, EndLine: 3, EndCol: 1}},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		if got := ast.SpanOf(stmt.Expression); got != tt.want {
+			t.Errorf("%q: expected span %+v, got %+v", tt.input, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+	"github.com/zylo-lang/zylo/internal/lexer"
+)
+
+func TestErrorRecovery(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		want       []string // "línea:columna código" de cada diagnóstico
+		statements int      // Sentencias de nivel superior que se recuperan
+	}{
+		{
+			name:       "missing variable name",
+			input:      "var = 1\nvar y = 2\nshow.log(y)",
+			want:       []string{"1:5 P003"},
+			statements: 2,
+		},
+		{
+			name:       "broken parameters skip the whole function",
+			input:      "func f(a b) {\n    return a\n}\nvar x = 1",
+			want:       []string{"1:10 P003"},
+			statements: 1,
+		},
+		{
+			name:       "error inside a block",
+			input:      "func f() {\n    var = 1\n    return 2\n}\nf()",
+			want:       []string{"2:9 P003"},
+			statements: 2,
+		},
+		{
+			name:       "statement keyword after an unfinished expression",
+			input:      "var x = 1 +\nvar y = 2",
+			want:       []string{"2:1 P002"},
+			statements: 2,
+		},
+		{
+			name:       "hash literal",
+			input:      "var h = {a: 1 b: 2}\nvar z = 3",
+			want:       []string{"1:15 P003"},
+			statements: 2,
+		},
+		{
+			name:       "class member",
+			input:      "class A {\n    x = 1\n    func f() {\n    }\n}\nvar a = A()",
+			want:       []string{"2:5 P002"},
+			statements: 2,
+		},
+		{
+			name:       "two statements on a line",
+			input:      "var x = 1 2\nvar y = 3",
+			want:       []string{"1:11 P003"},
+			statements: 2,
+		},
+		{
+			name:       "stray brace",
+			input:      "}\nvar x = 1",
+			want:       []string{"1:1 P002"},
+			statements: 1,
+		},
+		{
+			name:       "unterminated string",
+			input:      "var s = \"abc\nvar t = 1",
+			want:       []string{"1:13 P001"},
+			statements: 2,
+		},
+		{
+			name:       "one error per broken line",
+			input:      "var = 1\nshow.log(1 2)\nvar ok = 1\nreturn )",
+			want:       []string{"1:5 P003", "2:12 P003", "4:8 P002"},
+			statements: 2,
+		},
+		{
+			name:       "unclosed block",
+			input:      "func f() {\n    return 1\n",
+			want:       []string{"3:1 P004"},
+			statements: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		var got []string
+		for _, d := range p.Diagnostics() {
+			got = append(got, fmt.Sprintf("%d:%d %s", d.Span.StartLine, d.Span.StartCol, d.Code))
+		}
+		if strings.Join(got, ", ") != strings.Join(tt.want, ", ") {
+			t.Errorf("%s: expected diagnostics %v, got %v (%q)", tt.name, tt.want, got, p.Errors())
+		}
+		if len(program.Statements) != tt.statements {
+			t.Errorf("%s: expected %d statements, got %d", tt.name, tt.statements, len(program.Statements))
+		}
+	}
+}
+
+func TestLargePrograms(t *testing.T) {
+	var input strings.Builder
+	for i := 0; i < 3000; i++ {
+		fmt.Fprintf(&input, "var x%d = %d\n", i, i)
+	}
+	input.WriteString("func f() {\n")
+	for i := 0; i < 500; i++ {
+		fmt.Fprintf(&input, "    x%d = x%d + 1\n", i, i)
+	}
+	input.WriteString("}\n")
+
+	p := New(lexer.New(input.String()))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	if len(program.Statements) != 3001 {
+		t.Fatalf("expected 3001 statements, got %d", len(program.Statements))
+	}
+	fn, ok := program.Statements[3000].(*ast.FuncStatement)
+	if !ok || len(fn.Body.Statements) != 500 {
+		t.Errorf("expected a function with 500 statements, got %T", program.Statements[3000])
+	}
+}
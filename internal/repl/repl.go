@@ -0,0 +1,333 @@
+// Package repl implementa la sesión interactiva de 'zylo repl'.
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+	"github.com/zylo-lang/zylo/internal/evaluator"
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/parser"
+)
+
+const (
+	prompt         = "zylo> "
+	continuePrompt = "...   "
+)
+
+// historyShown es cuántas entradas muestra :history.
+const historyShown = 20
+
+const helpText = `Comandos:
+  :load <archivo>  Evalúa un archivo en la sesión actual
+  :reset           Descarta todas las variables y funciones definidas
+  :env             Lista las variables definidas en la sesión
+  :ast <código>    Muestra el AST del código sin evaluarlo
+  :tokens <código> Muestra los tokens del código
+  :history         Muestra las últimas entradas
+  :help            Muestra esta ayuda
+  :quit            Sale del REPL
+Un '{' o un '"""' sin cerrar continúa la entrada en la línea siguiente.`
+
+// REPL mantiene un evaluador vivo entre entradas: lo que se define en una
+// entrada queda disponible en las siguientes.
+type REPL struct {
+	in          *bufio.Reader
+	out         io.Writer
+	historyFile string // Vacío para no guardar el historial
+
+	eval     *evaluator.Evaluator
+	builtins map[string]evaluator.Value // Globales de un evaluador recién creado
+}
+
+// New crea un REPL que lee de in y escribe en out. Cada entrada se añade a
+// historyFile; con historyFile vacío no se guarda historial.
+func New(in io.Reader, out io.Writer, historyFile string) *REPL {
+	r := &REPL{
+		in:          bufio.NewReader(in),
+		out:         out,
+		historyFile: historyFile,
+	}
+	r.reset()
+	return r
+}
+
+// DefaultHistoryFile devuelve la ruta del historial en el directorio del
+// usuario, o "" si no se puede determinar.
+func DefaultHistoryFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return home + string(os.PathSeparator) + ".zylo_history"
+}
+
+// reset reemplaza el evaluador por uno nuevo. El evaluador comparte la
+// entrada con el REPL para que read.line lea la línea siguiente.
+func (r *REPL) reset() {
+	r.eval = evaluator.NewEvaluatorWithIO(r.in, r.out, r.out)
+	r.builtins = r.eval.Globals()
+}
+
+// Run lee y ejecuta entradas hasta el final de la entrada o hasta :quit.
+func (r *REPL) Run() error {
+	fmt.Fprintln(r.out, "Zylo REPL. Escribe :help para ver los comandos.")
+	for {
+		input, err := r.readInput()
+		if strings.TrimSpace(input) != "" {
+			r.appendHistory(input)
+			if !r.execute(input) {
+				return nil
+			}
+		}
+		if err == io.EOF {
+			fmt.Fprintln(r.out)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// readInput lee una entrada completa, que puede ocupar varias líneas.
+func (r *REPL) readInput() (string, error) {
+	fmt.Fprint(r.out, prompt)
+	var input strings.Builder
+	for {
+		line, err := r.in.ReadString('\n')
+		input.WriteString(line)
+		if err != nil {
+			return input.String(), err
+		}
+		if strings.HasPrefix(strings.TrimSpace(input.String()), ":") || !incomplete(input.String()) {
+			return input.String(), nil
+		}
+		fmt.Fprint(r.out, continuePrompt)
+	}
+}
+
+// incomplete indica si source tiene un bloque, una lista, un paréntesis o un
+// string multilínea sin cerrar, así que la entrada sigue en otra línea.
+func incomplete(source string) bool {
+	l := lexer.New(source)
+	depth := 0
+	for {
+		tok := l.NextToken()
+		switch tok.Type {
+		case lexer.EOF:
+			return depth > 0
+		case lexer.LEFT_BRACE, lexer.LEFT_PAREN, lexer.LEFT_BRACKET:
+			depth++
+		case lexer.RIGHT_BRACE, lexer.RIGHT_PAREN, lexer.RIGHT_BRACKET:
+			depth--
+		case "ERROR":
+			if strings.HasPrefix(tok.Lexeme, "Unterminated multi-line string") {
+				return true
+			}
+		}
+	}
+}
+
+// execute ejecuta un meta-comando o evalúa código. Devuelve false si la
+// sesión debe terminar.
+func (r *REPL) execute(input string) bool {
+	input = strings.TrimSpace(input)
+	if !strings.HasPrefix(input, ":") {
+		r.evaluate(input)
+		return true
+	}
+
+	command, arg, _ := strings.Cut(input, " ")
+	arg = strings.TrimSpace(arg)
+	switch command {
+	case ":quit", ":exit":
+		return false
+	case ":help":
+		fmt.Fprintln(r.out, helpText)
+	case ":reset":
+		r.reset()
+		fmt.Fprintln(r.out, "Sesión reiniciada.")
+	case ":env":
+		r.printEnv()
+	case ":load":
+		r.load(arg)
+	case ":ast":
+		r.printAST(arg)
+	case ":tokens":
+		r.printTokens(arg)
+	case ":history":
+		r.printHistory()
+	default:
+		fmt.Fprintf(r.out, "Comando desconocido: %s (escribe :help)\n", command)
+	}
+	return true
+}
+
+// parse analiza source e imprime los errores de sintaxis, si los hay.
+func (r *REPL) parse(source string) (*ast.Program, bool) {
+	p := parser.New(lexer.New(source))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		fmt.Fprintln(r.out, "Errores de parsing:")
+		for _, err := range p.Errors() {
+			fmt.Fprintf(r.out, "  %s\n", err)
+		}
+		return nil, false
+	}
+	return program, true
+}
+
+// evaluate evalúa source e imprime el valor si la última sentencia es una
+// expresión con resultado.
+func (r *REPL) evaluate(source string) {
+	program, ok := r.parse(source)
+	if !ok {
+		return
+	}
+	value, err := r.eval.EvaluateStatements(program)
+	if err != nil {
+		r.printError(err)
+		return
+	}
+
+	if len(program.Statements) == 0 {
+		return
+	}
+	if _, ok := program.Statements[len(program.Statements)-1].(*ast.ExpressionStatement); !ok {
+		return
+	}
+	if obj, ok := value.(evaluator.ZyloObject); ok {
+		if _, isNull := value.(*evaluator.Null); !isNull {
+			fmt.Fprintln(r.out, obj.Inspect())
+		}
+	}
+}
+
+// printError imprime un error de ejecución con su traza.
+func (r *REPL) printError(err error) {
+	var runtimeErr *evaluator.RuntimeError
+	if errors.As(err, &runtimeErr) {
+		fmt.Fprintf(r.out, "Error de ejecución:\n%s\n", runtimeErr.Traceback())
+		return
+	}
+	fmt.Fprintf(r.out, "Error de ejecución: %v\n", err)
+}
+
+// load evalúa el archivo path en la sesión actual. Solo se ejecutan sus
+// sentencias; main no se llama automáticamente.
+func (r *REPL) load(path string) {
+	if path == "" {
+		fmt.Fprintln(r.out, "Uso: :load <archivo>")
+		return
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(r.out, "Error leyendo archivo: %v\n", err)
+		return
+	}
+	program, ok := r.parse(string(content))
+	if !ok {
+		return
+	}
+
+	r.eval.SetFile(path)
+	defer r.eval.SetFile("")
+	if _, err := r.eval.EvaluateStatements(program); err != nil {
+		r.printError(err)
+		return
+	}
+	fmt.Fprintf(r.out, "Cargado %s\n", path)
+}
+
+// printEnv lista las variables definidas en la sesión, sin los built-ins.
+func (r *REPL) printEnv() {
+	globals := r.eval.Globals()
+	names := make([]string, 0, len(globals))
+	for name, value := range globals {
+		if builtin, ok := r.builtins[name]; ok && builtin == value {
+			continue
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		fmt.Fprintln(r.out, "(sin variables)")
+		return
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(r.out, "%s = %s\n", name, inspect(globals[name]))
+	}
+}
+
+// printAST muestra cada sentencia de source con su tipo de nodo.
+func (r *REPL) printAST(source string) {
+	program, ok := r.parse(source)
+	if !ok {
+		return
+	}
+	for _, stmt := range program.Statements {
+		fmt.Fprintf(r.out, "%s: %s\n", strings.TrimPrefix(fmt.Sprintf("%T", stmt), "*ast."), stmt.String())
+	}
+}
+
+// printTokens muestra los tokens de source con su posición.
+func (r *REPL) printTokens(source string) {
+	l := lexer.New(source)
+	for {
+		tok := l.NextToken()
+		if tok.Type == lexer.EOF {
+			return
+		}
+		fmt.Fprintf(r.out, "%d:%d %s %q\n", tok.StartLine, tok.StartCol, tok.Type, tok.Lexeme)
+	}
+}
+
+// appendHistory añade input al archivo de historial. Los errores se ignoran:
+// el historial no debe impedir usar el REPL.
+func (r *REPL) appendHistory(input string) {
+	if r.historyFile == "" {
+		return
+	}
+	f, err := os.OpenFile(r.historyFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintln(f, strings.TrimRight(input, "\n"))
+}
+
+// printHistory muestra las últimas líneas del historial, incluidas las de
+// sesiones anteriores.
+func (r *REPL) printHistory() {
+	if r.historyFile == "" {
+		fmt.Fprintln(r.out, "(historial desactivado)")
+		return
+	}
+	content, err := os.ReadFile(r.historyFile)
+	if err != nil {
+		fmt.Fprintln(r.out, "(historial vacío)")
+		return
+	}
+	lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+	if len(lines) > historyShown {
+		lines = lines[len(lines)-historyShown:]
+	}
+	for _, line := range lines {
+		fmt.Fprintln(r.out, line)
+	}
+}
+
+// inspect devuelve la representación legible de un valor.
+func inspect(value evaluator.Value) string {
+	if obj, ok := value.(evaluator.ZyloObject); ok {
+		return obj.Inspect()
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,136 @@
+package repl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runSession ejecuta el REPL con input y devuelve la salida completa.
+func runSession(t *testing.T, input, historyFile string) string {
+	t.Helper()
+	var out strings.Builder
+	if err := New(strings.NewReader(input), &out, historyFile).Run(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return out.String()
+}
+
+func TestIncomplete(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"var x = 1\n", false},
+		{"func f() {\n", true},
+		{"func f() {\n    return 1\n}\n", false},
+		{"if x {\n    if y {\n    }\n", true},
+		{"var s = \"\"\"\nuno\n", true},
+		{"var s = \"\"\"\nuno\n\"\"\"\n", false},
+		{"var l = [1,\n", true},
+		{"var s = \"{\"\n", false},
+	}
+
+	for _, tt := range tests {
+		if got := incomplete(tt.input); got != tt.expected {
+			t.Errorf("incomplete(%q) = %v, expected %v", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestSessionKeepsState(t *testing.T) {
+	out := runSession(t, `var x = 40
+func add(a, b) {
+    return a + b
+}
+add(x, 2)
+var unused = 1
+show.log("hola")
+`, "")
+
+	if !strings.Contains(out, prompt+continuePrompt+continuePrompt+prompt+"42\n") {
+		t.Errorf("expected the multi-line definition and the result 42, got:\n%s", out)
+	}
+	if !strings.Contains(out, "hola\n"+prompt) {
+		t.Errorf("expected show.log output without a printed null, got:\n%s", out)
+	}
+}
+
+func TestMetaCommands(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "lib.zylo")
+	if err := os.WriteFile(file, []byte("func triple(n) {\n    return n * 3\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "env lists user bindings only",
+			input:    "var b = 2\nvar a = [1]\n:env\n",
+			expected: []string{"a = [1]\nb = 2\n"},
+		},
+		{
+			name:     "reset clears the session",
+			input:    "var a = 1\n:reset\n:env\na\n",
+			expected: []string{"Sesión reiniciada.", "(sin variables)", "variable no definida: a"},
+		},
+		{
+			name:     "load evaluates a file",
+			input:    ":load " + file + "\ntriple(5)\n",
+			expected: []string{"Cargado " + file, "15\n"},
+		},
+		{
+			name:     "ast prints the parsed statements",
+			input:    ":ast 1 + 2 * 3\n",
+			expected: []string{"ExpressionStatement: (1 + (2 * 3))\n"},
+		},
+		{
+			name:     "tokens prints positions",
+			input:    ":tokens var y\n",
+			expected: []string{"1:1 VAR \"var\"\n1:5 IDENTIFIER \"y\"\n"},
+		},
+		{
+			name:     "errors do not end the session",
+			input:    "var = 1\nthrow \"boom\"\n1 + 1\n",
+			expected: []string{"Errores de parsing:", "boom", "2\n"},
+		},
+		{
+			name:     "unknown command",
+			input:    ":nope\n",
+			expected: []string{"Comando desconocido: :nope"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := runSession(t, tt.input, "")
+			for _, want := range tt.expected {
+				if !strings.Contains(out, want) {
+					t.Errorf("expected output to contain %q, got:\n%s", want, out)
+				}
+			}
+		})
+	}
+}
+
+func TestQuitStopsReading(t *testing.T) {
+	out := runSession(t, ":quit\nshow.log(\"unreachable\")\n", "")
+	if strings.Contains(out, "unreachable") {
+		t.Errorf("expected :quit to end the session, got:\n%s", out)
+	}
+}
+
+func TestHistoryPersists(t *testing.T) {
+	history := filepath.Join(t.TempDir(), "history")
+	runSession(t, "var x = 1\nfunc f() {\n}\n", history)
+
+	out := runSession(t, ":history\n", history)
+	if !strings.Contains(out, "var x = 1\nfunc f() {\n}\n:history\n") {
+		t.Errorf("expected the previous session in the history, got:\n%s", out)
+	}
+}
@@ -0,0 +1,740 @@
+package sema
+
+import (
+	"fmt"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+)
+
+// Códigos de TypeError. No cambian entre versiones.
+const (
+	CodeUnknownType = "T001" // Una anotación de tipo inválida
+	CodeAssign      = "T002" // Un valor que no cabe en el tipo declarado
+	CodeReturn      = "T003" // Un return que no cumple el tipo de la función
+	CodeIterate     = "T004" // Un for sobre un valor no iterable
+	CodeOperator    = "T005" // Un operador con operandos de otro tipo
+	CodeCall        = "T006" // La llamada a algo que no es una función
+	CodeArguments   = "T007" // Argumentos de más, de menos o de otro tipo
+	CodeIndex       = "T008" // Un índice inválido o un valor no indexable
+)
+
+// TypeError es un error de tipos en una posición del código fuente.
+type TypeError struct {
+	Span    ast.Span
+	Code    string
+	Message string
+}
+
+func (e *TypeError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Span.StartLine, e.Span.StartCol, e.Message)
+}
+
+// builtinTypes son los tipos de los built-ins del evaluador cuyo resultado
+// se conoce. Los que no están aquí son de tipo any.
+var builtinTypes = map[string]*Type{
+	"show.log":          VariadicFunc(Null),
+	"read.line":         VariadicFunc(String),
+	"read.int":          VariadicFunc(Int),
+	"getInput":          VariadicFunc(String),
+	"string":            Func(String, Any),
+	"len":               Func(Int, Any),
+	"split":             Func(ListOf(String), String, String),
+	"zyloruntime.Split": Func(ListOf(String), String, String),
+	"to_number":         Func(Any, Any),
+	"sleep":             Func(Null, Int),
+	"newList":           Func(ListOf(Any)),
+	"newMap":            Func(MapOf(Any)),
+	"readFile":          Func(String, String),
+	"writeFile":         Func(Null, String, String),
+	"toJSON":            Func(String, Any),
+	"fromJSON":          Func(Any, String),
+}
+
+// BuiltinType devuelve el tipo del built-in name, o any si no se conoce.
+func BuiltinType(name string) *Type {
+	if t, ok := builtinTypes[name]; ok {
+		return t
+	}
+	return Any
+}
+
+// Checker comprueba los tipos de un programa. Usa las anotaciones de
+// variables, parámetros y resultados, e infiere el tipo de lo que no está
+// anotado a partir de su valor; lo que no se puede inferir es any y se
+// comprueba al ejecutar. Los nombres sin declarar también son any: los
+// informa SemanticAnalyzer.
+type Checker struct {
+	types    map[ast.Expression]*Type // Tipo de cada expresión comprobada
+	names    map[*ast.Identifier]*Type
+	declared map[*ast.Identifier]bool // Usos de nombres declarados en el programa
+	classes  map[string]*Class
+	scope    *typeScope
+	fn       *function // Función cuyo cuerpo se comprueba, nil fuera de ellas
+	class    *Class    // Clase cuyos métodos se comprueban
+	errors   []*TypeError
+}
+
+// typeScope guarda el tipo de las variables de un ámbito.
+type typeScope struct {
+	parent *typeScope
+	vars   map[string]*Type
+}
+
+func (s *typeScope) lookup(name string) (*Type, bool) {
+	for scope := s; scope != nil; scope = scope.parent {
+		if t, ok := scope.vars[name]; ok {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// function es el contexto de la función cuyo cuerpo se comprueba.
+type function struct {
+	name     string
+	declared *Type // Tipo de resultado anotado, nil si se infiere
+	returns  *Type // Unión de los tipos devueltos, para inferir el resultado
+}
+
+// NewChecker crea un comprobador de tipos.
+func NewChecker() *Checker {
+	return &Checker{
+		types:    make(map[ast.Expression]*Type),
+		names:    make(map[*ast.Identifier]*Type),
+		declared: make(map[*ast.Identifier]bool),
+		classes:  make(map[string]*Class),
+		scope:    &typeScope{vars: make(map[string]*Type)},
+	}
+}
+
+// Check comprueba los tipos de program. Los errores se obtienen con Errors.
+func (c *Checker) Check(program *ast.Program) {
+	c.declareClasses(program.Statements)
+	c.statements(program.Statements)
+}
+
+// Errors devuelve los errores de tipos encontrados, en orden.
+func (c *Checker) Errors() []*TypeError {
+	return c.errors
+}
+
+// TypeOf devuelve el tipo de una expresión comprobada, o any si no se
+// comprobó.
+func (c *Checker) TypeOf(exp ast.Expression) *Type {
+	if t, ok := c.types[exp]; ok {
+		return t
+	}
+	return Any
+}
+
+// TypeOfName devuelve el tipo con el que se declaró una variable, un
+// parámetro o una función, o any si no se conoce.
+func (c *Checker) TypeOfName(ident *ast.Identifier) *Type {
+	if t, ok := c.names[ident]; ok {
+		return t
+	}
+	return Any
+}
+
+// Declared indica si ident, en un uso comprobado, se refiere a un nombre
+// declarado en el programa y no a un built-in.
+func (c *Checker) Declared(ident *ast.Identifier) bool {
+	return c.declared[ident]
+}
+
+// Class devuelve la clase name del programa.
+func (c *Checker) Class(name string) (*Class, bool) {
+	class, ok := c.classes[name]
+	return class, ok
+}
+
+func (c *Checker) errorf(node ast.Node, code, format string, args ...interface{}) {
+	c.errorAt(ast.SpanOf(node), code, format, args...)
+}
+
+func (c *Checker) errorAt(span ast.Span, code, format string, args ...interface{}) {
+	c.errors = append(c.errors, &TypeError{Span: span, Code: code, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *Checker) enterScope() {
+	c.scope = &typeScope{parent: c.scope, vars: make(map[string]*Type)}
+}
+
+func (c *Checker) exitScope() {
+	c.scope = c.scope.parent
+}
+
+func (c *Checker) define(ident *ast.Identifier, t *Type) {
+	c.scope.vars[ident.Value] = t
+	c.names[ident] = t
+}
+
+// annotation devuelve el tipo de una anotación, o nil si no la hay. Un tipo
+// desconocido se informa en node y se trata como any.
+func (c *Checker) annotation(node ast.Node, annotation string) *Type {
+	if annotation == "" {
+		return nil
+	}
+	t, err := parseType(annotation, c.classes)
+	if err != nil {
+		c.errorf(node, CodeUnknownType, "%v", err)
+		return Any
+	}
+	return t
+}
+
+// declareClasses registra las clases de nivel superior antes de comprobar
+// nada, para que las anotaciones puedan nombrarlas y las subclases puedan
+// declararse antes que sus superclases.
+func (c *Checker) declareClasses(statements []ast.Statement) {
+	var declared []*ast.ClassStatement
+	for _, stmt := range statements {
+		if export, ok := stmt.(*ast.ExportStatement); ok {
+			stmt = export.Statement
+		}
+		if class, ok := stmt.(*ast.ClassStatement); ok && class.Name != nil {
+			c.classes[class.Name.Value] = &Class{
+				Name:    class.Name.Value,
+				Fields:  make(map[string]*Type),
+				Methods: make(map[string]*Type),
+			}
+			declared = append(declared, class)
+		}
+	}
+
+	for _, stmt := range declared {
+		class := c.classes[stmt.Name.Value]
+		if stmt.SuperClass != nil {
+			class.Super = c.classes[stmt.SuperClass.Value]
+		}
+		for _, attr := range stmt.Attributes {
+			if t := c.annotation(attr.Name, attr.Type); t != nil {
+				class.Fields[attr.Name.Value] = t
+			} else {
+				class.Fields[attr.Name.Value] = Any
+			}
+		}
+		for _, method := range stmt.Methods {
+			sig := c.signature(method.Parameters, method.ReturnType, method.Async, method.Name)
+			if method.Name.Value == "init" {
+				class.Init = sig
+			} else {
+				class.Methods[method.Name.Value] = sig
+			}
+		}
+	}
+}
+
+// signature devuelve el tipo de una función a partir de sus anotaciones. Si
+// el resultado no está anotado es any hasta que se compruebe el cuerpo.
+func (c *Checker) signature(params []*ast.Identifier, returnType string, async bool, node ast.Node) *Type {
+	sig := &Type{Kind: KindFunc, Params: make([]*Type, len(params)), Result: Any}
+	for i, param := range params {
+		sig.Params[i] = Any
+		if t := c.annotation(param, param.Type); t != nil {
+			sig.Params[i] = t
+		}
+	}
+	if t := c.annotation(node, returnType); t != nil {
+		sig.Result = t
+	}
+	if async {
+		sig.Result = FutureOf(sig.Result)
+	}
+	return sig
+}
+
+// statements comprueba una secuencia de sentencias. Las funciones se
+// declaran antes, para que puedan llamarse entre sí en cualquier orden.
+func (c *Checker) statements(statements []ast.Statement) {
+	for _, stmt := range statements {
+		if export, ok := stmt.(*ast.ExportStatement); ok {
+			stmt = export.Statement
+		}
+		switch s := stmt.(type) {
+		case *ast.FuncStatement:
+			if s.Name != nil {
+				c.define(s.Name, c.signature(s.Parameters, s.ReturnType, s.Async, s.Name))
+			}
+		case *ast.ClassStatement:
+			if class, ok := c.classes[s.Name.Value]; ok {
+				c.define(s.Name, &Type{Kind: KindClass, Class: class})
+			}
+		}
+	}
+	for _, stmt := range statements {
+		c.statement(stmt)
+	}
+}
+
+func (c *Checker) block(block *ast.BlockStatement) {
+	if block == nil {
+		return
+	}
+	c.enterScope()
+	c.statements(block.Statements)
+	c.exitScope()
+}
+
+func (c *Checker) statement(stmt ast.Statement) {
+	switch s := stmt.(type) {
+	case *ast.VarStatement:
+		c.varStatement(s)
+	case *ast.ExpressionStatement:
+		if s.Expression != nil {
+			c.expr(s.Expression)
+		}
+	case *ast.FuncStatement:
+		if s.Name != nil {
+			c.function(s.Name.Value, c.names[s.Name], s.Parameters, s.ReturnType, s.Body)
+		}
+	case *ast.ReturnStatement:
+		c.returnStatement(s)
+	case *ast.IfStatement:
+		c.expr(s.Condition)
+		c.block(s.Consequence)
+		c.block(s.Alternative)
+	case *ast.WhileStatement:
+		c.expr(s.Condition)
+		c.block(s.Body)
+	case *ast.ForInStatement:
+		c.forInStatement(s)
+	case *ast.TryStatement:
+		c.block(s.TryBlock)
+		if s.CatchClause != nil {
+			c.enterScope()
+			if s.CatchClause.Parameter != nil {
+				c.define(s.CatchClause.Parameter, Any)
+			}
+			c.block(s.CatchClause.CatchBlock)
+			c.exitScope()
+		}
+		c.block(s.FinallyBlock)
+	case *ast.ThrowStatement:
+		if s.Exception != nil {
+			c.expr(s.Exception)
+		}
+	case *ast.BlockStatement:
+		c.block(s)
+	case *ast.ClassStatement:
+		c.classStatement(s)
+	case *ast.ImportStatement:
+		// Los valores de otros módulos son de tipo any
+		if s.Names == nil {
+			c.define(s.ModuleName, Any)
+		}
+		for _, name := range s.Names {
+			c.define(name, Any)
+		}
+	case *ast.ExportStatement:
+		c.statement(s.Statement)
+	}
+}
+
+func (c *Checker) varStatement(s *ast.VarStatement) {
+	declared := c.annotation(s.Name, s.Type)
+	t := Any
+	if s.Value != nil {
+		value := c.expr(s.Value)
+		if declared != nil && !Assignable(value, declared) {
+			c.errorf(s.Value, CodeAssign, "cannot assign %s to variable %s of type %s", value, s.Name.Value, declared)
+		}
+		// Una variable que empieza en null puede tomar cualquier valor
+		if value.Kind != KindNull {
+			t = value
+		}
+	}
+	if declared != nil {
+		t = declared
+	}
+	c.define(s.Name, t)
+}
+
+func (c *Checker) returnStatement(s *ast.ReturnStatement) {
+	value := Null
+	if s.ReturnValue != nil {
+		value = c.expr(s.ReturnValue)
+	}
+	if c.fn == nil {
+		return
+	}
+	if declared := c.fn.declared; declared != nil {
+		switch {
+		case s.ReturnValue == nil && declared.Kind != KindNull && declared.Kind != KindAny:
+			c.errorf(s, CodeReturn, "missing return value in %s, declared to return %s", c.fn.name, declared)
+		case !Assignable(value, declared):
+			c.errorf(s.ReturnValue, CodeReturn, "cannot return %s from %s, declared to return %s", value, c.fn.name, declared)
+		}
+		return
+	}
+	c.fn.returns = join(c.fn.returns, value)
+}
+
+func (c *Checker) forInStatement(s *ast.ForInStatement) {
+	iterable := c.expr(s.Iterable)
+	elem := Any
+	switch iterable.Kind {
+	case KindList:
+		elem = iterable.Elem
+	case KindString, KindMap:
+		elem = String
+	case KindAny:
+	default:
+		c.errorf(s.Iterable, CodeIterate, "cannot iterate over %s", iterable)
+	}
+	c.enterScope()
+	c.define(s.Identifier, elem)
+	c.block(s.Body)
+	c.exitScope()
+}
+
+// function comprueba el cuerpo de una función de tipo sig. Si el resultado
+// no está anotado, lo infiere de sus 'return' y actualiza sig.
+func (c *Checker) function(name string, sig *Type, params []*ast.Identifier, returnType string, body *ast.BlockStatement) {
+	if sig == nil || body == nil {
+		return
+	}
+	result := &sig.Result
+	if sig.Result.Kind == KindFuture {
+		result = &sig.Result.Elem
+	}
+
+	outer := c.fn
+	c.fn = &function{name: name}
+	if returnType != "" {
+		c.fn.declared = *result
+	}
+	c.enterScope()
+	for i, param := range params {
+		c.define(param, sig.Params[i])
+	}
+	c.statements(body.Statements)
+	c.exitScope()
+
+	if c.fn.declared == nil {
+		*result = Null
+		if c.fn.returns != nil {
+			*result = c.fn.returns
+		}
+	}
+	c.fn = outer
+}
+
+func (c *Checker) classStatement(s *ast.ClassStatement) {
+	class, ok := c.classes[s.Name.Value]
+	if !ok {
+		// Clase declarada dentro de una función
+		c.declareClasses([]ast.Statement{s})
+		class = c.classes[s.Name.Value]
+		c.define(s.Name, &Type{Kind: KindClass, Class: class})
+	}
+
+	outer := c.class
+	c.class = class
+	for _, attr := range s.Attributes {
+		if attr.Value != nil {
+			value := c.expr(attr.Value)
+			if field := class.Fields[attr.Name.Value]; !Assignable(value, field) {
+				c.errorf(attr.Value, CodeAssign, "cannot assign %s to attribute %s of type %s", value, attr.Name.Value, field)
+			}
+		}
+	}
+	for _, method := range s.Methods {
+		sig := class.Methods[method.Name.Value]
+		if method.Name.Value == "init" {
+			sig = class.Init
+		}
+		c.function(s.Name.Value+"."+method.Name.Value, sig, method.Parameters, method.ReturnType, method.Body)
+	}
+	c.class = outer
+}
+
+// expr comprueba una expresión y devuelve su tipo.
+func (c *Checker) expr(exp ast.Expression) *Type {
+	if exp == nil {
+		return Any
+	}
+	t := c.exprType(exp)
+	c.types[exp] = t
+	return t
+}
+
+func (c *Checker) exprType(exp ast.Expression) *Type {
+	switch e := exp.(type) {
+	case *ast.Identifier:
+		if t, ok := c.scope.lookup(e.Value); ok {
+			c.declared[e] = true
+			return t
+		}
+		if t, ok := builtinTypes[e.Value]; ok {
+			return t
+		}
+		return Any
+	case *ast.NumberLiteral:
+		if _, ok := e.Value.(float64); ok {
+			return Float
+		}
+		return Int
+	case *ast.StringLiteral:
+		return String
+	case *ast.BooleanLiteral:
+		return Bool
+	case *ast.NullLiteral:
+		return Null
+	case *ast.ListLiteral:
+		var elem *Type
+		for _, element := range e.Elements {
+			elem = join(elem, c.expr(element))
+		}
+		if elem == nil || elem.Kind == KindNull {
+			elem = Any
+		}
+		return ListOf(elem)
+	case *ast.HashLiteral:
+		var elem *Type
+		for key, value := range e.Pairs {
+			c.expr(key)
+			elem = join(elem, c.expr(value))
+		}
+		if elem == nil || elem.Kind == KindNull {
+			elem = Any
+		}
+		return MapOf(elem)
+	case *ast.PrefixExpression:
+		return c.prefix(e)
+	case *ast.InfixExpression:
+		if e.Operator == "=" {
+			return c.assignment(e)
+		}
+		return c.binary(e, c.expr(e.Left), c.expr(e.Right))
+	case *ast.CallExpression:
+		callee := c.expr(e.Function)
+		return c.call(e, callee, e.Arguments)
+	case *ast.ClassInstantiation:
+		callee := c.expr(e.ClassName)
+		return c.call(e, callee, e.Arguments)
+	case *ast.MemberExpression:
+		// show.log y read.line son built-ins, salvo que se redefina el objeto
+		if ident, ok := e.Object.(*ast.Identifier); ok {
+			if t, ok := builtinTypes[ident.Value+"."+e.Property.Value]; ok {
+				if _, shadowed := c.scope.lookup(ident.Value); !shadowed {
+					return t
+				}
+			}
+		}
+		object := c.expr(e.Object)
+		if object.Kind == KindInstance {
+			if t, ok := object.Class.Member(e.Property.Value); ok {
+				return t
+			}
+		}
+		if t, ok := CollectionMethod(object, e.Property.Value); ok {
+			return t
+		}
+		return Any
+	case *ast.IndexExpression:
+		return c.index(e)
+	case *ast.FunctionLiteral:
+		sig := c.signature(e.Parameters, e.ReturnType, false, e)
+		c.function("anonymous function", sig, e.Parameters, e.ReturnType, e.Body)
+		return sig
+	case *ast.ThisExpression:
+		if c.class != nil {
+			return InstanceOf(c.class)
+		}
+	case *ast.SuperExpression:
+		if c.class != nil && c.class.Super != nil {
+			return InstanceOf(c.class.Super)
+		}
+	case *ast.SpawnExpression:
+		c.expr(e.Call)
+		return Null
+	case *ast.AwaitExpression:
+		value := c.expr(e.Value)
+		if value.Kind == KindFuture {
+			return value.Elem
+		}
+		return value
+	case *ast.BlockExpression:
+		c.block(e.Block)
+	}
+	return Any
+}
+
+func (c *Checker) prefix(e *ast.PrefixExpression) *Type {
+	right := c.expr(e.Right)
+	switch e.Operator {
+	case "!":
+		return Bool
+	case "-", "+":
+		if right.IsNumeric() || right.Kind == KindAny {
+			return right
+		}
+		c.errorf(e, CodeOperator, "operator %s not defined for %s", e.Operator, right)
+	}
+	return Any
+}
+
+// binary devuelve el tipo del resultado de un operador binario, con las
+// mismas reglas que el evaluador.
+func (c *Checker) binary(e *ast.InfixExpression, left, right *Type) *Type {
+	numericOrAny := func(t *Type) bool { return t.IsNumeric() || t.Kind == KindAny }
+
+	switch e.Operator {
+	case "+":
+		// string + número concatena en cualquier orden
+		if (left.Kind == KindString && (right.Kind == KindString || numericOrAny(right))) ||
+			(right.Kind == KindString && numericOrAny(left)) {
+			return String
+		}
+		fallthrough
+	case "-", "*", "/", "%":
+		if !numericOrAny(left) || !numericOrAny(right) {
+			break
+		}
+		switch {
+		case left.Kind == KindInt && right.Kind == KindInt:
+			return Int
+		case left.Kind == KindAny || right.Kind == KindAny:
+			return Any
+		}
+		return Float
+	case "<", ">", "<=", ">=":
+		if numericOrAny(left) && numericOrAny(right) {
+			return Bool
+		}
+	case "==", "!=", "&&", "||", "and", "or", "instanceof":
+		return Bool
+	case "..":
+		intOrAny := func(t *Type) bool { return t.Kind == KindInt || t.Kind == KindAny }
+		if intOrAny(left) && intOrAny(right) {
+			return ListOf(Int)
+		}
+	default:
+		return Any
+	}
+	c.errorAt(ast.TokenSpan(e.Token), CodeOperator, "operator %s not defined for %s and %s", e.Operator, left, right)
+	return Any
+}
+
+// assignment comprueba que el valor asignado sea compatible con el tipo de
+// la variable, el atributo o los elementos de la lista que recibe.
+func (c *Checker) assignment(e *ast.InfixExpression) *Type {
+	value := c.expr(e.Right)
+	switch target := e.Left.(type) {
+	case *ast.Identifier:
+		if t, ok := c.scope.lookup(target.Value); ok && !Assignable(value, t) {
+			c.errorf(e.Right, CodeAssign, "cannot assign %s to variable %s of type %s", value, target.Value, t)
+		}
+		c.types[target] = c.exprType(target)
+	case *ast.MemberExpression:
+		object := c.expr(target.Object)
+		if object.Kind == KindInstance {
+			if t, ok := object.Class.Fields[target.Property.Value]; ok && !Assignable(value, t) {
+				c.errorf(e.Right, CodeAssign, "cannot assign %s to attribute %s of type %s", value, target.Property.Value, t)
+			}
+		}
+	case *ast.IndexExpression:
+		if list := c.expr(target.Left); list.Kind == KindList {
+			c.expr(target.Index)
+			if !Assignable(value, list.Elem) {
+				c.errorf(e.Right, CodeAssign, "cannot assign %s to an element of %s", value, list)
+			}
+		} else {
+			c.expr(target.Index)
+		}
+	default:
+		c.expr(e.Left)
+	}
+	return value
+}
+
+// call comprueba el número y el tipo de los argumentos de una llamada a una
+// función o a una clase y devuelve el tipo del resultado.
+func (c *Checker) call(node ast.Expression, callee *Type, args []ast.Expression) *Type {
+	argTypes := make([]*Type, len(args))
+	for i, arg := range args {
+		argTypes[i] = c.expr(arg)
+	}
+
+	var sig *Type
+	result := Any
+	name := calleeName(node)
+	switch callee.Kind {
+	case KindFunc:
+		sig, result = callee, callee.Result
+	case KindClass:
+		result = InstanceOf(callee.Class)
+		for class := callee.Class; class != nil && sig == nil; class = class.Super {
+			sig = class.Init
+		}
+		if sig == nil {
+			sig = Func(Null)
+		}
+	case KindAny, KindInstance:
+		return Any
+	default:
+		c.errorf(node, CodeCall, "cannot call %s, a value of type %s", name, callee)
+		return Any
+	}
+
+	if sig.Variadic {
+		return result
+	}
+	if len(args) != len(sig.Params) {
+		c.errorf(node, CodeArguments, "%s expects %d %s, got %d", name, len(sig.Params), plural(len(sig.Params), "argument"), len(args))
+		return result
+	}
+	for i, arg := range args {
+		if !Assignable(argTypes[i], sig.Params[i]) {
+			c.errorf(arg, CodeArguments, "argument %d to %s: cannot use %s as %s", i+1, name, argTypes[i], sig.Params[i])
+		}
+	}
+	return result
+}
+
+func (c *Checker) index(e *ast.IndexExpression) *Type {
+	left := c.expr(e.Left)
+	index := c.expr(e.Index)
+	switch left.Kind {
+	case KindList, KindString:
+		if index.Kind != KindInt && index.Kind != KindAny {
+			c.errorf(e.Index, CodeIndex, "%s index must be int, got %s", kindName(left), index)
+		}
+		if left.Kind == KindString {
+			return String
+		}
+		return left.Elem
+	case KindMap:
+		return left.Elem
+	case KindAny, KindInstance:
+		return Any
+	}
+	c.errorf(e, CodeIndex, "cannot index %s", left)
+	return Any
+}
+
+// calleeName describe la función llamada en los mensajes de error.
+func calleeName(node ast.Expression) string {
+	switch n := node.(type) {
+	case *ast.CallExpression:
+		return n.Function.String()
+	case *ast.ClassInstantiation:
+		return n.ClassName.Value
+	}
+	return node.String()
+}
+
+func kindName(t *Type) string {
+	if t.Kind == KindList {
+		return "list"
+	}
+	return t.String()
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
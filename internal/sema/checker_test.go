@@ -0,0 +1,193 @@
+package sema
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/parser"
+)
+
+func check(t *testing.T, input string) (*Checker, *ast.Program) {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+	c := NewChecker()
+	c.Check(program)
+	return c, program
+}
+
+func TestCheckerInference(t *testing.T) {
+	input := `
+var a = 1
+var b = 2.5
+var s = "n = " + a
+var sum = a + b
+var names = ["ana", "luis"]
+var first = names[0]
+var mixed = [1, 2.5]
+var scores: Map<String, Int> = {"ana": 1}
+func square(x: int) {
+    return x * x
+}
+var sq = square(3)
+func label(n) {
+    if n > 1 {
+        return "many"
+    }
+    return "one"
+}
+async func fetch(): string {
+    return "data"
+}
+async func load() {
+    return await fetch()
+}
+class Point {
+    var x: Int = 0
+    func init(x) {
+        this.x = x
+    }
+    func get() {
+        return this.x
+    }
+}
+var p = Point(1)
+var px = p.get()
+var cmp = a < b
+for name in names {
+    var upper = name
+}
+`
+	c, program := check(t, input)
+	if errs := c.Errors(); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	expected := map[string]string{
+		"a":      "int",
+		"b":      "float",
+		"s":      "string",
+		"sum":    "float",
+		"names":  "list<string>",
+		"first":  "string",
+		"mixed":  "list<float>",
+		"scores": "map<int>",
+		"square": "func(int) int",
+		"sq":     "int",
+		"label":  "func(any) string",
+		"fetch":  "func() future<string>",
+		"load":   "func() future<string>",
+		"p":      "Point",
+		"px":     "int",
+		"cmp":    "bool",
+		"name":   "string",
+	}
+	found := map[string]bool{}
+	var visit func(statements []ast.Statement)
+	visit = func(statements []ast.Statement) {
+		for _, stmt := range statements {
+			var name *ast.Identifier
+			switch s := stmt.(type) {
+			case *ast.VarStatement:
+				name = s.Name
+			case *ast.FuncStatement:
+				name = s.Name
+			case *ast.ForInStatement:
+				name = s.Identifier
+			}
+			if name == nil {
+				continue
+			}
+			if want, ok := expected[name.Value]; ok {
+				found[name.Value] = true
+				if got := c.TypeOfName(name).String(); got != want {
+					t.Errorf("%s: expected type %s, got %s", name.Value, want, got)
+				}
+			}
+		}
+	}
+	visit(program.Statements)
+	for _, stmt := range program.Statements {
+		if loop, ok := stmt.(*ast.ForInStatement); ok {
+			visit([]ast.Statement{loop})
+		}
+	}
+	for name := range expected {
+		if !found[name] {
+			t.Errorf("%s was not declared", name)
+		}
+	}
+}
+
+func TestCheckerErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+		code  string
+	}{
+		{"annotated variable", `var count: int = "many"`, "1:18: cannot assign string to variable count of type int", CodeAssign},
+		{"inferred variable", "var n = 1\nn = \"two\"", "2:5: cannot assign string to variable n of type int", CodeAssign},
+		{"unknown type", `var x: Strin = "a"`, "1:5: unknown type Strin", CodeUnknownType},
+		{"operator", "var x = true - 1", "1:14: operator - not defined for bool and int", CodeOperator},
+		{"string comparison", `var x = "a" < "b"`, "1:13: operator < not defined for string and string", CodeOperator},
+		{"prefix operator", `var x = -"a"`, "1:9: operator - not defined for string", CodeOperator},
+		{"arity", "func add(a, b) {\n    return a + b\n}\nadd(1)", "4:1: add expects 2 arguments, got 1", CodeArguments},
+		{"argument type", "func greet(name: string) {\n}\ngreet(42)", "3:7: argument 1 to greet: cannot use int as string", CodeArguments},
+		{"dotted builtin", `var n: int = read.line()`, "1:14: cannot assign string to variable n of type int", CodeAssign},
+		{"builtin arity", `var n = len("a", "b")`, "1:9: len expects 1 argument, got 2", CodeArguments},
+		{"constructor", "class P {\n    func init(x: int) {\n    }\n}\nvar p = P(\"a\")", "5:11: argument 1 to P: cannot use string as int", CodeArguments},
+		{"return type", "func f(): int {\n    return \"x\"\n}", "2:12: cannot return string from f, declared to return int", CodeReturn},
+		{"missing return value", "func f(): int {\n    return\n}", "2:5: missing return value in f, declared to return int", CodeReturn},
+		{"method return type", "class A {\n    func name(): string {\n        return 1\n    }\n}", "3:16: cannot return int from A.name, declared to return string", CodeReturn},
+		{"attribute", "class A {\n    var n: int = \"x\"\n}", "2:18: cannot assign string to attribute n of type int", CodeAssign},
+		{"call of non-function", "var x = 1\nx()", "2:1: cannot call x, a value of type int", CodeCall},
+		{"list index", "var l = [1]\nvar x = l[\"a\"]", "2:11: list index must be int, got string", CodeIndex},
+		{"iteration", "for x in 5 {\n}", "1:10: cannot iterate over int", CodeIterate},
+		{"list method", "var l = [1, 2]\nl.Append(\"a\")", "2:10: argument 1 to (l.Append): cannot use string as int", CodeArguments},
+		{"element assignment", "var l = [1, 2]\nl[0] = \"a\"", "2:8: cannot assign string to an element of list<int>", CodeAssign},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := check(t, tt.input)
+			var messages []string
+			for _, err := range c.Errors() {
+				messages = append(messages, err.Error())
+			}
+			if len(messages) != 1 || messages[0] != tt.want {
+				t.Errorf("expected error %q, got %q", tt.want, strings.Join(messages, "; "))
+			} else if c.Errors()[0].Code != tt.code {
+				t.Errorf("expected code %s, got %s", tt.code, c.Errors()[0].Code)
+			}
+		})
+	}
+}
+
+func TestCheckerDynamicCode(t *testing.T) {
+	// Lo que no se puede inferir es any y no produce errores
+	input := `
+import utils
+var x = null
+x = 1
+x = "a"
+func id(v) {
+    return v
+}
+var y = id(1) + id("a")
+var z = utils.anything(1, 2, 3)
+var h = {"a": 1, "b": "x"}
+var v = h["a"] - 1
+show.log("a", 1, true)
+var f = (a, b) => a * b
+var r = f(2, 3) / 2
+`
+	c, _ := check(t, input)
+	if errs := c.Errors(); len(errs) > 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+}
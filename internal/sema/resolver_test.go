@@ -0,0 +1,182 @@
+package sema
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/parser"
+)
+
+func resolve(t *testing.T, input string) []string {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+	sa := NewSemanticAnalyzer()
+	sa.Analyze(program)
+	return sa.Errors()
+}
+
+func TestResolverErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"if body", "if true {\n    show.log(a)\n}", "2:14: identifier not found: a"},
+		{"else body", "if true {\n} else {\n    b = 1\n}", "3:5: identifier not found: b"},
+		{"while body", "while false {\n    show.log(c)\n}", "2:14: identifier not found: c"},
+		{"for body", "for x in [1] {\n    show.log(x + d)\n}", "2:18: identifier not found: d"},
+		{"method body", "class A {\n    func f() {\n        return e\n    }\n}", "3:16: identifier not found: e"},
+		{"catch body", "try {\n} catch (err) {\n    show.log(f)\n}", "3:14: identifier not found: f"},
+		{"loop variable scope", "for x in [1] {\n}\nshow.log(x)", "3:10: identifier not found: x"},
+		{"catch variable scope", "try {\n} catch (err) {\n}\nshow.log(err)", "4:10: identifier not found: err"},
+		{"block scope", "if true {\n    var inner = 1\n}\nshow.log(inner)", "4:10: identifier not found: inner"},
+		{"use before declaration", "show.log(later)\nvar later = 1", "1:10: identifier not found: later"},
+		{"lambda", "var f = (a) => a + g", "1:20: identifier not found: g"},
+		{"hash value", `var h = {"k": v}`, "1:15: identifier not found: v"},
+		{"duplicate variable", "var x = 1\nvar x = 2", "2:5: duplicate declaration of x"},
+		{"duplicate function", "func f() {\n}\nfunc f() {\n}", "3:6: duplicate declaration of f"},
+		{"duplicate parameter", "func f(a, a) {\n}", "1:11: duplicate declaration of a"},
+		{"duplicate method", "class A {\n    func m() {\n    }\n    func m() {\n    }\n}", "4:10: duplicate declaration of m"},
+		{"break outside loop", "break", "1:1: break outside of a loop"},
+		{"continue outside loop", "if true {\n    continue\n}", "2:5: continue outside of a loop"},
+		{"break in function inside loop", "while true {\n    func f() {\n        break\n    }\n}", "3:9: break outside of a loop"},
+		{"this outside class", "show.log(this)", "1:10: 'this' outside of a class"},
+		{"super without superclass", "class A {\n    func f() {\n        return super.f()\n    }\n}", "3:16: 'super' outside of a subclass"},
+		{"misspelled dotted builtin", "show.lg(1)", "1:1: identifier not found: show.lg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := resolve(t, tt.input)
+			if len(errs) != 1 || errs[0] != tt.want {
+				t.Errorf("expected error %q, got %q", tt.want, strings.Join(errs, "; "))
+			}
+		})
+	}
+}
+
+func TestResolverSuggestions(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"show.lg(1)", "show.log"},
+		{"var cuenta = 1\nshow.log(cuenat)", "cuenta"},
+		{"func f(total) {\n    return totl\n}", "total"},
+		{"if true {\n    var inner = 1\n}\nshow.log(iner)", ""}, // inner no es visible
+		{"show.log(zzzzzz)", ""},
+	}
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		sa := NewSemanticAnalyzer()
+		sa.Analyze(p.ParseProgram())
+		errs := sa.NameErrors()
+		if len(errs) != 1 || errs[0].Suggestion != tt.want || errs[0].Code != CodeNotFound {
+			t.Errorf("%q: expected suggestion %q, got %v", tt.input, tt.want, sa.Errors())
+		}
+	}
+}
+
+func TestResolverValidPrograms(t *testing.T) {
+	input := `
+import zyloruntime
+func main() {
+    show.log(greeting, len(items))
+    helper()
+}
+var greeting = "hola"
+var items = [1, 2]
+func helper() {
+    var x = x2()
+    return x
+}
+func x2() {
+    return 1
+}
+class Animal {
+    var name = "a"
+    func init(name) {
+        this.name = name
+    }
+    func speak() {
+        return this.name
+    }
+}
+class Dog extends Animal {
+    func speak() {
+        return super.speak() + "!"
+    }
+}
+var d = Dog("rex")
+for item in items {
+    if item > 1 {
+        break
+    }
+    while false {
+        continue
+    }
+}
+try {
+    throw "x"
+} catch (e) {
+    show.log(e)
+}
+var len = 3
+var x = 1
+if true {
+    var x = x + 1
+}
+var input = read.line()
+var sq = (n) => n * n
+var parts = zyloruntime.Split("a b", " ")
+main()
+`
+	if errs := resolve(t, input); len(errs) > 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+}
+
+func TestSymbolReferences(t *testing.T) {
+	input := "var x = 1\nfunc f(x) {\n    return x\n}\nshow.log(x, f(x))\n"
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	sa := NewSemanticAnalyzer()
+	sa.Analyze(program)
+
+	global, ok := sa.Declared("x")
+	if !ok {
+		t.Fatal("x is not declared")
+	}
+	// Declared devuelve el último x declarado: el parámetro
+	if global.Node == nil || global.Node.Token.StartLine != 2 {
+		t.Fatalf("expected the parameter x, got %+v", global.Node)
+	}
+
+	var lines []int
+	for _, ident := range sa.References(global) {
+		lines = append(lines, ident.Token.StartLine)
+	}
+	if len(lines) != 2 || lines[0] != 2 || lines[1] != 3 {
+		t.Errorf("expected the parameter on lines 2 and 3, got %v", lines)
+	}
+
+	// Los usos en el nivel superior son del x global
+	call := program.Statements[2].(*ast.ExpressionStatement).Expression.(*ast.CallExpression)
+	use := call.Arguments[0].(*ast.Identifier)
+	sym, ok := sa.SymbolOf(use)
+	if !ok || sym.Node != program.Statements[0].(*ast.VarStatement).Name {
+		t.Errorf("expected x to resolve to the global declaration, got %+v", sym)
+	}
+	if refs := sa.References(sym); len(refs) != 3 {
+		t.Errorf("expected 3 references to the global x, got %d", len(refs))
+	}
+	f := call.Arguments[1].(*ast.CallExpression).Function.(*ast.Identifier)
+	if sym, ok := sa.SymbolOf(f); !ok || sym.Type != "func" {
+		t.Errorf("expected f to resolve to a function, got %+v", sym)
+	}
+}
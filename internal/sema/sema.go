@@ -2,8 +2,12 @@ package sema
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/zylo-lang/zylo/internal/ast"
+	"github.com/zylo-lang/zylo/internal/diag"
+	"github.com/zylo-lang/zylo/internal/evaluator"
 )
 
 // SymbolTable representa una tabla de símbolos para un ámbito específico.
@@ -17,8 +21,9 @@ type SymbolTable struct {
 // Symbol representa una entrada en la tabla de símbolos.
 type Symbol struct {
 	Name  string
-	Type  string // Tipo del identificador (e.g., "int", "string", "any").
-	Scope string // Ámbito en el que se definió.
+	Type  string          // Tipo del identificador (e.g., "int", "string", "any").
+	Scope string          // Ámbito en el que se definió.
+	Node  *ast.Identifier // Identificador que lo declara, nil en los built-ins.
 }
 
 // NewSymbolTable crea una nueva tabla de símbolos.
@@ -53,19 +58,69 @@ func (st *SymbolTable) Resolve(name string) (*Symbol, bool) {
 	return nil, false
 }
 
-// SemanticAnalyzer es el struct principal para el análisis semántico.
+// visibleNames devuelve los nombres declarados en la tabla y en sus padres.
+func (st *SymbolTable) visibleNames() []string {
+	var names []string
+	for table := st; table != nil; table = table.parent {
+		for name := range table.symbols {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Códigos de NameError. No cambian entre versiones.
+const (
+	CodeNotFound     = "N001" // Un nombre sin declarar
+	CodeDuplicate    = "N002" // Un nombre declarado dos veces en un ámbito
+	CodeOutsideLoop  = "N003" // break o continue fuera de un bucle
+	CodeOutsideClass = "N004" // this o super fuera de una clase o subclase
+)
+
+// NameError es un error de resolución de nombres en una posición del código
+// fuente. Suggestion es un nombre visible parecido al que no se encontró, o
+// "".
+type NameError struct {
+	Span       ast.Span
+	Code       string
+	Message    string
+	Suggestion string
+}
+
+func (e *NameError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Span.StartLine, e.Span.StartCol, e.Message)
+}
+
+// SemanticAnalyzer resuelve los nombres de un programa: informa los nombres
+// sin declarar, las declaraciones repetidas en un mismo ámbito y los break y
+// continue fuera de un bucle.
+//
+// Las declaraciones se ven a partir de donde aparecen, igual que al
+// ejecutar; los cuerpos de las funciones se resuelven al terminar el bloque
+// que las declara, porque pueden usar lo que se declara después de ellas.
 type SemanticAnalyzer struct {
 	symbolTable *SymbolTable
-	errors      []string
+	errors      []*NameError
+	declared    map[string]*Symbol          // Último símbolo declarado con cada nombre, en cualquier ámbito
+	uses        map[*ast.Identifier]*Symbol // Símbolo de cada declaración y uso resuelto
+	deferred    []func()                    // Cuerpos de funciones pendientes del bloque actual
+	loops       int                         // Bucles que rodean a la sentencia actual en su función
+	class       *ast.ClassStatement         // Clase cuyos métodos se resuelven
 }
 
-// NewSemanticAnalyzer crea un nuevo analizador semántico.
+// NewSemanticAnalyzer crea un nuevo analizador semántico. El ámbito global
+// desciende de uno con los built-ins del evaluador, que se pueden redefinir.
 func NewSemanticAnalyzer() *SemanticAnalyzer {
-	// Inicializar con una tabla de símbolos global.
-	globalScope := NewSymbolTable("global", 0, nil)
+	builtins := NewSymbolTable("builtins", 0, nil)
+	for _, name := range evaluator.BuiltinNames() {
+		builtins.Define(name, "builtin")
+	}
+	globalScope := NewSymbolTable("global", 0, builtins)
 	return &SemanticAnalyzer{
 		symbolTable: globalScope,
-		errors:      []string{},
+		errors:      []*NameError{},
+		declared:    make(map[string]*Symbol),
+		uses:        make(map[*ast.Identifier]*Symbol),
 	}
 }
 
@@ -73,40 +128,99 @@ func NewSemanticAnalyzer() *SemanticAnalyzer {
 func (sa *SemanticAnalyzer) Analyze(node ast.Node) {
 	switch n := node.(type) {
 	case *ast.Program:
-		for _, stmt := range n.Statements {
-			sa.Analyze(stmt) // Recursivamente analizar cada sentencia.
-		}
+		sa.analyzeStatements(n.Statements)
 	case *ast.VarStatement:
-		// Definir la variable en la tabla de símbolos.
-		// Por ahora, asumimos tipo "any" por defecto.
-		sa.symbolTable.Define(n.Name.Value, "any")
-		// Analizar el valor de la expresión y su tipo.
+		// El valor se resuelve antes de declarar la variable: 'var x = x'
+		// usa la x de un ámbito exterior.
 		if n.Value != nil {
 			sa.Analyze(n.Value)
 		}
+		sa.define(n.Name, "any")
 	case *ast.ExpressionStatement:
-		sa.Analyze(n.Expression)
+		if n.Expression != nil {
+			sa.Analyze(n.Expression)
+		}
+	case *ast.ImportStatement:
+		// Un import declara el módulo o, con 'from', los nombres importados.
+		if n.Names == nil {
+			sa.define(n.ModuleName, "module")
+		}
+		for _, name := range n.Names {
+			sa.define(name, "any")
+		}
+	case *ast.ExportStatement:
+		sa.Analyze(n.Statement)
 	case *ast.Identifier:
 		// Al encontrar un identificador, verificar si está definido.
-		if _, ok := sa.symbolTable.Resolve(n.Value); !ok {
-			sa.addError(fmt.Sprintf("identifier not found: %s", n.Value))
+		if sym, ok := sa.symbolTable.Resolve(n.Value); ok {
+			sa.uses[n] = sym
+		} else {
+			sa.notFound(n, n.Value)
 		}
 	case *ast.FuncStatement:
 		// Registrar la función en la tabla de símbolos.
-		// Por ahora, el tipo de la función es genérico "func".
-		sa.symbolTable.Define(n.Name.Value, "func")
-		// Analizar el cuerpo de la función en un nuevo scope.
-		sa.enterScope(n.Name.Value)
-		// Registrar los parámetros de la función en el nuevo scope
-		for _, param := range n.Parameters {
-			sa.symbolTable.Define(param.Value, "any") // Usar param.Value ya que es *ast.Identifier
-		}
-		sa.Analyze(n.Body)
-		sa.exitScope()
+		sa.define(n.Name, "func")
+		sa.deferFunction(n.Name.Value, n.Parameters, n.Body)
+	case *ast.FunctionLiteral:
+		// Las funciones anónimas abren su propio scope, como las declaradas.
+		// Se resuelven en el momento: solo ven lo declarado antes de ellas.
+		sa.analyzeFunction("<anónima>", n.Parameters, n.Body)
+	case *ast.ClassStatement:
+		sa.analyzeClass(n)
+	case *ast.ReturnStatement:
+		if n.ReturnValue != nil {
+			sa.Analyze(n.ReturnValue)
+		}
 	case *ast.BlockStatement:
-		// Analizar cada sentencia dentro del bloque.
-		for _, stmt := range n.Statements {
-			sa.Analyze(stmt)
+		sa.analyzeBlock("<bloque>", n)
+	case *ast.IfStatement:
+		sa.Analyze(n.Condition)
+		sa.analyzeBlock("<if>", n.Consequence)
+		if n.Alternative != nil {
+			sa.analyzeBlock("<else>", n.Alternative)
+		}
+	case *ast.WhileStatement:
+		sa.Analyze(n.Condition)
+		sa.loops++
+		sa.analyzeBlock("<while>", n.Body)
+		sa.loops--
+	case *ast.ForInStatement:
+		// La variable del bucle pertenece al cuerpo
+		sa.Analyze(n.Iterable)
+		sa.enterScope("<for>")
+		sa.define(n.Identifier, "any")
+		sa.loops++
+		if n.Body != nil {
+			sa.analyzeStatements(n.Body.Statements)
+		}
+		sa.loops--
+		sa.exitScope()
+	case *ast.TryStatement:
+		sa.analyzeBlock("<try>", n.TryBlock)
+		if c := n.CatchClause; c != nil {
+			sa.enterScope("<catch>")
+			if c.Parameter != nil {
+				sa.define(c.Parameter, "any")
+			}
+			if c.CatchBlock != nil {
+				sa.analyzeStatements(c.CatchBlock.Statements)
+			}
+			sa.exitScope()
+		}
+		if n.FinallyBlock != nil {
+			sa.analyzeBlock("<finally>", n.FinallyBlock)
+		}
+	case *ast.ThrowStatement:
+		if n.Exception != nil {
+			sa.Analyze(n.Exception)
+		}
+	case *ast.BreakStatement:
+		if sa.loops == 0 {
+			sa.addErrorAt(n, CodeOutsideLoop, "break outside of a loop")
+		}
+	case *ast.ContinueStatement:
+		if sa.loops == 0 {
+			sa.addErrorAt(n, CodeOutsideLoop, "continue outside of a loop")
 		}
 	case *ast.CallExpression:
 		// Analizar la función y los argumentos
@@ -114,6 +228,52 @@ func (sa *SemanticAnalyzer) Analyze(node ast.Node) {
 		for _, arg := range n.Arguments {
 			sa.Analyze(arg)
 		}
+	case *ast.ClassInstantiation:
+		sa.Analyze(n.ClassName)
+		for _, arg := range n.Arguments {
+			sa.Analyze(arg)
+		}
+	case *ast.MemberExpression:
+		// show.log y read.line son built-ins con punto en el nombre; del
+		// resto solo se resuelve el objeto, no la propiedad.
+		if object, ok := n.Object.(*ast.Identifier); ok {
+			name := object.Value + "." + n.Property.Value
+			if _, ok := sa.symbolTable.Resolve(name); ok {
+				return
+			}
+			// 'show.lg' es un error en el nombre completo, no en 'show'
+			if _, ok := sa.symbolTable.Resolve(object.Value); !ok && sa.isNamespace(object.Value) {
+				sa.notFound(n, name)
+				return
+			}
+		}
+		sa.Analyze(n.Object)
+	case *ast.IndexExpression:
+		sa.Analyze(n.Left)
+		sa.Analyze(n.Index)
+	case *ast.ListLiteral:
+		for _, element := range n.Elements {
+			sa.Analyze(element)
+		}
+	case *ast.HashLiteral:
+		for key, value := range n.Pairs {
+			sa.Analyze(key)
+			sa.Analyze(value)
+		}
+	case *ast.BlockExpression:
+		sa.analyzeBlock("<bloque>", n.Block)
+	case *ast.ThisExpression:
+		if sa.class == nil {
+			sa.addErrorAt(n, CodeOutsideClass, "'this' outside of a class")
+		}
+	case *ast.SuperExpression:
+		if sa.class == nil || sa.class.SuperClass == nil {
+			sa.addErrorAt(n, CodeOutsideClass, "'super' outside of a subclass")
+		}
+	case *ast.SpawnExpression:
+		sa.Analyze(n.Call)
+	case *ast.AwaitExpression:
+		sa.Analyze(n.Value)
 	case *ast.InfixExpression:
 		// Analizar las expresiones izquierda y derecha
 		sa.Analyze(n.Left)
@@ -121,12 +281,141 @@ func (sa *SemanticAnalyzer) Analyze(node ast.Node) {
 	case *ast.PrefixExpression:
 		// Analizar la expresión derecha
 		sa.Analyze(n.Right)
-	case *ast.NumberLiteral, *ast.StringLiteral, *ast.BooleanLiteral:
+	case *ast.NumberLiteral, *ast.StringLiteral, *ast.BooleanLiteral, *ast.NullLiteral:
 		// Los literales no necesitan análisis semántico adicional
-		// TODO: Añadir manejo para otros tipos de nodos (ClassDecl, IfStmt, WhileStmt, etc.)
 	}
 }
 
+// analyzeStatements resuelve las sentencias de un bloque en el ámbito actual
+// y, al final, los cuerpos de las funciones declaradas en él.
+func (sa *SemanticAnalyzer) analyzeStatements(statements []ast.Statement) {
+	outer := sa.deferred
+	sa.deferred = nil
+	for _, stmt := range statements {
+		sa.Analyze(stmt) // Recursivamente analizar cada sentencia.
+	}
+	for len(sa.deferred) > 0 {
+		next := sa.deferred[0]
+		sa.deferred = sa.deferred[1:]
+		next()
+	}
+	sa.deferred = outer
+}
+
+// analyzeBlock resuelve un bloque en un ámbito nuevo.
+func (sa *SemanticAnalyzer) analyzeBlock(name string, block *ast.BlockStatement) {
+	if block == nil {
+		return
+	}
+	sa.enterScope(name)
+	sa.analyzeStatements(block.Statements)
+	sa.exitScope()
+}
+
+// deferFunction deja el cuerpo de una función para el final del bloque
+// actual, resuelto en el ámbito donde se declaró.
+func (sa *SemanticAnalyzer) deferFunction(name string, params []*ast.Identifier, body *ast.BlockStatement) {
+	scope, class := sa.symbolTable, sa.class
+	sa.deferred = append(sa.deferred, func() {
+		outerScope, outerClass := sa.symbolTable, sa.class
+		sa.symbolTable, sa.class = scope, class
+		sa.analyzeFunction(name, params, body)
+		sa.symbolTable, sa.class = outerScope, outerClass
+	})
+}
+
+// analyzeFunction resuelve los parámetros y el cuerpo de una función en un
+// ámbito nuevo. Los bucles exteriores no cuentan para break y continue.
+func (sa *SemanticAnalyzer) analyzeFunction(name string, params []*ast.Identifier, body *ast.BlockStatement) {
+	outerLoops := sa.loops
+	sa.loops = 0
+	sa.enterScope(name)
+	// Registrar los parámetros de la función en el nuevo scope
+	for _, param := range params {
+		sa.define(param, "any")
+	}
+	if body != nil {
+		sa.analyzeStatements(body.Statements)
+	}
+	sa.exitScope()
+	sa.loops = outerLoops
+}
+
+// analyzeClass declara la clase y resuelve sus atributos y métodos, con
+// 'this' disponible en ellos.
+func (sa *SemanticAnalyzer) analyzeClass(n *ast.ClassStatement) {
+	if n.SuperClass != nil {
+		sa.Analyze(n.SuperClass)
+	}
+	sa.define(n.Name, "class")
+
+	outerClass := sa.class
+	sa.class = n
+	sa.enterScope(n.Name.Value)
+	members := NewSymbolTable(n.Name.Value, 0, nil) // Para detectar miembros repetidos
+	for _, attr := range n.Attributes {
+		if attr.Value != nil {
+			sa.Analyze(attr.Value)
+		}
+		sa.defineIn(members, attr.Name, "any")
+	}
+	for _, method := range n.Methods {
+		sa.defineIn(members, method.Name, "func")
+		sa.deferFunction(n.Name.Value+"."+method.Name.Value, method.Parameters, method.Body)
+	}
+	sa.exitScope()
+	sa.class = outerClass
+}
+
+// define declara ident en el ámbito actual.
+func (sa *SemanticAnalyzer) define(ident *ast.Identifier, symType string) {
+	if ident == nil {
+		return
+	}
+	sa.declared[ident.Value] = sa.defineIn(sa.symbolTable, ident, symType)
+}
+
+// defineIn declara ident en table e informa si ya estaba declarado en ella.
+func (sa *SemanticAnalyzer) defineIn(table *SymbolTable, ident *ast.Identifier, symType string) *Symbol {
+	if _, exists := table.symbols[ident.Value]; exists {
+		sa.addErrorAt(ident, CodeDuplicate, fmt.Sprintf("duplicate declaration of %s", ident.Value))
+	}
+	sym := table.Define(ident.Value, symType)
+	sym.Node = ident
+	sa.uses[ident] = sym
+	return sym
+}
+
+// Declared devuelve el último símbolo declarado con ese nombre en cualquier
+// ámbito del programa.
+func (sa *SemanticAnalyzer) Declared(name string) (*Symbol, bool) {
+	sym, ok := sa.declared[name]
+	return sym, ok
+}
+
+// SymbolOf devuelve el símbolo al que se refiere ident, que puede ser su
+// declaración o un uso.
+func (sa *SemanticAnalyzer) SymbolOf(ident *ast.Identifier) (*Symbol, bool) {
+	sym, ok := sa.uses[ident]
+	return sym, ok
+}
+
+// References devuelve la declaración y los usos de sym, en el orden en que
+// aparecen en el código fuente.
+func (sa *SemanticAnalyzer) References(sym *Symbol) []*ast.Identifier {
+	var refs []*ast.Identifier
+	for ident, s := range sa.uses {
+		if s == sym {
+			refs = append(refs, ident)
+		}
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		a, b := refs[i].Token, refs[j].Token
+		return a.StartLine < b.StartLine || (a.StartLine == b.StartLine && a.StartCol < b.StartCol)
+	})
+	return refs
+}
+
 // enterScope crea un nuevo ámbito y lo establece como el ámbito actual.
 func (sa *SemanticAnalyzer) enterScope(name string) {
 	newScope := NewSymbolTable(name, sa.symbolTable.scopeLevel+1, sa.symbolTable)
@@ -140,12 +429,44 @@ func (sa *SemanticAnalyzer) exitScope() {
 	}
 }
 
-// addError añade un error de análisis semántico.
-func (sa *SemanticAnalyzer) addError(msg string) {
-	sa.errors = append(sa.errors, msg)
+// addErrorAt añade un error con la posición de node.
+func (sa *SemanticAnalyzer) addErrorAt(node ast.Node, code, msg string) {
+	sa.errors = append(sa.errors, &NameError{Span: ast.SpanOf(node), Code: code, Message: msg})
 }
 
-// Errors devuelve los errores encontrados.
+// notFound informa que name, usado en node, no está declarado, y sugiere el
+// nombre visible más parecido.
+func (sa *SemanticAnalyzer) notFound(node ast.Node, name string) {
+	suggestion, _ := diag.Suggest(name, sa.symbolTable.visibleNames())
+	sa.errors = append(sa.errors, &NameError{
+		Span:       ast.SpanOf(node),
+		Code:       CodeNotFound,
+		Message:    fmt.Sprintf("identifier not found: %s", name),
+		Suggestion: suggestion,
+	})
+}
+
+// isNamespace indica si name es la parte anterior al punto de algún
+// built-in, como show en show.log.
+func (sa *SemanticAnalyzer) isNamespace(name string) bool {
+	for _, visible := range sa.symbolTable.visibleNames() {
+		if strings.HasPrefix(visible, name+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Errors devuelve los errores encontrados, como "3:5: mensaje".
 func (sa *SemanticAnalyzer) Errors() []string {
+	messages := make([]string, len(sa.errors))
+	for i, err := range sa.errors {
+		messages[i] = err.Error()
+	}
+	return messages
+}
+
+// NameErrors devuelve los errores encontrados con su región y sugerencia.
+func (sa *SemanticAnalyzer) NameErrors() []*NameError {
 	return sa.errors
 }
@@ -49,12 +49,12 @@ var x = y; // y is not declared
 			},
 		},
 		{
-			name: "Shadowing (optional, current implementation allows it)",
+			name: "Duplicate declaration in the same scope",
 			input: `
 var z = 10;
-var z = 20; // This might be allowed or an error depending on language rules.
+var z = 20; // Redeclarar en el mismo ámbito es un error.
 `,
-			expectedErrors: 0, // Asumiendo que el shadowing simple es permitido por ahora.
+			expectedErrors: 1,
 			expectedSymbols: map[string]string{
 				"z": "any", // El último 'z' define el símbolo.
 			},
@@ -63,7 +63,7 @@ var z = 20; // This might be allowed or an error depending on language rules.
 			name: "Function declaration and usage",
 			input: `
 func greet(name) {
-  print("Hello, " + name);
+  show.log("Hello, " + name);
 }
 greet("World");
 `,
@@ -102,12 +102,12 @@ func outer() {
 	var outerVar = 20;
 	func inner() {
 		var innerVar = 30;
-		print(globalVar);
-		print(outerVar);
-		print(innerVar);
+		show.log(globalVar);
+		show.log(outerVar);
+		show.log(innerVar);
 	}
 	inner();
-	print(outerVar);
+	show.log(outerVar);
 }
 outer();
 `,
@@ -124,7 +124,7 @@ outer();
 			name: "Undeclared variable in nested scope",
 			input: `
 func outer() {
-	print(undeclaredVar);
+	show.log(undeclaredVar);
 }
 outer();
 `,
@@ -153,7 +153,7 @@ outer();
 
 			// Verificar los símbolos definidos (simplificado)
 			for name, symType := range tt.expectedSymbols {
-				sym, ok := sa.symbolTable.Resolve(name)
+				sym, ok := sa.Declared(name)
 				if !ok {
 					t.Errorf("Symbol '%s' not found in symbol table", name)
 				} else if sym.Type != symType {
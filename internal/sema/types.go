@@ -0,0 +1,282 @@
+package sema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kind clasifica los tipos de Zylo.
+type Kind int
+
+const (
+	KindAny      Kind = iota // Tipo dinámico: se comprueba al ejecutar
+	KindNull                 // null
+	KindBool                 // true, false
+	KindInt                  // Enteros
+	KindFloat                // Números con decimales
+	KindString               // Strings
+	KindList                 // Listas, con el tipo de sus elementos en Elem
+	KindMap                  // Hashes, con el tipo de sus valores en Elem
+	KindFunc                 // Funciones, con Params y Result
+	KindClass                // Una clase, que se llama para crear instancias
+	KindInstance             // Una instancia de Class
+	KindFuture               // El resultado de una función async, con su valor en Elem
+)
+
+// Type es el tipo estático de una expresión o de una variable.
+type Type struct {
+	Kind     Kind
+	Elem     *Type   // Elementos de una lista, valores de un hash o valor de un future
+	Params   []*Type // Parámetros de una función
+	Result   *Type   // Resultado de una función
+	Variadic bool    // La función acepta cualquier número de argumentos
+	Class    *Class  // Clase de KindClass y KindInstance
+}
+
+// Class describe una clase declarada en el programa.
+type Class struct {
+	Name    string
+	Super   *Class
+	Fields  map[string]*Type // Atributos declarados con 'var'
+	Methods map[string]*Type // Métodos, sin contar init
+	Init    *Type            // Tipo del método init, nil si no lo tiene
+}
+
+// Tipos básicos, compartidos por todas las expresiones que los tienen.
+var (
+	Any    = &Type{Kind: KindAny}
+	Null   = &Type{Kind: KindNull}
+	Bool   = &Type{Kind: KindBool}
+	Int    = &Type{Kind: KindInt}
+	Float  = &Type{Kind: KindFloat}
+	String = &Type{Kind: KindString}
+)
+
+// ListOf devuelve el tipo de las listas de elem.
+func ListOf(elem *Type) *Type { return &Type{Kind: KindList, Elem: elem} }
+
+// MapOf devuelve el tipo de los hashes con valores de tipo elem.
+func MapOf(elem *Type) *Type { return &Type{Kind: KindMap, Elem: elem} }
+
+// FutureOf devuelve el tipo del future de una función async que devuelve elem.
+func FutureOf(elem *Type) *Type { return &Type{Kind: KindFuture, Elem: elem} }
+
+// Func devuelve el tipo de una función con esos parámetros y resultado.
+func Func(result *Type, params ...*Type) *Type {
+	return &Type{Kind: KindFunc, Params: params, Result: result}
+}
+
+// VariadicFunc devuelve el tipo de una función que acepta cualquier número
+// de argumentos de cualquier tipo.
+func VariadicFunc(result *Type) *Type {
+	return &Type{Kind: KindFunc, Result: result, Variadic: true}
+}
+
+// InstanceOf devuelve el tipo de las instancias de class.
+func InstanceOf(class *Class) *Type { return &Type{Kind: KindInstance, Class: class} }
+
+// IsNumeric indica si t es int o float.
+func (t *Type) IsNumeric() bool {
+	return t.Kind == KindInt || t.Kind == KindFloat
+}
+
+func (t *Type) String() string {
+	switch t.Kind {
+	case KindNull:
+		return "null"
+	case KindBool:
+		return "bool"
+	case KindInt:
+		return "int"
+	case KindFloat:
+		return "float"
+	case KindString:
+		return "string"
+	case KindList:
+		return "list<" + t.Elem.String() + ">"
+	case KindMap:
+		return "map<" + t.Elem.String() + ">"
+	case KindFuture:
+		return "future<" + t.Elem.String() + ">"
+	case KindFunc:
+		if t.Variadic {
+			return "func(...) " + t.Result.String()
+		}
+		params := make([]string, len(t.Params))
+		for i, param := range t.Params {
+			params[i] = param.String()
+		}
+		return fmt.Sprintf("func(%s) %s", strings.Join(params, ", "), t.Result)
+	case KindClass:
+		return "class " + t.Class.Name
+	case KindInstance:
+		return t.Class.Name
+	}
+	return "any"
+}
+
+// IsSubclassOf indica si c es other o hereda de ella.
+func (c *Class) IsSubclassOf(other *Class) bool {
+	for class := c; class != nil; class = class.Super {
+		if class == other {
+			return true
+		}
+	}
+	return false
+}
+
+// Member devuelve el tipo del atributo o método name, buscando también en
+// las superclases.
+func (c *Class) Member(name string) (*Type, bool) {
+	for class := c; class != nil; class = class.Super {
+		if t, ok := class.Fields[name]; ok {
+			return t, true
+		}
+		if t, ok := class.Methods[name]; ok {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// CollectionMethod devuelve el tipo del método name de las listas o los
+// hashes de tipo t: Get, Len y Append en las listas, Get, Set y Len en los
+// hashes. Los nombres valen también en minúsculas, como en la documentación.
+func CollectionMethod(t *Type, name string) (*Type, bool) {
+	switch t.Kind {
+	case KindList:
+		switch name {
+		case "Get", "get":
+			return Func(t.Elem, Int), true
+		case "Len", "len":
+			return Func(Int), true
+		case "Append", "append":
+			return Func(Null, t.Elem), true
+		}
+	case KindMap:
+		switch name {
+		case "Get", "get":
+			return Func(t.Elem, String), true
+		case "Set", "set":
+			return Func(Null, String, t.Elem), true
+		case "Len", "len":
+			return Func(Int), true
+		}
+	}
+	return nil, false
+}
+
+// Assignable indica si un valor de tipo from se puede usar donde se espera
+// to. any es compatible con todo, null con cualquier tipo, un int donde se
+// espera un float y una instancia donde se espera una de sus superclases.
+func Assignable(from, to *Type) bool {
+	if from.Kind == KindAny || to.Kind == KindAny || from.Kind == KindNull {
+		return true
+	}
+	switch to.Kind {
+	case KindFloat:
+		return from.IsNumeric()
+	case KindList, KindMap, KindFuture:
+		return from.Kind == to.Kind && Assignable(from.Elem, to.Elem)
+	case KindFunc:
+		if from.Kind != KindFunc {
+			return false
+		}
+		if from.Variadic || to.Variadic {
+			return true
+		}
+		if len(from.Params) != len(to.Params) || !Assignable(from.Result, to.Result) {
+			return false
+		}
+		for i := range from.Params {
+			if !Assignable(to.Params[i], from.Params[i]) {
+				return false
+			}
+		}
+		return true
+	case KindClass:
+		return from.Kind == KindClass && from.Class.IsSubclassOf(to.Class)
+	case KindInstance:
+		return from.Kind == KindInstance && from.Class.IsSubclassOf(to.Class)
+	}
+	return from.Kind == to.Kind
+}
+
+// join devuelve el tipo que abarca a a y b: el mismo si coinciden, float
+// para int y float, el otro si uno es null, y any en el resto de casos.
+func join(a, b *Type) *Type {
+	switch {
+	case a == nil:
+		return b
+	case a.Kind == KindNull:
+		return b
+	case b.Kind == KindNull:
+		return a
+	case a.IsNumeric() && b.IsNumeric() && a.Kind != b.Kind:
+		return Float
+	case Assignable(a, b) && Assignable(b, a):
+		return a
+	}
+	return Any
+}
+
+// basicTypes son los nombres de tipo de las anotaciones, en minúsculas: la
+// documentación usa 'int' y 'string' y los ejemplos 'Int' y 'String'.
+var basicTypes = map[string]*Type{
+	"any":     Any,
+	"dynamic": Any,
+	"null":    Null,
+	"void":    Null,
+	"bool":    Bool,
+	"boolean": Bool,
+	"int":     Int,
+	"integer": Int,
+	"float":   Float,
+	"number":  Float,
+	"string":  String,
+	"list":    ListOf(Any),
+	"array":   ListOf(Any),
+	"map":     MapOf(Any),
+	"hash":    MapOf(Any),
+}
+
+// parseType convierte una anotación como "Int", "Array<String>" o
+// "Map<String, Int>" en un tipo. classes son las clases del programa.
+func parseType(annotation string, classes map[string]*Class) (*Type, error) {
+	name, args, generic := strings.Cut(annotation, "<")
+	name = strings.TrimSpace(name)
+	if !generic {
+		if t, ok := basicTypes[strings.ToLower(name)]; ok {
+			return t, nil
+		}
+		if class, ok := classes[name]; ok {
+			return InstanceOf(class), nil
+		}
+		return nil, fmt.Errorf("unknown type %s", annotation)
+	}
+
+	var params []*Type
+	for _, arg := range strings.Split(strings.TrimSuffix(args, ">"), ",") {
+		t, err := parseType(strings.TrimSpace(arg), classes)
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, t)
+	}
+	switch strings.ToLower(name) {
+	case "list", "array":
+		if len(params) == 1 {
+			return ListOf(params[0]), nil
+		}
+	case "map", "hash":
+		// Las claves de un hash son siempre strings
+		if len(params) == 1 || len(params) == 2 {
+			return MapOf(params[len(params)-1]), nil
+		}
+	case "future":
+		if len(params) == 1 {
+			return FutureOf(params[0]), nil
+		}
+	}
+	return nil, fmt.Errorf("unknown type %s", annotation)
+}
@@ -0,0 +1,104 @@
+package testrunner
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Elementos del formato JUnit XML que leen los servidores de CI.
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// WriteJUnit escribe los resultados en formato JUnit XML, con un testsuite
+// por archivo.
+func WriteJUnit(w io.Writer, summary *Summary) error {
+	report := junitSuites{
+		Tests:    len(summary.Results),
+		Failures: summary.Failed,
+		Skipped:  summary.Skipped,
+	}
+
+	index := map[string]int{} // Archivo -> posición de su suite en report.Suites
+	durations := map[string]time.Duration{}
+	for _, r := range summary.Results {
+		i, ok := index[r.File]
+		if !ok {
+			i = len(report.Suites)
+			index[r.File] = i
+			report.Suites = append(report.Suites, junitSuite{Name: r.File})
+		}
+		suite := &report.Suites[i]
+		durations[r.File] += r.Duration
+
+		testCase := junitCase{
+			Name:      r.Name,
+			Classname: r.File,
+			Time:      seconds(r.Duration),
+			SystemOut: r.Output,
+		}
+		switch r.Status {
+		case Fail:
+			suite.Failures++
+			testCase.Failure = &junitMessage{Message: firstLine(r.Message), Text: r.Message}
+		case Skip:
+			suite.Skipped++
+			testCase.Skipped = &junitMessage{Message: r.Message}
+		}
+		suite.Tests++
+		suite.Cases = append(suite.Cases, testCase)
+	}
+	for i := range report.Suites {
+		report.Suites[i].Time = seconds(durations[report.Suites[i].Name])
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
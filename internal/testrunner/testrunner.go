@@ -0,0 +1,231 @@
+// Package testrunner implementa 'zylo test': busca archivos *_test.zylo y
+// ejecuta cada función test_* en un evaluador nuevo.
+package testrunner
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+	"github.com/zylo-lang/zylo/internal/evaluator"
+	"github.com/zylo-lang/zylo/internal/modules"
+)
+
+// Sufijo de los archivos de prueba y prefijo de las funciones de prueba.
+const (
+	fileSuffix = "_test.zylo"
+	testPrefix = "test_"
+)
+
+// Status es el resultado de una prueba.
+type Status string
+
+const (
+	Pass Status = "PASS"
+	Fail Status = "FAIL"
+	Skip Status = "SKIP"
+)
+
+// Códigos de los diagnósticos de las pruebas que no vienen de un error con
+// código propio. No cambian entre versiones.
+const (
+	CodeFailed  = "X001" // Una prueba que falló
+	CodeSkipped = "X002" // Una prueba omitida con skip()
+)
+
+// Result es el resultado de una prueba. Los errores al leer o parsear un
+// archivo se informan como una prueba fallida llamada "init".
+type Result struct {
+	File     string
+	Name     string
+	Span     ast.Span // Región del nombre de la función de prueba
+	Status   Status
+	Message  string // Error de un fallo o motivo de un salto
+	Err      error  // Error de un fallo, como un *evaluator.RuntimeError
+	Output   string // Lo que la prueba escribió en la salida
+	Duration time.Duration
+}
+
+// Summary agrupa los resultados de una ejecución.
+type Summary struct {
+	Results                 []Result
+	Passed, Failed, Skipped int
+}
+
+// OK indica si ninguna prueba falló.
+func (s *Summary) OK() bool {
+	return s.Failed == 0
+}
+
+func (s *Summary) add(results ...Result) {
+	for _, r := range results {
+		s.Results = append(s.Results, r)
+		switch r.Status {
+		case Pass:
+			s.Passed++
+		case Fail:
+			s.Failed++
+		case Skip:
+			s.Skipped++
+		}
+	}
+}
+
+// Discover devuelve los archivos de prueba de paths, ordenados. Un
+// directorio se recorre entero buscando *_test.zylo; un archivo se usa tal
+// cual.
+func Discover(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		err = filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && strings.HasSuffix(file, fileSuffix) {
+				files = append(files, file)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Run ejecuta las pruebas de files cuyo nombre coincide con filter (todas si
+// filter es nil).
+func Run(files []string, filter *regexp.Regexp) *Summary {
+	summary := &Summary{}
+	for _, file := range files {
+		summary.add(RunFile(file, filter)...)
+	}
+	return summary
+}
+
+// RunFile ejecuta las pruebas de un archivo cuyo nombre coincide con filter.
+func RunFile(filename string, filter *regexp.Regexp) []Result {
+	program, err := modules.Parse(filename)
+	if err != nil {
+		return []Result{{File: filename, Name: "init", Status: Fail, Message: err.Error(), Err: err}}
+	}
+
+	var results []Result
+	for _, test := range testFunctions(program) {
+		if filter != nil && !filter.MatchString(test.Name.Value) {
+			continue
+		}
+		results = append(results, runTest(filename, program, test))
+	}
+	return results
+}
+
+// testFunctions devuelve las funciones test_* de nivel superior, en el orden
+// del archivo.
+func testFunctions(program *ast.Program) []*ast.FuncStatement {
+	var tests []*ast.FuncStatement
+	for _, stmt := range program.Statements {
+		if fn, ok := stmt.(*ast.FuncStatement); ok && strings.HasPrefix(fn.Name.Value, testPrefix) {
+			tests = append(tests, fn)
+		}
+	}
+	return tests
+}
+
+// skipError es el error con el que skip() detiene una prueba.
+type skipError struct {
+	reason string
+}
+
+func (s *skipError) Error() string { return "skipped: " + s.reason }
+
+// runTest ejecuta una prueba en un evaluador nuevo: primero el código de
+// nivel superior del archivo y después la función.
+func runTest(filename string, program *ast.Program, test *ast.FuncStatement) Result {
+	result := Result{File: filename, Name: test.Name.Value, Span: ast.TokenSpan(test.Name.Token)}
+	var output strings.Builder
+	start := time.Now()
+	err := func() error {
+		if len(test.Parameters) > 0 {
+			return fmt.Errorf("%s: test functions take no parameters", test.Name.Value)
+		}
+		eval := evaluator.NewEvaluatorWithIO(strings.NewReader(""), &output, &output)
+		eval.SetFile(filename)
+		eval.Define("skip", &evaluator.BuiltinFunction{
+			Name: "skip",
+			Fn: func(args []evaluator.Value) (evaluator.Value, error) {
+				reason := ""
+				if len(args) > 0 {
+					if s, ok := args[0].(*evaluator.String); ok {
+						reason = s.Value
+					}
+				}
+				return nil, &skipError{reason: reason}
+			},
+		})
+		if _, err := eval.EvaluateStatements(program); err != nil {
+			return err
+		}
+		fn, _ := eval.Lookup(test.Name.Value)
+		_, err := eval.Call(fn, nil)
+		return err
+	}()
+	result.Duration = time.Since(start)
+	result.Output = output.String()
+
+	var skip *skipError
+	switch {
+	case err == nil:
+		result.Status = Pass
+	case errors.As(err, &skip):
+		result.Status = Skip
+		result.Message = skip.reason
+	default:
+		result.Status = Fail
+		result.Message = err.Error()
+		result.Err = err
+	}
+	return result
+}
+
+// Report escribe los resultados en w, una línea por prueba, y un resumen
+// final. La salida de una prueba solo se muestra si falla.
+func Report(w io.Writer, summary *Summary) {
+	for _, r := range summary.Results {
+		fmt.Fprintf(w, "--- %s: %s %s (%.2fs)\n", r.Status, r.File, r.Name, r.Duration.Seconds())
+		if r.Message != "" {
+			fmt.Fprintln(w, indent(r.Message))
+		}
+		if r.Status == Fail && r.Output != "" {
+			fmt.Fprintln(w, indent(strings.TrimRight(r.Output, "\n")))
+		}
+	}
+
+	status := Pass
+	if !summary.OK() {
+		status = Fail
+	}
+	fmt.Fprintf(w, "%s: %d pruebas, %d pasaron, %d fallaron, %d omitidas\n",
+		status, len(summary.Results), summary.Passed, summary.Failed, summary.Skipped)
+}
+
+func indent(s string) string {
+	return "    " + strings.ReplaceAll(s, "\n", "\n    ")
+}
@@ -0,0 +1,167 @@
+package testrunner
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/zylo-lang/zylo/internal/evaluator"
+	"github.com/zylo-lang/zylo/internal/modules"
+)
+
+const sample = `var base = 10
+
+func add(a, b) {
+    return a + b
+}
+
+func test_add() {
+    assertEqual(add(base, 2), 12)
+}
+
+func test_isolated() {
+    base = base + 1
+    assertEqual(base, 11)
+}
+
+func test_fails() {
+    show.log("debug")
+    assertEqual(add(1, 2), 4)
+}
+
+func test_skipped() {
+    skip("not ready")
+    assert(false)
+}
+
+func helper() {
+    assert(false)
+}
+`
+
+// writeFiles crea los archivos de files en un directorio temporal y devuelve
+// el directorio.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestDiscover(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"b_test.zylo":     "",
+		"a_test.zylo":     "",
+		"main.zylo":       "",
+		"sub/c_test.zylo": "",
+	})
+	files, err := Discover([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, file := range files {
+		rel, _ := filepath.Rel(dir, file)
+		names = append(names, filepath.ToSlash(rel))
+	}
+	if got := strings.Join(names, " "); got != "a_test.zylo b_test.zylo sub/c_test.zylo" {
+		t.Errorf("unexpected files: %s", got)
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"math_test.zylo": sample})
+	summary := Run([]string{filepath.Join(dir, "math_test.zylo")}, nil)
+
+	if summary.Passed != 2 || summary.Failed != 1 || summary.Skipped != 1 || summary.OK() {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	expected := map[string]Status{"test_add": Pass, "test_isolated": Pass, "test_fails": Fail, "test_skipped": Skip}
+	for _, r := range summary.Results {
+		if r.Status != expected[r.Name] {
+			t.Errorf("%s: expected %s, got %s (%s)", r.Name, expected[r.Name], r.Status, r.Message)
+		}
+	}
+
+	failed := summary.Results[2]
+	if !strings.HasSuffix(failed.Message, "math_test.zylo:18:5: assertion failed: expected 4, got 3") {
+		t.Errorf("expected the assertion with its position, got %q", failed.Message)
+	}
+	var runtimeErr *evaluator.RuntimeError
+	if !errors.As(failed.Err, &runtimeErr) || runtimeErr.Span.StartLine != 18 {
+		t.Errorf("expected the runtime error of the assertion, got %v", failed.Err)
+	}
+	if failed.Span.StartLine != 16 || failed.Span.StartCol != 6 {
+		t.Errorf("expected the test function at 16:6, got %+v", failed.Span)
+	}
+	if failed.Output != "debug\n" {
+		t.Errorf("expected the test output to be captured, got %q", failed.Output)
+	}
+	if summary.Results[3].Message != "not ready" {
+		t.Errorf("expected the skip reason, got %q", summary.Results[3].Message)
+	}
+}
+
+func TestRunFilter(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"math_test.zylo": sample})
+	summary := Run([]string{filepath.Join(dir, "math_test.zylo")}, regexp.MustCompile("^test_(add|skipped)$"))
+	if len(summary.Results) != 2 || !summary.OK() {
+		t.Errorf("expected only the matching tests, got %+v", summary.Results)
+	}
+}
+
+func TestRunInitErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"syntax_test.zylo":   "func test_x( {",
+		"toplevel_test.zylo": "throw \"boom\"\nfunc test_x() {\n}\n",
+	})
+	summary := Run([]string{filepath.Join(dir, "syntax_test.zylo"), filepath.Join(dir, "toplevel_test.zylo")}, nil)
+	if summary.Failed != 2 {
+		t.Fatalf("expected both files to fail, got %+v", summary.Results)
+	}
+	var parseErr *modules.ParseError
+	if r := summary.Results[0]; r.Name != "init" || !strings.Contains(r.Message, "errores de parsing") || !errors.As(r.Err, &parseErr) {
+		t.Errorf("unexpected result for a syntax error: %+v", r)
+	}
+	if r := summary.Results[1]; r.Name != "test_x" || !strings.Contains(r.Message, "boom") {
+		t.Errorf("unexpected result for a top-level error: %+v", r)
+	}
+}
+
+func TestReports(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"math_test.zylo": sample})
+	summary := Run([]string{filepath.Join(dir, "math_test.zylo")}, nil)
+
+	var text strings.Builder
+	Report(&text, summary)
+	if !strings.Contains(text.String(), "FAIL: 4 pruebas, 2 pasaron, 1 fallaron, 1 omitidas\n") {
+		t.Errorf("unexpected report:\n%s", text.String())
+	}
+
+	var junit strings.Builder
+	if err := WriteJUnit(&junit, summary); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<testsuites tests="4" failures="1" skipped="1">`,
+		`<testcase name="test_add"`,
+		`<failure message="`,
+		`<skipped message="not ready"></skipped>`,
+		`<system-out>debug&#xA;</system-out>`,
+	} {
+		if !strings.Contains(junit.String(), want) {
+			t.Errorf("expected JUnit output to contain %q, got:\n%s", want, junit.String())
+		}
+	}
+}
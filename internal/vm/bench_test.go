@@ -0,0 +1,124 @@
+package vm
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+	"github.com/zylo-lang/zylo/internal/compiler"
+	"github.com/zylo-lang/zylo/internal/evaluator"
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/parser"
+)
+
+// benchPrograms son los programas que se ejecutan con ambos motores.
+var benchPrograms = []struct {
+	name   string
+	source string
+}{
+	{"fib", `
+func fib(n) {
+    if n < 2 {
+        return n
+    }
+    return fib(n - 1) + fib(n - 2)
+}
+var result = fib(20)
+`},
+	{"loop", `
+var total = 0
+var i = 0
+while i < 100000 {
+    total = total + i * 2
+    i = i + 1
+}
+`},
+	{"nested_loops", `
+func count(n) {
+    var hits = 0
+    var i = 0
+    while i < n {
+        var j = 0
+        while j < n {
+            if (i + j) / 3 * 3 == i + j {
+                hits = hits + 1
+            }
+            j = j + 1
+        }
+        i = i + 1
+    }
+    return hits
+}
+var result = count(200)
+`},
+	{"lists", `
+var items = []
+var i = 0
+while i < 5000 {
+    items.Append(i)
+    i = i + 1
+}
+var total = 0
+for x in items {
+    total = total + x
+}
+`},
+	{"strings", `
+var s = ""
+var i = 0
+while i < 2000 {
+    s = s + "x"
+    i = i + 1
+}
+`},
+}
+
+func parseBenchProgram(b *testing.B, source string) *ast.Program {
+	b.Helper()
+	p := parser.New(lexer.New(source))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		b.Fatalf("Parser errors: %v", p.Errors())
+	}
+	return program
+}
+
+func newBenchHost() *evaluator.Evaluator {
+	return evaluator.NewEvaluatorWithIO(strings.NewReader(""), io.Discard, io.Discard)
+}
+
+// BenchmarkTreeWalker mide el evaluador de árboles sobre benchPrograms.
+func BenchmarkTreeWalker(b *testing.B) {
+	for _, prog := range benchPrograms {
+		b.Run(prog.name, func(b *testing.B) {
+			program := parseBenchProgram(b, prog.source)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := newBenchHost().EvaluateProgram(program); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkVM mide la VM sobre los mismos programas; la compilación se
+// incluye en cada iteración, igual que el evaluador recorre el AST completo.
+func BenchmarkVM(b *testing.B) {
+	for _, prog := range benchPrograms {
+		b.Run(prog.name, func(b *testing.B) {
+			program := parseBenchProgram(b, prog.source)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				bytecode, err := compiler.New().Compile(program)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if err := New(bytecode, newBenchHost()).Run(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
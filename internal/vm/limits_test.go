@@ -0,0 +1,134 @@
+package vm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zylo-lang/zylo/internal/compiler"
+	"github.com/zylo-lang/zylo/internal/evaluator"
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/parser"
+)
+
+// runVMWithLimits compila input y lo ejecuta en la VM con un anfitrión con
+// los límites y el contexto dados.
+func runVMWithLimits(t *testing.T, ctx context.Context, limits evaluator.Limits, input string) error {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+	bytecode, err := compiler.New().Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	host := evaluator.NewEvaluatorWithIO(strings.NewReader(""), &strings.Builder{}, &strings.Builder{})
+	host.SetContext(ctx)
+	host.SetLimits(limits)
+	return New(bytecode, host).Run()
+}
+
+func TestExecutionLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		limits   evaluator.Limits
+		input    string
+		expected error
+	}{
+		{"infinite loop hits step limit", evaluator.Limits{MaxSteps: 1000}, "while true {\n}", evaluator.ErrStepLimit},
+		{"runaway recursion hits call depth", evaluator.Limits{MaxCallDepth: 50}, "func f(n) {\n    return f(n + 1)\n}\nf(0)", evaluator.ErrCallDepth},
+		{"string growth hits allocation limit", evaluator.Limits{MaxAllocBytes: 1024}, "var s = \"x\"\nwhile true {\n    s = s + s\n}", evaluator.ErrAllocLimit},
+		{"list literals hit allocation limit", evaluator.Limits{MaxAllocBytes: 1024}, "var l = []\nwhile true {\n    l = [1, 2, 3]\n}", evaluator.ErrAllocLimit},
+		{"list growth hits allocation limit", evaluator.Limits{MaxAllocBytes: 1024}, "var l = []\nwhile true {\n    l.Append(1)\n}", evaluator.ErrAllocLimit},
+		{"split results hit allocation limit", evaluator.Limits{MaxAllocBytes: 1024}, "var parts = []\nwhile true {\n    parts = split(\"a,b,c,d\", \",\")\n}", evaluator.ErrAllocLimit},
+		{"hash literals hit allocation limit", evaluator.Limits{MaxAllocBytes: 1024}, "var h = {}\nwhile true {\n    h = {\"a\": 1, \"b\": 2}\n}", evaluator.ErrAllocLimit},
+		{"hash growth hits allocation limit", evaluator.Limits{MaxAllocBytes: 1024}, "var h = {}\nvar i = 0\nwhile true {\n    h[string(i)] = i\n    i = i + 1\n}", evaluator.ErrAllocLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := runVMWithLimits(t, context.Background(), tt.limits, tt.input)
+			if !errors.Is(err, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, err)
+			}
+			var runtimeErr *evaluator.RuntimeError
+			if !errors.As(err, &runtimeErr) || runtimeErr.Span.StartLine == 0 {
+				t.Fatalf("expected a positioned *RuntimeError, got %T (%v)", err, err)
+			}
+			if runtimeErr.Code() != evaluator.CodeLimit {
+				t.Errorf("expected code %s, got %s", evaluator.CodeLimit, runtimeErr.Code())
+			}
+		})
+	}
+}
+
+func TestContextCancellation(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := runVMWithLimits(t, ctx, evaluator.Limits{}, "while true {\n}")
+	if !errors.Is(err, evaluator.ErrCanceled) {
+		t.Fatalf("expected ErrCanceled, got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the context error to be wrapped, got %v", err)
+	}
+}
+
+func TestLimitsAreNotCatchable(t *testing.T) {
+	tests := []struct {
+		name     string
+		limits   evaluator.Limits
+		input    string
+		expected error
+	}{
+		{"empty catch", evaluator.Limits{}, "while true { try { while true { } } catch (e) { } }", evaluator.ErrCanceled},
+		{"catch doing work", evaluator.Limits{}, "var n = 0\nwhile true {\n    try {\n        while true { }\n    } catch (e) {\n        n = n + 1\n    }\n}", evaluator.ErrCanceled},
+		{"try with finally", evaluator.Limits{}, "var n = 0\ntry {\n    while true { }\n} finally {\n    n = 1\n}", evaluator.ErrCanceled},
+		{"step limit", evaluator.Limits{MaxSteps: 10000}, "while true { try { while true { } } catch (e) { } }", evaluator.ErrStepLimit},
+		{"call depth", evaluator.Limits{MaxCallDepth: 50}, "func f(n) {\n    return f(n + 1)\n}\nwhile true { try { f(0) } catch (e) { } }", evaluator.ErrCallDepth},
+		{"allocation", evaluator.Limits{MaxAllocBytes: 1024}, "var l = []\nwhile true { try { l.Append(1) } catch (e) { } }", evaluator.ErrAllocLimit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			done := make(chan error, 1)
+			go func() { done <- runVMWithLimits(t, ctx, tt.limits, tt.input) }()
+			select {
+			case err := <-done:
+				if !errors.Is(err, tt.expected) {
+					t.Fatalf("expected %v, got %v", tt.expected, err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("execution was still running 5s after the deadline")
+			}
+		})
+	}
+}
+
+func TestFinallyRunsOnCancellation(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	var out strings.Builder
+	p := parser.New(lexer.New("try {\n    while true { }\n} catch (e) {\n    show.log(\"caught\")\n} finally {\n    show.log(\"finally\")\n}"))
+	bytecode, err := compiler.New().Compile(p.ParseProgram())
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+	host := evaluator.NewEvaluatorWithIO(strings.NewReader(""), &out, &out)
+	host.SetContext(ctx)
+	if err := New(bytecode, host).Run(); !errors.Is(err, evaluator.ErrCanceled) {
+		t.Fatalf("expected ErrCanceled, got %v", err)
+	}
+	if got := out.String(); got != "finally\n" {
+		t.Errorf("expected only the finally block to run, got %q", got)
+	}
+}
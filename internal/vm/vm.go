@@ -0,0 +1,638 @@
+// Package vm ejecuta el bytecode generado por internal/compiler. Es un motor
+// alternativo al evaluador de árboles: usa los mismos valores y built-ins (a
+// través de un *evaluator.Evaluator anfitrión) pero resuelve las variables a
+// slots en lugar de recorrer la cadena de entornos.
+package vm
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zylo-lang/zylo/internal/compiler"
+	"github.com/zylo-lang/zylo/internal/evaluator"
+)
+
+const (
+	// StackSize es el número máximo de valores en la pila de la VM.
+	StackSize = 1 << 16
+	// initialStackSize es el tamaño inicial de la pila; crece según haga falta.
+	initialStackSize = 1 << 10
+	// stepInterval es cada cuántas instrucciones se contabilizan los pasos en
+	// el anfitrión, que comprueba la cancelación y el límite de pasos.
+	stepInterval = 256
+)
+
+// Valores compartidos: son inmutables, así que no hace falta reservarlos de nuevo.
+var (
+	True  = &evaluator.Boolean{Value: true}
+	False = &evaluator.Boolean{Value: false}
+	Null  = &evaluator.Null{}
+)
+
+// Enteros pequeños precreados para no reservar memoria en cada operación.
+const (
+	smallIntMin = -128
+	smallIntMax = 1023
+)
+
+var smallInts = func() []*evaluator.Integer {
+	ints := make([]*evaluator.Integer, smallIntMax-smallIntMin+1)
+	for i := range ints {
+		ints[i] = &evaluator.Integer{Value: int64(i + smallIntMin)}
+	}
+	return ints
+}()
+
+func newInteger(v int64) *evaluator.Integer {
+	if v >= smallIntMin && v <= smallIntMax {
+		return smallInts[v-smallIntMin]
+	}
+	return &evaluator.Integer{Value: v}
+}
+
+func newBoolean(v bool) *evaluator.Boolean {
+	if v {
+		return True
+	}
+	return False
+}
+
+// Frame es la activación de una función compilada.
+type Frame struct {
+	fn *compiler.CompiledFunction
+	ip int // Próxima instrucción
+	bp int // Base de los slots locales en la pila
+}
+
+// handler es un manejador de excepciones instalado por OpTry.
+type handler struct {
+	frame int // Índice del marco que lo instaló
+	sp    int // Altura de la pila al instalarlo
+	addr  int // Dirección del código del catch
+}
+
+// iterator recorre los elementos de un for-in.
+type iterator struct {
+	items []evaluator.Value
+	pos   int
+}
+
+// pendingError es la excepción que se está manejando, en la pila mientras se
+// ejecuta su catch o su finally.
+type pendingError struct {
+	err error
+}
+
+// VM ejecuta un programa compilado.
+type VM struct {
+	host      *evaluator.Evaluator
+	constants []evaluator.Value
+	globals   []evaluator.Value
+	names     []string // Nombre de cada slot global
+
+	stack []evaluator.Value
+	sp    int // Próximo slot libre de la pila
+
+	frames   []Frame
+	handlers []handler
+	opStart  int // Offset de la instrucción en curso, para los errores
+	file     string
+
+	steps    int // Instrucciones ejecutadas desde la última contabilización
+	maxDepth int // Profundidad máxima de llamadas, la del anfitrión; 0 sin límite
+}
+
+// New crea una VM para bytecode. host aporta los built-ins, la entrada y
+// salida y la semántica de los operadores; los globales cuyo nombre existe en
+// host (show.log, len, ...) se inicializan con su valor.
+func New(bytecode *compiler.Bytecode, host *evaluator.Evaluator) *VM {
+	globals := make([]evaluator.Value, len(bytecode.Globals))
+	for i, name := range bytecode.Globals {
+		if value, ok := host.Lookup(name); ok {
+			globals[i] = value
+		}
+	}
+
+	frames := make([]Frame, 1, 64)
+	frames[0] = Frame{fn: bytecode.Main}
+
+	return &VM{
+		host:      host,
+		constants: bytecode.Constants,
+		globals:   globals,
+		names:     bytecode.Globals,
+		stack:     make([]evaluator.Value, initialStackSize),
+		frames:    frames,
+	}
+}
+
+// SetFile indica el nombre del archivo fuente para los errores de ejecución.
+func (vm *VM) SetFile(filename string) {
+	vm.file = filename
+}
+
+// Global devuelve el valor de la variable global de nivel superior name.
+func (vm *VM) Global(name string) (evaluator.Value, bool) {
+	for i, n := range vm.names {
+		if n == name && vm.globals[i] != nil {
+			return vm.globals[i], true
+		}
+	}
+	return nil, false
+}
+
+// Run ejecuta el programa hasta el final. Los errores se devuelven como
+// *evaluator.RuntimeError con la posición y la pila de llamadas de Zylo.
+// La ejecución respeta el contexto y los límites del anfitrión, como
+// EvaluateProgram.
+func (vm *VM) Run() error {
+	vm.maxDepth = vm.host.Limits().MaxCallDepth
+	return vm.host.Execute(func() error {
+		for {
+			err := vm.run()
+			if err == nil {
+				return nil
+			}
+			runtimeErr := vm.runtimeError(err)
+			if !vm.handle(runtimeErr) {
+				return runtimeErr
+			}
+		}
+	})
+}
+
+// uncatchable indica si err atraviesa los catch. Además de la cancelación y
+// el deadlock, que tampoco captura el evaluador, la vm deja pasar los límites
+// de ejecución: solo los comprueba cada stepInterval instrucciones, y un
+// catch que los capturara podría mantener viva la ejecución para siempre.
+// Los bloques finally sí se ejecutan.
+func uncatchable(err error) bool {
+	for _, target := range []error{evaluator.ErrCanceled, evaluator.ErrDeadlock,
+		evaluator.ErrStepLimit, evaluator.ErrCallDepth, evaluator.ErrAllocLimit} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// handle transfiere el control al manejador de excepciones más reciente. Si
+// err no se puede capturar (ver uncatchable), OpCatch lo relanza después de
+// ejecutar el finally.
+func (vm *VM) handle(err error) bool {
+	if len(vm.handlers) == 0 {
+		return false
+	}
+	h := vm.handlers[len(vm.handlers)-1]
+	vm.handlers = vm.handlers[:len(vm.handlers)-1]
+
+	vm.frames = vm.frames[:h.frame+1]
+	vm.frames[h.frame].ip = h.addr
+	vm.sp = h.sp
+	vm.stack[vm.sp] = &pendingError{err: err}
+	vm.sp++
+	return true
+}
+
+// runtimeError ubica err en la instrucción en curso, salvo que ya esté ubicado.
+func (vm *VM) runtimeError(err error) error {
+	if runtimeErr, ok := err.(*evaluator.RuntimeError); ok {
+		return runtimeErr
+	}
+
+	stack := make([]evaluator.StackFrame, 0, len(vm.frames))
+	for i, frame := range vm.frames {
+		offset := frame.ip - 1 // La llamada en curso en los marcos externos
+		if i == len(vm.frames)-1 {
+			offset = vm.opStart
+		}
+		span := frame.fn.SpanAt(offset)
+		if span.StartLine == 0 {
+			continue // Llamadas sin posición, como la de main
+		}
+		stack = append(stack, evaluator.StackFrame{
+			Function: frame.fn.Name,
+			File:     vm.file,
+			Line:     span.StartLine,
+			Column:   span.StartCol,
+		})
+	}
+
+	return &evaluator.RuntimeError{
+		Message: err.Error(),
+		File:    vm.file,
+		Span:    vm.frames[len(vm.frames)-1].fn.SpanAt(vm.opStart),
+		Stack:   stack,
+		Cause:   err,
+	}
+}
+
+func (vm *VM) push(value evaluator.Value) error {
+	if vm.sp >= len(vm.stack) {
+		if err := vm.grow(vm.sp + 1); err != nil {
+			return err
+		}
+	}
+	vm.stack[vm.sp] = value
+	vm.sp++
+	return nil
+}
+
+// grow amplía la pila para que quepan size valores.
+func (vm *VM) grow(size int) error {
+	if size > StackSize {
+		return fmt.Errorf("desbordamiento de la pila de la vm")
+	}
+	stack := make([]evaluator.Value, min(max(2*len(vm.stack), size), StackSize))
+	copy(stack, vm.stack)
+	vm.stack = stack
+	return nil
+}
+
+// pop desapila un valor. El slot no se limpia: se sobrescribe en el siguiente
+// push y así se evita la barrera de escritura del GC en cada instrucción.
+func (vm *VM) pop() evaluator.Value {
+	vm.sp--
+	return vm.stack[vm.sp]
+}
+
+// run ejecuta instrucciones hasta que el programa termina o falla.
+func (vm *VM) run() error {
+	frame := &vm.frames[len(vm.frames)-1]
+	ins := frame.fn.Instructions
+
+	for frame.ip < len(ins) {
+		vm.opStart = frame.ip
+		op := compiler.Opcode(ins[frame.ip])
+		frame.ip++
+
+		if vm.steps++; vm.steps == stepInterval {
+			vm.steps = 0
+			if err := vm.host.Steps(stepInterval); err != nil {
+				return err
+			}
+		}
+
+		switch op {
+		case compiler.OpConstant:
+			index := compiler.ReadUint16(ins[frame.ip:])
+			frame.ip += 2
+			if err := vm.push(vm.constants[index]); err != nil {
+				return err
+			}
+
+		case compiler.OpNull:
+			if err := vm.push(Null); err != nil {
+				return err
+			}
+		case compiler.OpTrue:
+			if err := vm.push(True); err != nil {
+				return err
+			}
+		case compiler.OpFalse:
+			if err := vm.push(False); err != nil {
+				return err
+			}
+		case compiler.OpPop:
+			vm.pop()
+		case compiler.OpDup:
+			if err := vm.push(vm.stack[vm.sp-1]); err != nil {
+				return err
+			}
+
+		case compiler.OpAdd, compiler.OpSub, compiler.OpMul, compiler.OpDiv,
+			compiler.OpEqual, compiler.OpNotEqual, compiler.OpLess, compiler.OpGreater,
+			compiler.OpLessEqual, compiler.OpGreaterEqual, compiler.OpAnd, compiler.OpOr,
+			compiler.OpRange:
+			right := vm.pop()
+			left := vm.pop()
+			result, err := vm.binary(op, left, right)
+			if err != nil {
+				return err
+			}
+			if str, ok := result.(*evaluator.String); ok {
+				if err := vm.host.Allocate(int64(len(str.Value))); err != nil {
+					return err
+				}
+			}
+			vm.stack[vm.sp] = result
+			vm.sp++
+
+		case compiler.OpMinus:
+			operand := vm.pop()
+			num, ok := operand.(*evaluator.Integer)
+			if !ok {
+				return fmt.Errorf("operador '-' no soportado para tipo %T", operand)
+			}
+			vm.stack[vm.sp] = newInteger(-num.Value)
+			vm.sp++
+		case compiler.OpNot:
+			operand := vm.pop()
+			vm.stack[vm.sp] = newBoolean(!vm.host.IsTruthy(operand))
+			vm.sp++
+
+		case compiler.OpJump:
+			frame.ip = int(compiler.ReadUint16(ins[frame.ip:]))
+		case compiler.OpJumpIfFalse:
+			target := int(compiler.ReadUint16(ins[frame.ip:]))
+			frame.ip += 2
+			if !vm.truthy(vm.pop()) {
+				frame.ip = target
+			}
+
+		case compiler.OpGetGlobal:
+			index := compiler.ReadUint16(ins[frame.ip:])
+			frame.ip += 2
+			value := vm.globals[index]
+			if value == nil {
+				return fmt.Errorf("variable no definida: %s", vm.names[index])
+			}
+			if err := vm.push(value); err != nil {
+				return err
+			}
+		case compiler.OpSetGlobal:
+			index := compiler.ReadUint16(ins[frame.ip:])
+			frame.ip += 2
+			vm.globals[index] = vm.pop()
+		case compiler.OpGetLocal:
+			index := int(compiler.ReadUint16(ins[frame.ip:]))
+			frame.ip += 2
+			value := vm.stack[frame.bp+index]
+			if value == nil {
+				return fmt.Errorf("variable no definida: %s", frame.fn.LocalNames[index])
+			}
+			if err := vm.push(value); err != nil {
+				return err
+			}
+		case compiler.OpSetLocal:
+			index := int(compiler.ReadUint16(ins[frame.ip:]))
+			frame.ip += 2
+			vm.stack[frame.bp+index] = vm.pop()
+
+		case compiler.OpList:
+			count := int(compiler.ReadUint16(ins[frame.ip:]))
+			frame.ip += 2
+			if err := vm.host.Allocate(int64(count) * evaluator.ListItemSize); err != nil {
+				return err
+			}
+			items := make([]evaluator.Value, count)
+			copy(items, vm.stack[vm.sp-count:vm.sp])
+			vm.sp -= count
+			vm.stack[vm.sp] = &evaluator.List{Items: items}
+			vm.sp++
+		case compiler.OpHash:
+			count := int(compiler.ReadUint16(ins[frame.ip:]))
+			frame.ip += 2
+			pairs := make(map[string]evaluator.Value, count)
+			base := vm.sp - 2*count
+			var size int64
+			for i := base; i < vm.sp; i += 2 {
+				key, ok := vm.stack[i].(*evaluator.String)
+				if !ok {
+					return fmt.Errorf("hash key must be string")
+				}
+				if _, ok := pairs[key.Value]; !ok {
+					size += evaluator.HashEntrySize + int64(len(key.Value))
+				}
+				pairs[key.Value] = vm.stack[i+1]
+			}
+			if err := vm.host.Allocate(size); err != nil {
+				return err
+			}
+			vm.sp = base
+			vm.stack[vm.sp] = &evaluator.Hash{Pairs: pairs}
+			vm.sp++
+		case compiler.OpIndex:
+			index := vm.pop()
+			left := vm.pop()
+			value, err := vm.host.Index(left, index)
+			if err != nil {
+				return err
+			}
+			vm.stack[vm.sp] = value
+			vm.sp++
+		case compiler.OpSetIndex:
+			value := vm.pop()
+			index := vm.pop()
+			left := vm.pop()
+			if err := vm.host.SetIndex(left, index, value); err != nil {
+				return err
+			}
+			vm.stack[vm.sp] = value
+			vm.sp++
+		case compiler.OpGetMember:
+			name := vm.constants[compiler.ReadUint16(ins[frame.ip:])].(*evaluator.String).Value
+			frame.ip += 2
+			value, err := vm.host.Member(vm.pop(), name)
+			if err != nil {
+				return err
+			}
+			vm.stack[vm.sp] = value
+			vm.sp++
+		case compiler.OpSetMember:
+			name := vm.constants[compiler.ReadUint16(ins[frame.ip:])].(*evaluator.String).Value
+			frame.ip += 2
+			value := vm.pop()
+			if err := vm.host.SetMember(vm.pop(), name, value); err != nil {
+				return err
+			}
+			vm.stack[vm.sp] = value
+			vm.sp++
+
+		case compiler.OpCheckType:
+			annotation := vm.constants[compiler.ReadUint16(ins[frame.ip:])].(*evaluator.String).Value
+			frame.ip += 2
+			value, err := evaluator.CheckType(vm.stack[vm.sp-1], annotation)
+			if err != nil {
+				return err
+			}
+			vm.stack[vm.sp-1] = value
+
+		case compiler.OpCall:
+			argc := int(ins[frame.ip])
+			frame.ip++
+			callee := vm.stack[vm.sp-1-argc]
+			fn, ok := callee.(*compiler.CompiledFunction)
+			if !ok {
+				if err := vm.callHost(callee, argc); err != nil {
+					return err
+				}
+				continue
+			}
+			if vm.maxDepth > 0 && len(vm.frames) >= vm.maxDepth {
+				return fmt.Errorf("%w (%d)", evaluator.ErrCallDepth, vm.maxDepth)
+			}
+			bp := vm.sp - argc
+			if bp+fn.NumLocals >= len(vm.stack) {
+				if err := vm.grow(bp + fn.NumLocals + 1); err != nil {
+					return err
+				}
+			}
+			// Como en el evaluador, los parámetros sin argumento quedan sin definir
+			for i := min(argc, fn.NumParams); i < fn.NumLocals; i++ {
+				vm.stack[bp+i] = nil
+			}
+			vm.sp = bp + fn.NumLocals
+			vm.frames = append(vm.frames, Frame{fn: fn, bp: bp})
+			frame = &vm.frames[len(vm.frames)-1]
+			ins = fn.Instructions
+
+		case compiler.OpReturnValue, compiler.OpReturn:
+			var result evaluator.Value = Null
+			if op == compiler.OpReturnValue {
+				result = vm.pop()
+			}
+			returning := len(vm.frames) - 1
+			for len(vm.handlers) > 0 && vm.handlers[len(vm.handlers)-1].frame >= returning {
+				vm.handlers = vm.handlers[:len(vm.handlers)-1]
+			}
+			if returning == 0 {
+				return nil // Fin del programa
+			}
+			vm.sp = frame.bp - 1
+			vm.frames = vm.frames[:returning]
+			frame = &vm.frames[returning-1]
+			ins = frame.fn.Instructions
+			vm.stack[vm.sp] = result
+			vm.sp++
+
+		case compiler.OpIterStart:
+			iter, err := newIterator(vm.pop())
+			if err != nil {
+				return err
+			}
+			vm.stack[vm.sp] = iter
+			vm.sp++
+		case compiler.OpIterNext:
+			target := int(compiler.ReadUint16(ins[frame.ip:]))
+			frame.ip += 2
+			iter := vm.stack[vm.sp-1].(*iterator)
+			if iter.pos >= len(iter.items) {
+				frame.ip = target
+				continue
+			}
+			if err := vm.push(iter.items[iter.pos]); err != nil {
+				return err
+			}
+			iter.pos++
+
+		case compiler.OpTry:
+			addr := int(compiler.ReadUint16(ins[frame.ip:]))
+			frame.ip += 2
+			vm.handlers = append(vm.handlers, handler{frame: len(vm.frames) - 1, sp: vm.sp, addr: addr})
+		case compiler.OpEndTry:
+			vm.handlers = vm.handlers[:len(vm.handlers)-1]
+		case compiler.OpThrow:
+			return &evaluator.ZyloException{Value: vm.pop()}
+		case compiler.OpCatch:
+			rethrow := int(compiler.ReadUint16(ins[frame.ip:]))
+			frame.ip += 2
+			pending := vm.stack[vm.sp-1].(*pendingError)
+			if uncatchable(pending.err) {
+				if rethrow == 0 {
+					return vm.pop().(*pendingError).err
+				}
+				frame.ip = rethrow // El finally con la excepción pendiente
+				continue
+			}
+			vm.stack[vm.sp-1] = evaluator.ExceptionValue(pending.err)
+		case compiler.OpRethrow:
+			return vm.pop().(*pendingError).err
+
+		default:
+			return fmt.Errorf("opcode desconocido: %d", op)
+		}
+	}
+	return nil
+}
+
+// callHost llama a un built-in u otra función del evaluador anfitrión.
+func (vm *VM) callHost(callee evaluator.Value, argc int) error {
+	args := make([]evaluator.Value, argc)
+	copy(args, vm.stack[vm.sp-argc:vm.sp])
+	for i := vm.sp - argc - 1; i < vm.sp; i++ {
+		vm.stack[i] = nil
+	}
+	vm.sp -= argc + 1
+
+	var result evaluator.Value
+	var err error
+	switch fn := callee.(type) {
+	case *evaluator.BuiltinFunction:
+		if fn.EvalFn != nil {
+			result, err = vm.host.Call(fn, args) // Necesita el evaluador
+		} else {
+			result, err = fn.Fn(args)
+		}
+	case *evaluator.ZyloFunction, *evaluator.BoundMethod:
+		result, err = vm.host.Call(fn, args)
+	default:
+		return fmt.Errorf("no se puede llamar a: %T", callee)
+	}
+	if err != nil {
+		return err
+	}
+	if result == nil {
+		result = Null
+	}
+	vm.stack[vm.sp] = result
+	vm.sp++
+	return nil
+}
+
+// binary aplica un operador binario. Los enteros tienen un camino rápido; el
+// resto de combinaciones usa la semántica del evaluador.
+func (vm *VM) binary(op compiler.Opcode, left, right evaluator.Value) (evaluator.Value, error) {
+	if l, ok := left.(*evaluator.Integer); ok {
+		if r, ok := right.(*evaluator.Integer); ok {
+			switch op {
+			case compiler.OpAdd:
+				return newInteger(l.Value + r.Value), nil
+			case compiler.OpSub:
+				return newInteger(l.Value - r.Value), nil
+			case compiler.OpMul:
+				return newInteger(l.Value * r.Value), nil
+			case compiler.OpDiv:
+				if r.Value != 0 {
+					return newInteger(l.Value / r.Value), nil
+				}
+			case compiler.OpEqual:
+				return newBoolean(l.Value == r.Value), nil
+			case compiler.OpNotEqual:
+				return newBoolean(l.Value != r.Value), nil
+			case compiler.OpLess:
+				return newBoolean(l.Value < r.Value), nil
+			case compiler.OpGreater:
+				return newBoolean(l.Value > r.Value), nil
+			case compiler.OpLessEqual:
+				return newBoolean(l.Value <= r.Value), nil
+			case compiler.OpGreaterEqual:
+				return newBoolean(l.Value >= r.Value), nil
+			}
+		}
+	}
+	return vm.host.ApplyOperator(compiler.Operator(op), left, right)
+}
+
+// truthy evalúa una condición, con un camino rápido para los booleanos.
+func (vm *VM) truthy(value evaluator.Value) bool {
+	if b, ok := value.(*evaluator.Boolean); ok {
+		return b.Value
+	}
+	return vm.host.IsTruthy(value)
+}
+
+func newIterator(iterable evaluator.Value) (*iterator, error) {
+	switch it := iterable.(type) {
+	case *evaluator.List:
+		return &iterator{items: it.Elements()}, nil
+	case *evaluator.String:
+		var items []evaluator.Value
+		for _, char := range it.Value {
+			items = append(items, &evaluator.String{Value: string(char)})
+		}
+		return &iterator{items: items}, nil
+	default:
+		return nil, fmt.Errorf("cannot iterate over %T", iterable)
+	}
+}
@@ -0,0 +1,338 @@
+package vm
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/zylo-lang/zylo/internal/compiler"
+	"github.com/zylo-lang/zylo/internal/evaluator"
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/parser"
+)
+
+// runVM compila y ejecuta input en la VM, devolviendo la VM, la salida y el error.
+func runVM(t *testing.T, input string) (*VM, string, error) {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+	bytecode, err := compiler.New().Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	var out bytes.Buffer
+	host := evaluator.NewEvaluatorWithIO(strings.NewReader(""), &out, &out)
+	machine := New(bytecode, host)
+	err = machine.Run()
+	return machine, out.String(), err
+}
+
+// runTree ejecuta input con el evaluador de árboles y devuelve su salida.
+func runTree(t *testing.T, input string) (string, error) {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	var out bytes.Buffer
+	eval := evaluator.NewEvaluatorWithIO(strings.NewReader(""), &out, &out)
+	err := eval.EvaluateProgram(program)
+	return out.String(), err
+}
+
+func expectGlobal(t *testing.T, machine *VM, name, expected string) {
+	t.Helper()
+	value, ok := machine.Global(name)
+	if !ok {
+		t.Fatalf("global %q not defined", name)
+	}
+	obj, ok := value.(evaluator.ZyloObject)
+	if !ok {
+		t.Fatalf("global %q is %T", name, value)
+	}
+	if got := obj.Inspect(); got != expected {
+		t.Errorf("%s: expected %q, got %q", name, expected, got)
+	}
+}
+
+func TestPrograms(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"arithmetic", `var result = (2 + 3) * 4 - 10 / 2`, "15"},
+		{"float arithmetic", `var result = 1.5 * 2 + 1`, "4"},
+		{"string concatenation", `var result = "a" + 1 + "b"`, "a1b"},
+		{"comparison and logic", `var result = 1 < 2 && !(3 <= 2) || false`, "true"},
+		{"if elif else", `
+var n = 7
+var result = ""
+if n < 5 {
+    result = "small"
+} elif n < 10 {
+    result = "medium"
+} else {
+    result = "large"
+}`, "medium"},
+		{"while with break and continue", `
+var i = 0
+var result = 0
+while true {
+    i = i + 1
+    if i > 10 {
+        break
+    }
+    if i == 3 {
+        continue
+    }
+    result = result + i
+}`, "52"},
+		{"for in list and string", `
+var result = ""
+for x in [1, 2, 3] {
+    result = result + x
+}
+for c in "ab" {
+    result = result + c
+}`, "123ab"},
+		{"recursion", `
+func fib(n) {
+    if n < 2 {
+        return n
+    }
+    return fib(n - 1) + fib(n - 2)
+}
+var result = fib(15)`, "610"},
+		{"locals and block scopes", `
+func f(a) {
+    var x = a * 2
+    if true {
+        var x = 100
+    }
+    return x
+}
+var result = f(4)`, "8"},
+		{"missing argument stays undefined until assigned", `
+func f(a, b) {
+    return a
+}
+var result = f(1)`, "1"},
+		{"globals declared after the function", `
+func bump() {
+    counter = counter + 1
+}
+var counter = 10
+bump()
+bump()
+var result = counter`, "12"},
+		{"lists, hashes and index assignment", `
+var l = [1, 2, 3]
+l[1] = 20
+l.Append(4)
+var h = {"a": 1}
+h["b"] = 2
+var result = l[1] + len(l) + l[3]`, "28"},
+		{"try catch finally", `
+var result = ""
+try {
+    throw "boom"
+} catch (e) {
+    result = result + "caught " + e
+} finally {
+    result = result + ", finally"
+}`, "caught boom, finally"},
+		{"runtime errors are catchable", `
+var result = ""
+try {
+    var z = 1 / 0
+} catch e {
+    result = e
+}`, "división por cero"},
+		{"exception unwinds frames", `
+func inner() {
+    throw "deep"
+}
+func outer() {
+    inner()
+    return "unreachable"
+}
+var result = ""
+try {
+    outer()
+} catch (e) {
+    result = e
+}`, "deep"},
+		{"finally runs on return and break", `
+var result = ""
+func guarded() {
+    try {
+        return "ret"
+    } finally {
+        result = result + "f1 "
+    }
+}
+var i = 0
+while i < 3 {
+    i = i + 1
+    try {
+        if i == 2 {
+            break
+        }
+    } finally {
+        result = result + i + " "
+    }
+}
+var r = guarded()
+result = result + r`, "1 2 f1 ret"},
+		{"exception in catch runs finally and propagates", `
+var result = ""
+try {
+    try {
+        throw "first"
+    } catch (e) {
+        throw "second"
+    } finally {
+        result = "finally "
+    }
+} catch (e) {
+    result = result + e
+}`, "finally second"},
+		{"ranges", `
+var result = 0
+for i in 1..4 {
+    result = result + i
+}`, "6"},
+		{"main is called automatically", `
+var result = "top"
+func main() {
+    result = result + " main"
+}`, "top main"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			machine, _, err := runVM(t, tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expectGlobal(t, machine, "result", tt.expected)
+		})
+	}
+}
+
+func TestOutputMatchesTreeWalker(t *testing.T) {
+	input := `
+func classify(n) {
+    if n / 2 * 2 == n {
+        return "par"
+    }
+    return "impar"
+}
+for n in [1, 2, 3] {
+    show.log(n, classify(n))
+}
+show.log("len:", len("zylo"), string(3.5))
+var n = 100
+for n in [1, 2] {
+}
+show.log(n)
+`
+	_, vmOut, err := runVM(t, input)
+	if err != nil {
+		t.Fatalf("vm error: %v", err)
+	}
+	treeOut, err := runTree(t, input)
+	if err != nil {
+		t.Fatalf("tree-walker error: %v", err)
+	}
+	if vmOut != treeOut {
+		t.Errorf("output mismatch:\nvm:   %q\ntree: %q", vmOut, treeOut)
+	}
+}
+
+func TestTypeChecksMatchTreeWalker(t *testing.T) {
+	input := `
+func id(x) { return x }
+func half(x: float) { return x / 2 }
+func name(): string { return id(3) }
+var f: float = id(1)
+show.log(half(id(3)), f / 2)
+var n: int = 1
+try {
+    n = id("dos")
+} catch (e) {
+    show.log(e)
+}
+try {
+    half(id("x"))
+} catch (e) {
+    show.log(e)
+}
+try {
+    name()
+} catch (e) {
+    show.log(e)
+}
+show.log(n)
+var a: int = id("str")
+`
+	_, vmOut, vmErr := runVM(t, input)
+	treeOut, treeErr := runTree(t, input)
+	if vmOut != treeOut {
+		t.Errorf("output mismatch:\nvm:   %q\ntree: %q", vmOut, treeOut)
+	}
+	if vmErr == nil || treeErr == nil || vmErr.Error() != treeErr.Error() {
+		t.Errorf("error mismatch:\nvm:   %v\ntree: %v", vmErr, treeErr)
+	}
+}
+
+func TestRuntimeErrorMatchesTreeWalker(t *testing.T) {
+	input := `func divide(a, b) {
+    return a / b
+}
+func compute() {
+    var x = 1
+    return divide(x, 0)
+}
+compute()
+`
+	_, _, vmErr := runVM(t, input)
+	_, treeErr := runTree(t, input)
+
+	var vmRuntime, treeRuntime *evaluator.RuntimeError
+	if !errors.As(vmErr, &vmRuntime) || !errors.As(treeErr, &treeRuntime) {
+		t.Fatalf("expected runtime errors, got vm=%v tree=%v", vmErr, treeErr)
+	}
+	if vmRuntime.Traceback() != treeRuntime.Traceback() {
+		t.Errorf("traceback mismatch:\nvm:\n%s\ntree:\n%s", vmRuntime.Traceback(), treeRuntime.Traceback())
+	}
+}
+
+func TestUncaughtThrow(t *testing.T) {
+	_, _, err := runVM(t, `
+try {
+    throw "first"
+} finally {
+    var x = 1
+}
+`)
+	var ex *evaluator.ZyloException
+	if !errors.As(err, &ex) {
+		t.Fatalf("expected *ZyloException, got %T (%v)", err, err)
+	}
+	var runtimeErr *evaluator.RuntimeError
+	if !errors.As(err, &runtimeErr) || runtimeErr.Span.StartLine != 3 {
+		t.Errorf("expected the error at the throw on line 3, got %v", err)
+	}
+}
+
+func TestCallDepthLimit(t *testing.T) {
+	_, _, err := runVM(t, "func f(n) {\n    return f(n + 1)\n}\nf(0)")
+	if !errors.Is(err, evaluator.ErrCallDepth) {
+		t.Fatalf("expected ErrCallDepth, got %v", err)
+	}
+}
@@ -0,0 +1,199 @@
+package zyloruntime
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Future es el resultado pendiente de una función async. Una goroutine lo
+// resuelve con el valor de la función o lo rechaza con la excepción que
+// lanzó; Await espera a que ocurra y devuelve el valor o vuelve a lanzar la
+// excepción, que así se captura con try/catch donde se espera, como en el
+// evaluador.
+type Future[T any] struct {
+	done  chan struct{} // Se cierra al resolverse o rechazarse
+	value T
+	err   *ZyloError
+}
+
+// Async ejecuta fn en una goroutine y devuelve enseguida el Future que
+// recibirá su resultado.
+func Async[T any](fn func() T) *Future[T] {
+	f := &Future[T]{done: make(chan struct{})}
+	go func() {
+		defer close(f.done)
+		defer func() {
+			if r := recover(); r != nil {
+				err, ok := recovered(r)
+				if !ok {
+					panic(r)
+				}
+				f.err = err
+			}
+		}()
+		f.value = fn()
+	}()
+	return f
+}
+
+// Await espera a que el future se resuelva y devuelve su valor, o lanza la
+// excepción con la que se rechazó.
+func (f *Future[T]) Await() T {
+	<-f.done
+	if f.err != nil {
+		panic(f.err)
+	}
+	return f.value
+}
+
+func (f *Future[T]) settled() <-chan struct{} { return f.done }
+
+func (f *Future[T]) result() (Value, *ZyloError) { return f.value, f.err }
+
+// adopt hace que f se resuelva como src, convirtiendo su valor a T, o se
+// rechace si src se rechaza o su valor no se puede usar como T. Así As
+// convierte un future<int> en el future<any> que espera una lista mixta.
+func (f *Future[T]) adopt(src awaitable) {
+	f.done = make(chan struct{})
+	go func() {
+		defer close(f.done)
+		defer func() {
+			if r := recover(); r != nil {
+				err, ok := recovered(r)
+				if !ok {
+					panic(r)
+				}
+				f.err = err
+			}
+		}()
+		<-src.settled()
+		value, err := src.result()
+		if err != nil {
+			panic(err)
+		}
+		f.value = As[T](value)
+	}()
+}
+
+// awaitable es un Future con cualquier tipo de valor, para lo que no conoce
+// T: Await, los combinadores y Format.
+type awaitable interface {
+	settled() <-chan struct{}
+	result() (Value, *ZyloError)
+}
+
+// Await espera el resultado de v si es un future y lo devuelve, o lanza la
+// excepción con la que se rechazó. Cualquier otro valor se devuelve tal cual.
+func Await(v Value) Value {
+	f, ok := v.(awaitable)
+	if !ok {
+		return v
+	}
+	<-f.settled()
+	value, err := f.result()
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// All devuelve un future con los valores de los futures de la lista
+// futures, en orden. Se rechaza con el primer rechazo, sin esperar al resto.
+// Los elementos que no son futures cuentan como ya resueltos.
+func All(futures Value) *Future[Value] {
+	items := futureList("all", futures)
+	return Async(func() Value {
+		results := make([]Value, len(items))
+		var pending []int // Índices de items que siguen pendientes
+		for i, item := range items {
+			if _, ok := item.(awaitable); ok {
+				pending = append(pending, i)
+			} else {
+				results[i] = item
+			}
+		}
+		for len(pending) > 0 {
+			cases := make([]reflect.SelectCase, len(pending))
+			for j, i := range pending {
+				cases[j] = recvCase(items[i].(awaitable))
+			}
+			chosen, _, _ := reflect.Select(cases)
+			i := pending[chosen]
+			results[i] = Await(items[i])
+			pending = append(pending[:chosen], pending[chosen+1:]...)
+		}
+		return results
+	})
+}
+
+// Race devuelve un future que se resuelve o se rechaza como el primero de
+// los futures de la lista futures que lo haga.
+func Race(futures Value) *Future[Value] {
+	items := futureList("race", futures)
+	return Async(func() Value {
+		if len(items) == 0 {
+			Throw("race() expects at least one future")
+		}
+		cases := make([]reflect.SelectCase, len(items))
+		for i, item := range items {
+			f, ok := item.(awaitable)
+			if !ok {
+				return item
+			}
+			cases[i] = recvCase(f)
+		}
+		chosen, _, _ := reflect.Select(cases)
+		return Await(items[chosen])
+	})
+}
+
+// Timeout devuelve un future que se resuelve como future, pero se rechaza
+// si no lo hace en ms milisegundos.
+func Timeout(future Value, ms int) *Future[Value] {
+	if ms < 0 {
+		Throw("second argument to timeout() must be a non-negative integer")
+	}
+	return Async(func() Value {
+		f, ok := future.(awaitable)
+		if !ok {
+			return future
+		}
+		timer := time.NewTimer(time.Duration(ms) * time.Millisecond)
+		defer timer.Stop()
+		select {
+		case <-f.settled():
+			return Await(future)
+		case <-timer.C:
+			Throw(fmt.Sprintf("future timed out after %d ms", ms))
+		}
+		return nil
+	})
+}
+
+// futureList devuelve los elementos del argumento de all() o race().
+func futureList(name string, futures Value) []Value {
+	if reflect.ValueOf(futures).Kind() != reflect.Slice {
+		Throw(fmt.Sprintf("argument to %s() must be a list", name))
+	}
+	return Iterate(futures)
+}
+
+func recvCase(f awaitable) reflect.SelectCase {
+	return reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(f.settled())}
+}
+
+// formatFuture devuelve la representación de un future que muestra el
+// evaluador.
+func formatFuture(f awaitable) string {
+	select {
+	case <-f.settled():
+	default:
+		return "future(pending)"
+	}
+	value, err := f.result()
+	if err != nil {
+		return "future(rejected: " + Format(Caught(err)) + ")"
+	}
+	return "future(" + Format(value) + ")"
+}
@@ -3,9 +3,13 @@ package zyloruntime
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
+	"reflect"
+	"runtime"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -35,6 +39,7 @@ func Exit(code int) {
 // ZyloError representa un error en Zylo.
 type ZyloError struct {
 	Message string
+	Value   Value // Valor lanzado con throw
 }
 
 // Error implementa la interfaz error.
@@ -42,26 +47,40 @@ func (e *ZyloError) Error() string {
 	return e.Message
 }
 
-// Throw lanza una excepción Zylo.
-func Throw(message string) {
-	panic(&ZyloError{Message: message})
+// Throw lanza una excepción Zylo con cualquier valor, normalmente un string.
+func Throw(value Value) {
+	panic(&ZyloError{Message: Format(value), Value: value})
 }
 
 // Try ejecuta una función y captura excepciones.
 func Try(fn func(), catch func(error)) {
 	defer func() {
 		if r := recover(); r != nil {
-			if err, ok := r.(*ZyloError); ok {
-				catch(err)
-			} else {
+			err, ok := recovered(r)
+			if !ok {
 				// Re-panic para errores no-Zylo
 				panic(r)
 			}
+			catch(err)
 		}
 	}()
 	fn()
 }
 
+// recovered convierte lo recuperado de un panic en una excepción de Zylo: las
+// lanzadas con throw y los errores del runtime de Go, como un índice fuera
+// de rango, que se capturan igual que en el evaluador. Devuelve false para
+// el resto.
+func recovered(r interface{}) (*ZyloError, bool) {
+	switch err := r.(type) {
+	case *ZyloError:
+		return err, true
+	case runtime.Error:
+		return &ZyloError{Message: err.Error()}, true
+	}
+	return nil, false
+}
+
 // --- Estructuras de Datos ---
 
 // List representa una lista dinámica.
@@ -146,7 +165,7 @@ func (m *Map) Keys() []string {
 func ReadFile(filename string) string {
 	content, err := ioutil.ReadFile(filename)
 	if err != nil {
-		Throw(fmt.Sprintf("Error reading file: %v", err))
+		Throw(fmt.Sprintf("cannot read file: %v", err))
 	}
 	return string(content)
 }
@@ -155,7 +174,7 @@ func ReadFile(filename string) string {
 func WriteFile(filename string, content string) {
 	err := ioutil.WriteFile(filename, []byte(content), 0644)
 	if err != nil {
-		Throw(fmt.Sprintf("Error writing file: %v", err))
+		Throw(fmt.Sprintf("cannot write file: %v", err))
 	}
 }
 
@@ -167,23 +186,74 @@ func FileExists(filename string) bool {
 
 // --- JSON ---
 
-// ToJSON convierte un valor a JSON string.
+// ToJSON convierte un valor a JSON string. Como en el evaluador, solo se
+// convierten null, booleanos, números, strings, listas y hashes.
 func ToJSON(value interface{}) string {
-	jsonBytes, err := json.Marshal(value)
+	jsonBytes, err := json.Marshal(jsonValue(value))
 	if err != nil {
-		Throw(fmt.Sprintf("Error converting to JSON: %v", err))
+		Throw(fmt.Sprintf("cannot convert to JSON: %v", err))
 	}
 	return string(jsonBytes)
 }
 
-// FromJSON convierte un JSON string a un valor.
+// jsonValue comprueba que value se pueda convertir a JSON y devuelve las
+// listas y los hashes como []Value y map[string]Value.
+func jsonValue(value Value) Value {
+	switch v := reflect.ValueOf(value); v.Kind() {
+	case reflect.Invalid, reflect.Bool, reflect.Int, reflect.Float64, reflect.String:
+		return value
+	case reflect.Slice:
+		items := make([]Value, v.Len())
+		for i := range items {
+			items[i] = jsonValue(v.Index(i).Interface())
+		}
+		return items
+	case reflect.Map:
+		pairs := make(map[string]Value, v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			pairs[iter.Key().String()] = jsonValue(iter.Value().Interface())
+		}
+		return pairs
+	}
+	Throw(fmt.Sprintf("cannot convert %s to JSON", TypeName(value)))
+	return nil
+}
+
+// FromJSON convierte un JSON string a un valor: los objetos son hashes, los
+// arrays listas y los números sin decimales ni exponente, enteros.
 func FromJSON(jsonStr string) interface{} {
+	decoder := json.NewDecoder(strings.NewReader(jsonStr))
+	decoder.UseNumber()
 	var result interface{}
-	err := json.Unmarshal([]byte(jsonStr), &result)
-	if err != nil {
-		Throw(fmt.Sprintf("Error parsing JSON: %v", err))
+	if err := decoder.Decode(&result); err != nil {
+		Throw(fmt.Sprintf("cannot parse JSON: %v", err))
 	}
-	return result
+	if _, err := decoder.Token(); err != io.EOF {
+		Throw("cannot parse JSON: unexpected data after the value")
+	}
+	return fromJSONValue(result)
+}
+
+// fromJSONValue convierte los números de un valor decodificado con
+// UseNumber a int o float64.
+func fromJSONValue(value interface{}) Value {
+	switch v := value.(type) {
+	case json.Number:
+		if n, err := strconv.Atoi(v.String()); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case []interface{}:
+		for i, item := range v {
+			v[i] = fromJSONValue(item)
+		}
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = fromJSONValue(item)
+		}
+	}
+	return value
 }
 
 // --- Time y Utilidades ---
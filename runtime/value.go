@@ -0,0 +1,521 @@
+package zyloruntime
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Value es un valor de Zylo cuyo tipo no se conoce al compilar. El código
+// generado usa tipos de Go precisos siempre que el comprobador de tipos los
+// infiere, y Value con las funciones de este archivo para el resto.
+//
+// Un Value contiene int, float64, string, bool, nil (null), listas ([]T),
+// hashes (map[string]T), funciones, futures (*Future[T]) o punteros a las
+// structs de las clases.
+type Value = interface{}
+
+// As convierte v al tipo T que espera el código generado: un int a
+// float64, una lista o un hash elemento a elemento, y null al valor cero.
+// Lanza una excepción si v no se puede usar como T.
+func As[T any](v Value) T {
+	if t, ok := v.(T); ok {
+		return t
+	}
+	var zero T
+	if v == nil {
+		return zero
+	}
+	target := reflect.TypeOf(&zero).Elem()
+	return convert(reflect.ValueOf(v), target).Interface().(T)
+}
+
+// convert convierte value al tipo target, o lanza una excepción.
+func convert(value reflect.Value, target reflect.Type) reflect.Value {
+	if !value.IsValid() {
+		return reflect.Zero(target)
+	}
+	if value.Type().AssignableTo(target) {
+		result := reflect.New(target).Elem()
+		result.Set(value)
+		return result
+	}
+	if value.Kind() == reflect.Interface {
+		return convert(value.Elem(), target)
+	}
+
+	if src, ok := value.Interface().(awaitable); ok && target.Kind() == reflect.Ptr {
+		result := reflect.New(target.Elem())
+		if f, ok := result.Interface().(interface{ adopt(awaitable) }); ok {
+			f.adopt(src)
+			return result
+		}
+	}
+
+	switch target.Kind() {
+	case reflect.Float64:
+		if value.Kind() == reflect.Int {
+			return value.Convert(target)
+		}
+	case reflect.Slice:
+		if value.Kind() == reflect.Slice {
+			result := reflect.MakeSlice(target, value.Len(), value.Len())
+			for i := 0; i < value.Len(); i++ {
+				result.Index(i).Set(convert(value.Index(i), target.Elem()))
+			}
+			return result
+		}
+	case reflect.Map:
+		if value.Kind() == reflect.Map && value.Type().Key() == target.Key() {
+			result := reflect.MakeMapWithSize(target, value.Len())
+			iter := value.MapRange()
+			for iter.Next() {
+				result.SetMapIndex(iter.Key(), convert(iter.Value(), target.Elem()))
+			}
+			return result
+		}
+	}
+	Throw(fmt.Sprintf("cannot use %s as %s", TypeName(value.Interface()), typeAnnotation(target)))
+	return reflect.Value{}
+}
+
+// typeAnnotation devuelve la anotación de Zylo del tipo de Go target, para
+// que los errores de conversión coincidan con los del evaluador.
+func typeAnnotation(target reflect.Type) string {
+	switch target.Kind() {
+	case reflect.Int:
+		return "int"
+	case reflect.Float64:
+		return "float"
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "bool"
+	case reflect.Interface:
+		return "any"
+	case reflect.Slice:
+		return "list<" + typeAnnotation(target.Elem()) + ">"
+	case reflect.Map:
+		return "map<" + typeAnnotation(target.Elem()) + ">"
+	case reflect.Ptr:
+		if _, ok := reflect.New(target).Elem().Interface().(awaitable); ok {
+			return "future"
+		}
+		return target.Elem().Name()
+	}
+	return target.String()
+}
+
+// TypeName devuelve el nombre del tipo de Zylo de v, para los mensajes de
+// error.
+func TypeName(v Value) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case int:
+		return "int"
+	case float64:
+		return "float"
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case awaitable:
+		return "future"
+	}
+	switch value := reflect.ValueOf(v); value.Kind() {
+	case reflect.Slice:
+		return "list"
+	case reflect.Map:
+		return "map"
+	case reflect.Func:
+		return "function"
+	case reflect.Ptr:
+		return value.Elem().Type().Name()
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// Format devuelve la representación de v que muestra el evaluador: null,
+// los floats con %g, las listas como [1, 2] y los hashes como {a: 1}.
+func Format(v Value) string {
+	switch x := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return x
+	case int:
+		return strconv.Itoa(x)
+	case float64:
+		return strconv.FormatFloat(x, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	case awaitable:
+		return formatFuture(x)
+	}
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Slice:
+		parts := make([]string, value.Len())
+		for i := range parts {
+			parts[i] = Format(value.Index(i).Interface())
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	case reflect.Map:
+		keys := value.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		parts := make([]string, len(keys))
+		for i, key := range keys {
+			parts[i] = key.String() + ": " + Format(value.MapIndex(key).Interface())
+		}
+		return "{" + strings.Join(parts, ", ") + "}"
+	case reflect.Ptr:
+		if value.Elem().Kind() == reflect.Struct {
+			return "instance of " + value.Elem().Type().Name()
+		}
+	}
+	return fmt.Sprint(v)
+}
+
+// Truthy indica si v cuenta como verdadero en una condición: false, 0 y ""
+// son falsos. null, como en el evaluador, cuenta como verdadero.
+func Truthy(v Value) bool {
+	switch x := v.(type) {
+	case bool:
+		return x
+	case int:
+		return x != 0
+	case string:
+		return x != ""
+	}
+	return true
+}
+
+// Operate aplica un operador aritmético a dos valores con las reglas del
+// evaluador: + concatena si uno es string, int con int da int y cualquier
+// otra combinación de números da float.
+func Operate(op string, left, right Value) Value {
+	if op == "+" {
+		ls, lok := left.(string)
+		rs, rok := right.(string)
+		switch {
+		case lok && (rok || isNumber(right)):
+			return ls + Format(right)
+		case rok && isNumber(left):
+			return Format(left) + rs
+		}
+	}
+
+	if l, ok := left.(int); ok {
+		if r, ok := right.(int); ok {
+			switch op {
+			case "+":
+				return l + r
+			case "-":
+				return l - r
+			case "*":
+				return l * r
+			case "/":
+				if r == 0 {
+					Throw("división por cero")
+				}
+				return l / r
+			case "%":
+				if r == 0 {
+					Throw("división por cero")
+				}
+				return l % r
+			}
+		}
+	}
+	if isNumber(left) && isNumber(right) {
+		l, r := toFloat(left), toFloat(right)
+		switch op {
+		case "+":
+			return l + r
+		case "-":
+			return l - r
+		case "*":
+			return l * r
+		case "/":
+			if r == 0 {
+				Throw("división por cero")
+			}
+			return l / r
+		case "%":
+			return math.Mod(l, r)
+		}
+	}
+	Throw(fmt.Sprintf("operator %s not defined for %s and %s", op, TypeName(left), TypeName(right)))
+	return nil
+}
+
+// Compare aplica un operador de comparación (<, >, <=, >=) a dos números.
+func Compare(op string, left, right Value) bool {
+	if !isNumber(left) || !isNumber(right) {
+		Throw(fmt.Sprintf("operator %s not defined for %s and %s", op, TypeName(left), TypeName(right)))
+	}
+	l, r := toFloat(left), toFloat(right)
+	switch op {
+	case "<":
+		return l < r
+	case ">":
+		return l > r
+	case "<=":
+		return l <= r
+	case ">=":
+		return l >= r
+	}
+	Throw(fmt.Sprintf("unknown operator %s", op))
+	return false
+}
+
+// Range devuelve la lista del rango start..end: los enteros de start a end,
+// sin incluir end.
+func Range(start, end int) []int {
+	items := make([]int, max(end-start, 0))
+	for i := range items {
+		items[i] = start + i
+	}
+	return items
+}
+
+// Equal indica si dos valores son iguales: los números se comparan por su
+// valor aunque uno sea int y otro float, y null solo es igual a null.
+func Equal(left, right Value) bool {
+	if isNumber(left) && isNumber(right) {
+		return toFloat(left) == toFloat(right)
+	}
+	if isNull(left) || isNull(right) {
+		return isNull(left) && isNull(right)
+	}
+	l, r := reflect.ValueOf(left), reflect.ValueOf(right)
+	if l.Type() != r.Type() || !l.Type().Comparable() {
+		return false
+	}
+	return left == right
+}
+
+// Negate devuelve -v para un número.
+func Negate(v Value) Value {
+	switch x := v.(type) {
+	case int:
+		return -x
+	case float64:
+		return -x
+	}
+	Throw(fmt.Sprintf("operator - not defined for %s", TypeName(v)))
+	return nil
+}
+
+// Len devuelve el número de elementos de una lista o un hash, o de bytes de
+// un string.
+func Len(v Value) int {
+	switch value := reflect.ValueOf(v); value.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return value.Len()
+	}
+	Throw(fmt.Sprintf("len() not supported for %s", TypeName(v)))
+	return 0
+}
+
+// Index devuelve container[index] para una lista, un string o un hash.
+func Index(container, index Value) Value {
+	value := reflect.ValueOf(container)
+	switch value.Kind() {
+	case reflect.Slice, reflect.String:
+		i, ok := index.(int)
+		if !ok {
+			Throw(fmt.Sprintf("%s index must be int, got %s", TypeName(container), TypeName(index)))
+		}
+		if i < 0 || i >= value.Len() {
+			Throw(fmt.Sprintf("index out of range: %d", i))
+		}
+		if value.Kind() == reflect.String {
+			return string(value.String()[i])
+		}
+		return value.Index(i).Interface()
+	case reflect.Map:
+		element := value.MapIndex(reflect.ValueOf(Format(index)))
+		if !element.IsValid() {
+			return nil
+		}
+		return element.Interface()
+	}
+	Throw(fmt.Sprintf("cannot index %s", TypeName(container)))
+	return nil
+}
+
+// SetIndex asigna container[index] = element en una lista o un hash.
+func SetIndex(container, index, element Value) {
+	value := reflect.ValueOf(container)
+	switch value.Kind() {
+	case reflect.Slice:
+		i, ok := index.(int)
+		if !ok {
+			Throw(fmt.Sprintf("list index must be int, got %s", TypeName(index)))
+		}
+		if i < 0 || i >= value.Len() {
+			Throw(fmt.Sprintf("index out of range: %d", i))
+		}
+		value.Index(i).Set(convert(reflect.ValueOf(element), value.Type().Elem()))
+		return
+	case reflect.Map:
+		key := reflect.ValueOf(Format(index))
+		value.SetMapIndex(key, convert(reflect.ValueOf(element), value.Type().Elem()))
+		return
+	}
+	Throw(fmt.Sprintf("cannot assign to an element of %s", TypeName(container)))
+}
+
+// Iterate devuelve los valores que recorre 'for x in v': los elementos de
+// una lista, los caracteres de un string o las claves de un hash, ordenadas.
+func Iterate(v Value) []Value {
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Slice:
+		items := make([]Value, value.Len())
+		for i := range items {
+			items[i] = value.Index(i).Interface()
+		}
+		return items
+	case reflect.String:
+		var items []Value
+		for _, char := range value.String() {
+			items = append(items, string(char))
+		}
+		return items
+	case reflect.Map:
+		return Iterate(Keys(v))
+	}
+	Throw(fmt.Sprintf("cannot iterate over %s", TypeName(v)))
+	return nil
+}
+
+// Keys devuelve las claves de un hash, ordenadas.
+func Keys(v Value) []string {
+	value := reflect.ValueOf(v)
+	keys := make([]string, 0, value.Len())
+	for _, key := range value.MapKeys() {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Member devuelve el atributo o el método name de una instancia, o los
+// métodos Get, Len, Append y Set de las listas y los hashes. Los atributos y
+// métodos de las clases se generan con la primera letra en mayúscula para
+// poder encontrarlos aquí.
+func Member(object Value, name string) Value {
+	value := reflect.ValueOf(object)
+	if value.Kind() == reflect.Ptr && value.Elem().Kind() == reflect.Struct {
+		if field := value.Elem().FieldByName(name); field.IsValid() {
+			return field.Interface()
+		}
+		if method := value.MethodByName(name); method.IsValid() {
+			return method.Interface()
+		}
+	}
+	switch value.Kind() {
+	case reflect.Slice:
+		switch name {
+		case "Get":
+			return func(i int) Value { return Index(object, i) }
+		case "Len":
+			return func() int { return value.Len() }
+		case "Append":
+			// Las listas de Go no se pueden ampliar sin asignarlas: el
+			// generador traduce Append solo si conoce el tipo de la lista
+			Throw("cannot append to a list of unknown type; declare it with a type such as list<int>")
+		}
+	case reflect.Map:
+		switch name {
+		case "Get":
+			return func(key Value) Value { return Index(object, key) }
+		case "Set":
+			return func(key, element Value) { SetIndex(object, key, element) }
+		case "Len":
+			return func() int { return value.Len() }
+		}
+	}
+	Throw(fmt.Sprintf("%s has no attribute %s", TypeName(object), name))
+	return nil
+}
+
+// SetMember asigna el atributo name de una instancia.
+func SetMember(object Value, name string, element Value) {
+	value := reflect.ValueOf(object)
+	if value.Kind() == reflect.Ptr && value.Elem().Kind() == reflect.Struct {
+		if field := value.Elem().FieldByName(name); field.IsValid() {
+			field.Set(convert(reflect.ValueOf(element), field.Type()))
+			return
+		}
+	}
+	Throw(fmt.Sprintf("%s has no attribute %s", TypeName(object), name))
+}
+
+// CallMethod llama al método name de una instancia.
+func CallMethod(object Value, name string, args ...Value) Value {
+	return Call(Member(object, name), args...)
+}
+
+// Call llama a una función con argumentos dinámicos, convertidos a los tipos
+// de sus parámetros. Devuelve su resultado, o null si no tiene.
+func Call(fn Value, args ...Value) Value {
+	value := reflect.ValueOf(fn)
+	if value.Kind() != reflect.Func {
+		Throw(fmt.Sprintf("cannot call %s", TypeName(fn)))
+	}
+	fnType := value.Type()
+	if fnType.NumIn() != len(args) {
+		Throw(fmt.Sprintf("function expects %d arguments, got %d", fnType.NumIn(), len(args)))
+	}
+	in := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		in[i] = convert(reflect.ValueOf(arg), fnType.In(i))
+	}
+	out := value.Call(in)
+	if len(out) == 0 {
+		return nil
+	}
+	return out[0].Interface()
+}
+
+// Caught devuelve el valor de una excepción capturada: el que se lanzó con
+// throw o el mensaje de un error del runtime.
+func Caught(err error) Value {
+	if zyloErr, ok := err.(*ZyloError); ok && zyloErr.Value != nil {
+		return zyloErr.Value
+	}
+	return err.Error()
+}
+
+func isNumber(v Value) bool {
+	switch v.(type) {
+	case int, float64:
+		return true
+	}
+	return false
+}
+
+func isNull(v Value) bool {
+	if v == nil {
+		return true
+	}
+	switch value := reflect.ValueOf(v); value.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return value.IsNil()
+	}
+	return false
+}
+
+func toFloat(v Value) float64 {
+	if i, ok := v.(int); ok {
+		return float64(i)
+	}
+	return v.(float64)
+}
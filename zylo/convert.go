@@ -0,0 +1,255 @@
+package zylo
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/zylo-lang/zylo/internal/evaluator"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// toZylo convierte un valor Go en un valor de Zylo.
+func toZylo(value interface{}) (evaluator.Value, error) {
+	switch v := value.(type) {
+	case nil:
+		return &evaluator.Null{}, nil
+	case evaluator.ZyloObject, *evaluator.ZyloFunction, *evaluator.BuiltinFunction:
+		return v, nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Bool:
+		return &evaluator.Boolean{Value: rv.Bool()}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &evaluator.Integer{Value: rv.Int()}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := rv.Uint()
+		if u > 1<<63-1 {
+			return nil, fmt.Errorf("el entero %d no cabe en un Integer de Zylo", u)
+		}
+		return &evaluator.Integer{Value: int64(u)}, nil
+	case reflect.Float32, reflect.Float64:
+		return &evaluator.Float{Value: rv.Float()}, nil
+	case reflect.String:
+		return &evaluator.String{Value: rv.String()}, nil
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return &evaluator.Null{}, nil
+		}
+		items := make([]evaluator.Value, rv.Len())
+		for i := range items {
+			item, err := toZylo(rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return &evaluator.List{Items: items}, nil
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("solo se admiten mapas con claves string, no %s", rv.Type())
+		}
+		if rv.IsNil() {
+			return &evaluator.Null{}, nil
+		}
+		pairs := make(map[string]evaluator.Value, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			item, err := toZylo(iter.Value().Interface())
+			if err != nil {
+				return nil, err
+			}
+			pairs[iter.Key().String()] = item
+		}
+		return &evaluator.Hash{Pairs: pairs}, nil
+	case reflect.Func:
+		return wrapGoFunc("<go>", value)
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return &evaluator.Null{}, nil
+		}
+		return toZylo(rv.Elem().Interface())
+	}
+	return nil, fmt.Errorf("no se puede convertir %T a un valor de Zylo", value)
+}
+
+// fromZylo convierte un valor de Zylo en su equivalente Go. Los valores sin
+// equivalente (funciones, clases, instancias) se devuelven tal cual.
+func fromZylo(value evaluator.Value) interface{} {
+	switch v := value.(type) {
+	case nil, *evaluator.Null:
+		return nil
+	case *evaluator.Integer:
+		return v.Value
+	case *evaluator.Float:
+		return v.Value
+	case *evaluator.String:
+		return v.Value
+	case *evaluator.Boolean:
+		return v.Value
+	case *evaluator.List:
+		elements := v.Elements()
+		items := make([]interface{}, len(elements))
+		for i, item := range elements {
+			items[i] = fromZylo(item)
+		}
+		return items
+	case *evaluator.Hash:
+		entries := v.Entries()
+		pairs := make(map[string]interface{}, len(entries))
+		for key, item := range entries {
+			pairs[key] = fromZylo(item)
+		}
+		return pairs
+	default:
+		return value
+	}
+}
+
+// fromZyloAs convierte un valor de Zylo al tipo Go t.
+func fromZyloAs(value evaluator.Value, t reflect.Type) (reflect.Value, error) {
+	if t.Kind() == reflect.Interface && t.NumMethod() == 0 {
+		converted := fromZylo(value)
+		if converted == nil {
+			return reflect.Zero(t), nil
+		}
+		return reflect.ValueOf(converted), nil
+	}
+
+	switch v := value.(type) {
+	case *evaluator.Boolean:
+		if t.Kind() == reflect.Bool {
+			return reflect.ValueOf(v.Value).Convert(t), nil
+		}
+	case *evaluator.Integer:
+		out := reflect.New(t).Elem()
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if out.OverflowInt(v.Value) {
+				return reflect.Value{}, fmt.Errorf("el entero %d no cabe en %s", v.Value, t)
+			}
+			out.SetInt(v.Value)
+			return out, nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if v.Value < 0 || out.OverflowUint(uint64(v.Value)) {
+				return reflect.Value{}, fmt.Errorf("el entero %d no cabe en %s", v.Value, t)
+			}
+			out.SetUint(uint64(v.Value))
+			return out, nil
+		case reflect.Float32, reflect.Float64:
+			out.SetFloat(float64(v.Value))
+			return out, nil
+		}
+	case *evaluator.Float:
+		if t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64 {
+			return reflect.ValueOf(v.Value).Convert(t), nil
+		}
+	case *evaluator.String:
+		if t.Kind() == reflect.String {
+			return reflect.ValueOf(v.Value).Convert(t), nil
+		}
+	case *evaluator.Null:
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface, reflect.Func:
+			return reflect.Zero(t), nil
+		}
+	case *evaluator.List:
+		if t.Kind() == reflect.Slice {
+			elements := v.Elements()
+			out := reflect.MakeSlice(t, len(elements), len(elements))
+			for i, item := range elements {
+				elem, err := fromZyloAs(item, t.Elem())
+				if err != nil {
+					return reflect.Value{}, err
+				}
+				out.Index(i).Set(elem)
+			}
+			return out, nil
+		}
+	case *evaluator.Hash:
+		if t.Kind() == reflect.Map && t.Key().Kind() == reflect.String {
+			entries := v.Entries()
+			out := reflect.MakeMapWithSize(t, len(entries))
+			for key, item := range entries {
+				elem, err := fromZyloAs(item, t.Elem())
+				if err != nil {
+					return reflect.Value{}, err
+				}
+				out.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), elem)
+			}
+			return out, nil
+		}
+	}
+
+	if value != nil && reflect.TypeOf(value).AssignableTo(t) {
+		return reflect.ValueOf(value), nil
+	}
+	return reflect.Value{}, fmt.Errorf("se esperaba %s, se recibió %s", t, zyloTypeName(value))
+}
+
+// zyloTypeName devuelve el nombre del tipo de Zylo de value para los mensajes de error.
+func zyloTypeName(value evaluator.Value) string {
+	if obj, ok := value.(evaluator.ZyloObject); ok {
+		return obj.Type()
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// wrapGoFunc adapta una función Go a un built-in de Zylo.
+func wrapGoFunc(name string, fn interface{}) (*evaluator.BuiltinFunction, error) {
+	rv := reflect.ValueOf(fn)
+	if rv.Kind() != reflect.Func || rv.IsNil() {
+		return nil, fmt.Errorf("RegisterFunc(%q): se esperaba una función, se recibió %T", name, fn)
+	}
+	ft := rv.Type()
+
+	returnsError := ft.NumOut() > 0 && ft.Out(ft.NumOut()-1) == errorType
+	switch {
+	case ft.NumOut() > 2,
+		ft.NumOut() == 2 && !returnsError:
+		return nil, fmt.Errorf("RegisterFunc(%q): la función debe devolver a lo sumo un valor y un error", name)
+	}
+
+	return &evaluator.BuiltinFunction{
+		Name: name,
+		Fn: func(args []evaluator.Value) (evaluator.Value, error) {
+			numIn := ft.NumIn()
+			if ft.IsVariadic() {
+				if len(args) < numIn-1 {
+					return nil, fmt.Errorf("%s() espera al menos %d argumentos, recibió %d", name, numIn-1, len(args))
+				}
+			} else if len(args) != numIn {
+				return nil, fmt.Errorf("%s() espera %d argumentos, recibió %d", name, numIn, len(args))
+			}
+
+			in := make([]reflect.Value, len(args))
+			for i, arg := range args {
+				var paramType reflect.Type
+				if ft.IsVariadic() && i >= numIn-1 {
+					paramType = ft.In(numIn - 1).Elem()
+				} else {
+					paramType = ft.In(i)
+				}
+				converted, err := fromZyloAs(arg, paramType)
+				if err != nil {
+					return nil, fmt.Errorf("%s(): argumento %d: %w", name, i+1, err)
+				}
+				in[i] = converted
+			}
+
+			out := rv.Call(in)
+			if returnsError {
+				if errValue := out[len(out)-1]; !errValue.IsNil() {
+					return nil, errValue.Interface().(error)
+				}
+				out = out[:len(out)-1]
+			}
+			if len(out) == 0 {
+				return &evaluator.Null{}, nil
+			}
+			return toZylo(out[0].Interface())
+		},
+	}, nil
+}
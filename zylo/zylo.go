@@ -0,0 +1,206 @@
+// Package zylo permite ejecutar programas Zylo desde aplicaciones Go.
+//
+// Un Interpreter mantiene su estado global entre ejecuciones, de modo que el
+// código Go puede cargar un script, leer y escribir sus variables y llamar a
+// sus funciones:
+//
+//	interp := zylo.New(zylo.WithStdout(&buf))
+//	interp.RegisterFunc("double", func(n int) int { return n * 2 })
+//	if err := interp.Run(`func twice(x) { return double(x) }`); err != nil {
+//		return err
+//	}
+//	result, err := interp.Call("twice", 21) // int64(42)
+package zylo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/zylo-lang/zylo/internal/evaluator"
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/parser"
+)
+
+// RuntimeError es el error que devuelven Run y Call cuando falla la
+// ejecución; incluye la posición y la pila de llamadas de Zylo.
+type RuntimeError = evaluator.RuntimeError
+
+// Limits acota los recursos de cada ejecución; ver WithLimits.
+type Limits = evaluator.Limits
+
+// Errores de los límites de ejecución, para usar con errors.Is.
+var (
+	ErrCanceled   = evaluator.ErrCanceled
+	ErrStepLimit  = evaluator.ErrStepLimit
+	ErrCallDepth  = evaluator.ErrCallDepth
+	ErrAllocLimit = evaluator.ErrAllocLimit
+)
+
+// ErrDeadlock indica que todas las tareas lanzadas con spawn, incluida la
+// principal, quedaron bloqueadas para siempre.
+var ErrDeadlock = evaluator.ErrDeadlock
+
+// ParseError agrupa los errores de sintaxis de un programa.
+type ParseError struct {
+	File   string
+	Errors []string
+}
+
+func (pe *ParseError) Error() string {
+	file := pe.File
+	if file == "" {
+		file = "<entrada>"
+	}
+	return fmt.Sprintf("%s: errores de parsing:\n  %s", file, strings.Join(pe.Errors, "\n  "))
+}
+
+// Option configura un Interpreter.
+type Option func(*options)
+
+type options struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	limits *Limits
+}
+
+// WithStdin establece de dónde leen read.line, read.int y getInput.
+func WithStdin(r io.Reader) Option {
+	return func(o *options) { o.stdin = r }
+}
+
+// WithStdout establece dónde escribe show.log.
+func WithStdout(w io.Writer) Option {
+	return func(o *options) { o.stdout = w }
+}
+
+// WithStderr establece dónde se escriben los avisos del intérprete.
+func WithStderr(w io.Writer) Option {
+	return func(o *options) { o.stderr = w }
+}
+
+// WithLimits establece los límites de pasos, profundidad de llamadas y
+// memoria que se aplican a cada Run y Call. Sin esta opción solo se limita
+// la profundidad de llamadas, a 10000 llamadas anidadas. En Limits un valor
+// cero desactiva el límite correspondiente.
+func WithLimits(limits Limits) Option {
+	return func(o *options) { o.limits = &limits }
+}
+
+// Interpreter ejecuta código Zylo. No es seguro para uso concurrente.
+type Interpreter struct {
+	eval *evaluator.Evaluator
+}
+
+// New crea un intérprete con los built-ins de Zylo ya registrados. Por
+// defecto usa la entrada y salida estándar del proceso.
+func New(opts ...Option) *Interpreter {
+	o := options{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	eval := evaluator.NewEvaluatorWithIO(o.stdin, o.stdout, o.stderr)
+	if o.limits != nil {
+		eval.SetLimits(*o.limits)
+	}
+	return &Interpreter{eval: eval}
+}
+
+// Run parsea y ejecuta source en el entorno global del intérprete.
+func (i *Interpreter) Run(source string) error {
+	return i.RunContext(context.Background(), source)
+}
+
+// RunContext es como Run, pero detiene la ejecución con ErrCanceled cuando
+// se cancela ctx.
+func (i *Interpreter) RunContext(ctx context.Context, source string) error {
+	return i.run(ctx, "", source)
+}
+
+// RunFile lee y ejecuta un archivo .zylo.
+func (i *Interpreter) RunFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return i.run(context.Background(), path, string(content))
+}
+
+func (i *Interpreter) run(ctx context.Context, file, source string) error {
+	p := parser.New(lexer.New(source))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		return &ParseError{File: file, Errors: p.Errors()}
+	}
+	i.eval.SetFile(file)
+	i.eval.SetContext(ctx)
+	return i.eval.EvaluateProgram(program)
+}
+
+// RegisterFunc expone una función Go a los programas Zylo con el nombre dado.
+// fn puede recibir cualquier tipo convertible desde Zylo (ver GetGlobal) y
+// devolver nada, un valor, un error o un valor y un error. Un error devuelto
+// se propaga como excepción y puede capturarse con try/catch.
+func (i *Interpreter) RegisterFunc(name string, fn interface{}) error {
+	builtin, err := wrapGoFunc(name, fn)
+	if err != nil {
+		return err
+	}
+	i.eval.Define(name, builtin)
+	return nil
+}
+
+// SetGlobal declara o reemplaza una variable global convirtiendo value a Zylo:
+// bool, enteros, flotantes, string, slices, mapas con claves string y nil.
+func (i *Interpreter) SetGlobal(name string, value interface{}) error {
+	v, err := toZylo(value)
+	if err != nil {
+		return fmt.Errorf("SetGlobal(%q): %w", name, err)
+	}
+	i.eval.Define(name, v)
+	return nil
+}
+
+// GetGlobal devuelve el valor de una variable global convertido a Go: Integer
+// a int64, Float a float64, String a string, Boolean a bool, null a nil, List
+// a []interface{} y Hash a map[string]interface{}. Funciones, clases e
+// instancias se devuelven sin convertir.
+func (i *Interpreter) GetGlobal(name string) (interface{}, bool) {
+	v, ok := i.eval.Lookup(name)
+	if !ok {
+		return nil, false
+	}
+	return fromZylo(v), true
+}
+
+// Call llama a la función global fnName con args convertidos a Zylo y
+// devuelve el resultado convertido a Go.
+func (i *Interpreter) Call(fnName string, args ...interface{}) (interface{}, error) {
+	return i.CallContext(context.Background(), fnName, args...)
+}
+
+// CallContext es como Call, pero detiene la ejecución con ErrCanceled cuando
+// se cancela ctx.
+func (i *Interpreter) CallContext(ctx context.Context, fnName string, args ...interface{}) (interface{}, error) {
+	fn, ok := i.eval.Lookup(fnName)
+	if !ok {
+		return nil, fmt.Errorf("función no definida: %s", fnName)
+	}
+	zyloArgs := make([]evaluator.Value, len(args))
+	for idx, arg := range args {
+		v, err := toZylo(arg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: argumento %d: %w", fnName, idx+1, err)
+		}
+		zyloArgs[idx] = v
+	}
+	i.eval.SetContext(ctx)
+	result, err := i.eval.Call(fn, zyloArgs)
+	if err != nil {
+		return nil, err
+	}
+	return fromZylo(result), nil
+}
@@ -0,0 +1,200 @@
+package zylo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRunWritesToConfiguredStdout(t *testing.T) {
+	var out bytes.Buffer
+	interp := New(WithStdin(strings.NewReader("Ada\n")), WithStdout(&out))
+
+	err := interp.Run(`
+var name = read.line()
+show.log("hola " + name)
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := out.String(); got != "> hola Ada\n" {
+		t.Errorf("unexpected output: %q", got)
+	}
+}
+
+func TestRegisterFunc(t *testing.T) {
+	interp := New(WithStdout(&bytes.Buffer{}))
+	if err := interp.RegisterFunc("double", func(n int) int { return n * 2 }); err != nil {
+		t.Fatal(err)
+	}
+	if err := interp.RegisterFunc("sum", func(nums ...float64) float64 {
+		total := 0.0
+		for _, n := range nums {
+			total += n
+		}
+		return total
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := interp.RegisterFunc("fail", func(msg string) error { return errors.New(msg) }); err != nil {
+		t.Fatal(err)
+	}
+
+	err := interp.Run(`
+var doubled = double(21)
+var total = sum(1, 2.5, 3)
+var caught = ""
+try {
+    fail("host error")
+} catch (e) {
+    caught = e
+}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectGlobal(t, interp, "doubled", int64(42))
+	expectGlobal(t, interp, "total", 6.5)
+	expectGlobal(t, interp, "caught", "host error")
+}
+
+func TestRegisterFuncRejectsNonFunctions(t *testing.T) {
+	interp := New()
+	if err := interp.RegisterFunc("bad", 42); err == nil {
+		t.Error("expected error registering a non-function")
+	}
+	if err := interp.RegisterFunc("bad", func() (int, int) { return 1, 2 }); err == nil {
+		t.Error("expected error registering a function with two non-error results")
+	}
+}
+
+func TestRegisterFuncArgumentErrors(t *testing.T) {
+	interp := New()
+	if err := interp.RegisterFunc("double", func(n int) int { return n * 2 }); err != nil {
+		t.Fatal(err)
+	}
+
+	err := interp.Run(`double("x")`)
+	var runtimeErr *RuntimeError
+	if !errors.As(err, &runtimeErr) {
+		t.Fatalf("expected *RuntimeError, got %T (%v)", err, err)
+	}
+	if !strings.Contains(runtimeErr.Message, "se esperaba int") {
+		t.Errorf("unexpected message: %q", runtimeErr.Message)
+	}
+}
+
+func TestGlobals(t *testing.T) {
+	interp := New()
+	if err := interp.SetGlobal("config", map[string]interface{}{
+		"name":  "zylo",
+		"ports": []int{80, 443},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := interp.SetGlobal("ports", []uint16{80, 443}); err != nil {
+		t.Fatal(err)
+	}
+	if err := interp.SetGlobal("bad", make(chan int)); err == nil {
+		t.Error("expected error converting a channel")
+	}
+
+	if err := interp.Run(`var first = ports[0] + 1`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectGlobal(t, interp, "first", int64(81))
+	expectGlobal(t, interp, "config", map[string]interface{}{
+		"name":  "zylo",
+		"ports": []interface{}{int64(80), int64(443)},
+	})
+
+	if _, ok := interp.GetGlobal("missing"); ok {
+		t.Error("expected missing global to be reported as undefined")
+	}
+}
+
+func TestCall(t *testing.T) {
+	interp := New()
+	err := interp.Run(`
+func greet(name, times) {
+    return [name + "!", times * 2]
+}
+func nothing() {
+    var x = 1
+}
+func explode() {
+    throw "boom"
+}
+async func later(x) {
+    return x + 1
+}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, err := interp.Call("greet", "hi", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(result, []interface{}{"hi!", int64(4)}) {
+		t.Errorf("unexpected result: %#v", result)
+	}
+
+	result, err = interp.Call("nothing")
+	if err != nil || result != nil {
+		t.Errorf("expected nil result, got %#v (%v)", result, err)
+	}
+
+	// Las funciones async se esperan antes de devolver el resultado
+	result, err = interp.Call("later", 41)
+	if err != nil || result != int64(42) {
+		t.Errorf("expected 42, got %#v (%v)", result, err)
+	}
+
+	if _, err := interp.Call("explode"); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("expected thrown exception, got %v", err)
+	}
+	if _, err := interp.Call("missing"); err == nil {
+		t.Error("expected error calling an undefined function")
+	}
+}
+
+func TestParseError(t *testing.T) {
+	err := New().Run(`var = 1`)
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || len(parseErr.Errors) == 0 {
+		t.Fatalf("expected *ParseError, got %T (%v)", err, err)
+	}
+}
+
+func expectGlobal(t *testing.T, interp *Interpreter, name string, expected interface{}) {
+	t.Helper()
+	value, ok := interp.GetGlobal(name)
+	if !ok {
+		t.Fatalf("global %q not defined", name)
+	}
+	if !reflect.DeepEqual(value, expected) {
+		t.Errorf("%s: expected %#v, got %#v", name, expected, value)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	interp := New(WithLimits(Limits{MaxSteps: 10000}))
+	if err := interp.Run("func spin() {\n    while true {\n    }\n}"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := interp.Call("spin"); !errors.Is(err, ErrStepLimit) {
+		t.Errorf("expected ErrStepLimit, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := New().RunContext(ctx, "while true {\n}"); !errors.Is(err, ErrCanceled) {
+		t.Errorf("expected ErrCanceled, got %v", err)
+	}
+}